@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/adapters/audit/chain"
+	auditfile "github.com/haukened/kamini/internal/adapters/audit/file"
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+type keySource struct{ k crypto.Signer }
+
+func (k keySource) Load(context.Context) (crypto.Signer, error) { return k.k, nil }
+
+// writeLog writes n chained events to dir/audit.jsonl and the CA public key to dir/ca.pub.
+func writeLog(t *testing.T, dir string, n int) (logPath, pubPath string) {
+	t.Helper()
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	pub, _ := sshx.NewPublicKey(priv.Public())
+	pubPath = filepath.Join(dir, "ca.pub")
+	if err := os.WriteFile(pubPath, sshx.MarshalAuthorizedKey(pub), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logPath = filepath.Join(dir, "audit.jsonl")
+	f, err := auditfile.New(auditfile.Config{Path: logPath, Fsync: auditfile.FsyncNever}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := chain.New(f, chain.Config{CheckpointEvery: 10}, nil, keySource{priv}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0).UTC()
+	for i := 1; i <= n; i++ {
+		ev := domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "sub"}, []string{"alice"},
+			uint64(i), now, now.Add(time.Hour), domain.SignContext{Now: now}, nil)
+		if err := s.Write(context.Background(), ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return logPath, pubPath
+}
+
+func run(args ...string) (string, error) {
+	var out bytes.Buffer
+	app := newApp()
+	app.Writer = &out
+	app.ErrWriter = &out
+	err := app.Run(context.Background(), append([]string{"kamini-server"}, args...))
+	return out.String(), err
+}
+
+func TestAuditVerify(t *testing.T) {
+	logPath, pubPath := writeLog(t, t.TempDir(), 3)
+
+	out, err := run("audit", "verify", "--ca-pub", pubPath, logPath)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !strings.HasPrefix(out, "ok: 3 events, 1 checkpoints") || strings.Contains(out, "warning") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	data, _ := os.ReadFile(logPath)
+	if err := os.WriteFile(logPath, bytes.Replace(data, []byte(`"serial":2`), []byte(`"serial":9`), 1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = run("audit", "verify", "--ca-pub", pubPath, logPath)
+	if err == nil || !strings.Contains(err.Error(), "audit.jsonl:3: seq 3: prev hash mismatch") {
+		t.Fatalf("expected broken link at line 3, got %v", err)
+	}
+}
+
+func TestAuditVerify_Args(t *testing.T) {
+	if _, err := run("audit", "verify"); err == nil {
+		t.Fatalf("expected missing file error")
+	}
+	logPath, _ := writeLog(t, t.TempDir(), 1)
+	out, err := run("audit", "verify", "--rotated", logPath)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !strings.Contains(out, "use --ca-pub") {
+		t.Fatalf("expected untrusted-key warning, got %q", out)
+	}
+}
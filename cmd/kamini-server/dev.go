@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/haukened/kamini/internal/adapters/auth"
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func devCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "dev",
+		Usage: "development helpers (never use in production)",
+		Commands: []*cli.Command{
+			{
+				Name:  "token",
+				Usage: "mint a token accepted by a server with auth.mode: dev",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "config", Usage: "server config file; its auth.dev.key_path is used"},
+					&cli.StringFlag{Name: "key", Usage: "dev signing key (default: auth.dev.key_path); created if missing"},
+					&cli.StringFlag{Name: "sub", Usage: "subject", Required: true},
+					&cli.StringFlag{Name: "username", Usage: "preferred_username (default: --sub)"},
+					&cli.StringFlag{Name: "email", Usage: "email"},
+					&cli.StringSliceFlag{Name: "groups", Usage: "groups (repeat or comma-separate)"},
+					&cli.StringSliceFlag{Name: "roles", Usage: "roles (repeat or comma-separate)"},
+					&cli.DurationFlag{Name: "ttl", Usage: "token lifetime", Value: time.Hour},
+				},
+				Action: devToken,
+			},
+		},
+	}
+}
+
+func devToken(ctx context.Context, cmd *cli.Command) error {
+	keyPath := cmd.String("key")
+	if keyPath == "" {
+		cfg, err := config.Load(cmd.String("config"))
+		if err != nil {
+			return err
+		}
+		keyPath = cfg.Auth.Dev.KeyPath
+	}
+	if keyPath == "" {
+		return errors.New("no dev key path (--key or auth.dev.key_path)")
+	}
+	key, err := auth.LoadOrCreateDevKey(keyPath)
+	if err != nil {
+		return err
+	}
+	tok, err := auth.MintDevToken(key, auth.DevToken{
+		Subject:  cmd.String("sub"),
+		Username: cmd.String("username"),
+		Email:    cmd.String("email"),
+		Groups:   cmd.StringSlice("groups"),
+		Roles:    cmd.StringSlice("roles"),
+		TTL:      cmd.Duration("ttl"),
+	}, domain.SystemClock().Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.Root().ErrWriter, "warning: development token; only servers with auth.mode: dev accept it")
+	fmt.Fprintln(cmd.Root().Writer, tok)
+	return nil
+}
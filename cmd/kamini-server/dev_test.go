@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/haukened/kamini/internal/adapters/auth"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestDevToken(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "dev.key")
+	out, err := run("dev", "token", "--key", key, "--sub", "alice", "--groups", "ssh-users,wheel")
+	if err != nil {
+		t.Fatalf("dev token: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.Contains(lines[0], "development token") {
+		t.Fatalf("expected warning, got %q", out)
+	}
+
+	// A dev-mode server with the same key accepts it.
+	a, err := auth.NewDevAuthenticator(context.Background(), auth.DevAuthConfig{KeyPath: key}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := a.Authenticate(context.Background(), lines[len(lines)-1])
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if id.Username != "alice" || !slices.Equal(id.Groups, []string{"ssh-users", "wheel"}) {
+		t.Fatalf("identity=%+v", id)
+	}
+
+	if _, err := run("dev", "token", "--key", key); err == nil {
+		t.Fatalf("expected missing --sub error")
+	}
+}
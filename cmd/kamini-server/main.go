@@ -0,0 +1,28 @@
+// Command kamini-server runs and administers the Kamini SSH CA server.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+)
+
+func main() {
+	if err := newApp().Run(context.Background(), os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, "kamini-server:", err)
+		os.Exit(1)
+	}
+}
+
+func newApp() *cli.Command {
+	return &cli.Command{
+		Name:  "kamini-server",
+		Usage: "Kamini SSH certificate authority server",
+		Commands: []*cli.Command{
+			auditCommand(),
+			devCommand(),
+		},
+	}
+}
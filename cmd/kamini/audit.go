@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func auditCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "audit",
+		Usage: "search the server's audit log (admin)",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "list audit events, newest first",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "subject", Usage: "identity subject (OIDC sub)"},
+					&cli.StringFlag{Name: "principal", Usage: "events whose principals include this one"},
+					&cli.Uint64Flag{Name: "serial", Usage: "certificate serial"},
+					&cli.StringFlag{Name: "action", Usage: "ISSUE_USER_CERT, REVOKE_CERT, BLOCKLIST_ADD, ..."},
+					&cli.StringFlag{Name: "outcome", Usage: "success or failure"},
+					&cli.StringFlag{Name: "since", Usage: "RFC 3339 time or a duration ago (e.g. 24h)"},
+					&cli.StringFlag{Name: "until", Usage: "RFC 3339 time or a duration ago"},
+					&cli.IntFlag{Name: "limit", Value: 50, Usage: "events per page"},
+					&cli.Uint64Flag{Name: "cursor", Usage: "continue from a previous page"},
+					&cli.BoolFlag{Name: "all", Usage: "follow pages until the end"},
+					&cli.BoolFlag{Name: "json", Usage: "print one JSON record per line"},
+				},
+				Action: auditList,
+			},
+			{
+				Name:      "show",
+				Usage:     "show every event for a certificate serial, oldest first",
+				ArgsUsage: "<serial>",
+				Action:    auditShow,
+			},
+		},
+	}
+}
+
+func auditList(ctx context.Context, cmd *cli.Command) error {
+	c, err := apiClient(cmd)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	since, err := parseWhen(cmd.String("since"), now)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	until, err := parseWhen(cmd.String("until"), now)
+	if err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+	q := domain.AuditQuery{
+		Subject:   cmd.String("subject"),
+		Principal: cmd.String("principal"),
+		Serial:    cmd.Uint64("serial"),
+		Action:    domain.AuditAction(strings.ToUpper(cmd.String("action"))),
+		Outcome:   domain.AuditOutcome(strings.ToLower(cmd.String("outcome"))),
+		Since:     since,
+		Until:     until,
+		Before:    cmd.Uint64("cursor"),
+		Limit:     cmd.Int("limit"),
+	}
+	w := cmd.Root().Writer
+	asJSON := cmd.Bool("json")
+	for first := true; ; first = false {
+		page, err := c.ListAudit(ctx, q)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			if err := printAuditJSON(w, page.Records); err != nil {
+				return err
+			}
+		} else {
+			printAuditTable(w, page.Records, first)
+		}
+		if page.Next == 0 {
+			return nil
+		}
+		if !cmd.Bool("all") {
+			if !asJSON {
+				fmt.Fprintf(w, "more results: --cursor %d\n", page.Next)
+			}
+			return nil
+		}
+		q.Before = page.Next
+	}
+}
+
+func auditShow(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("usage: kamini audit show <serial>")
+	}
+	serial, err := strconv.ParseUint(cmd.Args().First(), 10, 64)
+	if err != nil || serial == 0 {
+		return fmt.Errorf("invalid serial %q", cmd.Args().First())
+	}
+	c, err := apiClient(cmd)
+	if err != nil {
+		return err
+	}
+	var recs []domain.AuditRecord
+	q := domain.AuditQuery{Serial: serial, Limit: 500}
+	for {
+		page, err := c.ListAudit(ctx, q)
+		if err != nil {
+			return err
+		}
+		recs = append(recs, page.Records...)
+		if page.Next == 0 {
+			break
+		}
+		q.Before = page.Next
+	}
+	if len(recs) == 0 {
+		return fmt.Errorf("no audit events for serial %d", serial)
+	}
+	slices.Reverse(recs)
+	w := cmd.Root().Writer
+	for i, r := range recs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		printAuditDetail(w, r)
+	}
+	return nil
+}
+
+// parseWhen accepts an RFC 3339 time or a duration before now.
+func parseWhen(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want an RFC 3339 time or a duration, got %q", s)
+	}
+	return t, nil
+}
+
+func outcome(r domain.AuditRecord) string {
+	if r.Success() {
+		return "ok"
+	}
+	return string(r.ErrorCode)
+}
+
+func printAuditTable(w io.Writer, recs []domain.AuditRecord, header bool) {
+	if len(recs) == 0 {
+		if header {
+			fmt.Fprintln(w, "no audit events")
+		}
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if header {
+		fmt.Fprintln(tw, "ID\tTIME\tACTION\tOUTCOME\tSUBJECT\tSERIAL\tPRINCIPALS")
+	}
+	for _, r := range recs {
+		serial := ""
+		if r.Serial != nil {
+			serial = strconv.FormatUint(*r.Serial, 10)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Time.UTC().Format(time.RFC3339), r.Action, outcome(r),
+			r.Subject, serial, strings.Join(r.Principals, ","))
+	}
+	_ = tw.Flush()
+}
+
+func printAuditJSON(w io.Writer, recs []domain.AuditRecord) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, r := range recs {
+		if err := enc.Encode(struct {
+			ID uint64 `json:"id"`
+			auditjson.Record
+		}{r.ID, auditjson.FromEvent(r.AuditEvent)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printAuditDetail(w io.Writer, r domain.AuditRecord) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	line := func(k, v string) {
+		if v != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", k, v)
+		}
+	}
+	ts := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	line("time", r.Time.UTC().Format(time.RFC3339))
+	line("action", string(r.Action))
+	line("stage", string(r.Stage))
+	line("outcome", outcome(r))
+	line("error", r.ErrorMessage)
+	line("subject", r.Subject)
+	line("principals", strings.Join(r.Principals, ", "))
+	line("not_before", ts(r.NotBefore))
+	line("not_after", ts(r.NotAfter))
+	line("key_fp", r.KeyFP)
+	line("key_id", r.KeyID)
+	line("source_ip", r.SourceIP)
+	line("trace_id", r.TraceID)
+	keys := make([]string, 0, len(r.Attrs))
+	for k := range r.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		line(k, r.Attrs[k])
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// auditServer serves two pages for serial 42: the revocation, then the issuance.
+func auditServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte(`{"events":[{"id":9,"time":"2025-01-02T04:00:00Z","action":"REVOKE_CERT","stage":"REVOKE","success":true,"subject":"alice","principals":["alice"],"serial":42,"attrs":{"reason":"laptop stolen"}}],"next_cursor":"9"}`))
+		case "9":
+			_, _ = w.Write([]byte(`{"events":[{"id":3,"time":"2025-01-02T03:04:05Z","action":"ISSUE_USER_CERT","stage":"SIGN","success":true,"subject":"alice","principals":["alice"],"serial":42,"source_ip":"10.0.0.1"}]}`))
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+		}
+	}))
+}
+
+func TestAuditList(t *testing.T) {
+	srv := auditServer(t)
+	defer srv.Close()
+
+	var out bytes.Buffer
+	app := newApp()
+	app.Writer = &out
+	if err := app.Run(context.Background(), []string{"kamini", "--server", srv.URL, "audit", "list", "--serial", "42"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if s := out.String(); !strings.Contains(s, "REVOKE_CERT") || strings.Contains(s, "ISSUE_USER_CERT") || !strings.Contains(s, "--cursor 9") {
+		t.Fatalf("unexpected first page output:\n%s", s)
+	}
+
+	out.Reset()
+	app = newApp()
+	app.Writer = &out
+	if err := app.Run(context.Background(), []string{"kamini", "--server", srv.URL, "audit", "list", "--all", "--json"}); err != nil {
+		t.Fatalf("run --all: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], `{"id":3,`) {
+		t.Fatalf("unexpected --all --json output:\n%s", out.String())
+	}
+}
+
+func TestAuditShow(t *testing.T) {
+	srv := auditServer(t)
+	defer srv.Close()
+
+	var out bytes.Buffer
+	app := newApp()
+	app.Writer = &out
+	if err := app.Run(context.Background(), []string{"kamini", "--server", srv.URL, "audit", "show", "42"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	s := out.String()
+	issue, revoke := strings.Index(s, "ISSUE_USER_CERT"), strings.Index(s, "REVOKE_CERT")
+	if issue < 0 || revoke < issue || !strings.Contains(s, "laptop stolen") || !strings.Contains(s, "10.0.0.1") {
+		t.Fatalf("unexpected show output:\n%s", s)
+	}
+}
+
+func TestParseWhen(t *testing.T) {
+	now := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	if got, _ := parseWhen("24h", now); !got.Equal(now.Add(-24 * time.Hour)) {
+		t.Fatalf("duration: %v", got)
+	}
+	if got, _ := parseWhen("2025-01-01T12:00:00Z", now); got.Hour() != 12 {
+		t.Fatalf("rfc3339: %v", got)
+	}
+	if _, err := parseWhen("yesterday", now); err == nil {
+		t.Fatalf("expected error")
+	}
+}
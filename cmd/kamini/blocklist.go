@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func blocklistCommand() *cli.Command {
+	target := []cli.Flag{
+		&cli.StringFlag{Name: "key", Usage: "SHA256 key fingerprint or path to a public key file"},
+		&cli.StringFlag{Name: "subject", Usage: "identity subject (OIDC sub)"},
+		&cli.StringFlag{Name: "reason", Usage: "operator note recorded in the audit log"},
+	}
+	return &cli.Command{
+		Name:  "blocklist",
+		Usage: "manage keys and subjects barred from certificate issuance (admin)",
+		Commands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "show blocklist entries",
+				Action: blocklistList,
+			},
+			{
+				Name:   "add",
+				Usage:  "block a key or subject",
+				Flags:  target,
+				Action: blocklistAdd,
+			},
+			{
+				Name:   "remove",
+				Usage:  "lift a block on a key or subject",
+				Flags:  target,
+				Action: blocklistRemove,
+			},
+		},
+	}
+}
+
+func blocklistList(ctx context.Context, cmd *cli.Command) error {
+	c, err := apiClient(cmd)
+	if err != nil {
+		return err
+	}
+	entries, err := c.ListBlocklist(ctx)
+	if err != nil {
+		return err
+	}
+	printBlockEntries(cmd.Root().Writer, entries)
+	return nil
+}
+
+func blocklistAdd(ctx context.Context, cmd *cli.Command) error {
+	c, err := apiClient(cmd)
+	if err != nil {
+		return err
+	}
+	kind, value, err := blockTarget(cmd)
+	if err != nil {
+		return err
+	}
+	e, err := c.AddBlock(ctx, kind, value, cmd.String("reason"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Root().Writer, "blocked: %s %s\n", strings.ToLower(string(e.Kind)), e.Value)
+	return nil
+}
+
+func blocklistRemove(ctx context.Context, cmd *cli.Command) error {
+	c, err := apiClient(cmd)
+	if err != nil {
+		return err
+	}
+	kind, value, err := blockTarget(cmd)
+	if err != nil {
+		return err
+	}
+	e, err := c.RemoveBlock(ctx, kind, value, cmd.String("reason"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Root().Writer, "unblocked: %s %s\n", strings.ToLower(string(e.Kind)), e.Value)
+	return nil
+}
+
+// blockTarget resolves exactly one of --key or --subject. --key accepts a
+// SHA256 fingerprint or a path to an authorized_keys-format public key.
+func blockTarget(cmd *cli.Command) (domain.BlockKind, string, error) {
+	key, subject := cmd.String("key"), cmd.String("subject")
+	switch {
+	case key != "" && subject != "":
+		return "", "", fmt.Errorf("use only one of --key or --subject")
+	case subject != "":
+		return domain.BlockSubject, subject, nil
+	case key == "":
+		return "", "", fmt.Errorf("one of --key or --subject is required")
+	case strings.HasPrefix(key, "SHA256:"):
+		return domain.BlockKey, key, nil
+	}
+	data, err := os.ReadFile(key)
+	if err != nil {
+		return "", "", fmt.Errorf("read public key: %w", err)
+	}
+	fp, err := domain.FingerprintSHA256(string(data))
+	if err != nil {
+		return "", "", err
+	}
+	return domain.BlockKey, fp, nil
+}
+
+func printBlockEntries(w io.Writer, entries []domain.BlockEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "blocklist is empty")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tVALUE\tADDED_BY\tADDED_AT\tREASON")
+	for _, e := range entries {
+		added := ""
+		if !e.Time.IsZero() {
+			added = e.Time.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", strings.ToLower(string(e.Kind)), e.Value, e.AddedBy, added, e.Reason)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPub = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC+r2/7FDwBaKPXDUw7UoDa7RXGrXPD9oXeUHCOmZ3oT test\n"
+
+func TestBlocklistAdd_KeyFile(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(got)
+	}))
+	defer srv.Close()
+
+	pub := filepath.Join(t.TempDir(), "id_ed25519.pub")
+	if err := os.WriteFile(pub, []byte(testPub), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	app := newApp()
+	app.Writer = &out
+	err := app.Run(context.Background(), []string{"kamini", "--server", srv.URL, "--token", "t", "blocklist", "add", "--key", pub, "--reason", "stolen"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got["kind"] != "KEY" || got["value"] != "SHA256:7q/0GrbmHLAri6PXTvduejHcNDqTxixEPm1DESBYX7g" || got["reason"] != "stolen" {
+		t.Fatalf("unexpected request body: %+v", got)
+	}
+	if !strings.Contains(out.String(), "blocked: key SHA256:") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestBlocklistAdd_RequiresTarget(t *testing.T) {
+	app := newApp()
+	app.Writer = &bytes.Buffer{}
+	app.ErrWriter = &bytes.Buffer{}
+	err := app.Run(context.Background(), []string{"kamini", "--server", "http://127.0.0.1:0", "blocklist", "add"})
+	if err == nil || !strings.Contains(err.Error(), "--key or --subject") {
+		t.Fatalf("expected target error, got %v", err)
+	}
+}
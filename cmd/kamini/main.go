@@ -0,0 +1,53 @@
+// Command kamini is the Kamini client CLI.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/haukened/kamini/internal/adapters/httpclient"
+)
+
+func main() {
+	if err := newApp().Run(context.Background(), os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, "kamini:", err)
+		os.Exit(1)
+	}
+}
+
+func newApp() *cli.Command {
+	return &cli.Command{
+		Name:  "kamini",
+		Usage: "short-lived SSH certificates from your identity provider",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "server",
+				Usage:   "Kamini server base URL",
+				Sources: cli.EnvVars("KAMINI_SERVER"),
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "bearer token for admin commands",
+				Sources: cli.EnvVars("KAMINI_TOKEN"),
+			},
+		},
+		Commands: []*cli.Command{
+			tokenCommand(),
+			renewCommand(),
+			blocklistCommand(),
+			auditCommand(),
+		},
+	}
+}
+
+// apiClient builds an API client from the global flags.
+func apiClient(cmd *cli.Command) (*httpclient.Client, error) {
+	server := cmd.String("server")
+	if server == "" {
+		return nil, fmt.Errorf("--server (or KAMINI_SERVER) is required")
+	}
+	return httpclient.New(server, cmd.String("token")), nil
+}
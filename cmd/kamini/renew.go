@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/crypto/ssh"
+)
+
+func renewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "renew",
+		Usage: "renew a valid certificate without logging in to the IdP again",
+		Description: "Proves possession of the certificate's private key and replaces the certificate file.\n" +
+			"Renewal stops at the server's maximum session length since the original login.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "identity", Aliases: []string{"i"}, Usage: "private key file (unencrypted)", Value: defaultIdentity()},
+			&cli.StringFlag{Name: "cert", Usage: "certificate file (default: <identity>-cert.pub)"},
+			&cli.DurationFlag{Name: "ttl", Usage: "requested lifetime (default: server policy)"},
+		},
+		Action: renew,
+	}
+}
+
+func defaultIdentity() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "id_ed25519")
+}
+
+func renew(ctx context.Context, cmd *cli.Command) error {
+	c, err := apiClient(cmd)
+	if err != nil {
+		return err
+	}
+	keyPath := cmd.String("identity")
+	certPath := cmd.String("cert")
+	if certPath == "" {
+		certPath = keyPath + "-cert.pub"
+	}
+	pem, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	signer, err := ssh.ParsePrivateKey(pem)
+	if err != nil {
+		return fmt.Errorf("%s: %w", keyPath, err)
+	}
+	cert, err := os.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	pub := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	out, err := c.RenewUserCert(ctx, strings.TrimSpace(string(cert)), pub, cmd.Duration("ttl"), func(msg []byte) ([]byte, error) {
+		sig, err := signer.Sign(rand.Reader, msg)
+		if err != nil {
+			return nil, err
+		}
+		return ssh.Marshal(sig), nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(certPath, []byte(out.CertificateAuthorizedKey+"\n"), 0o644); err != nil { // #nosec G306 -- certificates are public
+		return err
+	}
+	fmt.Fprintf(cmd.Root().Writer, "renewed: serial %d (was %d), principals %s, valid until %s\n",
+		out.Serial, out.RenewedFrom, strings.Join(out.Principals, ","), out.NotAfter.Local().Format(time.RFC3339))
+	fmt.Fprintf(cmd.Root().Writer, "session ends %s; log in again after that\n", out.SessionExpiresAt.Local().Format(time.RFC3339))
+	return nil
+}
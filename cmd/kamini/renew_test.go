@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func TestRenew_ReplacesCertificate(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	key := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(key, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(key+"-cert.pub", []byte("old-cert\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sshPub, _ := ssh.NewPublicKey(pub)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Certificate string `json:"certificate"`
+			PublicKey   string `json:"public_key"`
+			Timestamp   int64  `json:"timestamp"`
+			Signature   string `json:"signature"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		raw, _ := base64.StdEncoding.DecodeString(req.Signature)
+		var sig ssh.Signature
+		msg := domain.RenewalChallenge{PublicKeyAuthorized: req.PublicKey, Time: time.Unix(req.Timestamp, 0)}.Bytes()
+		if r.URL.Path != "/v1/certs/user/renew" || r.Header.Get("Authorization") != "" || req.Certificate != "old-cert" ||
+			ssh.Unmarshal(raw, &sig) != nil || sshPub.Verify(msg, &sig) != nil {
+			t.Errorf("unexpected request %s %+v", r.URL.Path, req)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"certificate_authorized_key": "new-cert", "serial": 8, "renewed_from": 7,
+			"principals": []string{"alice"}, "not_after": 1_700_003_600, "session_expires_at": 1_700_010_000,
+		})
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	app := newApp()
+	app.Writer = &out
+	if err := app.Run(context.Background(), []string{"kamini", "--server", srv.URL, "renew", "-i", key}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if b, _ := os.ReadFile(key + "-cert.pub"); string(b) != "new-cert\n" {
+		t.Fatalf("certificate file = %q", b)
+	}
+	if !strings.Contains(out.String(), "renewed: serial 8 (was 7), principals alice") || !strings.Contains(out.String(), "session ends") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// openBrowser opens u in the user's browser; tests replace it.
+var openBrowser = func(u string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", u).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", u).Start()
+	default:
+		return exec.Command("xdg-open", u).Start()
+	}
+}
+
+func tokenCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "token",
+		Usage: "log in through the server's SAML IdP and print a session token",
+		Description: "Opens the IdP login in a browser; the server hands the token to a listener on 127.0.0.1.\n" +
+			"Use it as KAMINI_TOKEN. It expires after a few minutes (auth.saml.session_ttl).",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{Name: "timeout", Usage: "how long to wait for the login", Value: 5 * time.Minute},
+			&cli.BoolFlag{Name: "no-browser", Usage: "only print the login URL"},
+		},
+		Action: token,
+	}
+}
+
+func token(ctx context.Context, cmd *cli.Command) error {
+	server := cmd.String("server")
+	if server == "" {
+		return fmt.Errorf("--server (or KAMINI_SERVER) is required")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	state := base64.RawURLEncoding.EncodeToString(b)
+
+	tokens := make(chan string, 1)
+	srv := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if r.URL.Path != "/callback" || q.Get("state") != state || q.Get("token") == "" {
+				http.Error(w, "unexpected callback", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintln(w, "Logged in to Kamini; you can close this window.")
+			select {
+			case tokens <- q.Get("token"):
+			default:
+			}
+		}),
+	}
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	login := strings.TrimSuffix(server, "/") + "/v1/saml/login?" + url.Values{
+		"redirect_uri": {"http://" + ln.Addr().String() + "/callback"},
+		"state":        {state},
+	}.Encode()
+	fmt.Fprintf(cmd.Root().ErrWriter, "log in at %s\n", login)
+	if !cmd.Bool("no-browser") {
+		if err := openBrowser(login); err != nil {
+			fmt.Fprintf(cmd.Root().ErrWriter, "cannot open a browser (%v); open the URL yourself\n", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cmd.Duration("timeout"))
+	defer cancel()
+	select {
+	case t := <-tokens:
+		fmt.Fprintln(cmd.Root().Writer, t)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("no login completed within %s", cmd.Duration("timeout"))
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToken_ReceivesCallback(t *testing.T) {
+	// The "browser" follows the login straight to the callback, as the server
+	// would after the IdP login.
+	orig, opened := openBrowser, ""
+	t.Cleanup(func() { openBrowser = orig })
+	openBrowser = func(u string) error {
+		opened = u
+		login, err := url.Parse(u)
+		if err != nil || login.Path != "/v1/saml/login" {
+			t.Errorf("login URL %q", u)
+			return nil
+		}
+		q := login.Query()
+		cb, _ := url.Parse(q.Get("redirect_uri"))
+		if cb.Hostname() != "127.0.0.1" {
+			t.Errorf("redirect_uri %q", cb)
+		}
+		// A callback without the state is ignored.
+		resp, err := http.Get(cb.String() + "?token=forged&state=other")
+		if err != nil || resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("callback without state: %v %v", resp, err)
+		} else {
+			resp.Body.Close()
+		}
+		go func() {
+			resp, err := http.Get(cb.String() + "?" + url.Values{"token": {"session-token"}, "state": {q.Get("state")}}.Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	var out, errOut bytes.Buffer
+	app := newApp()
+	app.Writer, app.ErrWriter = &out, &errOut
+	if err := app.Run(context.Background(), []string{"kamini", "--server", "https://kamini.example.com/", "token"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if out.String() != "session-token\n" || !strings.HasPrefix(opened, "https://kamini.example.com/v1/saml/login?") || !strings.Contains(errOut.String(), opened) {
+		t.Fatalf("out=%q err=%q", out.String(), errOut.String())
+	}
+}
+
+func TestToken_Timeout(t *testing.T) {
+	app := newApp()
+	app.ErrWriter = &bytes.Buffer{}
+	start := time.Now()
+	err := app.Run(context.Background(), []string{"kamini", "--server", "https://kamini.example.com", "token", "--no-browser", "--timeout", "50ms"})
+	if err == nil || !strings.Contains(err.Error(), "no login") || time.Since(start) > 5*time.Second {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
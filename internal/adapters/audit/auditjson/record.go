@@ -0,0 +1,107 @@
+// Package auditjson defines the canonical JSON encoding of domain.AuditEvent
+// shared by audit sinks and readers. Field names are part of the on-disk and
+// on-the-wire format; do not rename them.
+package auditjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// Record is the serialized form of a domain.AuditEvent. Times are UTC; optional
+// fields are omitted when empty. Attrs keys are emitted sorted (encoding/json),
+// so Marshal is deterministic for a given event.
+type Record struct {
+	Time         time.Time         `json:"time"`
+	Action       string            `json:"action"`
+	Stage        string            `json:"stage"`
+	Success      bool              `json:"success"`
+	TraceID      string            `json:"trace_id,omitempty"`
+	Subject      string            `json:"subject,omitempty"`
+	Principals   []string          `json:"principals,omitempty"`
+	Serial       *uint64           `json:"serial,omitempty"`
+	NotBefore    *time.Time        `json:"not_before,omitempty"`
+	NotAfter     *time.Time        `json:"not_after,omitempty"`
+	KeyFP        string            `json:"key_fp,omitempty"`
+	KeyID        string            `json:"key_id,omitempty"`
+	SourceIP     string            `json:"source_ip,omitempty"`
+	ErrorCode    string            `json:"error_code,omitempty"`
+	ErrorMessage string            `json:"error_message,omitempty"`
+	Attrs        map[string]string `json:"attrs,omitempty"`
+}
+
+// FromEvent converts an event to its canonical record.
+func FromEvent(ev domain.AuditEvent) Record {
+	r := Record{
+		Time:         ev.Time.UTC(),
+		Action:       string(ev.Action),
+		Stage:        string(ev.Stage),
+		Success:      ev.Success(),
+		TraceID:      ev.TraceID,
+		Subject:      ev.Subject,
+		Principals:   ev.Principals,
+		Serial:       ev.Serial,
+		KeyFP:        ev.KeyFP,
+		KeyID:        ev.KeyID,
+		SourceIP:     ev.SourceIP,
+		ErrorCode:    string(ev.ErrorCode),
+		ErrorMessage: ev.ErrorMessage,
+		Attrs:        ev.Attrs,
+	}
+	if ev.NotBefore != nil {
+		t := ev.NotBefore.UTC()
+		r.NotBefore = &t
+	}
+	if ev.NotAfter != nil {
+		t := ev.NotAfter.UTC()
+		r.NotAfter = &t
+	}
+	return r
+}
+
+// Event converts a record back into a domain event.
+func (r Record) Event() domain.AuditEvent {
+	return domain.AuditEvent{
+		Time:         r.Time,
+		Action:       domain.AuditAction(r.Action),
+		Stage:        domain.AuditStage(r.Stage),
+		TraceID:      r.TraceID,
+		Subject:      r.Subject,
+		Principals:   r.Principals,
+		Serial:       r.Serial,
+		NotBefore:    r.NotBefore,
+		NotAfter:     r.NotAfter,
+		KeyFP:        r.KeyFP,
+		KeyID:        r.KeyID,
+		SourceIP:     r.SourceIP,
+		ErrorCode:    domain.ErrorCode(r.ErrorCode),
+		ErrorMessage: r.ErrorMessage,
+		Attrs:        r.Attrs,
+	}
+}
+
+// Marshal returns the canonical single-line JSON encoding of ev (no trailing newline).
+func Marshal(ev domain.AuditEvent) ([]byte, error) {
+	return MarshalRecord(FromEvent(ev))
+}
+
+// MarshalRecord encodes r without HTML escaping so values round-trip byte-for-byte.
+func MarshalRecord(r Record) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Unmarshal decodes one canonical line.
+func Unmarshal(line []byte) (Record, error) {
+	var r Record
+	err := json.Unmarshal(line, &r)
+	return r, err
+}
@@ -0,0 +1,53 @@
+package auditjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	ctx := domain.SignContext{Now: now, SourceIP: "10.0.0.1", TraceID: "t-1"}
+	id := domain.Identity{Subject: "sub"}
+	ev := domain.NewAuditSuccess(domain.ActionIssueUserCert, id, []string{"alice"}, 42, now, now.Add(time.Hour), ctx,
+		map[string]string{"z": "1", "a": "<2>"})
+
+	b, err := Marshal(ev)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	s := string(b)
+	if strings.Contains(s, "\n") || !strings.Contains(s, `"a":"<2>","z":"1"`) {
+		t.Fatalf("unexpected encoding: %s", s)
+	}
+	again, _ := Marshal(ev)
+	if string(again) != s {
+		t.Fatalf("encoding is not deterministic")
+	}
+	r, err := Unmarshal(b)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !r.Success || !reflect.DeepEqual(r.Event(), ev) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", r.Event(), ev)
+	}
+}
+
+func TestMarshal_Failure(t *testing.T) {
+	ctx := domain.SignContext{Now: time.Unix(1, 0).UTC()}
+	ev := domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, domain.Identity{}, nil, ctx, domain.ErrPolicyDenied, nil)
+	b, err := Marshal(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"success":false`) || !strings.Contains(string(b), `"error_code":"POLICY_DENIED"`) {
+		t.Fatalf("unexpected encoding: %s", b)
+	}
+	if strings.Contains(string(b), "serial") {
+		t.Fatalf("failure must omit serial: %s", b)
+	}
+}
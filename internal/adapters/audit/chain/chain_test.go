@@ -0,0 +1,256 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+type memWriter struct{ lines [][]byte }
+
+func (m *memWriter) WriteLine(ctx context.Context, line []byte) error {
+	m.lines = append(m.lines, append([]byte(nil), line...))
+	return nil
+}
+
+func (m *memWriter) text() string {
+	var b bytes.Buffer
+	for _, l := range m.lines {
+		b.Write(l)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+type keySource struct{ k crypto.Signer }
+
+func (k keySource) Load(context.Context) (crypto.Signer, error) { return k.k, nil }
+
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+func newKey(t *testing.T) (crypto.Signer, sshx.PublicKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := sshx.NewPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv, pub
+}
+
+func event(serial uint64) domain.AuditEvent {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	ctx := domain.SignContext{Now: now, TraceID: "t"}
+	return domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "sub"}, []string{"alice"}, serial, now, now.Add(time.Hour), ctx, nil)
+}
+
+// writeChain writes n events with a checkpoint every 2 and a final seal on Close.
+func writeChain(t *testing.T, priv crypto.Signer, n int) *memWriter {
+	t.Helper()
+	w := &memWriter{}
+	s, err := New(w, Config{CheckpointEvery: 2}, nil, keySource{priv}, fixedClock{time.Unix(1_700_000_000, 0)}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= n; i++ {
+		if err := s.Write(context.Background(), event(uint64(i))); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return w
+}
+
+func verify(t *testing.T, trusted sshx.PublicKey, text string) (Report, *BrokenLink) {
+	t.Helper()
+	v := NewVerifier(trusted)
+	err := v.Feed("audit.jsonl", strings.NewReader(text))
+	var bl *BrokenLink
+	if err != nil && !errors.As(err, &bl) {
+		t.Fatalf("unexpected error type: %v", err)
+	}
+	return v.Report(), bl
+}
+
+func TestChain_VerifyIntact(t *testing.T) {
+	priv, pub := newKey(t)
+	w := writeChain(t, priv, 5)
+	// 5 events + checkpoints after 2, 4 and the final seal.
+	if len(w.lines) != 8 {
+		t.Fatalf("lines = %d, want 8", len(w.lines))
+	}
+	rep, bl := verify(t, pub, w.text())
+	if bl != nil {
+		t.Fatalf("intact chain reported broken: %v", bl)
+	}
+	if rep.Events != 5 || rep.Checkpoints != 3 || rep.FirstSeq != 1 || rep.LastSeq != 8 || rep.Unsealed != 0 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if rep.KeyFP != sshx.FingerprintSHA256(pub) {
+		t.Fatalf("KeyFP = %q", rep.KeyFP)
+	}
+}
+
+func TestChain_DetectsTampering(t *testing.T) {
+	priv, pub := newKey(t)
+	_, other := newKey(t)
+	w := writeChain(t, priv, 5)
+	lines := strings.Split(strings.TrimSuffix(w.text(), "\n"), "\n")
+	join := func(ls []string) string { return strings.Join(ls, "\n") + "\n" }
+
+	edited := append([]string(nil), lines...)
+	edited[1] = strings.Replace(edited[1], `"alice"`, `"mallory"`, 1)
+
+	removed := append(append([]string(nil), lines[:3]...), lines[4:]...)
+
+	rebased := append([]string(nil), lines...)
+	rebased[0] = strings.Replace(rebased[0], Genesis, "1"+Genesis[1:], 1)
+
+	swapped := append([]string(nil), lines...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+
+	cases := []struct {
+		name    string
+		text    string
+		trusted sshx.PublicKey
+		line    int
+		reason  string
+	}{
+		{"edited event", join(edited), pub, 3, "prev hash mismatch"},
+		{"removed line", join(removed), pub, 4, "sequence gap"},
+		{"bad genesis", join(rebased), pub, 1, "genesis"},
+		{"reordered", join(swapped), pub, 2, "sequence gap"},
+		{"untrusted key", w.text(), other, 3, "untrusted key"},
+		{"garbage", "not json\n", nil, 1, "malformed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, bl := verify(t, tc.trusted, tc.text)
+			if bl == nil {
+				t.Fatalf("expected broken link")
+			}
+			if bl.Line != tc.line || !strings.Contains(bl.Reason, tc.reason) {
+				t.Fatalf("got %v, want line %d containing %q", bl, tc.line, tc.reason)
+			}
+		})
+	}
+}
+
+func TestChain_ForgedCheckpointSignature(t *testing.T) {
+	priv, pub := newKey(t)
+	w := writeChain(t, priv, 2)
+	// Re-point the checkpoint at a different time; the signature no longer matches.
+	forged := strings.Replace(w.text(), `"time":"2023-11-14T22:13:20Z","key"`, `"time":"2023-11-14T22:13:21Z","key"`, 1)
+	if forged == w.text() {
+		t.Fatalf("fixture did not change")
+	}
+	_, bl := verify(t, pub, forged)
+	if bl == nil || !strings.Contains(bl.Reason, "signature invalid") {
+		t.Fatalf("expected signature failure, got %v", bl)
+	}
+}
+
+func TestChain_ResumeAndSegments(t *testing.T) {
+	priv, pub := newKey(t)
+	first := writeChain(t, priv, 3)
+
+	// A restarted sink continues the chain from the last written line.
+	w := &memWriter{}
+	s, err := New(w, Config{}, first.lines[len(first.lines)-1], keySource{priv}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(context.Background(), event(4)); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewVerifier(pub)
+	if err := v.Feed("audit.jsonl.1", strings.NewReader(first.text())); err != nil {
+		t.Fatalf("segment 1: %v", err)
+	}
+	if err := v.Feed("audit.jsonl", strings.NewReader(w.text())); err != nil {
+		t.Fatalf("segment 2: %v", err)
+	}
+	if rep := v.Report(); rep.Events != 4 || rep.Unsealed != 1 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+
+	// Verifying only the second segment (first pruned) is allowed to start mid-chain.
+	rep, bl := verify(t, pub, w.text())
+	if bl != nil || rep.FirstSeq != 6 {
+		t.Fatalf("pruned start: report=%+v broken=%v", rep, bl)
+	}
+
+	if _, err := New(w, Config{}, []byte("garbage"), nil, nil, nil); err == nil {
+		t.Fatalf("expected resume error for malformed last line")
+	}
+}
+
+func TestChain_ResumesFromHeadFile(t *testing.T) {
+	priv, pub := newKey(t)
+	headPath := filepath.Join(t.TempDir(), "audit.jsonl.head")
+	first := &memWriter{}
+	s, err := New(first, Config{HeadPath: headPath}, nil, keySource{priv}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := s.Write(context.Background(), event(uint64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first file was rotated away, so there is no last line to resume from.
+	second := &memWriter{}
+	s, err = New(second, Config{HeadPath: headPath}, nil, keySource{priv}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(context.Background(), event(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewVerifier(pub)
+	if err := v.Feed("audit.jsonl.1", strings.NewReader(first.text())); err != nil {
+		t.Fatalf("segment 1: %v", err)
+	}
+	if err := v.Feed("audit.jsonl", strings.NewReader(second.text())); err != nil {
+		t.Fatalf("segment 2: %v", err)
+	}
+	if rep := v.Report(); rep.Events != 3 || rep.Unsealed != 0 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+
+	if err := os.WriteFile(headPath, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(&memWriter{}, Config{HeadPath: headPath}, nil, nil, nil, nil); err == nil {
+		t.Fatalf("expected error for malformed head file")
+	}
+}
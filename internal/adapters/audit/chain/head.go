@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"fmt"
+	"os"
+)
+
+// head is the chain position recorded in Config.HeadPath after every line, so
+// a restarted sink can link to a file that an external logrotate moved away.
+type head struct {
+	Seq  uint64
+	Hash string
+}
+
+// headLen is the fixed size of an encoded head; every update overwrites it in place.
+const headLen = 20 + 1 + 64 + 1
+
+func (h head) encode() []byte {
+	return fmt.Appendf(nil, "%020d %s\n", h.Seq, h.Hash)
+}
+
+// readHead loads the head at path. A missing file yields the zero head.
+func readHead(path string) (head, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return head{}, nil
+	}
+	if err != nil {
+		return head{}, err
+	}
+	var h head
+	if len(b) != headLen {
+		return head{}, fmt.Errorf("audit chain: malformed head file %s", path)
+	}
+	if _, err := fmt.Sscanf(string(b), "%d %64s\n", &h.Seq, &h.Hash); err != nil || len(h.Hash) != 64 {
+		return head{}, fmt.Errorf("audit chain: malformed head file %s", path)
+	}
+	return h, nil
+}
@@ -0,0 +1,64 @@
+// Package chain implements a tamper-evident audit log: every line carries the
+// SHA-256 of the previous line, and periodic checkpoints are signed by the CA key.
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+)
+
+// Genesis is the prev hash of the first record in a chain.
+var Genesis = strings.Repeat("0", sha256.Size*2)
+
+// Line is one record in the chain. Exactly one of Event or Checkpoint is set.
+// Field names are part of the on-disk format.
+type Line struct {
+	Seq        uint64            `json:"seq"`
+	Prev       string            `json:"prev"`
+	Event      *auditjson.Record `json:"event,omitempty"`
+	Checkpoint *Checkpoint       `json:"checkpoint,omitempty"`
+}
+
+// Checkpoint seals the chain up to Line.Prev with a CA signature over
+// checkpointMessage(seq, prev, time).
+type Checkpoint struct {
+	Time time.Time `json:"time"`
+	Key  string    `json:"key"` // CA public key, authorized_keys format
+	Sig  string    `json:"sig"` // base64 SSH signature (wire format)
+}
+
+// Hash returns the hex SHA-256 of an encoded line (without newline).
+func Hash(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+func encodeLine(l Line) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(l); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func decodeLine(b []byte) (Line, error) {
+	var l Line
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&l)
+	return l, err
+}
+
+// checkpointMessage is the byte string the CA signs for a checkpoint.
+func checkpointMessage(seq uint64, prev string, t time.Time) []byte {
+	return fmt.Appendf(nil, "kamini-audit-checkpoint-v1\nseq=%d\nprev=%s\ntime=%s\n", seq, prev, t.UTC().Format(time.RFC3339Nano))
+}
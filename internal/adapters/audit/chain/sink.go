@@ -0,0 +1,175 @@
+package chain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// LineWriter appends one encoded line. The file audit sink implements it.
+type LineWriter interface {
+	WriteLine(ctx context.Context, line []byte) error
+}
+
+// Config configures the chain sink.
+type Config struct {
+	// CheckpointEvery writes a CA-signed checkpoint after this many events.
+	// 0 disables periodic checkpoints; Close still seals the tail.
+	CheckpointEvery int
+	// HeadPath, if set, records the seq and hash of the last line after every
+	// write. On startup the sink resumes from it when it is ahead of the last
+	// line passed to New, so the chain continues across external log rotation.
+	HeadPath string
+}
+
+// Sink hash-chains audit events onto a LineWriter. It is safe for concurrent use.
+type Sink struct {
+	w     LineWriter
+	cfg   Config
+	keys  usecase.CAKeySource // nil disables checkpoints
+	clock usecase.Clock
+	log   usecase.Logger
+	head  *os.File // nil unless Config.HeadPath is set
+
+	mu        sync.Mutex
+	seq       uint64
+	prev      string
+	sinceSeal int
+}
+
+var _ usecase.AuditSink = (*Sink)(nil)
+
+// New creates a chain sink continuing after last, the most recent line already
+// written (nil for a new chain; see file.LastLine).
+func New(w LineWriter, cfg Config, last []byte, keys usecase.CAKeySource, clk usecase.Clock, l usecase.Logger) (*Sink, error) {
+	if clk == nil {
+		clk = domain.SystemClock()
+	}
+	s := &Sink{w: w, cfg: cfg, keys: keys, clock: clk, log: l, prev: Genesis}
+	if len(last) > 0 {
+		ln, err := decodeLine(last)
+		if err != nil {
+			return nil, fmt.Errorf("audit chain: cannot resume from last line: %w", err)
+		}
+		s.seq, s.prev = ln.Seq, Hash(last)
+		if ln.Event != nil {
+			s.sinceSeal = 1 // unsealed tail from a previous run
+		}
+	}
+	if cfg.HeadPath == "" {
+		return s, nil
+	}
+	h, err := readHead(cfg.HeadPath)
+	if err != nil {
+		return nil, err
+	}
+	if h.Seq > s.seq {
+		// The file holding the last line was rotated away; link to it anyway.
+		s.seq, s.prev, s.sinceSeal = h.Seq, h.Hash, 1
+		if l != nil {
+			l.Info(context.Background(), "audit chain: resuming from head file", "path", cfg.HeadPath, "seq", h.Seq)
+		}
+	}
+	if s.head, err = os.OpenFile(cfg.HeadPath, os.O_RDWR|os.O_CREATE, 0o600); err != nil {
+		return nil, fmt.Errorf("audit chain: open head file: %w", err)
+	}
+	return s, nil
+}
+
+// Write appends ev as the next link, followed by a checkpoint when due.
+func (s *Sink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	rec := auditjson.FromEvent(ev)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append(ctx, Line{Event: &rec}); err != nil {
+		return err
+	}
+	s.sinceSeal++
+	if s.cfg.CheckpointEvery > 0 && s.sinceSeal >= s.cfg.CheckpointEvery {
+		// A missed checkpoint weakens but does not break the chain; never fail the event for it.
+		if err := s.checkpoint(ctx); err != nil && s.log != nil {
+			s.log.Error(ctx, "audit chain: checkpoint failed", "seq", s.seq, "error", err)
+		}
+	}
+	return nil
+}
+
+// Close seals any unsealed events with a final checkpoint and closes the
+// underlying writer when it implements io.Closer.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	var errs []error
+	if s.sinceSeal > 0 {
+		errs = append(errs, s.checkpoint(context.Background()))
+	}
+	s.mu.Unlock()
+	if s.head != nil {
+		errs = append(errs, s.head.Close())
+	}
+	if c, ok := s.w.(io.Closer); ok {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// append links ln to the chain and writes it. Callers hold s.mu.
+func (s *Sink) append(ctx context.Context, ln Line) error {
+	ln.Seq, ln.Prev = s.seq+1, s.prev
+	b, err := encodeLine(ln)
+	if err != nil {
+		return fmt.Errorf("audit chain: encode: %w", err)
+	}
+	if err := s.w.WriteLine(ctx, b); err != nil {
+		return err
+	}
+	s.seq, s.prev = ln.Seq, Hash(b)
+	if s.head != nil {
+		// The line is written; a stale head only matters after an external rotation.
+		if _, err := s.head.WriteAt(head{Seq: s.seq, Hash: s.prev}.encode(), 0); err != nil && s.log != nil {
+			s.log.Warn(ctx, "audit chain: update head file", "seq", s.seq, "error", err)
+		}
+	}
+	return nil
+}
+
+// checkpoint signs the current head. Callers hold s.mu.
+func (s *Sink) checkpoint(ctx context.Context) error {
+	if s.keys == nil {
+		return nil
+	}
+	priv, err := s.keys.Load(ctx)
+	if err != nil {
+		return err
+	}
+	signer, err := sshx.NewSignerFromSigner(priv)
+	if err != nil {
+		return err
+	}
+	now := s.clock.Now().UTC()
+	sig, err := signer.Sign(rand.Reader, checkpointMessage(s.seq+1, s.prev, now))
+	if err != nil {
+		return fmt.Errorf("audit chain: sign checkpoint: %w", err)
+	}
+	cp := &Checkpoint{
+		Time: now,
+		Key:  strings.TrimSpace(string(sshx.MarshalAuthorizedKey(signer.PublicKey()))),
+		Sig:  base64.StdEncoding.EncodeToString(sshx.Marshal(sig)),
+	}
+	if err := s.append(ctx, Line{Checkpoint: cp}); err != nil {
+		return err
+	}
+	s.sinceSeal = 0
+	return nil
+}
@@ -0,0 +1,138 @@
+package chain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	sshx "golang.org/x/crypto/ssh"
+)
+
+// BrokenLink describes the first place where a chain fails verification.
+type BrokenLink struct {
+	Source string // file name or other label passed to Verifier.Feed
+	Line   int    // 1-based line number within Source
+	Seq    uint64
+	Reason string
+}
+
+func (b *BrokenLink) Error() string {
+	return fmt.Sprintf("%s:%d: seq %d: %s", b.Source, b.Line, b.Seq, b.Reason)
+}
+
+// Report summarizes a verified chain.
+type Report struct {
+	Events      int
+	Checkpoints int
+	FirstSeq    uint64 // > 1 when earlier records were pruned
+	LastSeq     uint64
+	Head        string // hash of the last line
+	// Unsealed counts events after the last checkpoint; truncating them would
+	// go unnoticed.
+	Unsealed int
+	// KeyFP is the fingerprint of the checkpoint key (empty without checkpoints).
+	KeyFP string
+}
+
+// Verifier walks one or more chain segments in order.
+type Verifier struct {
+	trusted sshx.PublicKey
+	started bool
+	report  Report
+}
+
+// NewVerifier returns a verifier. When trusted is non-nil, every checkpoint must
+// be signed by that key; otherwise checkpoints are checked against the key they
+// embed, which proves consistency but not origin.
+func NewVerifier(trusted sshx.PublicKey) *Verifier {
+	return &Verifier{trusted: trusted}
+}
+
+// Feed verifies the next segment. It returns a *BrokenLink at the first broken link.
+func (v *Verifier) Feed(source string, r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	n := 0
+	for sc.Scan() {
+		n++
+		raw := sc.Bytes()
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		if err := v.next(raw); err != nil {
+			return &BrokenLink{Source: source, Line: n, Seq: v.report.LastSeq + 1, Reason: err.Error()}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return &BrokenLink{Source: source, Line: n + 1, Seq: v.report.LastSeq + 1, Reason: err.Error()}
+	}
+	return nil
+}
+
+// Report returns the summary of everything fed so far.
+func (v *Verifier) Report() Report { return v.report }
+
+func (v *Verifier) next(raw []byte) error {
+	ln, err := decodeLine(raw)
+	if err != nil {
+		return fmt.Errorf("malformed record: %v", err)
+	}
+	if (ln.Event == nil) == (ln.Checkpoint == nil) {
+		return fmt.Errorf("record must hold exactly one of event or checkpoint")
+	}
+	rep := &v.report
+	if !v.started {
+		// A chain may start mid-way when old segments were pruned; the first
+		// record's prev cannot be checked then.
+		if ln.Seq == 1 && ln.Prev != Genesis {
+			return fmt.Errorf("first record does not start from genesis")
+		}
+		v.started = true
+		rep.FirstSeq = ln.Seq
+	} else {
+		if ln.Seq != rep.LastSeq+1 {
+			return fmt.Errorf("sequence gap: got %d, want %d", ln.Seq, rep.LastSeq+1)
+		}
+		if ln.Prev != rep.Head {
+			return fmt.Errorf("prev hash mismatch: record altered, removed or reordered before this line")
+		}
+	}
+	if ln.Checkpoint != nil {
+		if err := v.checkCheckpoint(ln); err != nil {
+			return err
+		}
+		rep.Checkpoints++
+		rep.Unsealed = 0
+	} else {
+		rep.Events++
+		rep.Unsealed++
+	}
+	rep.LastSeq, rep.Head = ln.Seq, Hash(raw)
+	return nil
+}
+
+func (v *Verifier) checkCheckpoint(ln Line) error {
+	cp := ln.Checkpoint
+	key, _, _, _, err := sshx.ParseAuthorizedKey([]byte(cp.Key))
+	if err != nil {
+		return fmt.Errorf("checkpoint key: %v", err)
+	}
+	if v.trusted != nil && !bytes.Equal(key.Marshal(), v.trusted.Marshal()) {
+		return fmt.Errorf("checkpoint signed by untrusted key %s", sshx.FingerprintSHA256(key))
+	}
+	blob, err := base64.StdEncoding.DecodeString(cp.Sig)
+	if err != nil {
+		return fmt.Errorf("checkpoint signature encoding: %v", err)
+	}
+	var sig sshx.Signature
+	if err := sshx.Unmarshal(blob, &sig); err != nil {
+		return fmt.Errorf("checkpoint signature: %v", err)
+	}
+	if err := key.Verify(checkpointMessage(ln.Seq, ln.Prev, cp.Time), &sig); err != nil {
+		return fmt.Errorf("checkpoint signature invalid")
+	}
+	v.report.KeyFP = sshx.FingerprintSHA256(key)
+	return nil
+}
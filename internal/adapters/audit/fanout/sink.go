@@ -0,0 +1,390 @@
+// Package fanout implements an audit sink that writes every event to several
+// sinks and applies a failure policy.
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// Policy decides what a failed write means for the caller.
+type Policy string
+
+const (
+	// FailClosed returns an error when a required sink fails, so issuance is refused.
+	// Failures of optional sinks are queued for retry.
+	FailClosed Policy = "fail_closed"
+	// FailOpen never returns an error; failed events are queued and retried
+	// in the background, and dropped (and counted) when the queue is full.
+	FailOpen Policy = "fail_open"
+)
+
+// Target is one downstream sink.
+type Target struct {
+	Name     string
+	Sink     usecase.AuditSink
+	Required bool // under FailClosed; when no target is marked, all are required
+}
+
+// Config tunes the failure policy and retry queue.
+type Config struct {
+	Policy           Policy        // default FailClosed
+	QueueSize        int           // per-target retry queue capacity; default 1000
+	RetryInterval    time.Duration // first retry delay; default 1s
+	MaxRetryInterval time.Duration // backoff cap; default 1m
+}
+
+// Observer receives write outcomes, e.g. to export metrics. All methods must be
+// safe for concurrent use.
+type Observer interface {
+	AuditWrite(sink string, err error)
+	AuditQueue(sink string, depth int)
+	AuditDropped(sink string)
+}
+
+// TargetStats is a point-in-time view of one target.
+type TargetStats struct {
+	Name      string
+	Required  bool
+	Healthy   bool // last attempt succeeded
+	Queued    int
+	Written   uint64
+	Failed    uint64
+	Dropped   uint64
+	LastError string
+}
+
+type target struct {
+	Target
+
+	// send serialises delivery to this target, keeping its events in order;
+	// it is held across Sink.Write so a slow target only delays its own events.
+	send sync.Mutex
+
+	// The fields below are guarded by Sink.mu, which is never held across a write.
+	queue     []domain.AuditEvent
+	healthy   bool
+	written   uint64
+	failed    uint64
+	dropped   uint64
+	lastErr   error
+	backoff   time.Duration
+	nextRetry time.Time
+}
+
+// Sink fans out events to its targets. It is safe for concurrent use.
+type Sink struct {
+	cfg Config
+	obs Observer
+	log usecase.Logger
+
+	mu      sync.Mutex
+	targets []*target // configuration order
+	ordered []*target // required targets first, for Write
+	closed  bool
+	writes  sync.WaitGroup // Write calls in flight; Close waits for them
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ usecase.AuditSink = (*Sink)(nil)
+
+// New creates the fan-out sink and starts its retry loop. obs may be nil.
+func New(targets []Target, cfg Config, obs Observer, l usecase.Logger) (*Sink, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("audit fanout: no sinks configured")
+	}
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = FailClosed
+	case FailClosed, FailOpen:
+	default:
+		return nil, fmt.Errorf("audit fanout: unknown policy %q", cfg.Policy)
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = time.Second
+	}
+	if cfg.MaxRetryInterval < cfg.RetryInterval {
+		cfg.MaxRetryInterval = max(time.Minute, cfg.RetryInterval)
+	}
+	anyRequired := false
+	for _, t := range targets {
+		anyRequired = anyRequired || t.Required
+	}
+	s := &Sink{cfg: cfg, obs: obs, log: l, stop: make(chan struct{}), done: make(chan struct{})}
+	for _, t := range targets {
+		if !anyRequired {
+			t.Required = true
+		}
+		s.targets = append(s.targets, &target{Target: t, healthy: true})
+	}
+	s.ordered = slices.Clone(s.targets)
+	slices.SortStableFunc(s.ordered, func(a, b *target) int {
+		switch {
+		case a.Required == b.Required:
+			return 0
+		case a.Required:
+			return -1
+		default:
+			return 1
+		}
+	})
+	go s.retryLoop()
+	return s, nil
+}
+
+// Write delivers ev to every target, required ones first. Under FailClosed it
+// returns the errors of required targets and then skips the optional ones, so
+// they never see an event the caller is about to refuse; everything else that
+// fails is queued for retry. An optional target that is busy gets ev queued
+// rather than delaying the caller.
+func (s *Sink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("audit fanout: sink closed")
+	}
+	s.writes.Add(1)
+	s.mu.Unlock()
+	defer s.writes.Done()
+
+	var errs []error
+	for _, t := range s.ordered {
+		mustSucceed := s.cfg.Policy == FailClosed && t.Required
+		if !mustSucceed && len(errs) > 0 {
+			break
+		}
+		if mustSucceed {
+			t.send.Lock()
+		} else if !t.send.TryLock() {
+			s.enqueue(ctx, t, ev)
+			continue
+		}
+		// Keep per-target order: while a backlog exists, new events join it.
+		if !mustSucceed && s.queued(t) > 0 {
+			t.send.Unlock()
+			s.enqueue(ctx, t, ev)
+			continue
+		}
+		err := s.deliver(ctx, t, ev)
+		t.send.Unlock()
+		if err == nil {
+			continue
+		}
+		if mustSucceed {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Name, err))
+			continue
+		}
+		s.enqueue(ctx, t, ev)
+	}
+	return errors.Join(errs...)
+}
+
+// Check reports readiness: it fails while a required target is failing or any
+// retry queue is full.
+func (s *Sink) Check(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var problems []string
+	for _, t := range s.targets {
+		switch {
+		case t.Required && !t.healthy:
+			problems = append(problems, fmt.Sprintf("%s failing: %v", t.Name, t.lastErr))
+		case len(t.queue) >= s.cfg.QueueSize:
+			problems = append(problems, fmt.Sprintf("%s retry queue full", t.Name))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("audit: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Stats returns per-target counters in configuration order.
+func (s *Sink) Stats() []TargetStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]TargetStats, 0, len(s.targets))
+	for _, t := range s.targets {
+		st := TargetStats{
+			Name: t.Name, Required: t.Required, Healthy: t.healthy, Queued: len(t.queue),
+			Written: t.written, Failed: t.failed, Dropped: t.dropped,
+		}
+		if t.lastErr != nil {
+			st.LastError = t.lastErr.Error()
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// Close stops retrying, waits for writes in flight, makes one last attempt to
+// flush queued events and closes targets that implement io.Closer. Events still
+// queued are reported as dropped.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+	close(s.stop)
+	<-s.done
+	s.writes.Wait()
+
+	ctx := context.Background()
+	var errs []error
+	for _, t := range s.targets {
+		t.send.Lock()
+		s.flush(ctx, t)
+		t.send.Unlock()
+		s.mu.Lock()
+		n := len(t.queue)
+		if n > 0 {
+			t.dropped += uint64(n)
+			t.queue = nil
+		}
+		s.mu.Unlock()
+		if n > 0 {
+			errs = append(errs, fmt.Errorf("audit fanout: %s: %d queued events dropped on close", t.Name, n))
+		}
+		if c, ok := t.Sink.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("audit fanout: close %s: %w", t.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver writes to one target and updates its health. Callers hold t.send.
+func (s *Sink) deliver(ctx context.Context, t *target, ev domain.AuditEvent) error {
+	err := t.Sink.Write(ctx, ev)
+	if s.obs != nil {
+		s.obs.AuditWrite(t.Name, err)
+	}
+	s.mu.Lock()
+	wasHealthy := t.healthy
+	if err != nil {
+		t.healthy, t.lastErr = false, err
+		t.failed++
+	} else {
+		t.healthy, t.lastErr = true, nil
+		t.written++
+	}
+	s.mu.Unlock()
+	switch {
+	case s.log == nil:
+	case err != nil && wasHealthy:
+		s.log.Error(ctx, "audit sink failing", "sink", t.Name, "error", err)
+	case err == nil && !wasHealthy:
+		s.log.Info(ctx, "audit sink recovered", "sink", t.Name)
+	}
+	return err
+}
+
+// queued returns the length of t's retry queue.
+func (s *Sink) queued(t *target) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(t.queue)
+}
+
+// enqueue adds ev to the retry queue, dropping it when full.
+func (s *Sink) enqueue(ctx context.Context, t *target, ev domain.AuditEvent) {
+	s.mu.Lock()
+	full := len(t.queue) >= s.cfg.QueueSize
+	if full {
+		t.dropped++
+	} else {
+		if len(t.queue) == 0 {
+			t.backoff = s.cfg.RetryInterval
+			t.nextRetry = time.Now().Add(t.backoff)
+		}
+		t.queue = append(t.queue, ev)
+	}
+	depth := len(t.queue)
+	s.mu.Unlock()
+
+	if full {
+		if s.obs != nil {
+			s.obs.AuditDropped(t.Name)
+		}
+		if s.log != nil {
+			s.log.Error(ctx, "audit event dropped: retry queue full", "sink", t.Name, "action", ev.Action, "trace_id", ev.TraceID)
+		}
+		return
+	}
+	if s.obs != nil {
+		s.obs.AuditQueue(t.Name, depth)
+	}
+}
+
+// flush delivers queued events in order until one fails. Callers hold t.send,
+// which keeps the head of the queue in place while it is delivered.
+func (s *Sink) flush(ctx context.Context, t *target) bool {
+	for {
+		s.mu.Lock()
+		if len(t.queue) == 0 {
+			t.queue = nil
+			s.mu.Unlock()
+			return true
+		}
+		ev := t.queue[0]
+		s.mu.Unlock()
+		if err := s.deliver(ctx, t, ev); err != nil {
+			return false
+		}
+		s.mu.Lock()
+		t.queue[0] = domain.AuditEvent{}
+		t.queue = t.queue[1:]
+		depth := len(t.queue)
+		s.mu.Unlock()
+		if s.obs != nil {
+			s.obs.AuditQueue(t.Name, depth)
+		}
+	}
+}
+
+func (s *Sink) retryLoop() {
+	defer close(s.done)
+	tick := time.NewTicker(min(s.cfg.RetryInterval, time.Second))
+	defer tick.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-tick.C:
+			for _, t := range s.targets {
+				s.mu.Lock()
+				due := len(t.queue) > 0 && !now.Before(t.nextRetry)
+				s.mu.Unlock()
+				if !due || !t.send.TryLock() {
+					continue // busy targets are retried on the next tick
+				}
+				ok := s.flush(context.Background(), t)
+				t.send.Unlock()
+				if !ok {
+					s.mu.Lock()
+					t.backoff = min(t.backoff*2, s.cfg.MaxRetryInterval)
+					t.nextRetry = now.Add(t.backoff)
+					s.mu.Unlock()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+// flaky records events and fails while down is set.
+type flaky struct {
+	mu     sync.Mutex
+	down   bool
+	events []string
+	closed bool
+}
+
+func (f *flaky) Write(ctx context.Context, ev domain.AuditEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("sink down")
+	}
+	f.events = append(f.events, ev.TraceID)
+	return nil
+}
+
+func (f *flaky) Close() error { f.closed = true; return nil }
+
+func (f *flaky) set(down bool) { f.mu.Lock(); f.down = down; f.mu.Unlock() }
+
+func (f *flaky) got() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.events...)
+}
+
+type countObs struct {
+	mu            sync.Mutex
+	fails, drops  int
+	lastQueueSize int
+}
+
+func (c *countObs) AuditWrite(sink string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.fails++
+	}
+}
+func (c *countObs) AuditQueue(sink string, depth int) {
+	c.mu.Lock()
+	c.lastQueueSize = depth
+	c.mu.Unlock()
+}
+func (c *countObs) AuditDropped(sink string) { c.mu.Lock(); c.drops++; c.mu.Unlock() }
+
+func ev(trace string) domain.AuditEvent {
+	return domain.AuditEvent{Time: time.Unix(1, 0).UTC(), Action: domain.ActionIssueUserCert, TraceID: trace}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFailClosed_RequiredSinkRefuses(t *testing.T) {
+	primary, secondary := &flaky{}, &flaky{}
+	s, err := New([]Target{
+		{Name: "file", Sink: primary, Required: true},
+		{Name: "webhook", Sink: secondary},
+	}, Config{Policy: FailClosed, RetryInterval: 5 * time.Millisecond}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	primary.set(true)
+	err = s.Write(ctx, ev("a"))
+	if err == nil || !strings.Contains(err.Error(), "file: sink down") {
+		t.Fatalf("expected required sink error, got %v", err)
+	}
+	if got := secondary.got(); len(got) != 0 {
+		t.Fatalf("optional sink got %v for a refused event", got)
+	}
+	if err := s.Check(ctx); err == nil || !strings.Contains(err.Error(), "file failing") {
+		t.Fatalf("expected not ready, got %v", err)
+	}
+	primary.set(false)
+
+	// Optional sink failures are queued, not returned.
+	secondary.set(true)
+	if err := s.Write(ctx, ev("b")); err != nil {
+		t.Fatalf("optional sink failure must not fail the write: %v", err)
+	}
+	if err := s.Check(ctx); err != nil {
+		t.Fatalf("optional sink failure must not affect readiness: %v", err)
+	}
+	secondary.set(false)
+	waitFor(t, func() bool { return len(secondary.got()) == 1 })
+	if got := primary.got(); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("primary got %v", got)
+	}
+}
+
+func TestFailOpen_QueuesRetriesInOrder(t *testing.T) {
+	sinkA := &flaky{down: true}
+	obs := &countObs{}
+	s, err := New([]Target{{Name: "a", Sink: sinkA}}, Config{Policy: FailOpen, RetryInterval: 5 * time.Millisecond}, obs, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for _, id := range []string{"1", "2", "3"} {
+		if err := s.Write(context.Background(), ev(id)); err != nil {
+			t.Fatalf("fail-open write returned %v", err)
+		}
+	}
+	if st := s.Stats()[0]; st.Queued != 3 || st.Healthy || st.LastError == "" {
+		t.Fatalf("unexpected stats while down: %+v", st)
+	}
+	// Required (implicitly) and failing → not ready.
+	if err := s.Check(context.Background()); err == nil {
+		t.Fatalf("expected not ready while failing")
+	}
+	sinkA.set(false)
+	waitFor(t, func() bool { return len(sinkA.got()) == 3 })
+	if got := sinkA.got(); strings.Join(got, "") != "123" {
+		t.Fatalf("retry order = %v", got)
+	}
+	if st := s.Stats()[0]; st.Queued != 0 || !st.Healthy || st.Written != 3 {
+		t.Fatalf("unexpected stats after recovery: %+v", st)
+	}
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if obs.fails == 0 || obs.lastQueueSize != 0 {
+		t.Fatalf("observer not notified: %+v", obs)
+	}
+}
+
+// gated blocks every write until release is closed.
+type gated struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (g *gated) Write(ctx context.Context, ev domain.AuditEvent) error {
+	g.entered <- struct{}{}
+	<-g.release
+	return nil
+}
+
+func TestSlowSinkDoesNotStallOthers(t *testing.T) {
+	slow := &gated{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	fast := &flaky{}
+	s, err := New([]Target{
+		{Name: "slow", Sink: slow},
+		{Name: "fast", Sink: fast},
+	}, Config{Policy: FailOpen, RetryInterval: 5 * time.Millisecond}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	go func() { _ = s.Write(ctx, ev("1")) }()
+	<-slow.entered
+
+	// While "slow" holds the first event, the next one is queued for it and
+	// still reaches "fast"; Check and Stats answer.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = s.Write(ctx, ev("2"))
+		_ = s.Check(ctx)
+		_ = s.Stats()
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("fan-out stalled behind a slow sink")
+	}
+	if st := s.Stats()[0]; st.Queued != 1 {
+		t.Fatalf("slow sink queue = %d, want 1", st.Queued)
+	}
+	close(slow.release)
+	go func() {
+		for range slow.entered {
+		}
+	}()
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := fast.got(); len(got) != 2 {
+		t.Fatalf("fast sink got %v", got)
+	}
+}
+
+func TestFailOpen_DropsWhenFull(t *testing.T) {
+	sinkA := &flaky{down: true}
+	obs := &countObs{}
+	s, err := New([]Target{{Name: "a", Sink: sinkA}}, Config{Policy: FailOpen, QueueSize: 2, RetryInterval: time.Hour}, obs, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		_ = s.Write(context.Background(), ev(id))
+	}
+	if st := s.Stats()[0]; st.Queued != 2 || st.Dropped != 1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+	if err := s.Check(context.Background()); err == nil {
+		t.Fatalf("expected not ready with full queue")
+	}
+	if obs.drops != 1 {
+		t.Fatalf("drops = %d", obs.drops)
+	}
+	// Close flushes what it can.
+	sinkA.set(false)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := sinkA.got(); len(got) != 2 || !sinkA.closed {
+		t.Fatalf("close did not flush/close: %v closed=%v", got, sinkA.closed)
+	}
+	if err := s.Write(context.Background(), ev("4")); err == nil {
+		t.Fatalf("expected write after close to fail")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(nil, Config{}, nil, nil); err == nil {
+		t.Fatalf("expected error for no targets")
+	}
+	if _, err := New([]Target{{Name: "a", Sink: &flaky{}}}, Config{Policy: "maybe"}, nil, nil); err == nil {
+		t.Fatalf("expected error for unknown policy")
+	}
+}
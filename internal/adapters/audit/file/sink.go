@@ -0,0 +1,416 @@
+// Package file implements an audit sink that appends one canonical JSON object
+// per event to a dedicated file, separate from application logs.
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// FsyncPolicy controls when written events are flushed to stable storage.
+type FsyncPolicy string
+
+const (
+	// FsyncAlways syncs after every event. Slowest, but no acknowledged event is lost on crash.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval syncs in the background every Config.FsyncInterval when there are unsynced writes.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever leaves flushing to the OS.
+	FsyncNever FsyncPolicy = "never"
+)
+
+// backupLayout is appended to the active path when a file is rotated. It sorts
+// lexically in time order.
+const backupLayout = "20060102T150405.000000000Z"
+
+// Config configures the file sink. Zero values disable the corresponding rotation
+// or retention rule.
+type Config struct {
+	Path          string
+	Fsync         FsyncPolicy   // default FsyncAlways
+	FsyncInterval time.Duration // for FsyncInterval; default 1s
+	MaxSize       int64         // rotate before a write would exceed this many bytes
+	RotateEvery   time.Duration // rotate when the UTC period (e.g. 24h → midnight) changes
+	MaxBackups    int           // keep at most this many rotated files
+	MaxAge        time.Duration // delete rotated files older than this
+}
+
+// Sink appends JSON lines to Config.Path. It is safe for concurrent use.
+type Sink struct {
+	cfg   Config
+	clock usecase.Clock
+	log   usecase.Logger
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	period time.Time // RotateEvery bucket of the active file
+	dirty  bool
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ usecase.AuditSink = (*Sink)(nil)
+
+// New opens (or creates) the audit file and starts the background syncer when
+// the policy is FsyncInterval. Pass a nil clock to use the system clock.
+func New(cfg Config, clk usecase.Clock, l usecase.Logger) (*Sink, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("audit file: path is required")
+	}
+	switch cfg.Fsync {
+	case "":
+		cfg.Fsync = FsyncAlways
+	case FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return nil, fmt.Errorf("audit file: unknown fsync policy %q", cfg.Fsync)
+	}
+	if cfg.Fsync == FsyncInterval && cfg.FsyncInterval <= 0 {
+		cfg.FsyncInterval = time.Second
+	}
+	if clk == nil {
+		clk = domain.SystemClock()
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o700); err != nil {
+		return nil, fmt.Errorf("audit file: mkdir: %w", err)
+	}
+	s := &Sink{cfg: cfg, clock: clk, log: l}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	if cfg.Fsync == FsyncInterval {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.syncLoop()
+	}
+	return s, nil
+}
+
+// Write appends ev as a single JSON line, rotating first if required.
+func (s *Sink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	line, err := auditjson.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("audit file: encode: %w", err)
+	}
+	return s.WriteLine(ctx, line)
+}
+
+// WriteLine appends a pre-encoded record (without trailing newline) under the
+// same rotation and fsync rules as Write. Wrappers such as the hash chain use it
+// to control the line format.
+func (s *Sink) WriteLine(ctx context.Context, line []byte) error {
+	line = append(line[:len(line):len(line)], '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("audit file: sink closed")
+	}
+	if s.shouldRotate(int64(len(line))) {
+		if err := s.rotate(ctx); err != nil {
+			return err
+		}
+	}
+	n, err := s.f.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("audit file: write: %w", err)
+	}
+	switch s.cfg.Fsync {
+	case FsyncAlways:
+		if err := s.f.Sync(); err != nil {
+			return fmt.Errorf("audit file: fsync: %w", err)
+		}
+	case FsyncInterval:
+		s.dirty = true
+	}
+	return nil
+}
+
+// Reopen closes and re-opens Config.Path. Call it after an external tool such as
+// logrotate has moved the file away.
+func (s *Sink) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("audit file: sink closed")
+	}
+	if err := s.closeFile(); err != nil && s.log != nil {
+		s.log.Warn(context.Background(), "audit file: close on reopen", "error", err)
+	}
+	return s.open()
+}
+
+// ReopenOnSignal calls Reopen whenever one of sigs (default SIGHUP) is received,
+// until ctx is done.
+func (s *Sink) ReopenOnSignal(ctx context.Context, sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				if err := s.Reopen(); err != nil {
+					if s.log != nil {
+						s.log.Error(ctx, "audit file: reopen failed", "path", s.cfg.Path, "error", err)
+					}
+				} else if s.log != nil {
+					s.log.Info(ctx, "audit file: reopened", "path", s.cfg.Path)
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the background syncer and syncs and closes the file.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeFile()
+}
+
+// open opens the active file for appending. An existing file keeps its size and
+// is assigned to the period of its last modification, so a restart after the
+// period boundary still rotates it.
+func (s *Sink) open() error {
+	f, err := os.OpenFile(s.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("audit file: open: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("audit file: stat: %w", err)
+	}
+	s.f, s.size, s.dirty = f, st.Size(), false
+	started := s.clock.Now()
+	if st.Size() > 0 {
+		started = st.ModTime()
+	}
+	s.period = s.bucket(started)
+	return nil
+}
+
+func (s *Sink) closeFile() error {
+	if s.f == nil {
+		return nil
+	}
+	var errs []error
+	if s.cfg.Fsync != FsyncNever {
+		errs = append(errs, s.f.Sync())
+	}
+	errs = append(errs, s.f.Close())
+	s.f, s.dirty = nil, false
+	return errors.Join(errs...)
+}
+
+func (s *Sink) bucket(t time.Time) time.Time {
+	if s.cfg.RotateEvery <= 0 {
+		return time.Time{}
+	}
+	return t.UTC().Truncate(s.cfg.RotateEvery)
+}
+
+func (s *Sink) shouldRotate(next int64) bool {
+	if s.size == 0 {
+		return false
+	}
+	if s.cfg.MaxSize > 0 && s.size+next > s.cfg.MaxSize {
+		return true
+	}
+	return s.cfg.RotateEvery > 0 && !s.bucket(s.clock.Now()).Equal(s.period)
+}
+
+// rotate renames the active file to a timestamped backup, opens a fresh file and
+// applies retention. Callers hold s.mu.
+func (s *Sink) rotate(ctx context.Context) error {
+	if err := s.closeFile(); err != nil {
+		return fmt.Errorf("audit file: close for rotate: %w", err)
+	}
+	now := s.clock.Now().UTC()
+	backup := s.cfg.Path + "." + now.Format(backupLayout)
+	if err := os.Rename(s.cfg.Path, backup); err != nil {
+		// Keep writing to the existing file rather than dropping events.
+		if oerr := s.open(); oerr != nil {
+			return errors.Join(fmt.Errorf("audit file: rotate: %w", err), oerr)
+		}
+		return fmt.Errorf("audit file: rotate: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	if s.cfg.Fsync != FsyncNever {
+		syncDir(filepath.Dir(s.cfg.Path))
+	}
+	if err := s.prune(now); err != nil && s.log != nil {
+		s.log.Warn(ctx, "audit file: retention failed", "error", err)
+	}
+	return nil
+}
+
+// prune deletes rotated files beyond MaxBackups or older than MaxAge.
+func (s *Sink) prune(now time.Time) error {
+	if s.cfg.MaxBackups <= 0 && s.cfg.MaxAge <= 0 {
+		return nil
+	}
+	backups, err := Backups(s.cfg.Path)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, b := range backups {
+		// backups are oldest first; keep the newest MaxBackups.
+		tooMany := s.cfg.MaxBackups > 0 && i < len(backups)-s.cfg.MaxBackups
+		tooOld := s.cfg.MaxAge > 0 && now.Sub(b.Rotated) > s.cfg.MaxAge
+		if tooMany || tooOld {
+			if err := os.Remove(b.Path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Backup describes a rotated audit file.
+type Backup struct {
+	Path    string
+	Rotated time.Time
+}
+
+// Backups lists rotated files for the active path, oldest first.
+func Backups(path string) ([]Backup, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var out []Backup
+	for _, m := range matches {
+		t, err := time.Parse(backupLayout, strings.TrimPrefix(m, path+"."))
+		if err != nil {
+			continue
+		}
+		out = append(out, Backup{Path: m, Rotated: t})
+	}
+	slices.SortFunc(out, func(a, b Backup) int { return a.Rotated.Compare(b.Rotated) })
+	return out, nil
+}
+
+// TrimPartialLine truncates a trailing line that has no newline, as left by a
+// crash in the middle of a write, so the next record starts on a line of its
+// own. It returns the number of bytes removed; a missing file is not an error.
+func TrimPartialLine(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return 0, nil
+	}
+	keep := int64(bytes.LastIndexByte(data, '\n') + 1)
+	if err := os.Truncate(path, keep); err != nil {
+		return 0, err
+	}
+	return int64(len(data)) - keep, nil
+}
+
+// LastLine returns the last non-empty line written at path, looking at the newest
+// rotated file when the active one is empty or missing. It returns nil when no
+// records exist yet.
+func LastLine(path string) ([]byte, error) {
+	candidates := []string{path}
+	backups, err := Backups(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		candidates = append(candidates, backups[i].Path)
+	}
+	for _, c := range candidates {
+		data, err := os.ReadFile(c)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimRight(data, "\n")
+		if len(data) == 0 {
+			continue
+		}
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+func (s *Sink) syncLoop() {
+	defer close(s.done)
+	t := time.NewTicker(s.cfg.FsyncInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			s.mu.Lock()
+			if s.dirty && s.f != nil {
+				if err := s.f.Sync(); err != nil {
+					if s.log != nil {
+						s.log.Error(context.Background(), "audit file: fsync failed", "error", err)
+					}
+				} else {
+					s.dirty = false
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// syncDir makes a rename durable; best effort.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,204 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+type stepClock struct{ t time.Time }
+
+func (c *stepClock) Now() time.Time { return c.t }
+
+func event(serial uint64, now time.Time) domain.AuditEvent {
+	ctx := domain.SignContext{Now: now, TraceID: "t"}
+	return domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "sub"}, []string{"alice"}, serial, now, now.Add(time.Hour), ctx, nil)
+}
+
+func readLines(t *testing.T, path string) []auditjson.Record {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	var out []auditjson.Record
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		r, err := auditjson.Unmarshal(sc.Bytes())
+		if err != nil {
+			t.Fatalf("decode %q: %v", sc.Text(), err)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestSink_WritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	clk := &stepClock{t: time.Unix(1_700_000_000, 0).UTC()}
+	s, err := New(Config{Path: path}, clk, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for i := uint64(1); i <= 3; i++ {
+		if err := s.Write(context.Background(), event(i, clk.t)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	recs := readLines(t, path)
+	if len(recs) != 3 || *recs[2].Serial != 3 || recs[0].Action != "ISSUE_USER_CERT" {
+		t.Fatalf("unexpected records: %+v", recs)
+	}
+	st, _ := os.Stat(path)
+	if st.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, want 0600", st.Mode().Perm())
+	}
+	if err := s.Write(context.Background(), event(4, clk.t)); err == nil {
+		t.Fatalf("expected write after close to fail")
+	}
+}
+
+func TestSink_RotatesBySizeAndPrunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	clk := &stepClock{t: time.Unix(1_700_000_000, 0).UTC()}
+	line, _ := auditjson.Marshal(event(1, clk.t))
+	s, err := New(Config{Path: path, Fsync: FsyncNever, MaxSize: int64(len(line)+1) * 2, MaxBackups: 2}, clk, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+	for i := uint64(1); i <= 8; i++ {
+		clk.t = clk.t.Add(time.Second)
+		if err := s.Write(context.Background(), event(i, clk.t)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("backups = %d, want 2", len(backups))
+	}
+	// Files hold two events each: 1-2 was pruned, 3-4 and 5-6 remain, 7-8 is active.
+	if recs := readLines(t, backups[0].Path); len(recs) != 2 || *recs[0].Serial != 3 {
+		t.Fatalf("unexpected backup contents: %+v", recs)
+	}
+	if recs := readLines(t, path); len(recs) != 2 || *recs[1].Serial != 8 {
+		t.Fatalf("unexpected active contents: %+v", recs)
+	}
+}
+
+func TestSink_RotatesByPeriodAndAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	day := 24 * time.Hour
+	clk := &stepClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	s, err := New(Config{Path: path, RotateEvery: day, MaxAge: 36 * time.Hour}, clk, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+	for i := uint64(1); i <= 3; i++ {
+		if err := s.Write(context.Background(), event(i, clk.t)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		clk.t = clk.t.Add(day)
+	}
+	// Rotated on Jan 2 and Jan 3; both backups are still within MaxAge.
+	backups, _ := Backups(path)
+	if len(backups) != 2 {
+		t.Fatalf("backups = %d, want 2", len(backups))
+	}
+	if err := s.Write(context.Background(), event(4, clk.t)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	backups, _ = Backups(path)
+	if len(backups) != 2 || !strings.HasSuffix(backups[0].Path, "20250103T120000.000000000Z") {
+		t.Fatalf("expected Jan 2 backup pruned, got %+v", backups)
+	}
+}
+
+func TestSink_FsyncInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	s, err := New(Config{Path: path, Fsync: FsyncInterval, FsyncInterval: 5 * time.Millisecond}, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := s.Write(context.Background(), event(1, time.Now().UTC())); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	s.mu.Lock()
+	dirty := s.dirty
+	s.mu.Unlock()
+	if dirty {
+		t.Fatalf("expected background fsync to clear dirty flag")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(Config{Path: path, Fsync: "sometimes"}, nil, nil); err == nil {
+		t.Fatalf("expected unknown fsync policy error")
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if l, err := LastLine(path); err != nil || l != nil {
+		t.Fatalf("LastLine(missing) = %q, %v", l, err)
+	}
+	clk := &stepClock{t: time.Unix(1_700_000_000, 0).UTC()}
+	s, err := New(Config{Path: path, MaxSize: 1}, clk, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	_ = s.WriteLine(context.Background(), []byte("one"))
+	_ = s.WriteLine(context.Background(), []byte("two"))
+	if l, _ := LastLine(path); string(l) != "two" {
+		t.Fatalf("LastLine = %q, want two", l)
+	}
+	// After a rotation with nothing written yet, fall back to the newest backup.
+	clk.t = clk.t.Add(time.Second)
+	if err := s.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+"."+clk.t.Format(backupLayout)); err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := LastLine(path); string(l) != "two" {
+		t.Fatalf("LastLine(after rotate) = %q, want two", l)
+	}
+}
+
+func TestTrimPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if n, err := TrimPartialLine(path); err != nil || n != 0 {
+		t.Fatalf("TrimPartialLine(missing) = %d, %v", n, err)
+	}
+	if err := os.WriteFile(path, []byte("one\ntwo\n{\"seq\":3,\"pr"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	n, err := TrimPartialLine(path)
+	if err != nil || n != 12 {
+		t.Fatalf("TrimPartialLine = %d, %v; want 12", n, err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "one\ntwo\n" {
+		t.Fatalf("file after trim = %q", b)
+	}
+	if n, err := TrimPartialLine(path); err != nil || n != 0 {
+		t.Fatalf("TrimPartialLine(intact) = %d, %v", n, err)
+	}
+}
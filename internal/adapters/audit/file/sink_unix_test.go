@@ -0,0 +1,58 @@
+//go:build unix
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestSink_ReopenOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	clk := &stepClock{t: time.Unix(1_700_000_000, 0).UTC()}
+	s, err := New(Config{Path: path}, clk, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.ReopenOnSignal(ctx, syscall.SIGUSR1)
+
+	if err := s.Write(ctx, event(1, clk.t)); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate logrotate: move the file away, then signal.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("file not reopened after signal")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := s.Write(ctx, event(2, clk.t)); err != nil {
+		t.Fatal(err)
+	}
+	if recs := readLines(t, path); len(recs) != 1 || *recs[0].Serial != 2 {
+		t.Fatalf("unexpected reopened contents: %+v", recs)
+	}
+	if recs := readLines(t, path+".1"); len(recs) != 1 || *recs[0].Serial != 1 {
+		t.Fatalf("unexpected rotated contents: %+v", recs)
+	}
+}
@@ -0,0 +1,263 @@
+// Package sqlstore stores audit events in SQLite or Postgres and answers
+// queries over them. It is both an audit sink and the backend of GET /v1/audit.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" driver
+	_ "modernc.org/sqlite"             // registers the "sqlite" driver
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// Supported drivers.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Config selects the database.
+type Config struct {
+	Driver string // sqlite | postgres
+	// DSN is a file path (or file: URI) for sqlite and a connection URL for
+	// postgres, e.g. postgres://kamini@db/kamini?sslmode=verify-full.
+	DSN string
+}
+
+// Store is a usecase.AuditStore backed by database/sql. Each event is stored
+// as its canonical JSON record plus indexed columns for filtering; principals
+// live in a side table so any of them can be searched.
+type Store struct {
+	db     *sql.DB
+	driver string
+}
+
+var _ usecase.AuditStore = (*Store)(nil)
+
+// Open connects to the database and creates the schema if needed.
+func Open(ctx context.Context, cfg Config) (*Store, error) {
+	if cfg.DSN == "" {
+		return nil, errors.New("audit store: dsn is required")
+	}
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch cfg.Driver {
+	case DriverSQLite:
+		db, err = sql.Open("sqlite", sqliteDSN(cfg.DSN))
+		if err == nil {
+			// One writer at a time; the busy timeout covers other processes.
+			db.SetMaxOpenConns(1)
+		}
+	case DriverPostgres:
+		db, err = sql.Open("pgx", cfg.DSN)
+	default:
+		return nil, fmt.Errorf("audit store: unknown driver %q (want sqlite or postgres)", cfg.Driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audit store: open: %w", err)
+	}
+	s := &Store{db: db, driver: cfg.Driver}
+	if err := s.migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("audit store: migrate: %w", err)
+	}
+	return s, nil
+}
+
+// sqliteDSN turns a plain path into a URI with WAL and a busy timeout. URIs
+// (file:...) are used as given.
+func sqliteDSN(dsn string) string {
+	if strings.HasPrefix(dsn, "file:") || dsn == ":memory:" {
+		return dsn
+	}
+	return "file:" + dsn + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	id := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.driver == DriverPostgres {
+		id = "BIGSERIAL PRIMARY KEY"
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS audit_events (
+			id         ` + id + `,
+			time_ns    BIGINT NOT NULL,
+			action     TEXT NOT NULL,
+			stage      TEXT NOT NULL,
+			success    BOOLEAN NOT NULL,
+			subject    TEXT NOT NULL,
+			serial     BIGINT,
+			trace_id   TEXT NOT NULL,
+			error_code TEXT NOT NULL,
+			record     TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS audit_principals (
+			event_id  BIGINT NOT NULL REFERENCES audit_events (id) ON DELETE CASCADE,
+			principal TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS audit_events_time ON audit_events (time_ns)`,
+		`CREATE INDEX IF NOT EXISTS audit_events_subject ON audit_events (subject, id)`,
+		`CREATE INDEX IF NOT EXISTS audit_events_serial ON audit_events (serial)`,
+		`CREATE INDEX IF NOT EXISTS audit_principals_principal ON audit_principals (principal, event_id)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write stores ev and its principals in one transaction.
+func (s *Store) Write(ctx context.Context, ev domain.AuditEvent) error {
+	record, err := auditjson.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("audit store: encode: %w", err)
+	}
+	var serial sql.NullInt64
+	if ev.Serial != nil {
+		// Serials are allocated from 1 upwards; the cast is lossless in practice
+		// and round-trips through the JSON record either way.
+		serial = sql.NullInt64{Int64: int64(*ev.Serial), Valid: true}
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("audit store: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, s.rebind(`INSERT INTO audit_events
+		(time_ns, action, stage, success, subject, serial, trace_id, error_code, record)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`),
+		ev.Time.UnixNano(), string(ev.Action), string(ev.Stage), ev.Success(), ev.Subject,
+		serial, ev.TraceID, string(ev.ErrorCode), string(record),
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("audit store: insert: %w", err)
+	}
+	for _, p := range ev.Principals {
+		if _, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO audit_principals (event_id, principal) VALUES (?, ?)`), id, p); err != nil {
+			return fmt.Errorf("audit store: insert principal: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("audit store: commit: %w", err)
+	}
+	return nil
+}
+
+// Query returns records matching q, newest first.
+func (s *Store) Query(ctx context.Context, q domain.AuditQuery) ([]domain.AuditRecord, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		where = append(where, cond)
+		args = append(args, v)
+	}
+	if q.Subject != "" {
+		add("subject = ?", q.Subject)
+	}
+	if q.Principal != "" {
+		add("id IN (SELECT event_id FROM audit_principals WHERE principal = ?)", q.Principal)
+	}
+	if q.Serial != 0 {
+		add("serial = ?", int64(q.Serial))
+	}
+	if q.Action != "" {
+		add("action = ?", string(q.Action))
+	}
+	switch q.Outcome {
+	case domain.OutcomeSuccess:
+		add("success = ?", true)
+	case domain.OutcomeFailure:
+		add("success = ?", false)
+	}
+	if !q.Since.IsZero() {
+		add("time_ns >= ?", q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		add("time_ns < ?", q.Until.UnixNano())
+	}
+	if q.Before != 0 {
+		add("id < ?", int64(q.Before))
+	}
+	stmt := "SELECT id, record FROM audit_events"
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	stmt += " ORDER BY id DESC"
+	if q.Limit > 0 {
+		stmt += " LIMIT " + strconv.Itoa(q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(stmt), args...)
+	if err != nil {
+		return nil, fmt.Errorf("audit store: query: %w", err)
+	}
+	defer rows.Close()
+	var out []domain.AuditRecord
+	for rows.Next() {
+		var (
+			id     int64
+			record string
+		)
+		if err := rows.Scan(&id, &record); err != nil {
+			return nil, fmt.Errorf("audit store: scan: %w", err)
+		}
+		r, err := auditjson.Unmarshal([]byte(record))
+		if err != nil {
+			return nil, fmt.Errorf("audit store: decode record %d: %w", id, err)
+		}
+		out = append(out, domain.AuditRecord{ID: uint64(id), AuditEvent: r.Event()})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("audit store: query: %w", err)
+	}
+	return out, nil
+}
+
+// Check pings the database; it backs the readiness probe.
+func (s *Store) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("audit store: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error { return s.db.Close() }
+
+// rebind rewrites ? placeholders as $1, $2, ... for postgres.
+func (s *Store) rebind(query string) string {
+	if s.driver != DriverPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
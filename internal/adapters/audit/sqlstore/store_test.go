@@ -0,0 +1,150 @@
+package sqlstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+var t0 = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func openTemp(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.db")
+	s, err := Open(context.Background(), Config{Driver: DriverSQLite, DSN: path})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s, path
+}
+
+func issued(subject string, principals []string, serial uint64, at time.Time) domain.AuditEvent {
+	return domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: subject}, principals, serial, at, at.Add(time.Hour),
+		domain.SignContext{Now: at, SourceIP: "10.0.0.1", TraceID: "t-1"}, map[string]string{"authz": "static"})
+}
+
+func seed(t *testing.T, s *Store) {
+	t.Helper()
+	ctx := context.Background()
+	events := []domain.AuditEvent{
+		issued("alice", []string{"alice", "ops"}, 1, t0),
+		issued("bob", []string{"bob"}, 2, t0.Add(time.Minute)),
+		domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, domain.Identity{Subject: "mallory"}, []string{"root"},
+			domain.SignContext{Now: t0.Add(2 * time.Minute)}, domain.ErrPolicyDenied, nil),
+		domain.NewAuditRevocation(domain.Identity{Subject: "admin"},
+			domain.CertRecord{Serial: 1, Subject: "alice", Principals: []string{"alice", "ops"}, NotBefore: t0, NotAfter: t0.Add(time.Hour)},
+			domain.Revocation{Kind: domain.RevokeSerial, Reason: "laptop stolen"}, domain.SignContext{Now: t0.Add(3 * time.Minute)}),
+	}
+	for _, ev := range events {
+		if err := s.Write(ctx, ev); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+}
+
+func serials(recs []domain.AuditRecord) []uint64 {
+	out := make([]uint64, 0, len(recs))
+	for _, r := range recs {
+		var s uint64
+		if r.Serial != nil {
+			s = *r.Serial
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestStore_Query(t *testing.T) {
+	s, _ := openTemp(t)
+	seed(t, s)
+	ctx := context.Background()
+
+	all, err := s.Query(ctx, domain.AuditQuery{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(all) != 4 || all[0].ID <= all[1].ID || all[0].Action != domain.ActionRevokeCert {
+		t.Fatalf("expected 4 records newest first, got %+v", all)
+	}
+	if a := all[3]; a.Subject != "alice" || a.Attrs["authz"] != "static" || a.SourceIP != "10.0.0.1" || !a.NotAfter.Equal(t0.Add(time.Hour)) {
+		t.Fatalf("event did not round-trip: %+v", a)
+	}
+
+	cases := []struct {
+		name string
+		q    domain.AuditQuery
+		want int
+	}{
+		{"subject", domain.AuditQuery{Subject: "alice"}, 2},
+		{"principal", domain.AuditQuery{Principal: "ops"}, 2},
+		{"serial", domain.AuditQuery{Serial: 1}, 2},
+		{"action", domain.AuditQuery{Action: domain.ActionRevokeCert}, 1},
+		{"failures", domain.AuditQuery{Outcome: domain.OutcomeFailure}, 1},
+		{"successes", domain.AuditQuery{Outcome: domain.OutcomeSuccess}, 3},
+		{"window", domain.AuditQuery{Since: t0.Add(time.Minute), Until: t0.Add(3 * time.Minute)}, 2},
+		{"limit", domain.AuditQuery{Limit: 3}, 3},
+		{"cursor", domain.AuditQuery{Before: all[1].ID}, 2},
+		{"none", domain.AuditQuery{Subject: "nobody"}, 0},
+	}
+	for _, tc := range cases {
+		got, err := s.Query(ctx, tc.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(got) != tc.want {
+			t.Fatalf("%s: got %d records (%v), want %d", tc.name, len(got), serials(got), tc.want)
+		}
+		for _, r := range got {
+			if !tc.q.Match(r) {
+				t.Fatalf("%s: record %d does not match the query", tc.name, r.ID)
+			}
+		}
+	}
+}
+
+func TestStore_PersistsAcrossReopen(t *testing.T) {
+	s, path := openTemp(t)
+	seed(t, s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := Open(context.Background(), Config{Driver: DriverSQLite, DSN: path})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s2.Close()
+	if err := s2.Check(context.Background()); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if err := s2.Write(context.Background(), issued("carol", []string{"carol"}, 3, t0.Add(time.Hour))); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s2.Query(context.Background(), domain.AuditQuery{})
+	if err != nil || len(got) != 5 || *got[0].Serial != 3 {
+		t.Fatalf("unexpected records after reopen: %v %v", serials(got), err)
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	ctx := context.Background()
+	for _, cfg := range []Config{{Driver: DriverSQLite}, {Driver: "oracle", DSN: "x"}} {
+		if _, err := Open(ctx, cfg); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	pg := &Store{driver: DriverPostgres}
+	if got := pg.rebind("a = ? AND b IN (SELECT x FROM y WHERE z = ?)"); got != "a = $1 AND b IN (SELECT x FROM y WHERE z = $2)" {
+		t.Fatalf("rebind = %q", got)
+	}
+	lite := &Store{driver: DriverSQLite}
+	if got := lite.rebind("a = ?"); got != "a = ?" {
+		t.Fatalf("sqlite rebind = %q", got)
+	}
+}
@@ -0,0 +1,259 @@
+// Package syslog implements an audit sink that ships events to a syslog
+// collector as RFC 5424 messages over UDP, TCP or TLS.
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// DefaultSDID is the structured data element ID. 32473 is the IANA example
+// enterprise number; set Config.SDID to your own if your SIEM requires it.
+const DefaultSDID = "kamini@32473"
+
+// Config configures the syslog sink.
+type Config struct {
+	Network  string      // udp | tcp | tls
+	Address  string      // host:port
+	TLS      *tls.Config // for tls; nil uses system roots and the host from Address
+	Facility int         // default 10 (authpriv)
+	Hostname string      // default os.Hostname()
+	AppName  string      // default "kamini"
+	SDID     string      // default DefaultSDID
+
+	QueueSize    int           // buffered messages; default 1000
+	DialTimeout  time.Duration // default 5s
+	WriteTimeout time.Duration // default 5s
+	MaxBackoff   time.Duration // reconnect backoff cap; default 30s
+}
+
+// Sink queues formatted messages and delivers them from a background worker,
+// reconnecting with backoff. Write fails only when the queue is full, so a
+// fan-out sink can account for the loss.
+type Sink struct {
+	cfg Config
+	hdr header
+	log usecase.Logger
+
+	queue chan []byte
+	stop  chan struct{}
+	done  chan struct{}
+
+	pending atomic.Int64 // queued or in-flight messages
+
+	mu     sync.Mutex
+	closed bool
+	conn   net.Conn
+}
+
+var _ usecase.AuditSink = (*Sink)(nil)
+
+// New validates cfg and starts the delivery worker. The first connection is made
+// lazily, so the server starts while the collector is unreachable.
+func New(cfg Config, l usecase.Logger) (*Sink, error) {
+	switch cfg.Network {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("audit syslog: unknown network %q (want udp, tcp or tls)", cfg.Network)
+	}
+	if _, _, err := net.SplitHostPort(cfg.Address); err != nil {
+		return nil, fmt.Errorf("audit syslog: address: %w", err)
+	}
+	if cfg.Facility == 0 {
+		cfg.Facility = facilities["authpriv"]
+	}
+	if cfg.Facility < 0 || cfg.Facility > 23 {
+		return nil, fmt.Errorf("audit syslog: facility %d out of range", cfg.Facility)
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = "kamini"
+	}
+	if cfg.SDID == "" {
+		cfg.SDID = DefaultSDID
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 5 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	s := &Sink{
+		cfg: cfg,
+		hdr: header{
+			facility: cfg.Facility,
+			hostname: cfg.Hostname,
+			appName:  cfg.AppName,
+			procID:   strconv.Itoa(os.Getpid()),
+			sdID:     cfg.SDID,
+		},
+		log:   l,
+		queue: make(chan []byte, cfg.QueueSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Write formats ev and queues it for delivery.
+func (s *Sink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	msg, err := s.hdr.format(ev)
+	if err != nil {
+		return fmt.Errorf("audit syslog: format: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("audit syslog: sink closed")
+	}
+	s.pending.Add(1)
+	select {
+	case s.queue <- msg:
+		return nil
+	default:
+		s.pending.Add(-1)
+		return errors.New("audit syslog: queue full")
+	}
+}
+
+// Close stops accepting events, gives the worker up to WriteTimeout to drain the
+// queue and closes the connection. It reports how many messages were not sent.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(s.cfg.WriteTimeout):
+		close(s.stop)
+		<-s.done
+	}
+	var err error
+	if n := s.pending.Load(); n > 0 {
+		err = fmt.Errorf("audit syslog: %d queued messages not delivered", n)
+	}
+	s.mu.Lock()
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	s.mu.Unlock()
+	return err
+}
+
+// run delivers queued messages in order. A message is retried until it is
+// written or the sink is stopped.
+func (s *Sink) run() {
+	defer close(s.done)
+	backoff := 100 * time.Millisecond
+	for msg := range s.queue {
+		for {
+			err := s.send(msg)
+			if err == nil {
+				s.pending.Add(-1)
+				backoff = 100 * time.Millisecond
+				break
+			}
+			if s.log != nil {
+				s.log.Warn(context.Background(), "audit syslog: send failed; reconnecting", "address", s.cfg.Address, "error", err, "retry_in", backoff)
+			}
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, s.cfg.MaxBackoff)
+		}
+	}
+}
+
+// send writes one message, dialing first if needed. On error the connection is
+// dropped so the next attempt reconnects.
+func (s *Sink) send(msg []byte) error {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn != nil && s.cfg.Network != "udp" && peerClosed(conn) {
+		_ = conn.Close()
+		conn = nil
+	}
+	if conn == nil {
+		c, err := s.dial()
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.conn, conn = c, c
+		s.mu.Unlock()
+	}
+	frame := msg
+	if s.cfg.Network != "udp" {
+		// Octet-counting framing (RFC 5425 §4.3, RFC 6587 §3.4.1).
+		frame = append(strconv.AppendInt(nil, int64(len(msg)), 10), ' ')
+		frame = append(frame, msg...)
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(s.cfg.WriteTimeout))
+	if _, err := conn.Write(frame); err != nil {
+		_ = conn.Close()
+		s.mu.Lock()
+		s.conn = nil
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *Sink) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: s.cfg.DialTimeout}
+	switch s.cfg.Network {
+	case "tls":
+		cfg := s.cfg.TLS
+		if cfg == nil {
+			host, _, _ := net.SplitHostPort(s.cfg.Address)
+			cfg = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		}
+		return tls.DialWithDialer(d, "tcp", s.cfg.Address, cfg)
+	default:
+		return d.Dial(s.cfg.Network, s.cfg.Address)
+	}
+}
+
+// peerClosed reports whether the collector has closed a stream connection. A
+// write on such a connection often "succeeds" and is silently lost, so check
+// with a short read first. Collectors never send data, so any read result
+// other than a timeout means the connection is gone. The deadline must lie in
+// the future: an already expired one fails before the socket is read.
+func peerClosed(conn net.Conn) bool {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	var b [1]byte
+	_, err := conn.Read(b[:])
+	_ = conn.SetReadDeadline(time.Time{})
+	var ne net.Error
+	return !(errors.As(err, &ne) && ne.Timeout())
+}
@@ -0,0 +1,253 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func successEvent() domain.AuditEvent {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := domain.SignContext{Now: now, SourceIP: "10.0.0.1", TraceID: "t-1"}
+	return domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: `we"ird]sub`}, []string{"alice", "ops"}, 42, now, now.Add(time.Hour), ctx, nil)
+}
+
+func TestFormat(t *testing.T) {
+	h := header{facility: 10, hostname: "ca-1", appName: "kamini", procID: "99", sdID: DefaultSDID}
+	b, err := h.format(successEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(b)
+	want := `<86>1 2025-01-02T03:04:05Z ca-1 kamini 99 ISSUE_USER_CERT [kamini@32473 outcome="success" stage="SIGN" serial="42" subject="we\"ird\]sub" principals="alice,ops" source_ip="10.0.0.1" trace_id="t-1"] {"time":`
+	if !strings.HasPrefix(msg, want) {
+		t.Fatalf("unexpected message:\n got %s\nwant prefix %s", msg, want)
+	}
+
+	fail := domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, domain.Identity{}, nil,
+		domain.SignContext{Now: time.Unix(0, 0)}, domain.ErrPolicyDenied, nil)
+	b, _ = header{facility: 13, sdID: DefaultSDID}.format(fail)
+	if !strings.HasPrefix(string(b), "<108>1 ") || !strings.Contains(string(b), `outcome="failure" stage="POLICY" error_code="POLICY_DENIED"]`) {
+		t.Fatalf("unexpected failure message: %s", b)
+	}
+	if !strings.Contains(string(b), " - - - ISSUE_USER_CERT ") {
+		t.Fatalf("empty header fields must be NILVALUE: %s", b)
+	}
+
+	fine := successEvent()
+	fine.Time = time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	b, _ = h.format(fine)
+	if !strings.HasPrefix(string(b), "<86>1 2025-01-02T03:04:05.123456Z ") {
+		t.Fatalf("timestamp must carry at most 6 fractional digits: %s", b)
+	}
+}
+
+func TestParseFacility(t *testing.T) {
+	if f, ok := ParseFacility("LOCAL3"); !ok || f != 19 {
+		t.Fatalf("ParseFacility(LOCAL3) = %d, %v", f, ok)
+	}
+	if _, ok := ParseFacility("nope"); ok {
+		t.Fatalf("expected unknown facility")
+	}
+}
+
+func TestSink_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	s, err := New(Config{Network: "udp", Address: pc.LocalAddr().String(), Hostname: "ca-1"}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Write(context.Background(), successEvent()); err != nil {
+		t.Fatal(err)
+	}
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.HasPrefix(string(buf[:n]), "<86>1 ") {
+		t.Fatalf("unexpected datagram: %s", buf[:n])
+	}
+}
+
+// readFrame reads one octet-counted frame.
+func readFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	lenStr, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("read length: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(lenStr))
+	if err != nil {
+		t.Fatalf("bad length %q", lenStr)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	return string(buf)
+}
+
+func accept(t *testing.T, ln net.Listener) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conns := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			conns <- c
+		}
+	}()
+	select {
+	case c := <-conns:
+		t.Cleanup(func() { c.Close() })
+		_ = c.SetReadDeadline(time.Now().Add(5 * time.Second))
+		return c, bufio.NewReader(c)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no connection")
+		return nil, nil
+	}
+}
+
+func TestSink_TCPReconnects(t *testing.T) {
+	// Reserve an address, then leave it closed so the first attempts fail.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s, err := New(Config{Network: "tcp", Address: addr, MaxBackoff: 50 * time.Millisecond}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Write(context.Background(), successEvent()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not re-listen on %s: %v", addr, err)
+	}
+	defer ln.Close()
+	c, r := accept(t, ln)
+	if msg := readFrame(t, r); !strings.Contains(msg, `serial="42"`) {
+		t.Fatalf("unexpected frame: %s", msg)
+	}
+
+	// The collector drops the connection; the next event goes over a new one.
+	c.Close()
+	time.Sleep(20 * time.Millisecond)
+	if err := s.Write(context.Background(), successEvent()); err != nil {
+		t.Fatal(err)
+	}
+	_, r = accept(t, ln)
+	if msg := readFrame(t, r); !strings.HasPrefix(msg, "<86>1 ") {
+		t.Fatalf("unexpected frame after reconnect: %s", msg)
+	}
+}
+
+func selfSignedTLS(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "collector"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, pool
+}
+
+func TestSink_TLS(t *testing.T) {
+	srvCfg, pool := selfSignedTLS(t)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", srvCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	s, err := New(Config{
+		Network: "tls",
+		Address: ln.Addr().String(),
+		TLS:     &tls.Config{RootCAs: pool, ServerName: "localhost", MinVersion: tls.VersionTLS12},
+	}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(context.Background(), successEvent()); err != nil {
+		t.Fatal(err)
+	}
+	_, r := accept(t, ln)
+	if msg := readFrame(t, r); !strings.Contains(msg, "[kamini@32473 ") {
+		t.Fatalf("unexpected frame: %s", msg)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := s.Write(context.Background(), successEvent()); err == nil {
+		t.Fatalf("expected write after close to fail")
+	}
+}
+
+func TestSink_QueueFullAndValidation(t *testing.T) {
+	// Nothing listens here; the worker holds the first message while retrying.
+	s, err := New(Config{Network: "tcp", Address: "127.0.0.1:1", QueueSize: 1, DialTimeout: 50 * time.Millisecond, WriteTimeout: 50 * time.Millisecond}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lastErr error
+	for i := 0; i < 5 && lastErr == nil; i++ {
+		lastErr = s.Write(context.Background(), successEvent())
+	}
+	if lastErr == nil || !strings.Contains(lastErr.Error(), "queue full") {
+		t.Fatalf("expected queue full, got %v", lastErr)
+	}
+	if err := s.Close(); err == nil {
+		t.Fatalf("expected undelivered messages to be reported")
+	}
+
+	for _, cfg := range []Config{
+		{Network: "carrier", Address: "h:514"},
+		{Network: "udp", Address: "no-port"},
+		{Network: "udp", Address: "h:514", Facility: 99},
+	} {
+		if _, err := New(cfg, nil); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// statusError is a non-2xx response.
+type statusError struct{ code int }
+
+func (e *statusError) Error() string { return fmt.Sprintf("unexpected status %d", e.code) }
+
+// permanent reports whether retrying the same batch cannot succeed. Timeouts,
+// throttling and server errors are retried; other client errors are not.
+func (e *statusError) permanent() bool {
+	return e.code >= 400 && e.code < 500 && e.code != http.StatusRequestTimeout && e.code != http.StatusTooManyRequests
+}
+
+// endpoint delivers batches to one URL in order. Batches wait in a bounded
+// memory queue; once the head has failed MaxAttempts times the queue moves to
+// the spool, and new batches follow it there until the spool has drained.
+type endpoint struct {
+	url  string
+	cfg  *Config
+	sink *Sink
+
+	mu      sync.Mutex
+	mem     [][]byte
+	spool   *spool // nil when spooling is disabled
+	spills  uint64 // bumped by spill, so the worker notices its batch moved
+	dropped int
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (ep *endpoint) notify() {
+	select {
+	case ep.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue adds a batch. It never blocks on the network.
+func (ep *endpoint) enqueue(body []byte) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	defer ep.notify()
+	switch {
+	case ep.spool != nil && ep.spool.len() > 0:
+		ep.toSpool(body)
+	case len(ep.mem) < ep.cfg.QueueSize:
+		ep.mem = append(ep.mem, body)
+	case ep.spool != nil:
+		ep.spill()
+		ep.toSpool(body)
+	default:
+		ep.dropped++
+		ep.sink.warn("audit webhook: queue full; dropping batch", "url", ep.url)
+	}
+}
+
+// spill moves the memory queue to the spool, oldest first. Callers hold mu.
+func (ep *endpoint) spill() {
+	for _, b := range ep.mem {
+		ep.toSpool(b)
+	}
+	ep.mem = nil
+	ep.spills++
+}
+
+// toSpool appends one batch to the spool. Callers hold mu.
+func (ep *endpoint) toSpool(body []byte) {
+	if err := ep.spool.put(body); err != nil {
+		ep.dropped++
+		ep.sink.warn("audit webhook: spool failed; dropping batch", "url", ep.url, "error", err)
+	}
+}
+
+// head is the batch being delivered and where it came from.
+type head struct {
+	body    []byte
+	spooled bool
+	spills  uint64
+}
+
+// next returns the batch to deliver: the spool head while the spool holds
+// anything, else the memory head.
+func (ep *endpoint) next() (head, bool) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	for ep.spool != nil && ep.spool.len() > 0 {
+		b, err := ep.spool.head()
+		if err == nil {
+			return head{body: b, spooled: true}, true
+		}
+		// An unreadable file would wedge delivery; skip it.
+		ep.sink.warn("audit webhook: unreadable spool file; dropping batch", "url", ep.url, "error", err)
+		ep.dropped++
+		if err := ep.spool.pop(); err != nil {
+			return head{}, false
+		}
+	}
+	if len(ep.mem) > 0 {
+		return head{body: ep.mem[0], spills: ep.spills}, true
+	}
+	return head{}, false
+}
+
+// remove drops the batch returned by next. A memory batch may have been spilled
+// while in flight, in which case it is now the oldest spool file.
+func (ep *endpoint) remove(h head) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	if !h.spooled && h.spills == ep.spills {
+		ep.mem = ep.mem[1:]
+		return
+	}
+	if !h.spooled {
+		if ep.spool.len() == 0 {
+			return
+		}
+		if b, err := ep.spool.head(); err != nil || !bytes.Equal(b, h.body) {
+			return
+		}
+	}
+	if err := ep.spool.pop(); err != nil {
+		ep.sink.warn("audit webhook: remove spool file", "url", ep.url, "error", err)
+	}
+}
+
+func (ep *endpoint) pending() int {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return len(ep.mem)
+}
+
+// run delivers batches until stop is closed.
+func (ep *endpoint) run() {
+	defer close(ep.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-ep.stop
+		cancel()
+	}()
+
+	backoff := ep.cfg.RetryInterval
+	attempts := 0
+	for {
+		h, ok := ep.next()
+		if !ok {
+			select {
+			case <-ep.wake:
+				continue
+			case <-ep.stop:
+				return
+			}
+		}
+		err := ep.post(ctx, h.body)
+		var se *statusError
+		if err == nil || errors.As(err, &se) && se.permanent() {
+			if err != nil {
+				ep.mu.Lock()
+				ep.dropped++
+				ep.mu.Unlock()
+				ep.sink.warn("audit webhook: batch rejected; dropping", "url", ep.url, "error", err)
+			}
+			ep.remove(h)
+			backoff, attempts = ep.cfg.RetryInterval, 0
+			continue
+		}
+		attempts++
+		ep.sink.warn("audit webhook: delivery failed", "url", ep.url, "error", err, "attempt", attempts, "retry_in", backoff)
+		if !h.spooled && attempts >= ep.cfg.MaxAttempts && ep.spool != nil {
+			ep.mu.Lock()
+			ep.spill()
+			ep.mu.Unlock()
+		}
+		select {
+		case <-ep.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, ep.cfg.MaxRetryInterval)
+	}
+}
+
+func (ep *endpoint) post(ctx context.Context, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, ep.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set(SignatureHeader, Sign(ep.cfg.Secret, ts, body))
+	resp, err := ep.cfg.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{code: resp.StatusCode}
+	}
+	return nil
+}
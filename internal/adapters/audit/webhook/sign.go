@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Request headers set on every delivery.
+const (
+	SignatureHeader = "X-Kamini-Signature" // "sha256=" + hex HMAC-SHA256 of timestamp + "." + body
+	TimestampHeader = "X-Kamini-Timestamp" // Unix seconds when the request was signed
+)
+
+// Sign returns the SignatureHeader value for body signed at timestamp ts.
+// Binding the timestamp lets receivers reject replayed requests.
+func Sign(secret []byte, ts string, body []byte) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(ts))
+	m.Write([]byte{'.'})
+	m.Write(body)
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
+
+// Verify checks a delivery's signature and that its timestamp lies within
+// maxSkew of now. It is meant for receivers and tests.
+func Verify(secret []byte, ts string, body []byte, sig string, now time.Time, maxSkew time.Duration) bool {
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > maxSkew || d < -maxSkew {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(Sign(secret, ts, body)))
+}
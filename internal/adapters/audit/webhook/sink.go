@@ -0,0 +1,299 @@
+// Package webhook implements an audit sink that POSTs batches of events to
+// HTTP endpoints, signed with HMAC-SHA256, retrying with backoff and spooling
+// to disk while an endpoint is down.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// Outcome filters events by result.
+type Outcome string
+
+const (
+	OutcomeAll     Outcome = ""
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Config configures the webhook sink.
+type Config struct {
+	URLs   []string // every batch goes to each URL
+	Secret []byte   // HMAC-SHA256 key; required
+
+	Actions []domain.AuditAction // forward only these actions; empty = all
+	Outcome Outcome              // forward only successes or failures; empty = all
+
+	BatchSize        int           // events per request; default 100
+	FlushInterval    time.Duration // longest wait before a partial batch is sent; default 1s
+	QueueSize        int           // batches held in memory per URL; default 100
+	Timeout          time.Duration // per request, and for draining on Close; default 10s
+	MaxAttempts      int           // attempts before a batch is spooled; default 3
+	RetryInterval    time.Duration // first retry delay; default 1s
+	MaxRetryInterval time.Duration // backoff cap; default 1m
+
+	SpoolDir      string // undelivered batches are kept here per URL; empty keeps them in memory only
+	MaxSpoolBytes int64  // per URL; 0 = unlimited
+
+	HTTP *http.Client // optional
+}
+
+// batch is the request body.
+type batch struct {
+	ID     string             `json:"batch_id"` // stable across retries, for receiver de-duplication
+	Events []auditjson.Record `json:"events"`
+}
+
+// Sink buffers matching events and hands full (or FlushInterval-old) batches to
+// one delivery worker per URL. Write never waits for the network.
+type Sink struct {
+	cfg       Config
+	log       usecase.Logger
+	endpoints []*endpoint
+
+	mu     sync.Mutex
+	buf    []auditjson.Record
+	closed bool
+
+	full chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ usecase.AuditSink = (*Sink)(nil)
+
+// New validates cfg, opens the spools and starts the workers. Batches spooled by
+// a previous run are delivered first.
+func New(cfg Config, l usecase.Logger) (*Sink, error) {
+	if len(cfg.URLs) == 0 {
+		return nil, errors.New("audit webhook: no URLs configured")
+	}
+	if len(cfg.Secret) == 0 {
+		return nil, errors.New("audit webhook: secret is required")
+	}
+	for _, a := range cfg.Actions {
+		if !knownAction(a) {
+			return nil, fmt.Errorf("audit webhook: unknown action %q", a)
+		}
+	}
+	switch cfg.Outcome {
+	case OutcomeAll, OutcomeSuccess, OutcomeFailure:
+	default:
+		return nil, fmt.Errorf("audit webhook: unknown outcome %q (want success or failure)", cfg.Outcome)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = time.Second
+	}
+	if cfg.MaxRetryInterval < cfg.RetryInterval {
+		cfg.MaxRetryInterval = max(time.Minute, cfg.RetryInterval)
+	}
+	if cfg.HTTP == nil {
+		cfg.HTTP = &http.Client{}
+	}
+
+	s := &Sink{
+		cfg:  cfg,
+		log:  l,
+		full: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	for _, raw := range cfg.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("audit webhook: invalid URL %q", raw)
+		}
+		ep := &endpoint{
+			url:  raw,
+			cfg:  &s.cfg,
+			sink: s,
+			wake: make(chan struct{}, 1),
+			stop: make(chan struct{}),
+			done: make(chan struct{}),
+		}
+		if cfg.SpoolDir != "" {
+			// One directory per URL, named by a hash so it is stable and path-safe.
+			sum := sha256.Sum256([]byte(raw))
+			sp, err := openSpool(filepath.Join(cfg.SpoolDir, hex.EncodeToString(sum[:8])), cfg.MaxSpoolBytes)
+			if err != nil {
+				return nil, fmt.Errorf("audit webhook: %w", err)
+			}
+			ep.spool = sp
+		}
+		s.endpoints = append(s.endpoints, ep)
+	}
+	for _, ep := range s.endpoints {
+		go ep.run()
+	}
+	go s.run()
+	return s, nil
+}
+
+func knownAction(a domain.AuditAction) bool {
+	switch a {
+	case domain.ActionIssueUserCert, domain.ActionRenewUserCert, domain.ActionDeny, domain.ActionError,
+		domain.ActionRevokeCert, domain.ActionBlocklistAdd, domain.ActionBlocklistRemove:
+		return true
+	}
+	return false
+}
+
+func (s *Sink) match(ev domain.AuditEvent) bool {
+	if len(s.cfg.Actions) > 0 && !slices.Contains(s.cfg.Actions, ev.Action) {
+		return false
+	}
+	switch s.cfg.Outcome {
+	case OutcomeSuccess:
+		return ev.Success()
+	case OutcomeFailure:
+		return !ev.Success()
+	}
+	return true
+}
+
+// Write buffers ev if it passes the filter. Delivery problems surface in the
+// logs, not here: once buffered, an event is retried and spooled.
+func (s *Sink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	if !s.match(ev) {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("audit webhook: sink closed")
+	}
+	s.buf = append(s.buf, auditjson.FromEvent(ev))
+	if len(s.buf) >= s.cfg.BatchSize {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// run cuts batches when the buffer fills or FlushInterval elapses.
+func (s *Sink) run() {
+	defer close(s.done)
+	t := time.NewTicker(s.cfg.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			s.flush()
+			return
+		case <-t.C:
+			s.flush()
+		case <-s.full:
+			s.flush()
+		}
+	}
+}
+
+func (s *Sink) flush() {
+	s.mu.Lock()
+	events := s.buf
+	s.buf = nil
+	s.mu.Unlock()
+	for len(events) > 0 {
+		n := min(len(events), s.cfg.BatchSize)
+		body, err := encodeBatch(events[:n])
+		events = events[n:]
+		if err != nil {
+			s.warn("audit webhook: encode batch; dropping", "error", err)
+			continue
+		}
+		for _, ep := range s.endpoints {
+			ep.enqueue(body)
+		}
+	}
+}
+
+func encodeBatch(events []auditjson.Record) ([]byte, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(batch{ID: hex.EncodeToString(id[:]), Events: events}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Close flushes buffered events and gives the workers up to Timeout to deliver
+// what is queued in memory. Remaining batches move to the spool for the next
+// start; without a spool they are lost and reported.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+	close(s.stop)
+	<-s.done
+
+	deadline := time.Now().Add(s.cfg.Timeout)
+	for _, ep := range s.endpoints {
+		for ep.pending() > 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	lost := 0
+	for _, ep := range s.endpoints {
+		close(ep.stop)
+		<-ep.done
+		ep.mu.Lock()
+		if ep.spool != nil {
+			ep.spill()
+		}
+		lost += len(ep.mem) + ep.dropped
+		ep.mu.Unlock()
+	}
+	if lost > 0 {
+		return fmt.Errorf("audit webhook: %d batches not delivered", lost)
+	}
+	return nil
+}
+
+func (s *Sink) warn(msg string, args ...any) {
+	if s.log != nil {
+		s.log.Warn(context.Background(), msg, args...)
+	}
+}
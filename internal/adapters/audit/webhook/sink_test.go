@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+var secret = []byte("s3cret")
+
+func issued(serial uint64) domain.AuditEvent {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := domain.SignContext{Now: now, SourceIP: "10.0.0.1", TraceID: "t-1"}
+	return domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "sub"}, []string{"alice"}, serial, now, now.Add(time.Hour), ctx, nil)
+}
+
+func denied(action domain.AuditAction) domain.AuditEvent {
+	return domain.NewAuditFailure(action, domain.StageAuthz, domain.Identity{Subject: "sub"}, nil,
+		domain.SignContext{Now: time.Unix(1, 0).UTC()}, domain.ErrPolicyDenied, nil)
+}
+
+// receiver is a webhook endpoint that verifies signatures and records events.
+type receiver struct {
+	t      *testing.T
+	status atomic.Int32 // response status; 0 = 200
+	hits   atomic.Int32
+
+	mu      sync.Mutex
+	batches []batch
+}
+
+func newReceiver(t *testing.T) (*receiver, *httptest.Server) {
+	r := &receiver{t: t}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.hits.Add(1)
+		if code := r.status.Load(); code != 0 {
+			w.WriteHeader(int(code))
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		if !Verify(secret, req.Header.Get(TimestampHeader), body, req.Header.Get(SignatureHeader), time.Now(), time.Minute) {
+			t.Errorf("bad signature %q", req.Header.Get(SignatureHeader))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var b batch
+		if err := json.Unmarshal(body, &b); err != nil {
+			t.Errorf("decode batch: %v", err)
+		}
+		r.mu.Lock()
+		r.batches = append(r.batches, b)
+		r.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *receiver) serials() []uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []uint64
+	for _, b := range r.batches {
+		for _, ev := range b.Events {
+			var s uint64
+			if ev.Serial != nil {
+				s = *ev.Serial
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (r *receiver) waitFor(n int) []uint64 {
+	r.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if got := r.serials(); len(got) >= n {
+			return got
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	r.t.Fatalf("received %v, want %d events", r.serials(), n)
+	return nil
+}
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"events":[]}`)
+	sig := Sign(secret, "1700000000", body)
+	if !strings.HasPrefix(sig, "sha256=") || !Verify(secret, "1700000000", body, sig, now, time.Minute) {
+		t.Fatalf("signature does not verify: %s", sig)
+	}
+	if Verify(secret, "1700000000", []byte(`{"events":[1]}`), sig, now, time.Minute) {
+		t.Fatalf("tampered body verified")
+	}
+	if Verify([]byte("other"), "1700000000", body, sig, now, time.Minute) {
+		t.Fatalf("wrong secret verified")
+	}
+	if Verify(secret, "1700000000", body, sig, now.Add(time.Hour), time.Minute) {
+		t.Fatalf("stale timestamp verified")
+	}
+}
+
+func TestSink_BatchesInOrder(t *testing.T) {
+	r, srv := newReceiver(t)
+	s, err := New(Config{URLs: []string{srv.URL}, Secret: secret, BatchSize: 2, FlushInterval: 20 * time.Millisecond}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= 5; i++ {
+		if err := s.Write(context.Background(), issued(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := r.waitFor(5)
+	for i, serial := range got {
+		if serial != uint64(i+1) {
+			t.Fatalf("events out of order: %v", got)
+		}
+	}
+	r.mu.Lock()
+	for _, b := range r.batches {
+		if len(b.Events) > 2 || len(b.ID) != 32 {
+			t.Fatalf("unexpected batch: %+v", b)
+		}
+	}
+	r.mu.Unlock()
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := s.Write(context.Background(), issued(6)); err == nil {
+		t.Fatalf("expected error after Close")
+	}
+}
+
+func TestSink_Filter(t *testing.T) {
+	r, srv := newReceiver(t)
+	s, err := New(Config{
+		URLs:          []string{srv.URL},
+		Secret:        secret,
+		Actions:       []domain.AuditAction{domain.ActionIssueUserCert},
+		Outcome:       OutcomeFailure,
+		FlushInterval: 10 * time.Millisecond,
+	}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range []domain.AuditEvent{issued(1), denied(domain.ActionRevokeCert), denied(domain.ActionIssueUserCert)} {
+		if err := s.Write(context.Background(), ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.batches) != 1 || len(r.batches[0].Events) != 1 {
+		t.Fatalf("unexpected batches: %+v", r.batches)
+	}
+	if ev := r.batches[0].Events[0]; ev.Action != string(domain.ActionIssueUserCert) || ev.Success {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+}
+
+func TestSink_SpoolsWhileDownAndResumes(t *testing.T) {
+	r, srv := newReceiver(t)
+	r.status.Store(http.StatusServiceUnavailable)
+	dir := t.TempDir()
+	cfg := Config{
+		URLs:             []string{srv.URL},
+		Secret:           secret,
+		BatchSize:        1,
+		FlushInterval:    5 * time.Millisecond,
+		Timeout:          200 * time.Millisecond,
+		MaxAttempts:      1,
+		RetryInterval:    5 * time.Millisecond,
+		MaxRetryInterval: 10 * time.Millisecond,
+		SpoolDir:         dir,
+	}
+	s, err := New(cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= 3; i++ {
+		if err := s.Write(context.Background(), issued(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Wait until the retries have moved everything to disk.
+	deadline := time.Now().Add(5 * time.Second)
+	for spooled(t, dir) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("spooled %d batches, want 3", spooled(t, dir))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close with spool: %v", err)
+	}
+
+	// Next start: the endpoint is back and the spool drains in order.
+	r.status.Store(0)
+	deadline = time.Now().Add(5 * time.Second)
+	s, err = New(cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(context.Background(), issued(4)); err != nil {
+		t.Fatal(err)
+	}
+	got := r.waitFor(4)
+	if len(got) != 4 || got[0] != 1 || got[3] != 4 {
+		t.Fatalf("unexpected delivery order: %v", got)
+	}
+	for spooled(t, dir) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("spool not drained: %d files left", spooled(t, dir))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func spooled(t *testing.T, dir string) int {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(files)
+}
+
+func TestSink_RejectedBatchIsDropped(t *testing.T) {
+	r, srv := newReceiver(t)
+	r.status.Store(http.StatusBadRequest)
+	s, err := New(Config{URLs: []string{srv.URL}, Secret: secret, FlushInterval: 5 * time.Millisecond, RetryInterval: 5 * time.Millisecond}, ilog.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(context.Background(), issued(1)); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for r.hits.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err := s.Close(); err == nil || !strings.Contains(err.Error(), "1 batches not delivered") {
+		t.Fatalf("expected undelivered error, got %v", err)
+	}
+	if n := r.hits.Load(); n != 1 {
+		t.Fatalf("a 400 must not be retried; got %d requests", n)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	bad := []Config{
+		{Secret: secret},
+		{URLs: []string{"https://alerts.example.com/hook"}},
+		{URLs: []string{"ftp://alerts.example.com"}, Secret: secret},
+		{URLs: []string{"https://alerts.example.com/hook"}, Secret: secret, Actions: []domain.AuditAction{"LAUNCH"}},
+		{URLs: []string{"https://alerts.example.com/hook"}, Secret: secret, Outcome: "maybe"},
+	}
+	for _, cfg := range bad {
+		if s, err := New(cfg, ilog.NewNop()); err == nil {
+			_ = s.Close()
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+	// A spool directory that cannot be created.
+	f := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(f, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(Config{URLs: []string{"https://alerts.example.com/hook"}, Secret: secret, SpoolDir: f}, ilog.NewNop()); err == nil {
+		t.Fatalf("expected spool error")
+	}
+}
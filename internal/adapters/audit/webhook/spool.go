@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errSpoolFull = errors.New("spool full")
+
+// spool is a directory of undelivered batches, one file per batch, named by a
+// zero-padded sequence number so that lexical order is delivery order. It
+// survives restarts. Not safe for concurrent use; the endpoint serialises access.
+type spool struct {
+	dir   string
+	max   int64 // bytes; 0 = unlimited
+	files []spoolFile
+	size  int64
+	next  uint64
+}
+
+type spoolFile struct {
+	path string
+	size int64
+}
+
+func openSpool(dir string, maxBytes int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool dir: %w", err)
+	}
+	sp := &spool{dir: dir, max: maxBytes, next: 1}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			// Interrupted write; the batch was never acknowledged as spooled.
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("stat spool file: %w", err)
+		}
+		sp.files = append(sp.files, spoolFile{path: filepath.Join(dir, name), size: info.Size()})
+		sp.size += info.Size()
+		sp.next = max(sp.next, seq+1)
+	}
+	sort.Slice(sp.files, func(i, j int) bool { return sp.files[i].path < sp.files[j].path })
+	return sp, nil
+}
+
+func (sp *spool) len() int { return len(sp.files) }
+
+// put appends body as the newest batch. The file is synced before it counts as
+// spooled.
+func (sp *spool) put(body []byte) error {
+	if sp.max > 0 && sp.size+int64(len(body)) > sp.max {
+		return errSpoolFull
+	}
+	path := filepath.Join(sp.dir, fmt.Sprintf("%020d.json", sp.next))
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(body); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	sp.next++
+	sp.files = append(sp.files, spoolFile{path: path, size: int64(len(body))})
+	sp.size += int64(len(body))
+	return nil
+}
+
+// head returns the oldest batch.
+func (sp *spool) head() ([]byte, error) {
+	return os.ReadFile(sp.files[0].path)
+}
+
+// pop removes the oldest batch.
+func (sp *spool) pop() error {
+	f := sp.files[0]
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	sp.files = sp.files[1:]
+	sp.size -= f.size
+	return nil
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	oidc "github.com/coreos/go-oidc/v3/oidc"
+
+	"github.com/haukened/kamini/internal/adapters/ttlcache"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// IntrospectionConfig configures RFC 7662 token introspection for opaque
+// access tokens.
+type IntrospectionConfig struct {
+	URL          string // default: introspection_endpoint from discovery
+	ClientID     string // Kamini's credentials at the authorization server
+	ClientSecret string
+
+	// CacheTTL bounds how long an active response is reused (never past the
+	// token's exp); default 1m. Inactive responses are not cached.
+	CacheTTL   time.Duration
+	MaxEntries int           // cached tokens; default 10000
+	Clock      usecase.Clock // optional
+}
+
+// isJWT reports whether token looks like a compact JWS; anything else is
+// treated as opaque.
+func isJWT(token string) bool { return strings.Count(token, ".") == 2 }
+
+// checkAccessTokenType enforces the RFC 9068 "typ" header, so ID tokens (and
+// other JWTs signed by the same keys) are not accepted as access tokens.
+func checkAccessTokenType(token string) error {
+	head, _, _ := strings.Cut(token, ".")
+	raw, err := base64.RawURLEncoding.DecodeString(head)
+	if err != nil {
+		return fmt.Errorf("%w: malformed jwt header: %w", domain.ErrInvalidToken, err)
+	}
+	var h struct {
+		Typ string `json:"typ"`
+	}
+	if err := json.Unmarshal(raw, &h); err != nil {
+		return fmt.Errorf("%w: malformed jwt header: %w", domain.ErrInvalidToken, err)
+	}
+	switch strings.ToLower(h.Typ) {
+	case "at+jwt", "application/at+jwt":
+		return nil
+	default:
+		return fmt.Errorf("%w: not a JWT access token (typ %q)", domain.ErrInvalidToken, h.Typ)
+	}
+}
+
+// introspector asks the authorization server about opaque tokens and caches
+// active responses by token hash.
+type introspector struct {
+	cfg    IntrospectionConfig
+	client *http.Client
+
+	cache *ttlcache.Cache[[sha256.Size]byte, map[string]any]
+}
+
+func newIntrospector(cfg IntrospectionConfig, provider *oidc.Provider, client *http.Client) (*introspector, error) {
+	if cfg.URL == "" {
+		var meta struct {
+			Endpoint string `json:"introspection_endpoint"`
+		}
+		if err := provider.Claims(&meta); err != nil {
+			return nil, fmt.Errorf("introspection: read discovery metadata: %w", err)
+		}
+		if meta.Endpoint == "" {
+			return nil, errors.New("introspection: no URL configured and none in discovery metadata")
+		}
+		cfg.URL = meta.Endpoint
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("introspection: client ID required")
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = time.Minute
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = domain.SystemClock()
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &introspector{cfg: cfg, client: client, cache: ttlcache.New[[sha256.Size]byte, map[string]any](cfg.MaxEntries)}, nil
+}
+
+// introspect returns the claims of an active token. Inactive or expired tokens
+// wrap domain.ErrInvalidToken; an unreachable endpoint is a plain error.
+func (in *introspector) introspect(ctx context.Context, token string) (map[string]any, error) {
+	key := sha256.Sum256([]byte(token))
+	now := in.cfg.Clock.Now()
+	if claims, ok := in.cache.Get(key, now); ok {
+		return maps.Clone(claims), nil // callers may add (userinfo) claims
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, in.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(in.cfg.ClientID), url.QueryEscape(in.cfg.ClientSecret))
+	resp, err := in.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection: %s", resp.Status)
+	}
+	var claims map[string]any
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("introspection: decode response: %w", err)
+	}
+	if !getBool(claims, "active") {
+		return nil, fmt.Errorf("%w: token is not active", domain.ErrInvalidToken)
+	}
+	expires := now.Add(in.cfg.CacheTTL)
+	if exp, ok := claims["exp"].(float64); ok {
+		t := time.Unix(int64(exp), 0)
+		if !now.Before(t) {
+			return nil, fmt.Errorf("%w: token expired", domain.ErrInvalidToken)
+		}
+		if t.Before(expires) {
+			expires = t
+		}
+	}
+
+	in.cache.Set(key, maps.Clone(claims), expires)
+	return claims, nil
+}
+
+// authenticateOpaque maps an introspection response like a verified token.
+// A response without iss is taken to come from this issuer.
+func (a *OIDCAuthenticator) authenticateOpaque(ctx context.Context, token string) (domain.Identity, error) {
+	claims, err := a.introspect.introspect(ctx, token)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	sub := getString(claims, "sub")
+	if sub == "" {
+		return domain.Identity{}, fmt.Errorf("%w: introspection response has no sub", domain.ErrInvalidToken)
+	}
+	iss := getString(claims, "iss")
+	if iss == "" {
+		iss = strings.ReplaceAll(a.checks.issuer, TenantPlaceholder, getString(claims, "tid"))
+	}
+	aud := getStringSlice(claims, "aud")
+	if s := getString(claims, "aud"); s != "" {
+		aud = []string{s}
+	}
+	return a.identity(ctx, token, iss, aud, sub, claims)
+}
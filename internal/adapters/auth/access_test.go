@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func TestOIDCAuthenticator_AccessTokens(t *testing.T) {
+	idp := oidctest.New(t)
+
+	a, err := NewOIDCAuthenticator(context.Background(), OIDCAuthConfig{
+		IssuerURL:         idp.URL,
+		Audiences:         []string{"api://kamini"},
+		AccessTokens:      true,
+		AuthorizedParties: []string{"kamini-cli"},
+	}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	ctx := context.Background()
+	at := idp.AccessToken("api://kamini", "sub-1", map[string]any{
+		"client_id": "kamini-cli",
+		"scope":     "openid ssh:sign",
+		"roles":     []string{"ops"},
+	})
+	id, err := a.Authenticate(ctx, "Bearer "+at)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if id.Subject != "sub-1" || id.Username != "sub-1" || !slices.Equal(id.Roles, []string{"ops"}) || id.Claims["client_id"] != "kamini-cli" {
+		t.Fatalf("identity: %+v", id)
+	}
+	if scope, _ := id.Claims["scope"].([]string); !slices.Equal(scope, []string{"openid", "ssh:sign"}) {
+		t.Fatalf("scope=%v", id.Claims["scope"])
+	}
+
+	// An ID token signed by the same keys is not an access token.
+	idToken := idp.IDToken("api://kamini", "sub-1", map[string]any{"client_id": "kamini-cli"})
+	if _, err := a.Authenticate(ctx, idToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected typ rejection, got %v", err)
+	}
+	// Audience is Kamini's API, not the client.
+	wrongAud := idp.AccessToken("kamini-cli", "sub-1", map[string]any{"client_id": "kamini-cli"})
+	if _, err := a.Authenticate(ctx, wrongAud); !errors.Is(err, domain.ErrAudienceMismatch) {
+		t.Fatalf("expected audience mismatch, got %v", err)
+	}
+	otherClient := idp.AccessToken("api://kamini", "sub-1", map[string]any{"client_id": "other"})
+	if _, err := a.Authenticate(ctx, otherClient); !errors.Is(err, domain.ErrPartyMismatch) {
+		t.Fatalf("expected client_id mismatch, got %v", err)
+	}
+	noSub := idp.AccessToken("api://kamini", "", map[string]any{"client_id": "kamini-cli"})
+	if _, err := a.Authenticate(ctx, noSub); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected missing sub rejection, got %v", err)
+	}
+}
+
+// newIntrospectionServer answers RFC 7662 requests from responses keyed by token.
+func newIntrospectionServer(t *testing.T, responses map[string]map[string]any, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "kamini" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("token_type_hint") != "access_token" {
+			t.Errorf("unexpected form: %v %v", err, r.PostForm)
+		}
+		resp, ok := responses[r.PostForm.Get("token")]
+		if !ok {
+			resp = map[string]any{"active": false}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestOIDCAuthenticator_Introspection(t *testing.T) {
+	idp := oidctest.New(t)
+
+	clock := &fakeClock{t: time.Now()}
+	exp := float64(clock.t.Add(time.Hour).Unix())
+	var calls atomic.Int32
+	as := newIntrospectionServer(t, map[string]map[string]any{
+		"opaque-1":  {"active": true, "sub": "sub-1", "username": "Alice", "aud": "api://kamini", "scope": "ssh:sign", "exp": exp},
+		"other-aud": {"active": true, "sub": "sub-2", "aud": []string{"api://other"}, "exp": exp},
+		"no-sub":    {"active": true, "aud": "api://kamini", "exp": exp},
+	}, &calls)
+	defer as.Close()
+
+	cfg := OIDCAuthConfig{
+		IssuerURL:     idp.URL,
+		Audiences:     []string{"api://kamini"},
+		AccessTokens:  true,
+		UsernameClaim: "username",
+		Introspection: &IntrospectionConfig{URL: as.URL, ClientID: "kamini", ClientSecret: "s3cret", CacheTTL: time.Minute, Clock: clock},
+	}
+	a, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	ctx := context.Background()
+	id, err := a.Authenticate(ctx, "Bearer opaque-1")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if id.Subject != "sub-1" || id.Username != "alice" || id.Issuer() != idp.URL {
+		t.Fatalf("identity: %+v", id)
+	}
+	if _, err := a.Authenticate(ctx, "opaque-1"); err != nil || calls.Load() != 1 {
+		t.Fatalf("expected cached response: err=%v calls=%d", err, calls.Load())
+	}
+	clock.t = clock.t.Add(2 * time.Minute)
+	if _, err := a.Authenticate(ctx, "opaque-1"); err != nil || calls.Load() != 2 {
+		t.Fatalf("expected refresh after CacheTTL: err=%v calls=%d", err, calls.Load())
+	}
+
+	if _, err := a.Authenticate(ctx, "revoked"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected inactive token rejection, got %v", err)
+	}
+	if _, err := a.Authenticate(ctx, "other-aud"); !errors.Is(err, domain.ErrAudienceMismatch) {
+		t.Fatalf("expected audience mismatch, got %v", err)
+	}
+	if _, err := a.Authenticate(ctx, "no-sub"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected missing sub rejection, got %v", err)
+	}
+
+	// JWTs are still verified locally.
+	at := idp.AccessToken("api://kamini", "sub-3", nil)
+	before := calls.Load()
+	if _, err := a.Authenticate(ctx, at); err != nil || calls.Load() != before {
+		t.Fatalf("JWT access token: err=%v introspected=%v", err, calls.Load() != before)
+	}
+
+	// A failing endpoint is not an invalid token.
+	cfg.Introspection = &IntrospectionConfig{URL: as.URL, ClientID: "kamini", ClientSecret: "wrong"}
+	bad, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	if _, err := bad.Authenticate(ctx, "opaque-1"); err == nil || errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected endpoint error, got %v", err)
+	}
+
+	// Discovery without introspection_endpoint needs an explicit URL.
+	cfg.Introspection = &IntrospectionConfig{ClientID: "kamini"}
+	if _, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop()); err == nil {
+		t.Fatalf("expected missing introspection endpoint error")
+	}
+}
+
+func TestMultiOIDCAuthenticator_RoutesOpaqueTokens(t *testing.T) {
+	var calls atomic.Int32
+	as := newIntrospectionServer(t, map[string]map[string]any{
+		"opaque-1": {"active": true, "sub": "sub-1", "aud": "api://kamini"},
+	}, &calls)
+	defer as.Close()
+	s1 := oidctest.New(t)
+	s2 := oidctest.New(t)
+
+	intro := &IntrospectionConfig{URL: as.URL, ClientID: "kamini", ClientSecret: "s3cret"}
+	cfgs := []OIDCAuthConfig{
+		{Name: "entra", IssuerURL: s1.URL, ClientID: "kamini"},
+		{Name: "keycloak", IssuerURL: s2.URL, Audiences: []string{"api://kamini"}, AccessTokens: true, Introspection: intro},
+	}
+	m, err := NewMultiOIDCAuthenticator(context.Background(), cfgs, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewMultiOIDCAuthenticator: %v", err)
+	}
+	id, err := m.Authenticate(context.Background(), "Bearer opaque-1")
+	if err != nil || id.Provider != "oidc:keycloak" {
+		t.Fatalf("opaque token: %+v, %v", id, err)
+	}
+
+	cfgs[0].Introspection = intro
+	if _, err := NewMultiOIDCAuthenticator(context.Background(), cfgs, ilog.NewNop()); err == nil {
+		t.Fatalf("expected error with two introspecting issuers")
+	}
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// claimMapper reads one claim and normalizes its values. A spec is a claim
+// path followed by optional stages separated by " | ":
+//
+//	realm_access.roles
+//	resource_access["kamini-cli"].roles | trim_prefix:ssh- | lower
+//	$.groups | regex:^team-(.+)$
+//	scope
+//
+// Paths are dotted (an optional "$." prefix is accepted, JSONPath style);
+// bracketed, quoted segments hold keys containing dots. A top-level claim
+// named exactly like the whole path (e.g. "https://example.com/roles") wins
+// over path resolution.
+//
+// Stages: lower, upper, trim_prefix:<s>, trim_suffix:<s>, and regex:<re>,
+// which drops values that do not match and keeps the first capture group (or
+// the whole match). Values that end up empty are dropped.
+type claimMapper struct {
+	spec   string // the path as written, for the exact top-level lookup
+	path   []string
+	stages []func(string) (string, bool)
+}
+
+func parseClaimMapper(spec string) (claimMapper, error) {
+	parts := strings.Split(spec, " | ")
+	m := claimMapper{spec: strings.TrimSpace(parts[0])}
+	path, err := parseClaimPath(m.spec)
+	if err != nil {
+		return claimMapper{}, fmt.Errorf("claim %q: %w", spec, err)
+	}
+	m.path = path
+	for _, st := range parts[1:] {
+		f, err := parseStage(strings.TrimSpace(st))
+		if err != nil {
+			return claimMapper{}, fmt.Errorf("claim %q: %w", spec, err)
+		}
+		m.stages = append(m.stages, f)
+	}
+	return m, nil
+}
+
+func parseStage(st string) (func(string) (string, bool), error) {
+	name, arg, _ := strings.Cut(st, ":")
+	switch name {
+	case "lower":
+		return func(s string) (string, bool) { return strings.ToLower(s), true }, nil
+	case "upper":
+		return func(s string) (string, bool) { return strings.ToUpper(s), true }, nil
+	case "trim_prefix":
+		return func(s string) (string, bool) { return strings.TrimPrefix(s, arg), true }, nil
+	case "trim_suffix":
+		return func(s string) (string, bool) { return strings.TrimSuffix(s, arg), true }, nil
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(s string) (string, bool) {
+			sm := re.FindStringSubmatch(s)
+			switch {
+			case sm == nil:
+				return "", false
+			case len(sm) > 1:
+				return sm[1], true
+			default:
+				return sm[0], true
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown stage %q", name)
+	}
+}
+
+// parseClaimPath splits a.b["c.d"].e into [a b c.d e].
+func parseClaimPath(p string) ([]string, error) {
+	p = strings.TrimPrefix(p, "$.")
+	if p == "" {
+		return nil, errors.New("empty claim path")
+	}
+	var segs []string
+	for p != "" {
+		if p[0] == '[' {
+			if len(p) < 4 || (p[1] != '"' && p[1] != '\'') {
+				return nil, errors.New("bracket segments must be quoted")
+			}
+			end := strings.IndexByte(p[2:], p[1])
+			if end < 0 || len(p) < end+4 || p[end+3] != ']' {
+				return nil, errors.New("unterminated bracket segment")
+			}
+			segs = append(segs, p[2:end+2])
+			p = p[end+4:]
+		} else {
+			i := strings.IndexAny(p, ".[")
+			if i < 0 {
+				i = len(p)
+			}
+			if i == 0 {
+				return nil, errors.New("empty path segment")
+			}
+			segs = append(segs, p[:i])
+			p = p[i:]
+		}
+		if strings.HasPrefix(p, ".") {
+			p = p[1:]
+			if p == "" {
+				return nil, errors.New("trailing dot")
+			}
+		}
+	}
+	return segs, nil
+}
+
+// lookup returns the raw claim value, or nil.
+func (m claimMapper) lookup(claims map[string]any) any {
+	if v, ok := claims[m.spec]; ok {
+		return v
+	}
+	var cur any = claims
+	for _, seg := range m.path {
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = obj[seg]
+	}
+	return cur
+}
+
+// values returns the mapped values. Arrays yield their string elements; a
+// string claim is split on whitespace (e.g. "scope") when split is set.
+func (m claimMapper) values(claims map[string]any, split bool) []string {
+	var raw []string
+	switch v := m.lookup(claims).(type) {
+	case string:
+		if split {
+			raw = strings.Fields(v)
+		} else {
+			raw = []string{v}
+		}
+	case []any:
+		for _, it := range v {
+			if s, ok := it.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	case []string:
+		raw = v
+	}
+	return m.apply(raw)
+}
+
+// apply runs the mapping stages over values obtained elsewhere (e.g. groups
+// resolved after an overage), so they match what the claim would have yielded.
+func (m claimMapper) apply(raw []string) []string {
+	var out []string
+	for _, s := range raw {
+		ok := true
+		for _, st := range m.stages {
+			if s, ok = st(s); !ok {
+				break
+			}
+		}
+		if ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// strings maps a multi-valued claim (roles, groups).
+func (m claimMapper) strings(claims map[string]any) []string { return m.values(claims, true) }
+
+// string maps a single-valued claim (username, email): the first value.
+func (m claimMapper) string(claims map[string]any) string {
+	if vs := m.values(claims, false); len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+func TestParseClaimPath(t *testing.T) {
+	tests := map[string][]string{
+		"roles":                               {"roles"},
+		"$.realm_access.roles":                {"realm_access", "roles"},
+		`resource_access["kamini.cli"].roles`: {"resource_access", "kamini.cli", "roles"},
+		`a['b'].c`:                            {"a", "b", "c"},
+		`["x.y"]`:                             {"x.y"},
+	}
+	for in, want := range tests {
+		got, err := parseClaimPath(in)
+		if err != nil || !slices.Equal(got, want) {
+			t.Fatalf("%s: got %v, %v want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "$.", "a..b", "a.", "a[b]", `a["b`, `a["b"`, `a["b"x`} {
+		if _, err := parseClaimPath(bad); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestClaimMapper(t *testing.T) {
+	claims := map[string]any{
+		"realm_access":                     map[string]any{"roles": []any{"ssh-Admin", "offline_access", "ssh-dev"}},
+		"resource_access":                  map[string]any{"kamini.cli": map[string]any{"roles": []any{"operator"}}},
+		"scope":                            "openid profile ssh:login",
+		"groups":                           []any{"/team-infra", "/other", 7},
+		"https://example.com/claims/roles": []any{"urlrole"},
+		"emails":                           []any{"Alice@Example.com", "a2@example.com"},
+	}
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"realm_access.roles | regex:^ssh-(.+)$ | lower", []string{"admin", "dev"}},
+		{`resource_access["kamini.cli"].roles`, []string{"operator"}},
+		{"scope", []string{"openid", "profile", "ssh:login"}},
+		{"scope | regex:^ssh:", []string{"ssh:"}},
+		{"groups | trim_prefix:/ | regex:^team-", []string{"team-"}},
+		{"groups | trim_prefix:/team- | trim_suffix:a | upper", []string{"INFR", "/OTHER"}},
+		{"https://example.com/claims/roles", []string{"urlrole"}},
+		{"missing.path", nil},
+		{"scope.nested", nil},
+	}
+	for _, tt := range tests {
+		m, err := parseClaimMapper(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.spec, err)
+		}
+		if got := m.strings(claims); !slices.Equal(got, tt.want) {
+			t.Fatalf("%s: got %v want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	m, _ := parseClaimMapper("emails | lower")
+	if got := m.string(claims); got != "alice@example.com" {
+		t.Fatalf("single value from array: %q", got)
+	}
+	m, _ = parseClaimMapper("scope")
+	if got := m.string(claims); got != "openid profile ssh:login" {
+		t.Fatalf("single-valued string must not be split: %q", got)
+	}
+
+	for _, bad := range []string{"roles | nope", "roles | regex:(", "a..b | lower"} {
+		if _, err := parseClaimMapper(bad); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestOIDCAuthenticator_KeycloakClaims(t *testing.T) {
+	idp := oidctest.New(t)
+
+	cfg := OIDCAuthConfig{
+		IssuerURL:     idp.URL,
+		ClientID:      "kamini",
+		UsernameClaim: "preferred_username | trim_suffix:@corp",
+		RolesClaim:    `resource_access["kamini"].roles | trim_prefix:ssh- | lower`,
+		GroupsClaim:   "realm_access.roles",
+	}
+	a, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	token := idp.IDToken("kamini", "sub-1", map[string]any{
+		"preferred_username": "bob@corp",
+		"realm_access":       map[string]any{"roles": []string{"staff"}},
+		"resource_access":    map[string]any{"kamini": map[string]any{"roles": []string{"ssh-OPS"}}},
+	})
+	id, err := a.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if id.Username != "bob" || !slices.Equal(id.Roles, []string{"ops"}) || !slices.Equal(id.Groups, []string{"staff"}) {
+		t.Fatalf("identity: %+v", id)
+	}
+
+	cfg.RolesClaim = "roles | regex:["
+	if _, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop()); err == nil {
+		t.Fatalf("expected invalid claim spec error")
+	}
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+func TestClaimChecks(t *testing.T) {
+	const tmpl = "https://login.example.com/" + TenantPlaceholder + "/v2.0"
+	const tenant = "11111111-2222-3333-4444-555555555555"
+	c := claimChecks{
+		issuer:      tmpl,
+		multiTenant: true,
+		audiences:   []string{"kamini"},
+		tenants:     []string{tenant},
+		parties:     []string{"kamini-cli"},
+		amr:         []string{"mfa"},
+		acr:         []string{"c2", "c3"},
+	}
+	iss := "https://login.example.com/" + tenant + "/v2.0"
+	ok := func() map[string]any {
+		return map[string]any{"tid": tenant, "azp": "kamini-cli", "amr": []any{"pwd", "mfa"}, "acr": "c2"}
+	}
+	if err := c.check(iss, []string{"kamini"}, ok()); err != nil {
+		t.Fatalf("valid claims: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		iss    string
+		aud    []string
+		mutate func(map[string]any)
+		want   error
+	}{
+		{"no tid", iss, []string{"kamini"}, func(m map[string]any) { delete(m, "tid") }, domain.ErrTenantMismatch},
+		{"iss for another tenant", "https://login.example.com/other/v2.0", []string{"kamini"}, nil, domain.ErrIssuerMismatch},
+		{"tenant not allowed", "https://login.example.com/other/v2.0", []string{"kamini"}, func(m map[string]any) { m["tid"] = "other" }, domain.ErrTenantMismatch},
+		{"audience", iss, []string{"someone-else"}, nil, domain.ErrAudienceMismatch},
+		{"azp", iss, []string{"kamini"}, func(m map[string]any) { m["azp"] = "rogue-app" }, domain.ErrPartyMismatch},
+		{"appid fallback", iss, []string{"kamini"}, func(m map[string]any) { delete(m, "azp"); m["appid"] = "kamini-cli" }, nil},
+		{"no mfa", iss, []string{"kamini"}, func(m map[string]any) { m["amr"] = []any{"pwd"} }, domain.ErrMFARequired},
+		{"acr", iss, []string{"kamini"}, func(m map[string]any) { m["acr"] = "c1" }, domain.ErrACRInsufficient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := ok()
+			if tt.mutate != nil {
+				tt.mutate(claims)
+			}
+			err := c.check(tt.iss, tt.aud, claims)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if !c.matchesIssuer(iss) || c.matchesIssuer("https://login.example.com//v2.0") || c.matchesIssuer("https://login.example.com/a/b/v2.0") {
+		t.Fatalf("matchesIssuer template")
+	}
+	if (claimChecks{issuer: "https://a"}).matchesIssuer("https://b") {
+		t.Fatalf("matchesIssuer exact")
+	}
+}
+
+// newMultiTenantProvider mimics Entra's common endpoint: metadata is served
+// under /common/v2.0 but names the issuer template.
+func newMultiTenantProvider(t *testing.T) *oidctest.Provider {
+	t.Helper()
+	idp := oidctest.New(t)
+	idp.HandleFunc("/common/v2.0"+oidctest.PathDiscovery, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":   idp.URL + "/" + TenantPlaceholder + "/v2.0",
+			"jwks_uri": idp.URL + oidctest.PathJWKS,
+		})
+	})
+	return idp
+}
+
+func TestOIDCAuthenticator_MultiTenant(t *testing.T) {
+	idp := newMultiTenantProvider(t)
+	tmpl := idp.URL + "/" + TenantPlaceholder + "/v2.0"
+
+	if _, err := NewOIDCAuthenticator(context.Background(), OIDCAuthConfig{IssuerURL: tmpl, ClientID: "kamini"}, ilog.NewNop()); err == nil {
+		t.Fatalf("expected error: multi-tenant issuer without tenant IDs")
+	}
+	a, err := NewMultiOIDCAuthenticator(context.Background(), []OIDCAuthConfig{{
+		Name:        "entra",
+		IssuerURL:   tmpl,
+		ClientID:    "kamini",
+		TenantIDs:   []string{"home"},
+		RequiredAMR: []string{"mfa"},
+	}}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewMultiOIDCAuthenticator: %v", err)
+	}
+
+	token := func(tid string, amr ...string) string {
+		return idp.IDToken("kamini", "sub", map[string]any{"iss": idp.URL + "/" + tid + "/v2.0", "tid": tid, "amr": amr})
+	}
+	id, err := a.Authenticate(context.Background(), token("home", "pwd", "mfa"))
+	if err != nil {
+		t.Fatalf("home tenant: %v", err)
+	}
+	if id.Claims["tid"] != "home" || id.Issuer() != idp.URL+"/home/v2.0" {
+		t.Fatalf("claims: %+v", id.Claims)
+	}
+	if _, err := a.Authenticate(context.Background(), token("guest", "mfa")); !errors.Is(err, domain.ErrTenantMismatch) {
+		t.Fatalf("guest tenant: got %v", err)
+	}
+	if _, err := a.Authenticate(context.Background(), token("home", "pwd")); !errors.Is(err, domain.ErrMFARequired) {
+		t.Fatalf("no mfa: got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// ProviderDev is the Identity.Provider set by DevAuthenticator.
+const ProviderDev = "dev"
+
+// devIssuer is the "iss" of development tokens; they are not OIDC tokens and
+// no OIDC issuer accepts them.
+const devIssuer = "kamini-dev"
+
+// DevToken describes a development token minted by MintDevToken.
+type DevToken struct {
+	Subject  string // required
+	Username string // default: Subject
+	Email    string
+	Groups   []string
+	Roles    []string
+	TTL      time.Duration // default 1h
+}
+
+type devClaims struct {
+	jwt.RegisteredClaims
+	Username string   `json:"preferred_username,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+}
+
+// MintDevToken signs t with key, issued at now.
+func MintDevToken(key ed25519.PrivateKey, t DevToken, now time.Time) (string, error) {
+	if t.Subject == "" {
+		return "", errors.New("dev token: subject required")
+	}
+	if t.TTL <= 0 {
+		t.TTL = time.Hour
+	}
+	c := devClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    devIssuer,
+			Subject:   t.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.TTL)),
+		},
+		Username: firstNonEmpty(t.Username, t.Subject),
+		Email:    t.Email,
+		Groups:   t.Groups,
+		Roles:    t.Roles,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodEdDSA, c).SignedString(key)
+}
+
+// LoadOrCreateDevKey reads the PKCS#8 PEM ed25519 key at path, generating it
+// (mode 0600) when the file does not exist, so the server and
+// `kamini-server dev token` share a key without setup.
+func LoadOrCreateDevKey(path string) (ed25519.PrivateKey, error) {
+	return loadOrCreateKey(path, "dev key")
+}
+
+// loadOrCreateKey reads the PKCS#8 PEM ed25519 key at path, generating it
+// (mode 0600) when the file does not exist; what names the key in errors.
+func loadOrCreateKey(path, what string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+			return nil, fmt.Errorf("write %s: %w", what, err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", what, err)
+	}
+	blk, _ := pem.Decode(data)
+	if blk == nil {
+		return nil, fmt.Errorf("%s %s: not PEM", what, path)
+	}
+	k, err := x509.ParsePKCS8PrivateKey(blk.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", what, path, err)
+	}
+	key, ok := k.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s %s: want ed25519, got %T", what, path, k)
+	}
+	return key, nil
+}
+
+// DevAuthConfig configures DevAuthenticator.
+type DevAuthConfig struct {
+	KeyPath string        // created if missing; see LoadOrCreateDevKey
+	Clock   usecase.Clock // optional
+}
+
+// DevAuthenticator accepts tokens minted by MintDevToken with the local dev
+// key, so the whole flow runs without an IdP. Anyone who can read the key can
+// become anyone: never enable it outside development.
+type DevAuthenticator struct {
+	pub   ed25519.PublicKey
+	clock usecase.Clock
+	L     usecase.Logger
+}
+
+var _ usecase.Authenticator = (*DevAuthenticator)(nil)
+
+// NewDevAuthenticator loads (or creates) the dev key and logs a warning that
+// development authentication is enabled.
+func NewDevAuthenticator(ctx context.Context, cfg DevAuthConfig, l usecase.Logger) (*DevAuthenticator, error) {
+	if cfg.KeyPath == "" {
+		return nil, errors.New("dev key path required")
+	}
+	key, err := LoadOrCreateDevKey(cfg.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = domain.SystemClock()
+	}
+	if l != nil {
+		l.Warn(ctx, "DEVELOPMENT AUTHENTICATION ENABLED: any holder of the dev key can obtain certificates for any identity; do not use in production", "key_path", cfg.KeyPath)
+	}
+	return &DevAuthenticator{pub: key.Public().(ed25519.PublicKey), clock: cfg.Clock, L: l}, nil
+}
+
+// Authenticate verifies a dev token and maps its claims like an ID token.
+func (a *DevAuthenticator) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	token := stripBearer(bearer)
+	if token == "" {
+		return domain.Identity{}, errors.New("empty bearer token")
+	}
+	var c devClaims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) { return a.pub, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(devIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(a.clock.Now),
+	)
+	if err != nil {
+		return domain.Identity{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
+	}
+	if c.Subject == "" {
+		return domain.Identity{}, fmt.Errorf("%w: dev token has no sub", domain.ErrInvalidToken)
+	}
+	extras := map[string]any{"iss": devIssuer}
+	if c.IssuedAt != nil {
+		extras["auth_time"] = c.IssuedAt.UTC()
+	}
+	id := domain.Identity{
+		Subject:  c.Subject,
+		Username: strings.ToLower(firstNonEmpty(c.Username, c.Subject)),
+		Email:    strings.ToLower(c.Email),
+		Roles:    c.Roles,
+		Groups:   c.Groups,
+		Claims:   extras,
+		Provider: ProviderDev,
+	}
+	if a.L != nil {
+		a.L.Warn(ctx, "dev token accepted", "sub", id.Subject, "username", id.Username)
+	}
+	return id, nil
+}
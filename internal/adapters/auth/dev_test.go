@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestDevAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.key")
+	now := time.Unix(1_700_000_000, 0).UTC()
+	ctx := context.Background()
+	a, err := NewDevAuthenticator(ctx, DevAuthConfig{KeyPath: path, Clock: &fakeClock{now}}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewDevAuthenticator: %v", err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Fatalf("key not created 0600: %v %v", fi, err)
+	}
+
+	// The CLI loads the key the server created.
+	key, err := LoadOrCreateDevKey(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreateDevKey: %v", err)
+	}
+	tok, err := MintDevToken(key, DevToken{Subject: "alice", Email: "Alice@Example.com", Groups: []string{"ssh-users"}}, now)
+	if err != nil {
+		t.Fatalf("MintDevToken: %v", err)
+	}
+	id, err := a.Authenticate(ctx, "Bearer "+tok)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if id.Subject != "alice" || id.Username != "alice" || id.Email != "alice@example.com" || !slices.Equal(id.Groups, []string{"ssh-users"}) || id.Provider != ProviderDev || !id.AuthTime().Equal(now) {
+		t.Fatalf("identity=%+v", id)
+	}
+
+	// Expired tokens and tokens signed by another key are rejected.
+	old, _ := MintDevToken(key, DevToken{Subject: "alice", TTL: time.Minute}, now.Add(-time.Hour))
+	if _, err := a.Authenticate(ctx, old); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected expired token rejection, got %v", err)
+	}
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	forged, _ := MintDevToken(other, DevToken{Subject: "root"}, now)
+	if _, err := a.Authenticate(ctx, forged); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected signature rejection, got %v", err)
+	}
+	if _, err := MintDevToken(key, DevToken{}, now); err == nil {
+		t.Fatalf("expected missing subject error")
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.key")
+	if err := os.WriteFile(bad, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDevAuthenticator(ctx, DevAuthConfig{KeyPath: bad}, ilog.NewNop()); err == nil {
+		t.Fatalf("expected bad key error")
+	}
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+func TestOIDCAuthenticator_LazyDiscovery(t *testing.T) {
+	// The issuer's discovery document can be taken down; its keys are the
+	// fake provider's.
+	idp := oidctest.New(t)
+	var up atomic.Bool
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc(oidctest.PathDiscovery, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !up.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"issuer": srv.URL, "jwks_uri": idp.URL + oidctest.PathJWKS})
+	})
+	ctx := context.Background()
+	cfg := OIDCAuthConfig{IssuerURL: srv.URL, ClientID: "kamini"}
+
+	// Without LazyDiscovery an unreachable IdP fails startup.
+	if _, err := NewOIDCAuthenticator(ctx, cfg, ilog.NewNop()); err == nil {
+		t.Fatalf("expected discovery error")
+	}
+
+	cfg.LazyDiscovery = true
+	cfg.DiscoveryRetry = time.Hour
+	a, err := NewOIDCAuthenticator(ctx, cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	token := idp.IDToken("kamini", "user-1", map[string]any{"iss": srv.URL, "preferred_username": "alice"})
+	if _, err := a.Authenticate(ctx, token); !errors.Is(err, domain.ErrIssuerUnavailable) {
+		t.Fatalf("expected issuer unavailable, got %v", err)
+	}
+
+	// Retries are throttled: the IdP is back, but the hour has not passed.
+	up.Store(true)
+	before := calls.Load()
+	if _, err := a.Authenticate(ctx, token); !errors.Is(err, domain.ErrIssuerUnavailable) {
+		t.Fatalf("expected throttled retry, got %v", err)
+	}
+	if calls.Load() != before {
+		t.Fatalf("discovery retried within DiscoveryRetry")
+	}
+
+	a.cfg.DiscoveryRetry = time.Nanosecond
+	id, err := a.Authenticate(ctx, token)
+	if err != nil {
+		t.Fatalf("Authenticate after recovery: %v", err)
+	}
+	if id.Username != "alice" {
+		t.Fatalf("identity=%+v", id)
+	}
+	// Discovery is not repeated once it succeeded.
+	before = calls.Load()
+	up.Store(false)
+	if _, err := a.Authenticate(ctx, token); err != nil || calls.Load() != before {
+		t.Fatalf("err=%v calls=%d->%d", err, before, calls.Load())
+	}
+}
+
+func TestOIDCAuthenticator_InlineJWKS(t *testing.T) {
+	idp := oidctest.New(t)
+	b := idp.JWKS()
+	const iss = "https://idp.enclave.internal"
+	ctx := context.Background()
+	a, err := NewOIDCAuthenticator(ctx, OIDCAuthConfig{IssuerURL: iss, ClientID: "kamini", JWKS: string(b)}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	id, err := a.Authenticate(ctx, idp.IDToken("kamini", "user-1", map[string]any{"iss": iss, "preferred_username": "alice"}))
+	if err != nil || id.Username != "alice" {
+		t.Fatalf("id=%+v err=%v", id, err)
+	}
+	// The expected issuer is still enforced.
+	if _, err := a.Authenticate(ctx, idp.IDToken("kamini", "user-1", map[string]any{"iss": "https://other.example.com"})); !errors.Is(err, domain.ErrIssuerMismatch) {
+		t.Fatalf("expected issuer mismatch, got %v", err)
+	}
+
+	for name, jwks := range map[string]string{
+		"not json":     "keys",
+		"no keys":      `{"keys": []}`,
+		"private only": `{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`,
+	} {
+		if _, err := NewOIDCAuthenticator(ctx, OIDCAuthConfig{IssuerURL: iss, ClientID: "kamini", JWKS: jwks}, ilog.NewNop()); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	if _, err := NewOIDCAuthenticator(ctx, OIDCAuthConfig{IssuerURL: iss, ClientID: "kamini", JWKS: string(b), JWKSFile: "/etc/kamini/jwks.json"}, ilog.NewNop()); err == nil {
+		t.Fatalf("expected error for both JWKS sources")
+	}
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	oidc "github.com/coreos/go-oidc/v3/oidc"
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+// loadJWKS reads a JWKS document (e.g. a cluster's /openid/v1/jwks, saved
+// where discovery is unreachable) into a key set that is never refreshed.
+// Rotating keys means updating the file and restarting.
+func loadJWKS(path string) (*oidc.StaticKeySet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	return parseJWKS(b, path)
+}
+
+// parseJWKS decodes the signing keys of a JWKS document; src names it in
+// errors.
+func parseJWKS(b []byte, src string) (*oidc.StaticKeySet, error) {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("jwks %s: %w", src, err)
+	}
+	var keys []crypto.PublicKey
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if !k.IsPublic() {
+			return nil, fmt.Errorf("jwks %s: key %q is not a public key", src, k.KeyID)
+		}
+		keys = append(keys, k.Key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks %s: no signing keys", src)
+	}
+	return &oidc.StaticKeySet{PublicKeys: keys}, nil
+}
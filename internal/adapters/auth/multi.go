@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// MultiOIDCAuthenticator trusts several OIDC issuers, each verified by its own
+// OIDCAuthenticator (client ID, audiences, claim mappings). The verifier is
+// chosen by the token's unverified "iss" claim; that claim is then checked
+// again, with the signature, by the selected verifier. Multi-tenant issuers
+// (TenantPlaceholder) are tried when no exact issuer matches. Opaque access
+// tokens go to the one issuer configured for introspection.
+type MultiOIDCAuthenticator struct {
+	byIssuer  map[string]*OIDCAuthenticator
+	templated []*OIDCAuthenticator
+	opaque    *OIDCAuthenticator // introspects non-JWT tokens, which carry no iss
+}
+
+var _ usecase.Authenticator = (*MultiOIDCAuthenticator)(nil)
+
+// NewMultiOIDCAuthenticator runs discovery for every issuer. Issuer URLs and
+// names must be unique, and names are required when there is more than one
+// issuer so identities and audit events can tell them apart.
+func NewMultiOIDCAuthenticator(ctx context.Context, cfgs []OIDCAuthConfig, l usecase.Logger) (*MultiOIDCAuthenticator, error) {
+	if len(cfgs) == 0 {
+		return nil, errors.New("at least one issuer required")
+	}
+	m := &MultiOIDCAuthenticator{byIssuer: make(map[string]*OIDCAuthenticator, len(cfgs))}
+	names := map[string]bool{}
+	for _, cfg := range cfgs {
+		if len(cfgs) > 1 && cfg.Name == "" {
+			return nil, fmt.Errorf("issuer %q: name required with multiple issuers", cfg.IssuerURL)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("duplicate issuer name %q", cfg.Name)
+		}
+		a, err := NewOIDCAuthenticator(ctx, cfg, l)
+		if err != nil {
+			return nil, fmt.Errorf("issuer %q: %w", firstNonEmpty(cfg.Name, cfg.IssuerURL), err)
+		}
+		// Keyed by the resolved issuer: profiles fill in their platform's URL.
+		if _, dup := m.byIssuer[a.checks.issuer]; dup {
+			return nil, fmt.Errorf("duplicate issuer %q", a.checks.issuer)
+		}
+		names[cfg.Name] = true
+		m.byIssuer[a.checks.issuer] = a
+		if a.checks.multiTenant {
+			m.templated = append(m.templated, a)
+		}
+		if a.cfg.Introspection != nil {
+			if m.opaque != nil {
+				return nil, fmt.Errorf("issuer %q: only one issuer may introspect opaque tokens", firstNonEmpty(cfg.Name, cfg.IssuerURL))
+			}
+			m.opaque = a
+		}
+	}
+	return m, nil
+}
+
+// Authenticate routes the token to the verifier of its issuer.
+func (m *MultiOIDCAuthenticator) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	if m.opaque != nil && !isJWT(stripBearer(bearer)) {
+		return m.opaque.Authenticate(ctx, bearer)
+	}
+	iss, err := unverifiedIssuer(bearer)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	if a, ok := m.byIssuer[iss]; ok {
+		return a.Authenticate(ctx, bearer)
+	}
+	for _, a := range m.templated {
+		if a.checks.matchesIssuer(iss) {
+			return a.Authenticate(ctx, bearer)
+		}
+	}
+	return domain.Identity{}, fmt.Errorf("%w: %q", domain.ErrIssuerMismatch, iss)
+}
+
+// IssuerRouter sends JWTs whose unverified "iss" is in Routes to that
+// authenticator (which verifies it again) and every other token, opaque ones
+// included, to Default. It mounts Kamini-minted tokens, such as SAML session
+// tokens, beside the configured OIDC verification.
+type IssuerRouter struct {
+	Routes  map[string]usecase.Authenticator
+	Default usecase.Authenticator // nil: only routed issuers are accepted
+}
+
+var _ usecase.Authenticator = IssuerRouter{}
+
+// Authenticate routes the token by issuer.
+func (r IssuerRouter) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	if isJWT(stripBearer(bearer)) {
+		if iss, err := unverifiedIssuer(bearer); err == nil {
+			if a, ok := r.Routes[iss]; ok {
+				return a.Authenticate(ctx, bearer)
+			}
+		}
+	}
+	if r.Default == nil {
+		return domain.Identity{}, fmt.Errorf("%w: no authenticator for this token", domain.ErrIssuerMismatch)
+	}
+	return r.Default.Authenticate(ctx, bearer)
+}
+
+// unverifiedIssuer reads "iss" from a JWT payload without checking anything.
+// It only selects a verifier and must never be trusted on its own.
+func unverifiedIssuer(bearer string) (string, error) {
+	parts := strings.Split(stripBearer(bearer), ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("%w: malformed jwt", domain.ErrInvalidToken)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed jwt payload: %w", domain.ErrInvalidToken, err)
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("%w: malformed jwt payload: %w", domain.ErrInvalidToken, err)
+	}
+	if claims.Iss == "" {
+		return "", fmt.Errorf("%w: token has no issuer", domain.ErrIssuerMismatch)
+	}
+	return claims.Iss, nil
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+func TestMultiOIDCAuthenticator_RoutesByIssuer(t *testing.T) {
+	entra, okta := oidctest.New(t), oidctest.New(t)
+	a, err := NewMultiOIDCAuthenticator(context.Background(), []OIDCAuthConfig{
+		{Name: "entra", IssuerURL: entra.URL, ClientID: "kamini-entra"},
+		{Name: "okta", IssuerURL: okta.URL, ClientID: "kamini-okta", UsernameClaim: "login"},
+	}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewMultiOIDCAuthenticator: %v", err)
+	}
+
+	id, err := a.Authenticate(context.Background(), "Bearer "+entra.IDToken("kamini-entra", "emp-1", map[string]any{"preferred_username": "alice"}))
+	if err != nil {
+		t.Fatalf("entra: %v", err)
+	}
+	if id.Username != "alice" || id.Provider != "oidc:entra" || id.Claims["issuer"] != "entra" || id.Issuer() != entra.URL {
+		t.Fatalf("entra identity: %+v", id)
+	}
+
+	id, err = a.Authenticate(context.Background(), okta.IDToken("kamini-okta", "ctr-1", map[string]any{"login": "bob"}))
+	if err != nil {
+		t.Fatalf("okta: %v", err)
+	}
+	if id.Username != "bob" || id.Provider != "oidc:okta" || id.Issuer() != okta.URL {
+		t.Fatalf("okta identity: %+v", id)
+	}
+
+	// Each issuer keeps its own client ID.
+	if _, err := a.Authenticate(context.Background(), okta.IDToken("kamini-entra", "ctr-1", nil)); err == nil {
+		t.Fatalf("expected audience error for okta token minted for the entra client")
+	}
+}
+
+func TestMultiOIDCAuthenticator_Rejects(t *testing.T) {
+	entra, okta := oidctest.New(t), oidctest.New(t)
+	a, err := NewMultiOIDCAuthenticator(context.Background(), []OIDCAuthConfig{
+		{Name: "entra", IssuerURL: entra.URL, ClientID: "kamini"},
+	}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewMultiOIDCAuthenticator: %v", err)
+	}
+	// Untrusted issuer.
+	if _, err := a.Authenticate(context.Background(), okta.IDToken("kamini", "x", nil)); !errors.Is(err, domain.ErrIssuerMismatch) {
+		t.Fatalf("expected ErrIssuerMismatch, got %v", err)
+	}
+	// A token claiming the trusted issuer but signed by another key.
+	forged := okta.IDToken("kamini", "x", map[string]any{"iss": entra.URL})
+	if _, err := a.Authenticate(context.Background(), forged); err == nil {
+		t.Fatalf("expected signature error for forged iss")
+	}
+	for _, tok := range []string{"", "not-a-jwt", "a.!!!.c", "a.e30.c"} {
+		if _, err := a.Authenticate(context.Background(), tok); err == nil {
+			t.Fatalf("expected error for %q", tok)
+		}
+	}
+}
+
+func TestMultiOIDCAuthenticator_Config(t *testing.T) {
+	entra, okta := oidctest.New(t), oidctest.New(t)
+	cases := map[string][]OIDCAuthConfig{
+		"empty":          nil,
+		"unnamed":        {{IssuerURL: entra.URL, ClientID: "k"}, {Name: "okta", IssuerURL: okta.URL, ClientID: "k"}},
+		"duplicate name": {{Name: "x", IssuerURL: entra.URL, ClientID: "k"}, {Name: "x", IssuerURL: okta.URL, ClientID: "k"}},
+		"duplicate url":  {{Name: "a", IssuerURL: entra.URL, ClientID: "k"}, {Name: "b", IssuerURL: entra.URL, ClientID: "k"}},
+		"no client":      {{Name: "a", IssuerURL: entra.URL}},
+	}
+	for name, cfgs := range cases {
+		if _, err := NewMultiOIDCAuthenticator(context.Background(), cfgs, ilog.NewNop()); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestOIDCAuthenticator_Audiences(t *testing.T) {
+	idp := oidctest.New(t)
+	a, err := NewOIDCAuthenticator(context.Background(), OIDCAuthConfig{
+		IssuerURL: idp.URL,
+		ClientID:  "kamini",
+		Audiences: []string{"api://kamini"},
+	}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	for _, aud := range []string{"kamini", "api://kamini"} {
+		id, err := a.Authenticate(context.Background(), idp.IDToken(aud, "sub", nil))
+		if err != nil {
+			t.Fatalf("aud %s: %v", aud, err)
+		}
+		if id.Provider != ProviderOIDC {
+			t.Fatalf("unnamed issuer provider=%q", id.Provider)
+		}
+	}
+	if _, err := a.Authenticate(context.Background(), idp.IDToken("other", "sub", nil)); err == nil {
+		t.Fatalf("expected audience error")
+	}
+}
+
+// providerAuth accepts every token as its provider.
+type providerAuth string
+
+func (p providerAuth) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	return domain.Identity{Subject: "x", Provider: string(p)}, nil
+}
+
+func TestIssuerRouter(t *testing.T) {
+	idp := oidctest.New(t)
+	r := IssuerRouter{Routes: map[string]usecase.Authenticator{SAMLSessionIssuer: providerAuth("saml")}, Default: providerAuth("oidc")}
+	for token, want := range map[string]string{
+		idp.IDToken("kamini", "x", map[string]any{"iss": SAMLSessionIssuer}): "saml",
+		idp.IDToken("kamini", "x", nil):                                      "oidc",
+		"opaque-access-token":                                                "oidc",
+	} {
+		if id, err := r.Authenticate(context.Background(), "Bearer "+token); err != nil || id.Provider != want {
+			t.Fatalf("%s: got %+v %v, want %s", token, id, err, want)
+		}
+	}
+	r.Default = nil
+	if _, err := r.Authenticate(context.Background(), idp.IDToken("kamini", "x", nil)); !errors.Is(err, domain.ErrIssuerMismatch) {
+		t.Fatalf("expected ErrIssuerMismatch without a default, got %v", err)
+	}
+}
@@ -3,8 +3,13 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	oidc "github.com/coreos/go-oidc/v3/oidc"
 
@@ -12,86 +17,317 @@ import (
 	"github.com/haukened/kamini/internal/usecase"
 )
 
+// ProviderOIDC is the Identity.Provider set by OIDCAuthenticator; a named
+// issuer yields "oidc:<name>".
+const ProviderOIDC = "oidc"
+
 // OIDCAuthConfig controls OIDC authenticator behavior.
 type OIDCAuthConfig struct {
-	IssuerURL         string
+	// Name labels the issuer (e.g. "entra", "okta") in Identity.Provider and
+	// Identity.Claims["issuer"]; optional with a single issuer.
+	Name string
+	// IssuerURL is the expected "iss". It may contain TenantPlaceholder (Entra
+	// multi-tenant), which is replaced by the token's tid before comparing;
+	// TenantIDs is then required.
+	IssuerURL string
+	// DiscoveryURL is where metadata is fetched when it differs from the
+	// issuer; default: IssuerURL with TenantPlaceholder replaced by "common".
+	DiscoveryURL string
+	// JWKSFile or JWKS (an inline JWKS document) verify signatures with static
+	// keys instead of discovery, for air-gapped deployments or a cluster's
+	// /openid/v1/jwks; the keys are never refreshed. Introspection and UserInfo
+	// need discovery.
+	JWKSFile string
+	JWKS     string
+	// LazyDiscovery lets construction succeed while discovery fails (the IdP
+	// is down): authentication then fails with domain.ErrIssuerUnavailable and
+	// retries discovery at most once per DiscoveryRetry (default 30s).
+	LazyDiscovery     bool
+	DiscoveryRetry    time.Duration
 	ClientID          string
 	SkipClientIDCheck bool
+	// Audiences are accepted in addition to ClientID; a token is valid when its
+	// aud contains any of them.
+	Audiences []string
+
+	// Required claims; empty lists skip the check.
+	TenantIDs         []string // tid must be one of these
+	AuthorizedParties []string // azp (or Entra v1 appid) must be one of these
+	RequiredAMR       []string // amr must contain all of these, e.g. ["mfa"]
+	AllowedACR        []string // acr must be one of these
 
+	// Claim specs: a claim name or path plus optional mapping stages, e.g.
+	// `realm_access.roles | trim_prefix:ssh- | lower` (see claimMapper).
 	UsernameClaim string // default: "preferred_username"
 	EmailClaim    string // default: "email"
 	RolesClaim    string // default: "roles"
 	GroupsClaim   string // default: "groups"
 
+	// AccessTokens verifies RFC 9068 JWT access tokens (typ "at+jwt", sub
+	// required) instead of ID tokens; ClientID/Audiences then name Kamini's API
+	// (e.g. "api://kamini"), and AuthorizedParties also matches client_id.
+	AccessTokens bool
+	// Introspection validates opaque (non-JWT) access tokens at an RFC 7662
+	// endpoint; optional. JWTs are still verified locally.
+	Introspection *IntrospectionConfig
+	// UserInfo fills claims missing from the token from the userinfo
+	// endpoint, called with the access token; optional.
+	UserInfo *UserInfoConfig
+
+	// Profile maps CI job tokens (ProfileGitHubActions, ProfileGitLabCI) or
+	// Kubernetes ServiceAccount tokens (ProfileKubernetes) to workload
+	// identities (domain.Workload) instead of users; IssuerURL then defaults to
+	// the CI platform's issuer. Claim mapping and UserInfo are unused.
+	Profile string
+
+	// GroupResolver looks up groups when the token signals an overage (Entra ID
+	// omits "groups" beyond 200 memberships); optional. Resolved groups go
+	// through the GroupsClaim stages like token groups.
+	GroupResolver usecase.GroupResolver
+
 	HTTPClient *http.Client // optional; if nil, default client is used
 }
 
 // OIDCAuthenticator verifies ID tokens and maps claims to a domain.Identity.
 type OIDCAuthenticator struct {
-	verifier      *oidc.IDTokenVerifier
-	usernameClaim string
-	emailClaim    string
-	rolesClaim    string
-	groupsClaim   string
+	cfg OIDCAuthConfig
+
+	// Set by discovery (or static keys); read only once ready is true.
+	ready      atomic.Bool
+	mu         sync.Mutex // serializes lazy discovery
+	lastTry    time.Time
+	verifier   *oidc.IDTokenVerifier
+	introspect *introspector
+	userinfo   *userInfo
+
+	name          string
+	checks        claimChecks
+	usernameClaim claimMapper
+	emailClaim    claimMapper
+	rolesClaim    claimMapper
+	groupsClaim   claimMapper
+	groups        usecase.GroupResolver
+	accessTokens  bool
+	profile       string
 	L             usecase.Logger
 }
 
 // assert interfaces
 var _ usecase.Authenticator = (*OIDCAuthenticator)(nil)
 
-// NewOIDCAuthenticator constructs an OIDC authenticator backed by discovery metadata.
+// NewOIDCAuthenticator constructs an OIDC authenticator backed by discovery
+// metadata, or by static keys when JWKSFile or JWKS is set.
 func NewOIDCAuthenticator(ctx context.Context, cfg OIDCAuthConfig, l usecase.Logger) (*OIDCAuthenticator, error) {
+	defaultIssuer, err := profileIssuer(cfg.Profile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.IssuerURL = firstNonEmpty(cfg.IssuerURL, defaultIssuer)
 	if cfg.IssuerURL == "" {
 		return nil, errors.New("issuer URL required")
 	}
-	if cfg.ClientID == "" && !cfg.SkipClientIDCheck {
-		return nil, errors.New("clientID required unless SkipClientIDCheck is true")
+	if cfg.ClientID == "" && len(cfg.Audiences) == 0 && !cfg.SkipClientIDCheck {
+		return nil, errors.New("clientID or audiences required unless SkipClientIDCheck is true")
+	}
+	if cfg.Profile == ProfileKubernetes {
+		// Every pod holds a token for the API server; only tokens requested
+		// for Kamini's audience may authenticate.
+		if cfg.SkipClientIDCheck || slices.Contains(append([]string{cfg.ClientID}, cfg.Audiences...), cfg.IssuerURL) {
+			return nil, errors.New("kubernetes profile requires an audience other than the API server's")
+		}
+	}
+	static := cfg.JWKSFile != "" || cfg.JWKS != ""
+	if cfg.JWKSFile != "" && cfg.JWKS != "" {
+		return nil, errors.New("set JWKS file or inline JWKS, not both")
+	}
+	if static && (cfg.Introspection != nil || cfg.UserInfo != nil) {
+		return nil, errors.New("introspection and userinfo need discovery, not static keys")
+	}
+	if cfg.UserInfo != nil && !cfg.AccessTokens && cfg.Introspection == nil {
+		return nil, errors.New("userinfo needs access tokens (AccessTokens or Introspection)")
+	}
+	multiTenant := strings.Contains(cfg.IssuerURL, TenantPlaceholder)
+	if multiTenant && len(cfg.TenantIDs) == 0 {
+		return nil, errors.New("tenant IDs required with a multi-tenant issuer URL")
+	}
+	// Parse claim specs before any network round trip.
+	a := &OIDCAuthenticator{cfg: cfg, name: cfg.Name, groups: cfg.GroupResolver, accessTokens: cfg.AccessTokens, profile: cfg.Profile, L: l}
+	for _, c := range []struct {
+		dst  *claimMapper
+		spec string
+	}{
+		{&a.usernameClaim, firstNonEmpty(cfg.UsernameClaim, "preferred_username")},
+		{&a.emailClaim, firstNonEmpty(cfg.EmailClaim, "email")},
+		{&a.rolesClaim, firstNonEmpty(cfg.RolesClaim, "roles")},
+		{&a.groupsClaim, firstNonEmpty(cfg.GroupsClaim, "groups")},
+	} {
+		m, err := parseClaimMapper(c.spec)
+		if err != nil {
+			return nil, err
+		}
+		*c.dst = m
+	}
+	checks := claimChecks{
+		issuer:      cfg.IssuerURL,
+		multiTenant: multiTenant,
+		tenants:     cfg.TenantIDs,
+		parties:     cfg.AuthorizedParties,
+		amr:         cfg.RequiredAMR,
+		acr:         cfg.AllowedACR,
+	}
+	if !cfg.SkipClientIDCheck {
+		checks.audiences = slices.DeleteFunc(append([]string{cfg.ClientID}, cfg.Audiences...), func(s string) bool { return s == "" })
+	}
+	a.checks = checks
+	if static {
+		var keys *oidc.StaticKeySet
+		if cfg.JWKSFile != "" {
+			keys, err = loadJWKS(cfg.JWKSFile)
+		} else {
+			keys, err = parseJWKS([]byte(cfg.JWKS), "inline")
+		}
+		if err != nil {
+			return nil, err
+		}
+		a.verifier = oidc.NewVerifier(cfg.IssuerURL, keys, verifierConfig())
+		a.ready.Store(true)
+		return a, nil
+	}
+	a.lastTry = time.Now()
+	if err := a.discover(ctx); err != nil {
+		if !cfg.LazyDiscovery {
+			return nil, err
+		}
+		if l != nil {
+			l.Warn(ctx, "oidc discovery failed; retrying on use", "issuer", cfg.IssuerURL, "error", err)
+		}
 	}
+	return a, nil
+}
+
+// verifierConfig skips go-oidc's issuer and audience checks: claimChecks
+// performs them, with their own error codes.
+func verifierConfig() *oidc.Config {
+	// ClockSkew and time are derived from context; default tolerance is small.
+	return &oidc.Config{SkipClientIDCheck: true, SkipIssuerCheck: true}
+}
+
+// discover fetches the issuer's metadata and builds the verifier, introspector
+// and userinfo client from it. The provider keeps ctx for JWKS refreshes.
+func (a *OIDCAuthenticator) discover(ctx context.Context) error {
+	cfg := a.cfg
 	if cfg.HTTPClient != nil {
 		ctx = oidc.ClientContext(ctx, cfg.HTTPClient)
 	}
-	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	discovery := cfg.DiscoveryURL
+	if discovery == "" {
+		discovery = strings.ReplaceAll(cfg.IssuerURL, TenantPlaceholder, "common")
+	}
+	if discovery != cfg.IssuerURL {
+		// Metadata names the issuer (template) rather than the discovery URL.
+		ctx = oidc.InsecureIssuerURLContext(ctx, cfg.IssuerURL)
+	}
+	provider, err := oidc.NewProvider(ctx, discovery)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	v := provider.Verifier(&oidc.Config{
-		ClientID:          cfg.ClientID,
-		SkipClientIDCheck: cfg.SkipClientIDCheck,
-		// ClockSkew and time are derived from context; default tolerance is small.
-	})
-	a := &OIDCAuthenticator{
-		verifier:      v,
-		usernameClaim: firstNonEmpty(cfg.UsernameClaim, "preferred_username"),
-		emailClaim:    firstNonEmpty(cfg.EmailClaim, "email"),
-		rolesClaim:    firstNonEmpty(cfg.RolesClaim, "roles"),
-		groupsClaim:   firstNonEmpty(cfg.GroupsClaim, "groups"),
-		L:             l,
+	if cfg.Introspection != nil {
+		if a.introspect, err = newIntrospector(*cfg.Introspection, provider, cfg.HTTPClient); err != nil {
+			return err
+		}
 	}
-	return a, nil
+	if cfg.UserInfo != nil {
+		if a.userinfo, err = newUserInfo(*cfg.UserInfo, provider, cfg.HTTPClient); err != nil {
+			return err
+		}
+	}
+	a.verifier = provider.Verifier(verifierConfig())
+	a.ready.Store(true)
+	return nil
 }
 
-// Authenticate verifies the bearer token (ID token) and returns a normalized Identity.
-func (a *OIDCAuthenticator) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
-	token := strings.TrimSpace(bearer)
-	if strings.HasPrefix(strings.ToLower(token), "bearer ") {
-		token = strings.TrimSpace(token[7:])
+// discovered retries a failed (lazy) discovery, at most once per
+// DiscoveryRetry; until one succeeds, it fails with domain.ErrIssuerUnavailable.
+func (a *OIDCAuthenticator) discovered(ctx context.Context) error {
+	if a.ready.Load() {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ready.Load() {
+		return nil
+	}
+	retry := a.cfg.DiscoveryRetry
+	if retry <= 0 {
+		retry = 30 * time.Second
+	}
+	if time.Since(a.lastTry) < retry {
+		return fmt.Errorf("%w: %s: discovery pending", domain.ErrIssuerUnavailable, a.checks.issuer)
 	}
+	a.lastTry = time.Now()
+	// The provider outlives this request (JWKS refreshes use its context).
+	if err := a.discover(context.WithoutCancel(ctx)); err != nil {
+		if a.L != nil {
+			a.L.Warn(ctx, "oidc discovery failed", "issuer", a.checks.issuer, "error", err)
+		}
+		return fmt.Errorf("%w: %s: %w", domain.ErrIssuerUnavailable, a.checks.issuer, err)
+	}
+	if a.L != nil {
+		a.L.Info(ctx, "oidc discovery succeeded", "issuer", a.checks.issuer)
+	}
+	return nil
+}
+
+// Authenticate verifies the bearer token (an ID token, or an access token when
+// configured) and returns a normalized Identity.
+func (a *OIDCAuthenticator) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	token := stripBearer(bearer)
 	if token == "" {
 		return domain.Identity{}, errors.New("empty bearer token")
 	}
+	if err := a.discovered(ctx); err != nil {
+		return domain.Identity{}, err
+	}
+	if a.introspect != nil && !isJWT(token) {
+		return a.authenticateOpaque(ctx, token)
+	}
+	if a.accessTokens {
+		if err := checkAccessTokenType(token); err != nil {
+			return domain.Identity{}, err
+		}
+	}
 	idt, err := a.verifier.Verify(ctx, token)
 	if err != nil {
-		return domain.Identity{}, err
+		return domain.Identity{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
 	}
 	// Extract raw claims into a generic map for mapping.
 	var claims map[string]any
 	if err := idt.Claims(&claims); err != nil {
 		return domain.Identity{}, err
 	}
+	if a.accessTokens && idt.Subject == "" {
+		return domain.Identity{}, fmt.Errorf("%w: access token has no sub", domain.ErrInvalidToken)
+	}
+	return a.identity(ctx, token, idt.Issuer, idt.Audience, idt.Subject, claims)
+}
+
+// identity checks the claims of a verified (or introspected) token, merges
+// userinfo claims when configured, and maps them to a domain.Identity.
+func (a *OIDCAuthenticator) identity(ctx context.Context, token, iss string, aud []string, sub string, claims map[string]any) (domain.Identity, error) {
+	if err := a.checks.check(iss, aud, claims); err != nil {
+		return domain.Identity{}, err
+	}
+	if a.profile != "" {
+		return a.workloadIdentity(ctx, iss, aud, sub, claims)
+	}
+	if a.userinfo != nil {
+		if err := a.userinfo.merge(ctx, token, sub, claims); err != nil {
+			return domain.Identity{}, err
+		}
+	}
 
-	sub := idt.Subject
-	email := getString(claims, a.emailClaim)
-	username := getString(claims, a.usernameClaim)
+	email := a.emailClaim.string(claims)
+	username := a.usernameClaim.string(claims)
 	if username == "" && email != "" {
 		if i := strings.IndexByte(email, '@'); i > 0 {
 			username = email[:i]
@@ -100,15 +336,35 @@ func (a *OIDCAuthenticator) Authenticate(ctx context.Context, bearer string) (do
 	if username == "" {
 		username = sub
 	}
-	roles := getStringSlice(claims, a.rolesClaim)
-	groups := getStringSlice(claims, a.groupsClaim)
+	roles := a.rolesClaim.strings(claims)
+	groups := a.groupsClaim.strings(claims)
 
 	// Keep only a small set of extra claims to avoid carting the whole token.
 	extras := map[string]any{
-		"iss":            idt.Issuer,
-		"aud":            idt.Audience,
+		"iss":            iss,
+		"aud":            aud,
 		"email_verified": getBool(claims, "email_verified"),
 	}
+	for _, k := range []string{"tid", "oid", "azp", "client_id", "acr"} {
+		if v := getString(claims, k); v != "" {
+			extras[k] = v
+		}
+	}
+	// Authentication strength, for per-principal requirements (domain.AuthRequirement).
+	if amr := getStringSlice(claims, "amr"); amr != nil {
+		extras["amr"] = amr
+	}
+	if scope := strings.Fields(getString(claims, "scope")); len(scope) > 0 {
+		extras["scope"] = scope
+	}
+	if at, ok := claims["auth_time"].(float64); ok && at > 0 {
+		extras["auth_time"] = time.Unix(int64(at), 0).UTC()
+	}
+	provider := ProviderOIDC
+	if a.name != "" {
+		extras["issuer"] = a.name
+		provider += ":" + a.name
+	}
 
 	id := domain.Identity{
 		Subject:  sub,
@@ -117,13 +373,48 @@ func (a *OIDCAuthenticator) Authenticate(ctx context.Context, bearer string) (do
 		Roles:    roles,
 		Groups:   groups,
 		Claims:   extras,
+		Provider: provider,
+	}
+	if len(groups) == 0 && groupsOverage(claims) {
+		if a.groups == nil {
+			if a.L != nil {
+				a.L.Warn(ctx, "oidc groups overage but no group resolver configured", "sub", sub, "iss", iss)
+			}
+		} else {
+			resolved, err := a.groups.ResolveGroups(ctx, id)
+			if err != nil {
+				return domain.Identity{}, fmt.Errorf("%w: %w", domain.ErrGroupsUnavailable, err)
+			}
+			id.Groups = a.groupsClaim.apply(resolved)
+		}
 	}
 	if a.L != nil {
-		a.L.Debug(ctx, "oidc authenticated", "sub", id.Subject, "username", id.Username)
+		a.L.Debug(ctx, "oidc authenticated", "sub", id.Subject, "username", id.Username, "iss", iss)
 	}
 	return id, nil
 }
 
+// groupsOverage reports whether the token left out group membership: Entra ID
+// replaces "groups" with a _claim_names reference (or, for implicit flows,
+// hasgroups) when a user is in too many groups to fit in the token.
+func groupsOverage(claims map[string]any) bool {
+	if names, ok := claims["_claim_names"].(map[string]any); ok {
+		if _, ok := names["groups"]; ok {
+			return true
+		}
+	}
+	return getBool(claims, "hasgroups")
+}
+
+// stripBearer trims an optional "Bearer " scheme from an Authorization value.
+func stripBearer(bearer string) string {
+	token := strings.TrimSpace(bearer)
+	if strings.HasPrefix(strings.ToLower(token), "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	return token
+}
+
 func firstNonEmpty(v, d string) string {
 	if v != "" {
 		return v
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+type fakeGroups struct {
+	groups []string
+	err    error
+	got    domain.Identity
+	calls  int
+}
+
+func (f *fakeGroups) ResolveGroups(_ context.Context, id domain.Identity) ([]string, error) {
+	f.calls++
+	f.got = id
+	return f.groups, f.err
+}
+
+func TestOIDCAuthenticator_GroupsOverage(t *testing.T) {
+	idp := oidctest.New(t)
+
+	overage := map[string]any{
+		"preferred_username": "senior@example.com",
+		"tid":                "tenant-1",
+		"oid":                "oid-1",
+		"_claim_names":       map[string]any{"groups": "src1"},
+		"_claim_sources":     map[string]any{"src1": map[string]any{"endpoint": "https://graph.windows.net/tenant-1/users/oid-1/getMemberObjects"}},
+	}
+	newAuth := func(r *fakeGroups) *OIDCAuthenticator {
+		cfg := OIDCAuthConfig{IssuerURL: idp.URL, ClientID: "kamini", GroupsClaim: "groups | lower"}
+		if r != nil {
+			cfg.GroupResolver = r
+		}
+		a, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop())
+		if err != nil {
+			t.Fatalf("NewOIDCAuthenticator: %v", err)
+		}
+		return a
+	}
+
+	r := &fakeGroups{groups: []string{"G-1", "G-2"}}
+	id, err := newAuth(r).Authenticate(context.Background(), idp.IDToken("kamini", "sub-1", overage))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if !slices.Equal(id.Groups, []string{"g-1", "g-2"}) {
+		t.Fatalf("groups=%v (resolved groups go through the claim stages)", id.Groups)
+	}
+	if r.got.ObjectID() != "oid-1" || r.got.Tenant() != "tenant-1" || r.got.Subject != "sub-1" {
+		t.Fatalf("resolver got %+v", r.got)
+	}
+
+	// Tokens that carry groups never hit the resolver.
+	withGroups := map[string]any{"groups": []string{"eng"}, "oid": "oid-1"}
+	if _, err := newAuth(r).Authenticate(context.Background(), idp.IDToken("kamini", "sub-1", withGroups)); err != nil || r.calls != 1 {
+		t.Fatalf("unexpected resolver call: calls=%d err=%v", r.calls, err)
+	}
+
+	// Implicit-flow tokens signal the overage with hasgroups.
+	hasGroups := map[string]any{"hasgroups": true, "oid": "oid-1"}
+	if id, err := newAuth(r).Authenticate(context.Background(), idp.IDToken("kamini", "sub-1", hasGroups)); err != nil || len(id.Groups) != 2 {
+		t.Fatalf("hasgroups: %v, %v", id.Groups, err)
+	}
+
+	failing := &fakeGroups{err: errors.New("graph: 503")}
+	_, err = newAuth(failing).Authenticate(context.Background(), idp.IDToken("kamini", "sub-1", overage))
+	if !errors.Is(err, domain.ErrGroupsUnavailable) {
+		t.Fatalf("expected ErrGroupsUnavailable, got %v", err)
+	}
+
+	// Without a resolver the overage is logged and the identity has no groups.
+	id, err = newAuth(nil).Authenticate(context.Background(), idp.IDToken("kamini", "sub-1", overage))
+	if err != nil || len(id.Groups) != 0 {
+		t.Fatalf("no resolver: %v, %v", id.Groups, err)
+	}
+}
@@ -0,0 +1,601 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/haukened/kamini/internal/adapters/ttlcache"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// ProviderSAML is the Identity.Provider of SAML session tokens.
+const ProviderSAML = "saml"
+
+// SAMLSessionIssuer is the "iss" of the session tokens minted after a SAML
+// login; route it to the SAMLServiceProvider (see IssuerRouter).
+const SAMLSessionIssuer = "kamini-saml"
+
+// Routes served by SAMLServiceProvider.Handler.
+const (
+	SAMLLoginPath    = "/v1/saml/login"
+	SAMLACSPath      = "/v1/saml/acs"
+	SAMLMetadataPath = "/v1/saml/metadata"
+)
+
+// loginCookiePrefix names the cookie carrying a login in progress; the
+// RelayState completes the name, so parallel logins in one browser coexist.
+const loginCookiePrefix = "kamini_saml_"
+
+// maxAnsweredLogins bounds the AuthnRequest IDs remembered to refuse replayed
+// responses; the oldest are forgotten first.
+const maxAnsweredLogins = 10000
+
+// SAMLConfig configures SAMLServiceProvider.
+type SAMLConfig struct {
+	// RootURL is Kamini's external base URL (e.g. https://kamini.example.com);
+	// the ACS and metadata URLs are derived from it.
+	RootURL string
+	// EntityID names Kamini to the IdP; default: the metadata URL.
+	EntityID string
+	// IdPMetadata is the IdP's metadata document (an EntityDescriptor, or an
+	// EntitiesDescriptor holding one IdP): SSO URL and signing certificates.
+	IdPMetadata []byte
+	// NameIDFormat is requested from the IdP: "persistent", "email" or
+	// "unspecified" (default, the IdP's choice). Transient NameIDs are refused:
+	// they change every login, so revocations and blocklist entries by
+	// subject would never match.
+	NameIDFormat string
+
+	// Attribute specs: an attribute Name or FriendlyName plus optional mapping
+	// stages, as for OIDC claims (see claimMapper).
+	UsernameAttribute string // default "uid"; then the email local part, then the NameID
+	EmailAttribute    string // default "mail"
+	RolesAttribute    string // default "roles"
+	GroupsAttribute   string // default "groups"
+
+	// SessionKeyPath holds the ed25519 key signing session tokens (created if
+	// missing), so replicas accept each other's tokens; empty: a random key
+	// per process.
+	SessionKeyPath string
+	SessionTTL     time.Duration // session token lifetime; default 5m
+	LoginTTL       time.Duration // time allowed at the IdP; default 10m
+	Clock          usecase.Clock // optional
+}
+
+// SAMLServiceProvider is a SAML 2.0 SP for browser logins. Handler starts a
+// login at the IdP (HTTP-Redirect binding) and consumes its signed response
+// (HTTP-POST binding), handing a short-lived session token to the CLI's
+// loopback listener; Authenticate accepts those tokens like bearer tokens.
+// A login in progress travels in a cookie signed with a key derived from the
+// session key, so starting one stores nothing on the server and replicas
+// sharing SessionKeyPath need no session affinity.
+type SAMLServiceProvider struct {
+	sp       saml.ServiceProvider
+	audience string
+	key      ed25519.PrivateKey
+
+	username claimMapper
+	email    claimMapper
+	roles    claimMapper
+	groups   claimMapper
+
+	sessionTTL time.Duration
+	loginTTL   time.Duration
+	clock      usecase.Clock
+
+	loginKey     []byte // signs login cookies
+	cookiePath   string // the ACS path, where login cookies are sent
+	secureCookie bool   // RootURL is https
+
+	mu       sync.Mutex                        // makes checking and recording an answer atomic
+	answered *ttlcache.Cache[string, struct{}] // AuthnRequest IDs already answered
+	L        usecase.Logger
+}
+
+// pendingLogin is a login sent to the IdP and not yet answered. It is kept in
+// the browser as the login cookie.
+type pendingLogin struct {
+	RequestID string `json:"id"`          // AuthnRequest ID; the response must answer it
+	Redirect  string `json:"r,omitempty"` // loopback URL receiving the token; empty shows it instead
+	State     string `json:"s,omitempty"` // echoed to redirect
+	Expires   int64  `json:"exp"`         // Unix time, LoginTTL after the start
+}
+
+// samlClaims are the claims of a session token.
+type samlClaims struct {
+	jwt.RegisteredClaims
+	IdP      string   `json:"idp"` // IdP entity ID
+	Username string   `json:"preferred_username,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	AuthTime int64    `json:"auth_time,omitempty"`
+	ACR      string   `json:"acr,omitempty"` // AuthnContextClassRef
+}
+
+var _ usecase.Authenticator = (*SAMLServiceProvider)(nil)
+
+// NewSAMLServiceProvider parses the IdP metadata and attribute specs and loads
+// (or creates) the session key. It needs no network access.
+func NewSAMLServiceProvider(ctx context.Context, cfg SAMLConfig, l usecase.Logger) (*SAMLServiceProvider, error) {
+	root, err := url.Parse(strings.TrimSuffix(cfg.RootURL, "/"))
+	if err != nil || root.Scheme == "" || root.Host == "" {
+		return nil, fmt.Errorf("saml: root URL %q must be absolute", cfg.RootURL)
+	}
+	idp, err := parseIdPMetadata(cfg.IdPMetadata)
+	if err != nil {
+		return nil, err
+	}
+	nameID, err := samlNameIDFormat(cfg.NameIDFormat)
+	if err != nil {
+		return nil, err
+	}
+	s := &SAMLServiceProvider{
+		sessionTTL: cfg.SessionTTL,
+		loginTTL:   cfg.LoginTTL,
+		clock:      cfg.Clock,
+		answered:   ttlcache.New[string, struct{}](maxAnsweredLogins),
+		L:          l,
+	}
+	if s.sessionTTL <= 0 {
+		s.sessionTTL = 5 * time.Minute
+	}
+	if s.loginTTL <= 0 {
+		s.loginTTL = 10 * time.Minute
+	}
+	if s.clock == nil {
+		s.clock = domain.SystemClock()
+	}
+	for _, c := range []struct {
+		dst  *claimMapper
+		spec string
+	}{
+		{&s.username, firstNonEmpty(cfg.UsernameAttribute, "uid")},
+		{&s.email, firstNonEmpty(cfg.EmailAttribute, "mail")},
+		{&s.roles, firstNonEmpty(cfg.RolesAttribute, "roles")},
+		{&s.groups, firstNonEmpty(cfg.GroupsAttribute, "groups")},
+	} {
+		m, err := parseClaimMapper(c.spec)
+		if err != nil {
+			return nil, fmt.Errorf("saml: %w", err)
+		}
+		*c.dst = m
+	}
+	if cfg.SessionKeyPath != "" {
+		s.key, err = loadOrCreateKey(cfg.SessionKeyPath, "saml session key")
+	} else {
+		_, s.key, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, s.key.Seed())
+	mac.Write([]byte("kamini saml login cookie"))
+	s.loginKey = mac.Sum(nil)
+	s.secureCookie = root.Scheme == "https"
+	s.sp = saml.ServiceProvider{
+		EntityID:          cfg.EntityID,
+		MetadataURL:       *root.JoinPath(SAMLMetadataPath),
+		AcsURL:            *root.JoinPath(SAMLACSPath),
+		IDPMetadata:       idp,
+		AuthnNameIDFormat: nameID,
+	}
+	s.audience = firstNonEmpty(cfg.EntityID, s.sp.MetadataURL.String())
+	s.cookiePath = "/" + strings.TrimPrefix(s.sp.AcsURL.Path, "/")
+	if l != nil {
+		l.Info(ctx, "saml service provider ready", "idp", idp.EntityID, "entity_id", s.audience, "acs", s.sp.AcsURL.String())
+	}
+	return s, nil
+}
+
+// parseIdPMetadata accepts an EntityDescriptor or an EntitiesDescriptor with
+// exactly one IdP, which must offer the HTTP-Redirect binding and a signing
+// certificate.
+func parseIdPMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("saml: IdP metadata required")
+	}
+	var ed saml.EntityDescriptor
+	if err := xml.Unmarshal(data, &ed); err != nil {
+		var eds saml.EntitiesDescriptor
+		if xml.Unmarshal(data, &eds) != nil {
+			return nil, fmt.Errorf("saml: IdP metadata: %w", err)
+		}
+		found := 0
+		for _, e := range eds.EntityDescriptors {
+			if len(e.IDPSSODescriptors) > 0 {
+				ed = e
+				found++
+			}
+		}
+		if found != 1 {
+			return nil, fmt.Errorf("saml: IdP metadata describes %d IdPs, want 1", found)
+		}
+	}
+	if ed.EntityID == "" || len(ed.IDPSSODescriptors) == 0 {
+		return nil, errors.New("saml: IdP metadata has no IDPSSODescriptor")
+	}
+	redirect, signing := false, false
+	for _, d := range ed.IDPSSODescriptors {
+		for _, sso := range d.SingleSignOnServices {
+			redirect = redirect || sso.Binding == saml.HTTPRedirectBinding
+		}
+		for _, k := range d.KeyDescriptors {
+			signing = signing || (k.Use != "encryption" && len(k.KeyInfo.X509Data.X509Certificates) > 0)
+		}
+	}
+	if !redirect {
+		return nil, errors.New("saml: IdP metadata offers no HTTP-Redirect SingleSignOnService")
+	}
+	if !signing {
+		return nil, errors.New("saml: IdP metadata has no signing certificate")
+	}
+	return &ed, nil
+}
+
+func samlNameIDFormat(f string) (saml.NameIDFormat, error) {
+	switch f {
+	case "", "unspecified":
+		return saml.UnspecifiedNameIDFormat, nil
+	case "persistent":
+		return saml.PersistentNameIDFormat, nil
+	case "email":
+		return saml.EmailAddressNameIDFormat, nil
+	}
+	return "", fmt.Errorf("saml: name ID format %q: want persistent, email or unspecified", f)
+}
+
+// Handler serves the login, ACS and metadata routes.
+func (s *SAMLServiceProvider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+SAMLLoginPath, s.login)
+	mux.HandleFunc("POST "+SAMLACSPath, s.acs)
+	mux.HandleFunc("GET "+SAMLMetadataPath, s.metadata)
+	return mux
+}
+
+// login serves GET /v1/saml/login?redirect_uri=&state=: it records the
+// AuthnRequest and where the token goes in the login cookie, then sends the
+// browser to the IdP.
+func (s *SAMLServiceProvider) login(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, state := q.Get("redirect_uri"), q.Get("state")
+	if redirect != "" && !loopbackURL(redirect) {
+		http.Error(w, "redirect_uri must be http://127.0.0.1:<port>/... or http://localhost:<port>/...", http.StatusBadRequest)
+		return
+	}
+	if len(state) > 256 {
+		http.Error(w, "state too long", http.StatusBadRequest)
+		return
+	}
+	req, err := s.sp.MakeAuthenticationRequest(s.sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, "cannot start login", err)
+		return
+	}
+	relay := randomRelayState()
+	cookie, err := s.loginCookie(relay, pendingLogin{RequestID: req.ID, Redirect: redirect, State: state, Expires: s.clock.Now().Add(s.loginTTL).Unix()})
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, "cannot start login", err)
+		return
+	}
+	u, err := req.Redirect(relay, &s.sp)
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, "cannot start login", err)
+		return
+	}
+	http.SetCookie(w, cookie)
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+// acs serves POST /v1/saml/acs: it validates the IdP's response to a pending
+// login and hands over a session token.
+func (s *SAMLServiceProvider) acs(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "malformed form", http.StatusBadRequest)
+		return
+	}
+	p, ok := s.take(w, r, r.PostForm.Get("RelayState"))
+	if !ok {
+		http.Error(w, "unknown or expired login; start again", http.StatusBadRequest)
+		return
+	}
+	raw, err := base64.StdEncoding.DecodeString(r.PostForm.Get("SAMLResponse"))
+	if err != nil {
+		http.Error(w, "malformed SAMLResponse", http.StatusBadRequest)
+		return
+	}
+	// Signature, issuer, audience, destination, recipient, validity window
+	// and InResponseTo; the library keeps the reason out of Error().
+	assertion, err := s.sp.ParseXMLResponse(raw, []string{p.RequestID}, s.sp.AcsURL)
+	if err != nil {
+		var ire *saml.InvalidResponseError
+		if errors.As(err, &ire) {
+			err = ire.PrivateErr
+		}
+		s.fail(w, r, http.StatusUnauthorized, "SAML response rejected", err)
+		return
+	}
+	claims, err := s.sessionClaims(assertion)
+	if err != nil {
+		s.fail(w, r, http.StatusUnauthorized, "SAML response rejected: "+err.Error(), err)
+		return
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims).SignedString(s.key)
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, "cannot issue session token", err)
+		return
+	}
+	if s.L != nil {
+		s.L.Info(r.Context(), "saml login", "sub", claims.Subject, "username", claims.Username, "idp", claims.IdP)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if p.Redirect == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = tokenPage.Execute(w, map[string]string{
+			"User":  firstNonEmpty(claims.Username, claims.Subject),
+			"Until": claims.ExpiresAt.UTC().Format(time.RFC3339),
+			"Token": token,
+		})
+		return
+	}
+	u, _ := url.Parse(p.Redirect) // checked by login
+	v := u.Query()
+	v.Set("token", token)
+	if p.State != "" {
+		v.Set("state", p.State)
+	}
+	u.RawQuery = v.Encode()
+	http.Redirect(w, r, u.String(), http.StatusSeeOther)
+}
+
+var tokenPage = template.Must(template.New("token").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>Kamini</title></head><body>
+<p>Signed in as {{.User}}. Session token, valid until {{.Until}}:</p>
+<pre style="white-space:pre-wrap;word-break:break-all">{{.Token}}</pre>
+<p>Use it as <code>KAMINI_TOKEN</code>; it is a bearer credential.</p>
+</body></html>
+`))
+
+// metadata serves the SP metadata to register with the IdP.
+func (s *SAMLServiceProvider) metadata(w http.ResponseWriter, r *http.Request) {
+	md := s.sp.Metadata()
+	// Only the HTTP-POST binding is consumed; artifacts are not resolved.
+	for i := range md.SPSSODescriptors {
+		md.SPSSODescriptors[i].AssertionConsumerServices = md.SPSSODescriptors[i].AssertionConsumerServices[:1]
+	}
+	buf, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, "cannot render metadata", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	_, _ = w.Write(buf)
+}
+
+func (s *SAMLServiceProvider) fail(w http.ResponseWriter, r *http.Request, status int, msg string, err error) {
+	if s.L != nil {
+		s.L.Warn(r.Context(), "saml: "+msg, "error", err)
+	}
+	http.Error(w, msg, status)
+}
+
+// loginCookie seals p into the cookie for relay: base64url JSON, a dot and an
+// HMAC over the RelayState and the JSON, so neither can be altered or swapped.
+// The IdP posts the response cross-site, hence SameSite=None on https.
+func (s *SAMLServiceProvider) loginCookie(relay string, p pendingLogin) (*http.Cookie, error) {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	value := base64.RawURLEncoding.EncodeToString(payload)
+	c := &http.Cookie{
+		Name:     loginCookiePrefix + relay,
+		Value:    value + "." + s.loginMAC(relay, value),
+		Path:     s.cookiePath,
+		MaxAge:   int(s.loginTTL / time.Second),
+		HttpOnly: true,
+		Secure:   s.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if s.secureCookie {
+		c.SameSite = http.SameSiteNoneMode
+	}
+	return c, nil
+}
+
+func (s *SAMLServiceProvider) loginMAC(relay, value string) string {
+	mac := hmac.New(sha256.New, s.loginKey)
+	mac.Write([]byte(relay + "." + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// take returns the login cookie for relay and clears it. Each AuthnRequest is
+// answered at most once: its ID is remembered until the login expires.
+func (s *SAMLServiceProvider) take(w http.ResponseWriter, r *http.Request, relay string) (pendingLogin, bool) {
+	if relay == "" {
+		return pendingLogin{}, false
+	}
+	c, err := r.Cookie(loginCookiePrefix + relay)
+	if err != nil {
+		return pendingLogin{}, false
+	}
+	http.SetCookie(w, &http.Cookie{Name: c.Name, Path: s.cookiePath, MaxAge: -1, HttpOnly: true, Secure: s.secureCookie})
+	value, mac, ok := strings.Cut(c.Value, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(s.loginMAC(relay, value))) {
+		return pendingLogin{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return pendingLogin{}, false
+	}
+	var p pendingLogin
+	if err := json.Unmarshal(payload, &p); err != nil || p.RequestID == "" {
+		return pendingLogin{}, false
+	}
+	now := s.clock.Now()
+	expires := time.Unix(p.Expires, 0)
+	if now.After(expires) {
+		return pendingLogin{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, seen := s.answered.Get(p.RequestID, now); seen {
+		return pendingLogin{}, false
+	}
+	s.answered.Set(p.RequestID, struct{}{}, expires.Add(time.Second))
+	return p, true
+}
+
+// sessionClaims maps a verified assertion to session token claims. The token
+// expires after SessionTTL, or earlier when the IdP ends its session first.
+func (s *SAMLServiceProvider) sessionClaims(a *saml.Assertion) (samlClaims, error) {
+	if a.Subject == nil || a.Subject.NameID == nil || a.Subject.NameID.Value == "" {
+		return samlClaims{}, errors.New("assertion has no NameID")
+	}
+	if a.Subject.NameID.Format == string(saml.TransientNameIDFormat) {
+		return samlClaims{}, errors.New("transient NameID; configure a persistent NameID at the IdP")
+	}
+	sub := a.Subject.NameID.Value
+	attrs := map[string]any{}
+	for _, st := range a.AttributeStatements {
+		for _, at := range st.Attributes {
+			var vs []string
+			for _, v := range at.Values {
+				vs = append(vs, v.Value)
+			}
+			names := []string{at.Name}
+			if at.FriendlyName != "" && at.FriendlyName != at.Name {
+				names = append(names, at.FriendlyName)
+			}
+			for _, name := range names {
+				prev, _ := attrs[name].([]string)
+				attrs[name] = append(prev, vs...)
+			}
+		}
+	}
+	email := s.email.string(attrs)
+	username := s.username.string(attrs)
+	if username == "" && email != "" {
+		if i := strings.IndexByte(email, '@'); i > 0 {
+			username = email[:i]
+		}
+	}
+	now := s.clock.Now()
+	exp := now.Add(s.sessionTTL)
+	c := samlClaims{
+		IdP:      a.Issuer.Value,
+		Username: firstNonEmpty(username, sub),
+		Email:    email,
+		Roles:    s.roles.strings(attrs),
+		Groups:   s.groups.strings(attrs),
+	}
+	if len(a.AuthnStatements) > 0 {
+		st := a.AuthnStatements[0]
+		if !st.AuthnInstant.IsZero() {
+			c.AuthTime = st.AuthnInstant.Unix()
+		}
+		if st.AuthnContext.AuthnContextClassRef != nil {
+			c.ACR = st.AuthnContext.AuthnContextClassRef.Value
+		}
+		if st.SessionNotOnOrAfter != nil && st.SessionNotOnOrAfter.Before(exp) {
+			exp = *st.SessionNotOnOrAfter
+		}
+	}
+	if !exp.After(now) {
+		return samlClaims{}, errors.New("IdP session already ended")
+	}
+	c.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    SAMLSessionIssuer,
+		Subject:   sub,
+		Audience:  jwt.ClaimStrings{s.audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(exp),
+	}
+	return c, nil
+}
+
+// Authenticate verifies a session token minted by this provider (or one
+// sharing its SessionKeyPath).
+func (s *SAMLServiceProvider) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	token := stripBearer(bearer)
+	if token == "" {
+		return domain.Identity{}, errors.New("empty bearer token")
+	}
+	var c samlClaims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) { return s.key.Public(), nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(SAMLSessionIssuer),
+		jwt.WithAudience(s.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.clock.Now),
+	)
+	if err != nil {
+		return domain.Identity{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
+	}
+	if c.Subject == "" {
+		return domain.Identity{}, fmt.Errorf("%w: session token has no sub", domain.ErrInvalidToken)
+	}
+	extras := map[string]any{"iss": c.IdP}
+	if c.AuthTime > 0 {
+		extras["auth_time"] = time.Unix(c.AuthTime, 0).UTC()
+	}
+	if c.ACR != "" {
+		extras["acr"] = c.ACR
+	}
+	id := domain.Identity{
+		Subject:  c.Subject,
+		Username: strings.ToLower(c.Username),
+		Email:    strings.ToLower(c.Email),
+		Roles:    c.Roles,
+		Groups:   c.Groups,
+		Claims:   extras,
+		Provider: ProviderSAML,
+	}
+	if s.L != nil {
+		s.L.Debug(ctx, "saml session authenticated", "sub", id.Subject, "username", id.Username, "idp", c.IdP)
+	}
+	return id, nil
+}
+
+// loopbackURL reports whether raw is an http URL on the loopback interface
+// with an explicit port, where the CLI waits for the token (RFC 8252 §7.3).
+func loopbackURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "http" || u.Port() == "" || u.User != nil || u.Fragment != "" {
+		return false
+	}
+	switch u.Hostname() {
+	case "127.0.0.1", "::1", "localhost":
+		return true
+	}
+	return false
+}
+
+// randomRelayState is an unguessable RelayState, well under the 80 bytes
+// SAML bindings allow.
+func randomRelayState() string {
+	b := make([]byte, 18)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,400 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/xml"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+// testIdP is an IdP signing with a locally generated key, answering
+// AuthnRequests directly instead of serving a login page.
+type testIdP struct {
+	idp *saml.IdentityProvider
+	sps map[string]*saml.EntityDescriptor
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	p := &testIdP{sps: map[string]*saml.EntityDescriptor{}}
+	p.idp = &saml.IdentityProvider{
+		Key:                     key,
+		Certificate:             cert,
+		MetadataURL:             url.URL{Scheme: "https", Host: "idp.example.com", Path: "/metadata"},
+		SSOURL:                  url.URL{Scheme: "https", Host: "idp.example.com", Path: "/sso"},
+		ServiceProviderProvider: p,
+	}
+	return p
+}
+
+func (p *testIdP) GetServiceProvider(r *http.Request, id string) (*saml.EntityDescriptor, error) {
+	if sp, ok := p.sps[id]; ok {
+		return sp, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (p *testIdP) metadata(t *testing.T) []byte {
+	t.Helper()
+	buf, err := xml.Marshal(p.idp.Metadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+// register trusts the SP, using the metadata it serves.
+func (p *testIdP) register(t *testing.T, h http.Handler) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, SAMLMetadataPath, nil))
+	var md saml.EntityDescriptor
+	if rec.Code != http.StatusOK || xml.Unmarshal(rec.Body.Bytes(), &md) != nil {
+		t.Fatalf("metadata: %d %s", rec.Code, rec.Body)
+	}
+	p.sps[md.EntityID] = &md
+}
+
+// respond answers the AuthnRequest at location (the SP's redirect) for sess.
+func (p *testIdP) respond(t *testing.T, location string, sess *saml.Session) url.Values {
+	t.Helper()
+	req, err := saml.NewIdpAuthnRequest(p.idp, httptest.NewRequest(http.MethodGet, location, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("IdP rejected AuthnRequest: %v", err)
+	}
+	if err := (saml.DefaultAssertionMaker{}).MakeAssertion(req, sess); err != nil {
+		t.Fatal(err)
+	}
+	if err := req.MakeAssertionEl(); err != nil {
+		t.Fatal(err)
+	}
+	form, err := req.PostBinding()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return url.Values{"SAMLResponse": {form.SAMLResponse}, "RelayState": {form.RelayState}}
+}
+
+func newTestSAML(t *testing.T, idp *testIdP, mutate func(*SAMLConfig)) (*SAMLServiceProvider, http.Handler) {
+	t.Helper()
+	cfg := SAMLConfig{
+		RootURL:         "https://kamini.example.com",
+		IdPMetadata:     idp.metadata(t),
+		GroupsAttribute: "eduPersonAffiliation",
+	}
+	if mutate != nil {
+		mutate(&cfg)
+	}
+	s, err := NewSAMLServiceProvider(context.Background(), cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewSAMLServiceProvider: %v", err)
+	}
+	h := &browser{h: s.Handler(), jar: map[string]*http.Cookie{}}
+	idp.register(t, h)
+	return s, h
+}
+
+// browser keeps the cookies its handler sets and sends them back, like the
+// user's browser between the login and the ACS.
+type browser struct {
+	h   http.Handler
+	jar map[string]*http.Cookie
+}
+
+func (b *browser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, c := range b.jar {
+		r.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	b.h.ServeHTTP(rec, r)
+	for _, c := range rec.Result().Cookies() {
+		if c.MaxAge < 0 {
+			delete(b.jar, c.Name)
+		} else {
+			b.jar[c.Name] = c
+		}
+	}
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+// startLogin returns the IdP URL the login redirects to.
+func startLogin(t *testing.T, h http.Handler, query string) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, SAMLLoginPath+"?"+query, nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body)
+	}
+	return rec.Header().Get("Location")
+}
+
+func postACS(h http.Handler, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, SAMLACSPath, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func aliceSession() *saml.Session {
+	return &saml.Session{
+		NameID:       "emp-1042",
+		NameIDFormat: string(saml.PersistentNameIDFormat),
+		UserName:     "Alice",
+		UserEmail:    "alice@example.com",
+		Groups:       []string{"ssh-users", "ops"},
+		CustomAttributes: []saml.Attribute{
+			{Name: "roles", Values: []saml.AttributeValue{{Value: "admin"}}},
+		},
+		CreateTime: time.Now().Add(-time.Minute).Truncate(time.Second),
+	}
+}
+
+func TestSAMLServiceProvider_Login(t *testing.T) {
+	idp := newTestIdP(t)
+	s, h := newTestSAML(t, idp, nil)
+
+	location := startLogin(t, h, "redirect_uri="+url.QueryEscape("http://127.0.0.1:8123/callback")+"&state=xyz")
+	if !strings.HasPrefix(location, "https://idp.example.com/sso?") {
+		t.Fatalf("redirected to %q", location)
+	}
+	sess := aliceSession()
+	form := idp.respond(t, location, sess)
+	rec := postACS(h, form)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("acs: %d %s", rec.Code, rec.Body)
+	}
+	cb, _ := url.Parse(rec.Header().Get("Location"))
+	if cb.Host != "127.0.0.1:8123" || cb.Path != "/callback" || cb.Query().Get("state") != "xyz" {
+		t.Fatalf("callback %q", cb)
+	}
+	token := cb.Query().Get("token")
+
+	id, err := s.Authenticate(context.Background(), "Bearer "+token)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if id.Subject != "emp-1042" || id.Username != "alice" || id.Email != "alice@example.com" || id.Provider != ProviderSAML ||
+		!slices.Equal(id.Groups, []string{"ssh-users", "ops"}) || !slices.Equal(id.Roles, []string{"admin"}) {
+		t.Fatalf("identity=%+v", id)
+	}
+	if id.Issuer() != "https://idp.example.com/metadata" || !id.AuthTime().Equal(sess.CreateTime) || id.ACR() == "" {
+		t.Fatalf("claims=%+v", id.Claims)
+	}
+
+	// Each login is answered once.
+	if rec := postACS(h, form); rec.Code != http.StatusBadRequest {
+		t.Fatalf("replayed response: %d", rec.Code)
+	}
+}
+
+func TestSAMLServiceProvider_ShowsTokenWithoutRedirect(t *testing.T) {
+	idp := newTestIdP(t)
+	s, h := newTestSAML(t, idp, func(c *SAMLConfig) { c.UsernameAttribute = "mail | regex:^([^@]+)@" })
+	sess := aliceSession()
+	sess.UserName = ""
+	rec := postACS(h, idp.respond(t, startLogin(t, h, ""), sess))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Signed in as alice") {
+		t.Fatalf("acs: %d %s", rec.Code, rec.Body)
+	}
+	token := strings.TrimSpace(strings.SplitN(strings.SplitN(rec.Body.String(), "<pre", 2)[1], ">", 2)[1])
+	token = strings.SplitN(token, "<", 2)[0]
+	if id, err := s.Authenticate(context.Background(), token); err != nil || id.Username != "alice" {
+		t.Fatalf("Authenticate: %+v %v", id, err)
+	}
+}
+
+func TestSAMLServiceProvider_Rejects(t *testing.T) {
+	idp := newTestIdP(t)
+	_, h := newTestSAML(t, idp, nil)
+
+	for _, redirect := range []string{"https://127.0.0.1:8123/", "http://evil.example.com:8123/", "http://127.0.0.1/", "http://user@localhost:1/"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, SAMLLoginPath+"?redirect_uri="+url.QueryEscape(redirect), nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("redirect_uri %q: %d", redirect, rec.Code)
+		}
+	}
+
+	// Unknown RelayState.
+	form := idp.respond(t, startLogin(t, h, ""), aliceSession())
+	form.Set("RelayState", "made-up")
+	if rec := postACS(h, form); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown relay state: %d", rec.Code)
+	}
+
+	// Signed by a key the metadata does not list.
+	rogue := newTestIdP(t)
+	rogue.register(t, h)
+	if rec := postACS(h, rogue.respond(t, startLogin(t, h, ""), aliceSession())); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("forged signature: %d %s", rec.Code, rec.Body)
+	}
+
+	// Answering a different request.
+	first := startLogin(t, h, "")
+	second := idp.respond(t, startLogin(t, h, ""), aliceSession())
+	second.Set("RelayState", idp.respond(t, first, aliceSession()).Get("RelayState"))
+	if rec := postACS(h, second); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("mismatched InResponseTo: %d", rec.Code)
+	}
+
+	// Transient NameIDs cannot be revoked or blocked.
+	sess := aliceSession()
+	sess.NameIDFormat = string(saml.TransientNameIDFormat)
+	if rec := postACS(h, idp.respond(t, startLogin(t, h, ""), sess)); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "transient") {
+		t.Fatalf("transient NameID: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestSAMLServiceProvider_SessionToken(t *testing.T) {
+	idp := newTestIdP(t)
+	clock := &fakeClock{time.Now()}
+	s, h := newTestSAML(t, idp, func(c *SAMLConfig) { c.Clock = clock; c.SessionTTL = time.Minute })
+	cb, _ := url.Parse(postACS(h, idp.respond(t, startLogin(t, h, "redirect_uri=http://localhost:9/"), aliceSession())).Header().Get("Location"))
+	token := cb.Query().Get("token")
+
+	// Another provider (another key) does not accept it.
+	other, _ := newTestSAML(t, idp, nil)
+	if _, err := other.Authenticate(context.Background(), token); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected foreign key rejection, got %v", err)
+	}
+	clock.t = clock.t.Add(2 * time.Minute)
+	if _, err := s.Authenticate(context.Background(), token); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected expiry, got %v", err)
+	}
+}
+
+func TestSAMLServiceProvider_SharedSessionKey(t *testing.T) {
+	idp := newTestIdP(t)
+	path := t.TempDir() + "/saml.key"
+	a, h := newTestSAML(t, idp, func(c *SAMLConfig) { c.SessionKeyPath = path })
+	b, _ := newTestSAML(t, idp, func(c *SAMLConfig) { c.SessionKeyPath = path })
+	cb, _ := url.Parse(postACS(h, idp.respond(t, startLogin(t, h, "redirect_uri=http://localhost:9/"), aliceSession())).Header().Get("Location"))
+	for _, s := range []*SAMLServiceProvider{a, b} {
+		if _, err := s.Authenticate(context.Background(), cb.Query().Get("token")); err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+	}
+}
+
+func TestSAMLServiceProvider_LoginStateInCookie(t *testing.T) {
+	idp := newTestIdP(t)
+	path := t.TempDir() + "/saml.key"
+	a, h := newTestSAML(t, idp, func(c *SAMLConfig) { c.SessionKeyPath = path })
+	jar := h.(*browser).jar
+
+	// Starting logins stores nothing on the server.
+	for range 100 {
+		startLogin(t, h, "")
+	}
+	if n := a.answered.Len(); n != 0 || len(jar) != 100 {
+		t.Fatalf("answered=%d cookies=%d", n, len(jar))
+	}
+	clear(jar)
+	location := startLogin(t, h, "redirect_uri=http://localhost:9/")
+	var c *http.Cookie
+	for _, v := range jar {
+		c = v
+	}
+	if !strings.HasPrefix(c.Name, loginCookiePrefix) || !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteNoneMode || c.Path != SAMLACSPath {
+		t.Fatalf("cookie %+v", c)
+	}
+	saved := *c
+	form := idp.respond(t, location, aliceSession())
+
+	// A tampered cookie is refused and cleared.
+	tampered := saved
+	tampered.Value = "x" + saved.Value[1:]
+	jar[c.Name] = &tampered
+	if rec := postACS(h, form); rec.Code != http.StatusBadRequest || len(jar) != 0 {
+		t.Fatalf("tampered cookie: %d cookies=%d", rec.Code, len(jar))
+	}
+
+	// Another replica sharing the session key finishes the login.
+	_, other := newTestSAML(t, idp, func(c *SAMLConfig) { c.SessionKeyPath = path })
+	other.(*browser).jar[saved.Name] = &saved
+	if rec := postACS(other, form); rec.Code != http.StatusSeeOther {
+		t.Fatalf("other replica: %d %s", rec.Code, rec.Body)
+	}
+
+	// A replica answers each login once.
+	other.(*browser).jar[saved.Name] = &saved
+	if rec := postACS(other, form); rec.Code != http.StatusBadRequest {
+		t.Fatalf("replayed cookie: %d", rec.Code)
+	}
+}
+
+func TestNewSAMLServiceProvider_Config(t *testing.T) {
+	idp := newTestIdP(t)
+	md := idp.metadata(t)
+	cases := map[string]SAMLConfig{
+		"relative root":   {RootURL: "/kamini", IdPMetadata: md},
+		"no metadata":     {RootURL: "https://kamini.example.com"},
+		"not metadata":    {RootURL: "https://kamini.example.com", IdPMetadata: []byte("<html/>")},
+		"transient":       {RootURL: "https://kamini.example.com", IdPMetadata: md, NameIDFormat: "transient"},
+		"bad attribute":   {RootURL: "https://kamini.example.com", IdPMetadata: md, UsernameAttribute: "uid | nope"},
+		"two idps in set": {RootURL: "https://kamini.example.com", IdPMetadata: entities(t, idp, newTestIdP(t))},
+	}
+	for name, cfg := range cases {
+		if _, err := NewSAMLServiceProvider(context.Background(), cfg, ilog.NewNop()); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	if _, err := NewSAMLServiceProvider(context.Background(), SAMLConfig{RootURL: "https://kamini.example.com", IdPMetadata: entities(t, idp)}, ilog.NewNop()); err != nil {
+		t.Fatalf("EntitiesDescriptor: %v", err)
+	}
+}
+
+func entities(t *testing.T, idps ...*testIdP) []byte {
+	t.Helper()
+	var eds saml.EntitiesDescriptor
+	for _, p := range idps {
+		md := p.idp.Metadata()
+		md.EntityID += "/" + strings.Repeat("x", len(eds.EntityDescriptors))
+		eds.EntityDescriptors = append(eds.EntityDescriptors, *md)
+	}
+	buf, err := xml.Marshal(eds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	oidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/haukened/kamini/internal/adapters/ttlcache"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// UserInfoConfig enables enrichment from the provider's userinfo endpoint,
+// for IdPs that leave claims such as email or groups out of their tokens. It
+// needs an access token, so AccessTokens or Introspection must be set.
+type UserInfoConfig struct {
+	// Claims are copied from the userinfo response when the token lacks them,
+	// before claim mapping; e.g. ["email", "groups"]. Required.
+	Claims []string
+
+	CacheTTL   time.Duration // responses reused per subject; default 1m
+	MaxEntries int           // cached subjects; default 10000
+	Clock      usecase.Clock // optional
+}
+
+// userInfo fetches and caches userinfo claims per subject.
+type userInfo struct {
+	cfg      UserInfoConfig
+	provider *oidc.Provider
+	client   *http.Client
+
+	cache *ttlcache.Cache[string, map[string]any] // by subject
+}
+
+func newUserInfo(cfg UserInfoConfig, provider *oidc.Provider, client *http.Client) (*userInfo, error) {
+	if provider.UserInfoEndpoint() == "" {
+		return nil, errors.New("userinfo: provider metadata has no userinfo_endpoint")
+	}
+	if len(cfg.Claims) == 0 {
+		return nil, errors.New("userinfo: claims to merge required")
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = time.Minute
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = domain.SystemClock()
+	}
+	return &userInfo{cfg: cfg, provider: provider, client: client, cache: ttlcache.New[string, map[string]any](cfg.MaxEntries)}, nil
+}
+
+// merge fills the configured claims the token lacks from the userinfo response
+// for sub, fetched with accessToken. The response must be about the same sub.
+func (u *userInfo) merge(ctx context.Context, accessToken, sub string, claims map[string]any) error {
+	now := u.cfg.Clock.Now()
+	info, ok := u.cache.Get(sub, now)
+	if !ok {
+		fetched, err := u.fetch(ctx, accessToken, sub)
+		if err != nil {
+			return err
+		}
+		info = fetched
+		u.cache.Set(sub, info, now.Add(u.cfg.CacheTTL))
+	}
+	for _, name := range u.cfg.Claims {
+		if _, present := claims[name]; present {
+			continue
+		}
+		if v, ok := info[name]; ok {
+			claims[name] = v
+		}
+	}
+	return nil
+}
+
+func (u *userInfo) fetch(ctx context.Context, accessToken, sub string) (map[string]any, error) {
+	if u.client != nil {
+		ctx = oidc.ClientContext(ctx, u.client)
+	}
+	info, err := u.provider.UserInfo(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}))
+	if err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	// OIDC Core 5.3.2: the response may only be used when sub matches.
+	if info.Subject != sub {
+		return nil, fmt.Errorf("%w: userinfo sub %q does not match token", domain.ErrInvalidToken, info.Subject)
+	}
+	var out map[string]any
+	if err := info.Claims(&out); err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	return out, nil
+}
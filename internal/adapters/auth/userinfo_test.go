@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+// newUserInfoProvider is an OIDC provider whose userinfo answers per access
+// token.
+func newUserInfoProvider(t *testing.T, responses map[string]map[string]any, calls *atomic.Int32) *oidctest.Provider {
+	t.Helper()
+	idp := oidctest.New(t)
+	idp.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		resp, ok := responses[r.Header.Get("Authorization")]
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	idp.SetMetadata("userinfo_endpoint", idp.URL+"/userinfo")
+	return idp
+}
+
+func TestOIDCAuthenticator_UserInfo(t *testing.T) {
+	responses := map[string]map[string]any{}
+	var calls atomic.Int32
+	idp := newUserInfoProvider(t, responses, &calls)
+
+	clock := &fakeClock{t: time.Now()}
+	cfg := OIDCAuthConfig{
+		IssuerURL:    idp.URL,
+		Audiences:    []string{"api://kamini"},
+		AccessTokens: true,
+		UserInfo:     &UserInfoConfig{Claims: []string{"email", "groups", "preferred_username"}, CacheTTL: time.Minute, Clock: clock},
+	}
+	a, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	ctx := context.Background()
+	token := idp.AccessToken("api://kamini", "sub-1", map[string]any{"preferred_username": "alice"})
+	responses["Bearer "+token] = map[string]any{
+		"sub":                "sub-1",
+		"email":              "Alice@Example.com",
+		"groups":             []string{"ssh-users"},
+		"preferred_username": "mallory", // the token's own claim wins
+		"phone_number":       "+1555",   // not configured
+	}
+
+	id, err := a.Authenticate(ctx, token)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if id.Email != "alice@example.com" || !slices.Equal(id.Groups, []string{"ssh-users"}) || id.Username != "alice" {
+		t.Fatalf("identity: %+v", id)
+	}
+	if _, err := a.Authenticate(ctx, token); err != nil || calls.Load() != 1 {
+		t.Fatalf("expected cached userinfo: err=%v calls=%d", err, calls.Load())
+	}
+	clock.t = clock.t.Add(2 * time.Minute)
+	if _, err := a.Authenticate(ctx, token); err != nil || calls.Load() != 2 {
+		t.Fatalf("expected refetch after CacheTTL: err=%v calls=%d", err, calls.Load())
+	}
+
+	// A userinfo response about someone else must not be merged.
+	other := idp.AccessToken("api://kamini", "sub-2", nil)
+	responses["Bearer "+other] = map[string]any{"sub": "sub-3", "email": "eve@example.com"}
+	if _, err := a.Authenticate(ctx, other); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected sub mismatch rejection, got %v", err)
+	}
+	// Userinfo failures fail authentication.
+	rejected := idp.AccessToken("api://kamini", "sub-4", nil)
+	if _, err := a.Authenticate(ctx, rejected); err == nil {
+		t.Fatalf("expected userinfo error")
+	}
+
+	cfg.AccessTokens = false
+	if _, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop()); err == nil {
+		t.Fatalf("expected error: userinfo needs access tokens")
+	}
+	cfg.AccessTokens, cfg.UserInfo = true, &UserInfoConfig{}
+	if _, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop()); err == nil {
+		t.Fatalf("expected error without claims to merge")
+	}
+	plain := oidctest.New(t)
+	cfg.IssuerURL, cfg.UserInfo = plain.URL, &UserInfoConfig{Claims: []string{"email"}}
+	if _, err := NewOIDCAuthenticator(context.Background(), cfg, ilog.NewNop()); err == nil {
+		t.Fatalf("expected error without userinfo_endpoint")
+	}
+}
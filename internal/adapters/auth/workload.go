@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// Profiles map a CI platform's or cluster's OIDC token to a workload identity
+// instead of a person. Set OIDCAuthConfig.Profile and an audience only Kamini
+// accepts.
+const (
+	ProfileGitHubActions = "github-actions"
+	ProfileGitLabCI      = "gitlab-ci"
+	ProfileKubernetes    = "kubernetes" // projected ServiceAccount tokens; IssuerURL required
+
+	GitHubActionsIssuer = "https://token.actions.githubusercontent.com"
+	GitLabIssuer        = "https://gitlab.com" // self-managed instances use their own URL
+)
+
+// profileIssuer returns the default issuer for profile, or an error for an
+// unknown one.
+func profileIssuer(profile string) (string, error) {
+	switch profile {
+	case "", ProfileKubernetes:
+		return "", nil
+	case ProfileGitHubActions:
+		return GitHubActionsIssuer, nil
+	case ProfileGitLabCI:
+		return GitLabIssuer, nil
+	default:
+		return "", fmt.Errorf("unknown profile %q (want %s, %s or %s)", profile, ProfileGitHubActions, ProfileGitLabCI, ProfileKubernetes)
+	}
+}
+
+// workloadIdentity maps a CI job or ServiceAccount token. Username is the user
+// who triggered the job, or the ServiceAccount's Kubernetes username (for audit
+// only); principals come from the authorizer's workload rules.
+func (a *OIDCAuthenticator) workloadIdentity(ctx context.Context, iss string, aud []string, sub string, claims map[string]any) (domain.Identity, error) {
+	w := domain.Workload{Platform: a.profile, Environment: getString(claims, "environment")}
+	extras := map[string]any{"iss": iss, "aud": aud}
+	var actor string
+	switch a.profile {
+	case ProfileGitHubActions:
+		// workflow_ref: "org/app/.github/workflows/deploy.yml@refs/heads/main"
+		w.Repository = getString(claims, "repository")
+		w.Ref = getString(claims, "ref")
+		wf, _, _ := strings.Cut(getString(claims, "workflow_ref"), "@")
+		w.Workflow = strings.TrimPrefix(wf, w.Repository+"/")
+		actor = getString(claims, "actor")
+		for _, k := range []string{"sha", "run_id", "event_name", "workflow"} {
+			if v := getString(claims, k); v != "" {
+				extras[k] = v
+			}
+		}
+	case ProfileGitLabCI:
+		// ci_config_ref_uri: "gitlab.com/group/app//.gitlab-ci.yml@refs/heads/main"
+		w.Repository = getString(claims, "project_path")
+		w.Ref = getString(claims, "ref_path")
+		if w.Ref == "" && getString(claims, "ref") != "" {
+			switch getString(claims, "ref_type") {
+			case "branch":
+				w.Ref = "refs/heads/" + getString(claims, "ref")
+			case "tag":
+				w.Ref = "refs/tags/" + getString(claims, "ref")
+			}
+		}
+		if _, wf, ok := strings.Cut(getString(claims, "ci_config_ref_uri"), "//"); ok {
+			w.Workflow, _, _ = strings.Cut(wf, "@")
+		}
+		actor = getString(claims, "user_login")
+		for _, k := range []string{"sha", "pipeline_id", "pipeline_source"} {
+			if v := getString(claims, k); v != "" {
+				extras[k] = v
+			}
+		}
+	case ProfileKubernetes:
+		// "kubernetes.io": {"namespace": "payments", "serviceaccount": {"name": "batch"}, "pod": {...}}
+		k8s, _ := claims["kubernetes.io"].(map[string]any)
+		sa, _ := k8s["serviceaccount"].(map[string]any)
+		ns, name := getString(k8s, "namespace"), getString(sa, "name")
+		if ns == "" || name == "" || sub != "system:serviceaccount:"+ns+":"+name {
+			return domain.Identity{}, fmt.Errorf("%w: not a bound service account token", domain.ErrInvalidToken)
+		}
+		w.Repository = ns + "/" + name
+		w.Environment = a.name // the cluster, when several are trusted
+		actor = sub
+		extras["namespace"], extras["serviceaccount"] = ns, name
+		for _, k := range []string{"pod", "node"} {
+			obj, _ := k8s[k].(map[string]any)
+			if v := getString(obj, "name"); v != "" {
+				extras[k] = v
+			}
+		}
+	}
+	if w.Repository == "" || sub == "" {
+		return domain.Identity{}, fmt.Errorf("%w: not a %s job token", domain.ErrInvalidToken, a.profile)
+	}
+	extras["workload"] = w
+	if a.name != "" {
+		extras["issuer"] = a.name
+	}
+	id := domain.Identity{
+		Subject:  sub,
+		Username: strings.ToLower(actor),
+		Claims:   extras,
+		Provider: a.profile,
+	}
+	if a.L != nil {
+		a.L.Debug(ctx, "workload authenticated", "sub", sub, "repository", w.Repository, "ref", w.Ref)
+	}
+	return id, nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+func TestOIDCAuthenticator_WorkloadProfiles(t *testing.T) {
+	idp := oidctest.New(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		profile string
+		sub     string
+		claims  map[string]any
+		want    domain.Workload
+		actor   string
+	}{
+		{
+			profile: ProfileGitHubActions,
+			sub:     "repo:org/app:environment:prod",
+			claims: map[string]any{
+				"repository":   "org/app",
+				"ref":          "refs/heads/main",
+				"environment":  "prod",
+				"workflow":     "Deploy",
+				"workflow_ref": "org/app/.github/workflows/deploy.yml@refs/heads/main",
+				"actor":        "OctoCat",
+				"run_id":       "42",
+			},
+			want:  domain.Workload{Platform: ProfileGitHubActions, Repository: "org/app", Ref: "refs/heads/main", Environment: "prod", Workflow: ".github/workflows/deploy.yml"},
+			actor: "octocat",
+		},
+		{
+			profile: ProfileGitLabCI,
+			sub:     "project_path:group/app:ref_type:tag:ref:v1.0.0",
+			claims: map[string]any{
+				"project_path":      "group/app",
+				"ref":               "v1.0.0",
+				"ref_type":          "tag",
+				"ci_config_ref_uri": "gitlab.example.com/group/app//.gitlab-ci.yml@refs/tags/v1.0.0",
+				"user_login":        "dev1",
+				"pipeline_source":   "push",
+			},
+			want:  domain.Workload{Platform: ProfileGitLabCI, Repository: "group/app", Ref: "refs/tags/v1.0.0", Workflow: ".gitlab-ci.yml"},
+			actor: "dev1",
+		},
+	}
+	for _, tt := range tests {
+		a, err := NewOIDCAuthenticator(ctx, OIDCAuthConfig{IssuerURL: idp.URL, Audiences: []string{"kamini"}, Profile: tt.profile}, ilog.NewNop())
+		if err != nil {
+			t.Fatalf("%s: NewOIDCAuthenticator: %v", tt.profile, err)
+		}
+		id, err := a.Authenticate(ctx, idp.IDToken("kamini", tt.sub, tt.claims))
+		if err != nil {
+			t.Fatalf("%s: Authenticate: %v", tt.profile, err)
+		}
+		w, ok := id.Workload()
+		if !ok || w != tt.want {
+			t.Fatalf("%s: workload=%+v ok=%v", tt.profile, w, ok)
+		}
+		if id.Provider != tt.profile || id.Subject != tt.sub || id.Username != tt.actor || len(id.Roles)+len(id.Groups) != 0 {
+			t.Fatalf("%s: identity=%+v", tt.profile, id)
+		}
+
+		// The audience pins tokens minted for Kamini.
+		if _, err := a.Authenticate(ctx, idp.IDToken("https://github.com/org", tt.sub, tt.claims)); !errors.Is(err, domain.ErrAudienceMismatch) {
+			t.Fatalf("%s: expected audience mismatch, got %v", tt.profile, err)
+		}
+		// An ordinary user token from the same issuer is not a job token.
+		if _, err := a.Authenticate(ctx, idp.IDToken("kamini", "user-1", map[string]any{"preferred_username": "alice"})); !errors.Is(err, domain.ErrInvalidToken) {
+			t.Fatalf("%s: expected non-job token rejection, got %v", tt.profile, err)
+		}
+	}
+
+	if _, err := NewOIDCAuthenticator(ctx, OIDCAuthConfig{Audiences: []string{"kamini"}, Profile: "jenkins"}, ilog.NewNop()); err == nil {
+		t.Fatalf("expected unknown profile error")
+	}
+}
+
+func TestOIDCAuthenticator_KubernetesJWKSFile(t *testing.T) {
+	idp := oidctest.New(t)
+	jwks := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwks, idp.JWKS(), 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+	const iss = "https://kubernetes.default.svc.cluster.local"
+	ctx := context.Background()
+	// No discovery: the issuer is unreachable from here.
+	a, err := NewOIDCAuthenticator(ctx, OIDCAuthConfig{Name: "prod", IssuerURL: iss, JWKSFile: jwks, Audiences: []string{"kamini"}, Profile: ProfileKubernetes}, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator: %v", err)
+	}
+	sub := "system:serviceaccount:payments:batch"
+	claims := map[string]any{"iss": iss, "kubernetes.io": map[string]any{
+		"namespace":      "payments",
+		"serviceaccount": map[string]any{"name": "batch", "uid": "u1"},
+		"pod":            map[string]any{"name": "batch-7d9f", "uid": "u2"},
+	}}
+	id, err := a.Authenticate(ctx, idp.IDToken("kamini", sub, claims))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	w, ok := id.Workload()
+	want := domain.Workload{Platform: ProfileKubernetes, Repository: "payments/batch", Environment: "prod"}
+	if !ok || w != want {
+		t.Fatalf("workload=%+v ok=%v", w, ok)
+	}
+	if id.Provider != ProfileKubernetes || id.Username != sub || id.Claims["namespace"] != "payments" || id.Claims["serviceaccount"] != "batch" || id.Claims["pod"] != "batch-7d9f" {
+		t.Fatalf("identity=%+v", id)
+	}
+
+	// Tokens bound to the API server, claiming another account, or signed by
+	// an unknown key are rejected.
+	if _, err := a.Authenticate(ctx, idp.IDToken(iss, sub, claims)); !errors.Is(err, domain.ErrAudienceMismatch) {
+		t.Fatalf("expected audience mismatch, got %v", err)
+	}
+	if _, err := a.Authenticate(ctx, idp.IDToken("kamini", "system:serviceaccount:payments:admin", claims)); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected sub mismatch rejection, got %v", err)
+	}
+	other := oidctest.New(t)
+	if _, err := a.Authenticate(ctx, other.IDToken("kamini", sub, claims)); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected signature rejection, got %v", err)
+	}
+
+	for name, cfg := range map[string]OIDCAuthConfig{
+		"api server audience": {IssuerURL: iss, JWKSFile: jwks, Audiences: []string{iss}, Profile: ProfileKubernetes},
+		"no audience":         {IssuerURL: iss, JWKSFile: jwks, SkipClientIDCheck: true, Profile: ProfileKubernetes},
+		"no issuer":           {JWKSFile: jwks, Audiences: []string{"kamini"}, Profile: ProfileKubernetes},
+		"missing jwks":        {IssuerURL: iss, JWKSFile: filepath.Join(t.TempDir(), "none.json"), Audiences: []string{"kamini"}, Profile: ProfileKubernetes},
+		"jwks with userinfo":  {IssuerURL: iss, JWKSFile: jwks, Audiences: []string{"kamini"}, AccessTokens: true, UserInfo: &UserInfoConfig{Claims: []string{"email"}}},
+	} {
+		if _, err := NewOIDCAuthenticator(ctx, cfg, ilog.NewNop()); err == nil {
+			t.Fatalf("%s: expected config error", name)
+		}
+	}
+}
@@ -26,6 +26,21 @@ type OIDCAuthorizerConfig struct {
 
 	// Optional IP CIDRs (source-address critical option) when set.
 	SourceCIDRs []string // e.g., ["10.0.0.0/8", "192.168.0.0/16"]
+
+	// Any of these roles/groups grants administrative access (revocation, blocklists).
+	// If both slices are empty, no identity is an admin.
+	AdminRoles  []string
+	AdminGroups []string
+
+	// Requirements gate individual principals on how the user authenticated
+	// (amr, acr, auth_time). Principals failing one are withheld from the
+	// certificate; the request is denied only when none remain.
+	Requirements []domain.AuthRequirement
+
+	// Workloads grant principals to CI jobs (identities with a
+	// domain.Workload). Workloads get only these principals; role/group rules
+	// and templates apply to people only. No matching rule denies.
+	Workloads []domain.WorkloadRule
 }
 
 // OIDCAuthorizer implements a simple role/group based authorization.
@@ -35,6 +50,7 @@ type OIDCAuthorizer struct {
 
 // assert interfaces
 var _ usecase.Authorizer = (*OIDCAuthorizer)(nil)
+var _ usecase.AdminAuthorizer = (*OIDCAuthorizer)(nil)
 
 func NewOIDCAuthorizer(cfg OIDCAuthorizerConfig) *OIDCAuthorizer {
 	return &OIDCAuthorizer{cfg: cfg}
@@ -42,17 +58,31 @@ func NewOIDCAuthorizer(cfg OIDCAuthorizerConfig) *OIDCAuthorizer {
 
 // Decide returns a PolicyDecision or a PolicyDeny.
 func (a *OIDCAuthorizer) Decide(id domain.Identity, ctx domain.SignContext) (domain.PolicyDecision, error) {
-	if !a.allowed(id) {
-		return domain.PolicyDecision{}, domain.PolicyDeny{Code: domain.DenyDefault, Message: "access denied"}
+	bounds := domain.TTL{Default: a.cfg.DefaultTTL, Max: a.cfg.MaxTTL}
+	var principals []string
+	if w, ok := id.Workload(); ok {
+		var err error
+		if principals, bounds, err = a.workloadGrant(w, bounds); err != nil {
+			return domain.PolicyDecision{}, err
+		}
+	} else {
+		if !a.allowed(id) {
+			return domain.PolicyDecision{}, domain.PolicyDeny{Code: domain.DenyDefault, Message: "access denied"}
+		}
+		principals = a.buildPrincipals(id)
 	}
 
-	principals := domain.NormalizePrincipals(a.buildPrincipals(id))
+	principals = domain.NormalizePrincipals(principals)
 	if len(principals) == 0 {
 		return domain.PolicyDecision{}, domain.PolicyDeny{Code: domain.DenyPrincipalNotAllowed, Message: "no principals"}
 	}
+	principals, withheld, err := a.applyRequirements(id, ctx.Now, principals)
+	if err != nil {
+		return domain.PolicyDecision{}, err
+	}
 
 	// TTL clamp: enforce defaults and caps according to config.
-	ttl := (domain.TTL{Default: a.cfg.DefaultTTL, Max: a.cfg.MaxTTL}).Clamp(ctx.RequestedTTL)
+	ttl := bounds.Clamp(ctx.RequestedTTL)
 
 	opts := map[string]string{}
 	if len(a.cfg.SourceCIDRs) > 0 {
@@ -72,9 +102,72 @@ func (a *OIDCAuthorizer) Decide(id domain.Identity, ctx domain.SignContext) (dom
 		TTL:             ttl,
 		CriticalOptions: opts,
 		Extensions:      map[string]string{"permit-pty": ""},
+		Withheld:        withheld,
 	}, nil
 }
 
+// workloadGrant returns the principals of every rule matching w and narrows
+// the TTL bounds to the shortest matching rule TTL.
+func (a *OIDCAuthorizer) workloadGrant(w domain.Workload, bounds domain.TTL) ([]string, domain.TTL, error) {
+	var principals []string
+	matched := false
+	for _, r := range a.cfg.Workloads {
+		if !r.Matches(w) {
+			continue
+		}
+		matched = true
+		principals = append(principals, r.Principals...)
+		if r.TTL > 0 && (bounds.Max <= 0 || r.TTL < bounds.Max) {
+			bounds.Max = r.TTL
+		}
+	}
+	if !matched {
+		return nil, bounds, domain.PolicyDeny{Code: domain.DenyDefault, Message: "no workload rule matches"}
+	}
+	if bounds.Max > 0 && (bounds.Default <= 0 || bounds.Default > bounds.Max) {
+		bounds.Default = bounds.Max
+	}
+	return principals, bounds, nil
+}
+
+// applyRequirements withholds principals whose requirements id does not meet.
+// If that leaves none, the first unmet requirement's denial is returned.
+func (a *OIDCAuthorizer) applyRequirements(id domain.Identity, now time.Time, principals []string) (kept, withheld []string, err error) {
+	var first error
+	for _, p := range principals {
+		ok := true
+		for _, r := range a.cfg.Requirements {
+			if !r.Applies(p) {
+				continue
+			}
+			if err := r.Check(id, now); err != nil {
+				ok = false
+				if first == nil {
+					first = err
+				}
+				break
+			}
+		}
+		if ok {
+			kept = append(kept, p)
+		} else {
+			withheld = append(withheld, p)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, withheld, first
+	}
+	return kept, withheld, nil
+}
+
+// AuthorizeAdmin returns nil if id holds an admin role/group, else a PolicyDeny.
+func (a *OIDCAuthorizer) AuthorizeAdmin(id domain.Identity) error {
+	if intersectsFold(a.cfg.AdminRoles, id.Roles) || intersectsFold(a.cfg.AdminGroups, id.Groups) {
+		return nil
+	}
+	return domain.PolicyDeny{Code: domain.DenyRoleMissing, Message: "admin role required"}
+}
+
 func (a *OIDCAuthorizer) allowed(id domain.Identity) bool {
 	if len(a.cfg.AllowRoles) == 0 && len(a.cfg.AllowGroups) == 0 {
 		return false
@@ -1,6 +1,7 @@
 package authorize
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -78,3 +79,109 @@ func TestOIDCAuthorizer_TTLDefaultWhenZero(t *testing.T) {
 		t.Fatalf("ttl=%s want=%s", dec.TTL, 30*time.Minute)
 	}
 }
+
+func TestOIDCAuthorizer_AuthorizeAdmin(t *testing.T) {
+	a := NewOIDCAuthorizer(OIDCAuthorizerConfig{
+		AllowRoles:  []string{"dev"},
+		AdminGroups: []string{"SSH-Admins"},
+	})
+	if err := a.AuthorizeAdmin(domain.Identity{Subject: "s", Groups: []string{"ssh-admins"}}); err != nil {
+		t.Fatalf("expected admin, got %v", err)
+	}
+	err := a.AuthorizeAdmin(domain.Identity{Subject: "s", Roles: []string{"dev"}})
+	var pd domain.PolicyDeny
+	if !errors.As(err, &pd) || pd.Code != domain.DenyRoleMissing {
+		t.Fatalf("expected DenyRoleMissing, got %v", err)
+	}
+}
+
+func TestOIDCAuthorizer_Requirements(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	a := NewOIDCAuthorizer(OIDCAuthorizerConfig{
+		AllowRoles: []string{"dev"},
+		Principals: []string{"{username}", "prod-admin"},
+		Requirements: []domain.AuthRequirement{
+			{Principals: []string{"prod-admin"}, AMR: []string{"mfa", "hwk"}, MaxAge: 15 * time.Minute},
+		},
+	})
+	id := func(claims map[string]any) domain.Identity {
+		return domain.Identity{Subject: "s", Username: "alice", Roles: []string{"dev"}, Claims: claims}
+	}
+
+	dec, err := a.Decide(id(map[string]any{"amr": []string{"pwd", "mfa"}, "auth_time": now.Add(-5 * time.Minute)}), domain.SignContext{Now: now})
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	if len(dec.Principals) != 2 || len(dec.Withheld) != 0 {
+		t.Fatalf("mfa: principals=%v withheld=%v", dec.Principals, dec.Withheld)
+	}
+
+	dec, err = a.Decide(id(map[string]any{"amr": []string{"pwd"}, "auth_time": now}), domain.SignContext{Now: now})
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	if len(dec.Principals) != 1 || dec.Principals[0] != "alice" || len(dec.Withheld) != 1 || dec.Withheld[0] != "prod-admin" {
+		t.Fatalf("password only: principals=%v withheld=%v", dec.Principals, dec.Withheld)
+	}
+
+	// Only the guarded principal requested: nothing left, so deny with the reason.
+	only := NewOIDCAuthorizer(OIDCAuthorizerConfig{
+		AllowRoles:   []string{"dev"},
+		Principals:   []string{"prod-admin"},
+		Requirements: a.cfg.Requirements,
+	})
+	_, err = only.Decide(id(map[string]any{"amr": []string{"hwk"}, "auth_time": now.Add(-time.Hour)}), domain.SignContext{Now: now})
+	var pd domain.PolicyDeny
+	if !errors.As(err, &pd) || pd.Code != domain.DenyAuthTooOld {
+		t.Fatalf("expected DenyAuthTooOld, got %v", err)
+	}
+}
+
+func TestOIDCAuthorizer_Workloads(t *testing.T) {
+	a := NewOIDCAuthorizer(OIDCAuthorizerConfig{
+		AllowGroups: []string{"eng"},
+		Principals:  []string{"{username}"},
+		DefaultTTL:  time.Hour,
+		MaxTTL:      8 * time.Hour,
+		Workloads: []domain.WorkloadRule{
+			{Platform: "github-actions", Repository: "org/app", Ref: "refs/heads/main", Principals: []string{"deploy"}, TTL: 10 * time.Minute},
+			{Platform: "github-actions", Repository: "org/app", Environment: "prod", Principals: []string{"deploy-prod"}, TTL: 5 * time.Minute},
+			{Platform: "github-actions", Repository: "org/*", Ref: "refs/tags/v*", Principals: []string{"release"}},
+		},
+	})
+	job := func(w domain.Workload) domain.Identity {
+		if w.Platform == "" {
+			w.Platform = "github-actions"
+		}
+		// Groups on a workload identity must not grant user principals.
+		return domain.Identity{Subject: "repo:" + w.Repository, Username: "octocat", Groups: []string{"eng"}, Claims: map[string]any{"workload": w}}
+	}
+
+	dec, err := a.Decide(job(domain.Workload{Repository: "org/app", Ref: "refs/heads/main"}), domain.SignContext{})
+	if err != nil || len(dec.Principals) != 1 || dec.Principals[0] != "deploy" || dec.TTL != 10*time.Minute {
+		t.Fatalf("main: %+v, %v", dec, err)
+	}
+	// Requests are clamped to the rule TTL; matching rules add up and the shortest TTL wins.
+	dec, err = a.Decide(job(domain.Workload{Repository: "org/app", Ref: "refs/heads/main", Environment: "prod"}), domain.SignContext{RequestedTTL: time.Hour})
+	if err != nil || len(dec.Principals) != 2 || dec.TTL != 5*time.Minute {
+		t.Fatalf("prod: %+v, %v", dec, err)
+	}
+	dec, err = a.Decide(job(domain.Workload{Repository: "org/lib", Ref: "refs/tags/v1.2.0"}), domain.SignContext{RequestedTTL: 2 * time.Hour})
+	if err != nil || dec.Principals[0] != "release" || dec.TTL != 2*time.Hour {
+		t.Fatalf("release: %+v, %v", dec, err)
+	}
+
+	var pd domain.PolicyDeny
+	_, err = a.Decide(job(domain.Workload{Repository: "org/app", Ref: "refs/heads/feature"}), domain.SignContext{})
+	if !errors.As(err, &pd) || pd.Code != domain.DenyDefault {
+		t.Fatalf("feature branch: expected deny, got %v", err)
+	}
+	_, err = a.Decide(job(domain.Workload{Repository: "evil/app", Ref: "refs/heads/main"}), domain.SignContext{})
+	if !errors.As(err, &pd) {
+		t.Fatalf("other repository: expected deny, got %v", err)
+	}
+	_, err = a.Decide(job(domain.Workload{Platform: "gitlab-ci", Repository: "org/app", Ref: "refs/heads/main"}), domain.SignContext{})
+	if !errors.As(err, &pd) {
+		t.Fatalf("same path on another platform: expected deny, got %v", err)
+	}
+}
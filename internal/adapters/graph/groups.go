@@ -0,0 +1,183 @@
+// Package graph resolves Entra ID group membership through Microsoft Graph,
+// for tokens whose "groups" claim was dropped because of a groups overage.
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/haukened/kamini/internal/adapters/ttlcache"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+const (
+	DefaultAuthorityURL = "https://login.microsoftonline.com"
+	DefaultGraphURL     = "https://graph.microsoft.com/v1.0"
+	// Scope requests the app permissions granted to the client (GroupMember.Read.All).
+	Scope = "https://graph.microsoft.com/.default"
+)
+
+// Config configures the Graph group resolver. The client authenticates with
+// client credentials in the user's tenant (the token's tid), or TenantID when
+// the identity carries none.
+type Config struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	AuthorityURL string // token endpoint base; default DefaultAuthorityURL
+	GraphURL     string // default DefaultGraphURL
+
+	// SecurityEnabledOnly limits results to security groups, as the "groups"
+	// claim does when the app registration emits SecurityGroup only.
+	SecurityEnabledOnly bool
+
+	CacheTTL   time.Duration // how long memberships are reused per user; default 10m
+	MaxEntries int           // cached users; default 10000
+
+	HTTP  *http.Client // optional; also used for token requests
+	Clock usecase.Clock
+}
+
+// GroupResolver calls Graph getMemberObjects for the user's object ID (oid)
+// and caches the result per user for CacheTTL. Errors are not cached.
+type GroupResolver struct {
+	cfg Config
+
+	mu     sync.Mutex
+	tokens map[string]oauth2.TokenSource // per tenant
+
+	cache *ttlcache.Cache[string, []string] // tid/oid
+}
+
+var _ usecase.GroupResolver = (*GroupResolver)(nil)
+
+// New validates cfg and applies defaults.
+func New(cfg Config) (*GroupResolver, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, errors.New("graph: client ID and secret are required")
+	}
+	cfg.AuthorityURL = strings.TrimRight(cfg.AuthorityURL, "/")
+	if cfg.AuthorityURL == "" {
+		cfg.AuthorityURL = DefaultAuthorityURL
+	}
+	cfg.GraphURL = strings.TrimRight(cfg.GraphURL, "/")
+	if cfg.GraphURL == "" {
+		cfg.GraphURL = DefaultGraphURL
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 10 * time.Minute
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	if cfg.HTTP == nil {
+		cfg.HTTP = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = domain.SystemClock()
+	}
+	return &GroupResolver{
+		cfg:    cfg,
+		tokens: map[string]oauth2.TokenSource{},
+		cache:  ttlcache.New[string, []string](cfg.MaxEntries),
+	}, nil
+}
+
+// ResolveGroups returns the IDs of the groups (and directory roles) the user
+// is a transitive member of.
+func (g *GroupResolver) ResolveGroups(ctx context.Context, id domain.Identity) ([]string, error) {
+	oid := id.ObjectID()
+	if oid == "" {
+		return nil, errors.New("graph: identity has no object ID (oid)")
+	}
+	tenant := id.Tenant()
+	if tenant == "" {
+		tenant = g.cfg.TenantID
+	}
+	if tenant == "" {
+		return nil, errors.New("graph: identity has no tenant (tid) and no tenant is configured")
+	}
+	key := tenant + "/" + oid
+	now := g.cfg.Clock.Now()
+	if groups, ok := g.cache.Get(key, now); ok {
+		return append([]string(nil), groups...), nil
+	}
+
+	groups, err := g.memberObjects(ctx, tenant, oid)
+	if err != nil {
+		return nil, err
+	}
+	g.cache.Set(key, groups, now.Add(g.cfg.CacheTTL))
+	return append([]string(nil), groups...), nil
+}
+
+func (g *GroupResolver) memberObjects(ctx context.Context, tenant, oid string) ([]string, error) {
+	tok, err := g.tokenSource(tenant).Token()
+	if err != nil {
+		return nil, fmt.Errorf("graph: token for tenant %q: %w", tenant, err)
+	}
+	body, _ := json.Marshal(map[string]bool{"securityEnabledOnly": g.cfg.SecurityEnabledOnly})
+	u := g.cfg.GraphURL + "/users/" + url.PathEscape(oid) + "/getMemberObjects"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	tok.SetAuthHeader(req)
+	resp, err := g.cfg.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("graph: getMemberObjects: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+		return nil, fmt.Errorf("graph: getMemberObjects: %s %s", resp.Status, e.Error.Code)
+	}
+	var out struct {
+		Value []string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("graph: decode getMemberObjects: %w", err)
+	}
+	return out.Value, nil
+}
+
+// tokenSource returns the cached client-credentials token source for tenant.
+func (g *GroupResolver) tokenSource(tenant string) oauth2.TokenSource {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ts, ok := g.tokens[tenant]; ok {
+		return ts
+	}
+	cc := clientcredentials.Config{
+		ClientID:     g.cfg.ClientID,
+		ClientSecret: g.cfg.ClientSecret,
+		TokenURL:     g.cfg.AuthorityURL + "/" + url.PathEscape(tenant) + "/oauth2/v2.0/token",
+		Scopes:       []string{Scope},
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+	// The token source outlives the request, so it must not hold its context.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, g.cfg.HTTP)
+	ts := cc.TokenSource(ctx)
+	g.tokens[tenant] = ts
+	return ts
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+// newGraphServer stands in for both the Entra token endpoint and Graph.
+func newGraphServer(t *testing.T, groups map[string][]string, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token"):
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("client_secret") != "s3cret" || r.PostForm.Get("scope") != Scope {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+				return
+			}
+			tenant := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+			_, _ = w.Write([]byte(`{"access_token":"tok-` + tenant + `","token_type":"Bearer","expires_in":3600}`))
+		case strings.HasSuffix(r.URL.Path, "/getMemberObjects"):
+			calls.Add(1)
+			oid := strings.Split(strings.TrimPrefix(r.URL.Path, "/users/"), "/")[0]
+			var body struct {
+				SecurityEnabledOnly bool `json:"securityEnabledOnly"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !body.SecurityEnabledOnly {
+				t.Errorf("unexpected body: %v %+v", err, body)
+			}
+			auth := r.Header.Get("Authorization")
+			g, ok := groups[auth+"|"+oid]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":"Request_ResourceNotFound","message":"no such user"}}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"value": g})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestResolveGroups(t *testing.T) {
+	var calls atomic.Int32
+	srv := newGraphServer(t, map[string][]string{
+		"Bearer tok-home|u1":    {"g1", "g2"},
+		"Bearer tok-partner|u1": {"p1"},
+	}, &calls)
+	defer srv.Close()
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	g, err := New(Config{
+		TenantID: "home", ClientID: "kamini", ClientSecret: "s3cret",
+		AuthorityURL: srv.URL, GraphURL: srv.URL + "/",
+		SecurityEnabledOnly: true, CacheTTL: time.Minute, Clock: clock,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	user := domain.Identity{Subject: "s1", Claims: map[string]any{"oid": "u1"}}
+
+	got, err := g.ResolveGroups(ctx, user)
+	if err != nil || !slices.Equal(got, []string{"g1", "g2"}) {
+		t.Fatalf("home: %v, %v", got, err)
+	}
+	got[0] = "mutated"
+	if got, _ := g.ResolveGroups(ctx, user); got[0] != "g1" || calls.Load() != 1 {
+		t.Fatalf("expected cached copy, got %v after %d calls", got, calls.Load())
+	}
+
+	// The token's tid selects the tenant for both the token and the cache key.
+	guest := domain.Identity{Claims: map[string]any{"oid": "u1", "tid": "partner"}}
+	if got, err := g.ResolveGroups(ctx, guest); err != nil || !slices.Equal(got, []string{"p1"}) {
+		t.Fatalf("partner: %v, %v", got, err)
+	}
+
+	clock.t = clock.t.Add(2 * time.Minute)
+	if _, err := g.ResolveGroups(ctx, user); err != nil || calls.Load() != 3 {
+		t.Fatalf("expected refetch after TTL: %v, calls=%d", err, calls.Load())
+	}
+
+	missing := domain.Identity{Claims: map[string]any{"oid": "nobody"}}
+	if _, err := g.ResolveGroups(ctx, missing); err == nil || !strings.Contains(err.Error(), "Request_ResourceNotFound") {
+		t.Fatalf("expected Graph error, got %v", err)
+	}
+	if _, err := g.ResolveGroups(ctx, domain.Identity{}); err == nil {
+		t.Fatalf("expected error without oid")
+	}
+}
+
+func TestResolveGroups_TokenFailure(t *testing.T) {
+	var calls atomic.Int32
+	srv := newGraphServer(t, nil, &calls)
+	defer srv.Close()
+	g, err := New(Config{ClientID: "kamini", ClientSecret: "wrong", AuthorityURL: srv.URL, GraphURL: srv.URL})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	id := domain.Identity{Claims: map[string]any{"oid": "u1", "tid": "home"}}
+	if _, err := g.ResolveGroups(context.Background(), id); err == nil || calls.Load() != 0 {
+		t.Fatalf("expected token error before calling Graph: %v", err)
+	}
+	if _, err := g.ResolveGroups(context.Background(), domain.Identity{Claims: map[string]any{"oid": "u1"}}); err == nil {
+		t.Fatalf("expected error without tenant")
+	}
+}
+
+func TestNew_RequiresCredentials(t *testing.T) {
+	if _, err := New(Config{ClientID: "kamini"}); err == nil {
+		t.Fatalf("expected error without secret")
+	}
+}
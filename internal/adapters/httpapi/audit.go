@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// auditEventJSON is a stored event: its ID plus the canonical audit record.
+type auditEventJSON struct {
+	ID uint64 `json:"id"`
+	auditjson.Record
+}
+
+type auditListResponse struct {
+	Events     []auditEventJSON `json:"events"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// handleAuditList serves GET /v1/audit (admin only). Results are newest first;
+// pass next_cursor back as ?cursor= for the following page.
+func (s *Server) handleAuditList(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditQuery(r.URL.Query())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	out, err := s.Audit.Execute(r.Context(), usecase.AuditQueryInput{
+		Bearer:   r.Header.Get("Authorization"),
+		Query:    q,
+		SourceIP: sourceIP(r),
+		TraceID:  traceID(r),
+	})
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	resp := auditListResponse{Events: make([]auditEventJSON, 0, len(out.Records))}
+	for _, rec := range out.Records {
+		resp.Events = append(resp.Events, auditEventJSON{ID: rec.ID, Record: auditjson.FromEvent(rec.AuditEvent)})
+	}
+	if out.Next != 0 {
+		resp.NextCursor = strconv.FormatUint(out.Next, 10)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseAuditQuery reads subject, principal, serial, action, outcome, since,
+// until, limit and cursor. Times are RFC 3339 or Unix seconds.
+func parseAuditQuery(v url.Values) (domain.AuditQuery, error) {
+	q := domain.AuditQuery{
+		Subject:   v.Get("subject"),
+		Principal: v.Get("principal"),
+		Action:    domain.AuditAction(strings.ToUpper(v.Get("action"))),
+		Outcome:   domain.AuditOutcome(strings.ToLower(v.Get("outcome"))),
+	}
+	var err error
+	if q.Serial, err = parseUintParam(v, "serial"); err != nil {
+		return q, err
+	}
+	if q.Before, err = parseUintParam(v, "cursor"); err != nil {
+		return q, err
+	}
+	limit, err := parseUintParam(v, "limit")
+	if err != nil {
+		return q, err
+	}
+	q.Limit = int(min(limit, usecase.MaxAuditPageSize))
+	if q.Since, err = parseTimeParam(v, "since"); err != nil {
+		return q, err
+	}
+	if q.Until, err = parseTimeParam(v, "until"); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+func parseUintParam(v url.Values, name string) (uint64, error) {
+	s := v.Get(name)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be a non-negative integer", domain.ErrInvalidAuditQuery, name)
+	}
+	return n, nil
+}
+
+func parseTimeParam(v url.Values, name string) (time.Time, error) {
+	s := v.Get(name)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be RFC 3339 or Unix seconds", domain.ErrInvalidAuditQuery, name)
+	}
+	return t, nil
+}
@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type blockRequest struct {
+	Kind   string `json:"kind"`   // key or subject
+	Value  string `json:"value"`  // SHA256 fingerprint or OIDC subject
+	Reason string `json:"reason"` // optional operator note
+}
+
+type blockEntryJSON struct {
+	Kind    string `json:"kind"`
+	Value   string `json:"value"`
+	Reason  string `json:"reason,omitempty"`
+	AddedBy string `json:"added_by,omitempty"`
+	AddedAt int64  `json:"added_at,omitempty"`
+}
+
+type blockListResponse struct {
+	Entries []blockEntryJSON `json:"entries"`
+}
+
+func toBlockJSON(e domain.BlockEntry) blockEntryJSON {
+	out := blockEntryJSON{Kind: string(e.Kind), Value: e.Value, Reason: e.Reason, AddedBy: e.AddedBy}
+	if !e.Time.IsZero() {
+		out.AddedAt = e.Time.Unix()
+	}
+	return out
+}
+
+// handleBlocklistList serves GET /v1/blocklist (admin only).
+func (s *Server) handleBlocklistList(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.Blocklist.List(r.Context(), s.blockInput(r, blockRequest{}, ""))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	resp := blockListResponse{Entries: make([]blockEntryJSON, 0, len(entries))}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, toBlockJSON(e))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleBlocklistAdd serves POST /v1/blocklist (admin only).
+func (s *Server) handleBlocklistAdd(w http.ResponseWriter, r *http.Request) {
+	var req blockRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+		writeErrorCode(w, r, http.StatusBadRequest, CodeBadRequest, "malformed json")
+		return
+	}
+	kind, err := domain.ParseBlockKind(req.Kind)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	entry, err := s.Blocklist.Add(r.Context(), s.blockInput(r, req, kind))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toBlockJSON(entry))
+}
+
+// handleBlocklistRemove serves DELETE /v1/blocklist?kind=&value= (admin only).
+func (s *Server) handleBlocklistRemove(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	kind, err := domain.ParseBlockKind(q.Get("kind"))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	req := blockRequest{Value: q.Get("value"), Reason: q.Get("reason")}
+	entry, err := s.Blocklist.Remove(r.Context(), s.blockInput(r, req, kind))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toBlockJSON(entry))
+}
+
+func (s *Server) blockInput(r *http.Request, req blockRequest, kind domain.BlockKind) usecase.BlocklistInput {
+	return usecase.BlocklistInput{
+		Bearer:   r.Header.Get("Authorization"),
+		Kind:     kind,
+		Value:    req.Value,
+		Reason:   req.Reason,
+		SourceIP: sourceIP(r),
+		TraceID:  traceID(r),
+	}
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type revokeRequest struct {
+	Kind   string `json:"kind"`   // serial (default), key_id, subject, key
+	Reason string `json:"reason"` // optional operator note
+}
+
+// directRevokeRequest names a subject or key to revoke without a certificate.
+type directRevokeRequest struct {
+	Kind    string `json:"kind"`    // subject or key
+	Subject string `json:"subject"` // kind subject
+	KeyFP   string `json:"key_fp"`  // kind key, "SHA256:..."
+	Reason  string `json:"reason"`
+}
+
+type revokeResponse struct {
+	Serial    uint64 `json:"serial,omitempty"` // absent for direct revocations
+	Kind      string `json:"kind"`
+	RevokedAt int64  `json:"revoked_at"`
+}
+
+// handleRevoke serves POST /v1/certs/{serial}/revoke (admin only).
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	serial, err := strconv.ParseUint(r.PathValue("serial"), 10, 64)
+	if err != nil || serial == 0 {
+		writeErrorCode(w, r, http.StatusBadRequest, CodeBadRequest, "invalid serial")
+		return
+	}
+	var req revokeRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorCode(w, r, http.StatusBadRequest, CodeBadRequest, "malformed json")
+		return
+	}
+	kind, err := domain.ParseRevocationKind(req.Kind)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	out, err := s.Revoke.Execute(r.Context(), usecase.RevokeCertInput{
+		Bearer:   r.Header.Get("Authorization"),
+		Serial:   serial,
+		Kind:     kind,
+		Reason:   req.Reason,
+		SourceIP: sourceIP(r),
+		TraceID:  traceID(r),
+	})
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, revokeResponse{
+		Serial:    serial,
+		Kind:      string(out.Revocation.Kind),
+		RevokedAt: out.Revocation.Time.Unix(),
+	})
+}
+
+// handleRevokeDirect serves POST /v1/revocations (admin only): revokes a
+// subject or public key without naming one of its certificates.
+func (s *Server) handleRevokeDirect(w http.ResponseWriter, r *http.Request) {
+	var req directRevokeRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+		writeErrorCode(w, r, http.StatusBadRequest, CodeBadRequest, "malformed json")
+		return
+	}
+	kind, err := domain.ParseRevocationKind(req.Kind)
+	if err != nil || (kind != domain.RevokeSubject && kind != domain.RevokeKey) {
+		s.writeError(w, r, fmt.Errorf("%w: kind must be subject or key", domain.ErrInvalidRevocation))
+		return
+	}
+	out, err := s.Revoke.Execute(r.Context(), usecase.RevokeCertInput{
+		Bearer:   r.Header.Get("Authorization"),
+		Kind:     kind,
+		Subject:  req.Subject,
+		KeyFP:    req.KeyFP,
+		Reason:   req.Reason,
+		SourceIP: sourceIP(r),
+		TraceID:  traceID(r),
+	})
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, revokeResponse{
+		Kind:      string(out.Revocation.Kind),
+		RevokedAt: out.Revocation.Time.Unix(),
+	})
+}
+
+// handleKRL serves GET /v1/krl: the binary OpenSSH KRL for sshd's RevokedKeys.
+func (s *Server) handleKRL(w http.ResponseWriter, r *http.Request) {
+	out, err := s.KRL.Execute(r.Context())
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-KRL-Version", strconv.FormatUint(out.Version, 10))
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(out.KRL)
+}
@@ -0,0 +1,91 @@
+package httpapi
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type dashboardResponse struct {
+	GeneratedAt      time.Time        `json:"generated_at"`
+	WindowSeconds    int64            `json:"window_seconds"`
+	Issued           []auditEventJSON `json:"issued"`
+	Denials          []denialJSON     `json:"denials"`
+	DenialsTruncated bool             `json:"denials_truncated,omitempty"`
+	Active           []certJSON       `json:"active"`
+	CAKeys           []caKeyJSON      `json:"ca_keys"`
+}
+
+type denialJSON struct {
+	Code  string    `json:"code"`
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+type certJSON struct {
+	Serial     uint64    `json:"serial"`
+	Subject    string    `json:"subject"`
+	Principals []string  `json:"principals"`
+	KeyID      string    `json:"key_id,omitempty"`
+	KeyFP      string    `json:"key_fp,omitempty"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+}
+
+type caKeyJSON struct {
+	Type        string `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// handleDashboard serves GET /v1/dashboard (admin only): the data behind the web UI.
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	out, err := s.Dashboard.Execute(r.Context(), usecase.DashboardInput{
+		Bearer:   r.Header.Get("Authorization"),
+		SourceIP: sourceIP(r),
+		TraceID:  traceID(r),
+	})
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	resp := dashboardResponse{
+		GeneratedAt:      out.Now,
+		WindowSeconds:    int64(out.Window / time.Second),
+		Issued:           make([]auditEventJSON, 0, len(out.Issued)),
+		Denials:          make([]denialJSON, 0, len(out.Denials)),
+		DenialsTruncated: out.DenialsTruncated,
+		Active:           make([]certJSON, 0, len(out.Active)),
+		CAKeys:           make([]caKeyJSON, 0, len(out.CAKeys)),
+	}
+	for _, rec := range out.Issued {
+		resp.Issued = append(resp.Issued, auditEventJSON{ID: rec.ID, Record: auditjson.FromEvent(rec.AuditEvent)})
+	}
+	for _, d := range out.Denials {
+		resp.Denials = append(resp.Denials, denialJSON{Code: d.Code, Count: d.Count, Last: d.Last.UTC()})
+	}
+	for _, c := range out.Active {
+		resp.Active = append(resp.Active, certJSON{
+			Serial:     c.Serial,
+			Subject:    c.Subject,
+			Principals: c.Principals,
+			KeyID:      c.KeyID,
+			KeyFP:      c.KeyFP,
+			NotBefore:  c.NotBefore.UTC(),
+			NotAfter:   c.NotAfter.UTC(),
+		})
+	}
+	for _, k := range out.CAKeys {
+		pub, err := ssh.NewPublicKey(k)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		resp.CAKeys = append(resp.CAKeys, caKeyJSON{Type: pub.Type(), Fingerprint: ssh.FingerprintSHA256(pub)})
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, resp)
+}
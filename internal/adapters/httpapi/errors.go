@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// CodeBadRequest is returned for malformed requests rejected before reaching a usecase.
+const CodeBadRequest domain.ErrorCode = "INPUT_BAD_REQUEST"
+
+type errorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+// writeError classifies err into a stable code and writes the JSON error envelope.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	code, msg := domain.ClassifyError(err)
+	status := statusFor(code)
+	if status >= http.StatusInternalServerError && s.Log != nil {
+		s.Log.Error(r.Context(), "request failed", "path", r.URL.Path, "trace_id", traceID(r), "error", err)
+	}
+	writeErrorCode(w, r, status, code, msg)
+}
+
+func writeErrorCode(w http.ResponseWriter, r *http.Request, status int, code domain.ErrorCode, msg string) {
+	writeJSON(w, status, errorEnvelope{Error: errorBody{
+		Code:      string(code),
+		Message:   msg,
+		Retryable: status >= http.StatusInternalServerError,
+		TraceID:   traceID(r),
+	}})
+}
+
+// statusFor maps error codes to HTTP statuses (see .github/instructions/errors.md).
+func statusFor(code domain.ErrorCode) int {
+	switch code {
+	case domain.CodeInvalidToken, domain.CodeIssuerMismatch, domain.CodeAudienceMismatch, domain.CodeTenantMismatch,
+		domain.CodePartyMismatch, domain.CodeMFARequired, domain.CodeACRInsufficient,
+		domain.CodeInvalidCertificate, domain.CodeSessionExpired:
+		return http.StatusUnauthorized
+	case domain.CodePolicyDenied, domain.CodeBlocklisted:
+		return http.StatusForbidden
+	case domain.CodeCertNotFound, domain.CodeBlockNotFound:
+		return http.StatusNotFound
+	case domain.CodeMissingPublicKey, domain.CodeInvalidPublicKey, domain.CodeNoPrincipals,
+		domain.CodeInvalidValidity, domain.CodeInvalidRevocation, domain.CodeInvalidBlockEntry,
+		domain.CodeInvalidAuditQuery, CodeBadRequest:
+		return http.StatusBadRequest
+	case domain.CodeAuditUnavailable, domain.CodeGroupsUnavailable, domain.CodeIssuerUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
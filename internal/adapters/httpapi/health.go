@@ -0,0 +1,28 @@
+package httpapi
+
+import "net/http"
+
+type healthResponse struct {
+	Status string            `json:"status"` // ok | unavailable
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// handleHealth serves GET /v1/healthz: 200 when every check passes, 503 otherwise.
+// Suitable as a readiness probe.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	resp := healthResponse{Status: "ok"}
+	status := http.StatusOK
+	for name, check := range s.Checks {
+		if resp.Checks == nil {
+			resp.Checks = map[string]string{}
+		}
+		if err := check(r.Context()); err != nil {
+			resp.Checks[name] = err.Error()
+			resp.Status, status = "unavailable", http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, resp)
+}
@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type renewRequest struct {
+	Certificate string `json:"certificate"` // current certificate, authorized-key form
+	PublicKey   string `json:"public_key"`  // key to certify
+	Timestamp   int64  `json:"timestamp"`   // challenge time, Unix seconds
+	Signature   string `json:"signature"`   // base64 SSH signature over the challenge
+	TTLSeconds  int64  `json:"ttl_seconds"`
+}
+
+type renewResponse struct {
+	CertificateAuthorizedKey string   `json:"certificate_authorized_key"`
+	Serial                   uint64   `json:"serial"`
+	NotBefore                int64    `json:"not_before"`
+	NotAfter                 int64    `json:"not_after"`
+	Principals               []string `json:"principals"`
+	RenewedFrom              uint64   `json:"renewed_from"`
+	AuthTime                 int64    `json:"auth_time"`
+	SessionExpiresAt         int64    `json:"session_expires_at"`
+}
+
+// handleRenew serves POST /v1/certs/user/renew: a new certificate for the
+// identity of a currently valid one, proven by a signed challenge (no bearer).
+func (s *Server) handleRenew(w http.ResponseWriter, r *http.Request) {
+	var req renewRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+		writeErrorCode(w, r, http.StatusBadRequest, CodeBadRequest, "malformed json")
+		return
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil || req.TTLSeconds < 0 {
+		writeErrorCode(w, r, http.StatusBadRequest, CodeBadRequest, "invalid signature or ttl_seconds")
+		return
+	}
+	out, err := s.Renew.Execute(r.Context(), usecase.RenewUserInput{
+		Certificate:         req.Certificate,
+		PublicKeyAuthorized: req.PublicKey,
+		Time:                time.Unix(req.Timestamp, 0),
+		Signature:           sig,
+		RequestedTTL:        time.Duration(req.TTLSeconds) * time.Second,
+		SourceIP:            sourceIP(r),
+		TraceID:             traceID(r),
+	})
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	cert, err := sshx.ParsePublicKey(out.Certificate)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, renewResponse{
+		CertificateAuthorizedKey: strings.TrimSpace(string(sshx.MarshalAuthorizedKey(cert))),
+		Serial:                   out.Serial,
+		NotBefore:                out.NotBefore.Unix(),
+		NotAfter:                 out.NotAfter.Unix(),
+		Principals:               out.Principals,
+		RenewedFrom:              out.RenewedFrom,
+		AuthTime:                 out.AuthTime.Unix(),
+		SessionExpiresAt:         out.SessionEnd.Unix(),
+	})
+}
@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// Server exposes Kamini usecases over HTTP. Routes are only registered for
+// the services that are wired, so partial deployments stay minimal.
+type Server struct {
+	Renew     *usecase.RenewUserService
+	Revoke    *usecase.RevokeCertService
+	KRL       *usecase.GetKRLService
+	Blocklist *usecase.BlocklistService
+	Audit     *usecase.AuditQueryService
+	Dashboard *usecase.DashboardService
+	// UI serves the admin web UI under /ui/ (see adapters/webui); it needs Dashboard.
+	UI http.Handler
+	// Metrics serves GET /metrics (see adapters/metrics).
+	Metrics http.Handler
+	// SAML serves the browser login under /v1/saml/ (auth.SAMLServiceProvider.Handler).
+	SAML http.Handler
+	// Trace wraps the router, e.g. tracing.Tracer.Middleware for OpenTelemetry spans.
+	Trace func(http.Handler) http.Handler
+	Log   usecase.Logger
+	// Checks back GET /v1/healthz, keyed by dependency name (e.g. "audit").
+	Checks map[string]Check
+}
+
+// Check reports whether a dependency can currently serve requests.
+type Check func(ctx context.Context) error
+
+func New(deps Server) *Server { return &deps }
+
+// Handler returns the routed handler wrapped with request-id (and, when set,
+// tracing) middleware.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/healthz", s.handleHealth)
+	if s.Metrics != nil {
+		mux.Handle("GET /metrics", s.Metrics)
+	}
+	if s.SAML != nil {
+		mux.Handle("/v1/saml/", s.SAML)
+	}
+	if s.Renew != nil {
+		mux.HandleFunc("POST /v1/certs/user/renew", s.handleRenew)
+	}
+	if s.Revoke != nil {
+		mux.HandleFunc("POST /v1/certs/{serial}/revoke", s.handleRevoke)
+		mux.HandleFunc("POST /v1/revocations", s.handleRevokeDirect)
+	}
+	if s.KRL != nil {
+		mux.HandleFunc("GET /v1/krl", s.handleKRL)
+	}
+	if s.Blocklist != nil {
+		mux.HandleFunc("GET /v1/blocklist", s.handleBlocklistList)
+		mux.HandleFunc("POST /v1/blocklist", s.handleBlocklistAdd)
+		mux.HandleFunc("DELETE /v1/blocklist", s.handleBlocklistRemove)
+	}
+	if s.Audit != nil {
+		mux.HandleFunc("GET /v1/audit", s.handleAuditList)
+	}
+	if s.Dashboard != nil {
+		mux.HandleFunc("GET /v1/dashboard", s.handleDashboard)
+		if s.UI != nil {
+			mux.Handle("GET /ui/", s.UI)
+			mux.Handle("GET /ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently))
+		}
+	}
+	if s.Trace != nil {
+		return withTraceID(s.Trace(mux))
+	}
+	return withTraceID(mux)
+}
+
+type traceKey struct{}
+
+// withTraceID propagates X-Request-ID (or generates one) into the request context
+// and echoes it on the response for correlation with audit events.
+func withTraceID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			var b [8]byte
+			_, _ = rand.Read(b[:])
+			id = hex.EncodeToString(b[:])
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), traceKey{}, id)))
+	})
+}
+
+// traceID returns the request's correlation id.
+func traceID(r *http.Request) string {
+	id, _ := r.Context().Value(traceKey{}).(string)
+	return id
+}
+
+// sourceIP returns the client address without port.
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
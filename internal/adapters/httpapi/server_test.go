@@ -0,0 +1,510 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/adapters/authorize"
+	"github.com/haukened/kamini/internal/adapters/metrics"
+	sshsigner "github.com/haukened/kamini/internal/adapters/signer/ssh"
+	"github.com/haukened/kamini/internal/adapters/storage/memory"
+	"github.com/haukened/kamini/internal/adapters/webui"
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+// tokenAuth maps bearer tokens to identities.
+type tokenAuth map[string]domain.Identity
+
+func (a tokenAuth) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	id, ok := a[strings.TrimPrefix(bearer, "Bearer ")]
+	if !ok {
+		return domain.Identity{}, errors.New("unknown token")
+	}
+	return id, nil
+}
+
+type captureSink struct{ events []domain.AuditEvent }
+
+func (c *captureSink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	c.events = append(c.events, ev)
+	return nil
+}
+
+type keySource struct{ k crypto.Signer }
+
+func (k keySource) Load(context.Context) (crypto.Signer, error) { return k.k, nil }
+
+type fixture struct {
+	handler http.Handler
+	certs   *memory.MemoryCertStore
+	block   *memory.MemoryBlocklist
+	audit   *captureSink
+	store   *memory.MemoryAuditStore
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	now := time.Unix(1_700_000_000, 0).UTC()
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs := memory.NewMemoryCertStore(ilog.NewNop())
+	_ = certs.Put(context.Background(), domain.CertRecord{
+		Serial: 7, KeyID: "7|sub|alice", Subject: "sub",
+		NotBefore: now.Add(-time.Minute), NotAfter: now.Add(time.Hour), KeyFP: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE",
+	})
+	block := memory.NewMemoryBlocklist(ilog.NewNop())
+	aud := &captureSink{}
+	store := memory.NewMemoryAuditStore(ilog.NewNop())
+	auth := tokenAuth{
+		"admin-token": {Subject: "admin", Groups: []string{"ssh-admins"}},
+		"user-token":  {Subject: "sub", Groups: []string{"ssh-users"}},
+	}
+	authz := authorize.NewOIDCAuthorizer(authorize.OIDCAuthorizerConfig{AdminGroups: []string{"ssh-admins"}})
+	clk := fixedClock{t: now}
+	srv := New(Server{
+		Revoke: usecase.NewRevokeCertService(usecase.RevokeCertService{
+			Log: ilog.NewNop(), Auth: auth, Admin: authz, Certs: certs, Block: block, Audit: aud, Clock: clk,
+		}),
+		Blocklist: usecase.NewBlocklistService(usecase.BlocklistService{
+			Log: ilog.NewNop(), Auth: auth, Admin: authz, Block: block, Audit: aud, Clock: clk,
+		}),
+		Audit: usecase.NewAuditQueryService(usecase.AuditQueryService{
+			Log: ilog.NewNop(), Auth: auth, Admin: authz, Store: store,
+		}),
+		Dashboard: usecase.NewDashboardService(usecase.DashboardService{
+			Log: ilog.NewNop(), Clock: clk, Auth: auth, Admin: authz, Audit: store, Certs: certs, Keys: []usecase.CAKeySource{keySource{caPriv}},
+		}),
+		UI:      webui.Handler(),
+		Metrics: metrics.New().Handler(),
+		KRL:     usecase.NewGetKRLService(certs, sshsigner.NewKRLGenerator(keySource{caPriv}, ilog.NewNop()), clk, ilog.NewNop()),
+		Log:     ilog.NewNop(),
+	})
+	return fixture{handler: srv.Handler(), certs: certs, block: block, audit: aud, store: store}
+}
+
+func do(h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("X-Request-ID", "trace-1")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+	var env errorEnvelope
+	if err := json.Unmarshal(rr.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode error envelope: %v (%s)", err, rr.Body.String())
+	}
+	return env.Error
+}
+
+func TestRevoke_Admin(t *testing.T) {
+	f := newFixture(t)
+	rr := do(f.handler, http.MethodPost, "/v1/certs/7/revoke", "admin-token", `{"kind":"key","reason":"laptop stolen"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	var resp revokeResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Serial != 7 || resp.Kind != "KEY" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	revs, _ := f.certs.Revocations(context.Background())
+	if len(revs) != 1 || revs[0].KeyFP != "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE" {
+		t.Fatalf("revocation not persisted: %+v", revs)
+	}
+	last := f.audit.events[len(f.audit.events)-1]
+	if last.Action != domain.ActionRevokeCert || last.TraceID != "trace-1" {
+		t.Fatalf("unexpected audit event: %+v", last)
+	}
+}
+
+func TestRevoke_Direct(t *testing.T) {
+	f := newFixture(t)
+	rr := do(f.handler, http.MethodPost, "/v1/revocations", "admin-token", `{"kind":"subject","subject":"mallory","reason":"left"}`)
+	if rr.Code != http.StatusOK || strings.Contains(rr.Body.String(), "serial") {
+		t.Fatalf("status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	revs, _ := f.certs.Revocations(context.Background())
+	if len(revs) != 1 || revs[0].Kind != domain.RevokeSubject || revs[0].Subject != "mallory" {
+		t.Fatalf("revocation not persisted: %+v", revs)
+	}
+	for _, body := range []string{`{"kind":"serial"}`, `{"kind":"subject"}`, `{"kind":"key","key_fp":"md5:x"}`} {
+		if rr := do(f.handler, http.MethodPost, "/v1/revocations", "admin-token", body); rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status=%d body=%s", body, rr.Code, rr.Body.String())
+		}
+	}
+	if rr := do(f.handler, http.MethodPost, "/v1/revocations", "user-token", `{"kind":"subject","subject":"x"}`); rr.Code != http.StatusForbidden {
+		t.Fatalf("non-admin: status=%d", rr.Code)
+	}
+}
+
+// A well-prefixed but malformed fingerprint must not reach the revocation
+// list, where it would break every KRL built afterwards.
+func TestRevoke_DirectMalformedKey(t *testing.T) {
+	f := newFixture(t)
+	rr := do(f.handler, http.MethodPost, "/v1/revocations", "admin-token", `{"kind":"key","key_fp":"SHA256:abc"}`)
+	if rr.Code != http.StatusBadRequest || decodeError(t, rr).Code != string(domain.CodeInvalidRevocation) {
+		t.Fatalf("status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if revs, _ := f.certs.Revocations(context.Background()); len(revs) != 0 {
+		t.Fatalf("malformed revocation persisted: %+v", revs)
+	}
+	if rr := do(f.handler, http.MethodPost, "/v1/certs/7/revoke", "admin-token", `{"kind":"key"}`); rr.Code != http.StatusOK {
+		t.Fatalf("revoke status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if rr := do(f.handler, http.MethodGet, "/v1/krl", "", ""); rr.Code != http.StatusOK || !bytes.HasPrefix(rr.Body.Bytes(), []byte("SSHKRL\n\x00")) {
+		t.Fatalf("krl status=%d body=%q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRevoke_Errors(t *testing.T) {
+	f := newFixture(t)
+	cases := []struct {
+		name, path, token, body string
+		status                  int
+		code                    domain.ErrorCode
+	}{
+		{"no token", "/v1/certs/7/revoke", "", "", http.StatusUnauthorized, domain.CodeInvalidToken},
+		{"bad token", "/v1/certs/7/revoke", "nope", "", http.StatusUnauthorized, domain.CodeInvalidToken},
+		{"not admin", "/v1/certs/7/revoke", "user-token", "", http.StatusForbidden, domain.CodePolicyDenied},
+		{"unknown serial", "/v1/certs/8/revoke", "admin-token", "", http.StatusNotFound, domain.CodeCertNotFound},
+		{"bad serial", "/v1/certs/abc/revoke", "admin-token", "", http.StatusBadRequest, CodeBadRequest},
+		{"bad kind", "/v1/certs/7/revoke", "admin-token", `{"kind":"host"}`, http.StatusBadRequest, domain.CodeInvalidRevocation},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := do(f.handler, http.MethodPost, tc.path, tc.token, tc.body)
+			if rr.Code != tc.status {
+				t.Fatalf("status=%d want=%d body=%s", rr.Code, tc.status, rr.Body.String())
+			}
+			if e := decodeError(t, rr); e.Code != string(tc.code) || e.TraceID != "trace-1" {
+				t.Fatalf("unexpected error body: %+v", e)
+			}
+		})
+	}
+}
+
+func TestKRL(t *testing.T) {
+	f := newFixture(t)
+	if rr := do(f.handler, http.MethodPost, "/v1/certs/7/revoke", "admin-token", ""); rr.Code != http.StatusOK {
+		t.Fatalf("revoke status=%d", rr.Code)
+	}
+	rr := do(f.handler, http.MethodGet, "/v1/krl", "", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Fatalf("content-type=%q", ct)
+	}
+	if !bytes.HasPrefix(rr.Body.Bytes(), []byte("SSHKRL\n\x00")) {
+		t.Fatalf("response is not a KRL")
+	}
+	if v := rr.Header().Get("X-KRL-Version"); v != "1" { // first revocation in a fresh store
+		t.Fatalf("X-KRL-Version=%q", v)
+	}
+}
+
+func TestBlocklist_AddListRemove(t *testing.T) {
+	f := newFixture(t)
+	rr := do(f.handler, http.MethodPost, "/v1/blocklist", "admin-token", `{"kind":"subject","value":"mallory","reason":"offboarded"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("add status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if err := f.block.Check(context.Background(), "mallory", ""); !errors.Is(err, domain.ErrBlocked) {
+		t.Fatalf("entry not persisted: %v", err)
+	}
+	if last := f.audit.events[len(f.audit.events)-1]; last.Action != domain.ActionBlocklistAdd {
+		t.Fatalf("unexpected audit action: %s", last.Action)
+	}
+
+	rr = do(f.handler, http.MethodGet, "/v1/blocklist", "admin-token", "")
+	var list blockListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("list status=%d err=%v", rr.Code, err)
+	}
+	if len(list.Entries) != 1 || list.Entries[0].Kind != "SUBJECT" || list.Entries[0].AddedBy != "admin" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	rr = do(f.handler, http.MethodDelete, "/v1/blocklist?kind=subject&value=mallory", "admin-token", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("remove status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	rr = do(f.handler, http.MethodDelete, "/v1/blocklist?kind=subject&value=mallory", "admin-token", "")
+	if rr.Code != http.StatusNotFound || decodeError(t, rr).Code != string(domain.CodeBlockNotFound) {
+		t.Fatalf("second remove status=%d body=%s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestBlocklist_Errors(t *testing.T) {
+	f := newFixture(t)
+	if rr := do(f.handler, http.MethodGet, "/v1/blocklist", "user-token", ""); rr.Code != http.StatusForbidden {
+		t.Fatalf("non-admin list status=%d", rr.Code)
+	}
+	rr := do(f.handler, http.MethodPost, "/v1/blocklist", "admin-token", `{"kind":"key","value":"not-a-fingerprint"}`)
+	if rr.Code != http.StatusBadRequest || decodeError(t, rr).Code != string(domain.CodeInvalidBlockEntry) {
+		t.Fatalf("invalid entry status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if rr := do(f.handler, http.MethodPost, "/v1/blocklist", "admin-token", `{`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("malformed status=%d", rr.Code)
+	}
+}
+
+func TestAudit_List(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0).UTC()
+	for i := uint64(1); i <= 3; i++ {
+		at := now.Add(time.Duration(i) * time.Minute)
+		_ = f.store.Write(ctx, domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "alice"}, []string{"alice", "ops"}, i, at, at.Add(time.Hour), domain.SignContext{Now: at}, nil))
+	}
+	_ = f.store.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, domain.Identity{Subject: "mallory"}, nil, domain.SignContext{Now: now}, domain.ErrPolicyDenied, nil))
+
+	rr := do(f.handler, http.MethodGet, "/v1/audit?principal=ops&outcome=success&limit=2", "admin-token", "")
+	var page auditListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("list status=%d err=%v body=%s", rr.Code, err, rr.Body.String())
+	}
+	if len(page.Events) != 2 || *page.Events[0].Serial != 3 || page.NextCursor == "" {
+		t.Fatalf("unexpected first page: %s", rr.Body.String())
+	}
+	rr = do(f.handler, http.MethodGet, "/v1/audit?principal=ops&outcome=success&limit=2&cursor="+page.NextCursor, "admin-token", "")
+	page = auditListResponse{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil || len(page.Events) != 1 || page.NextCursor != "" || *page.Events[0].Serial != 1 {
+		t.Fatalf("unexpected second page: %s", rr.Body.String())
+	}
+
+	since := now.Add(90 * time.Second).Format(time.RFC3339)
+	rr = do(f.handler, http.MethodGet, "/v1/audit?serial=2&since="+since, "admin-token", "")
+	if !strings.Contains(rr.Body.String(), `"serial":2`) || strings.Contains(rr.Body.String(), `"serial":1`) {
+		t.Fatalf("serial filter: %s", rr.Body.String())
+	}
+}
+
+func TestAudit_Errors(t *testing.T) {
+	f := newFixture(t)
+	if rr := do(f.handler, http.MethodGet, "/v1/audit", "user-token", ""); rr.Code != http.StatusForbidden {
+		t.Fatalf("non-admin status=%d", rr.Code)
+	}
+	for _, q := range []string{"serial=abc", "since=yesterday", "outcome=maybe", "since=200&until=100"} {
+		rr := do(f.handler, http.MethodGet, "/v1/audit?"+q, "admin-token", "")
+		if rr.Code != http.StatusBadRequest || decodeError(t, rr).Code != string(domain.CodeInvalidAuditQuery) {
+			t.Fatalf("%s: status=%d body=%s", q, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	var auditErr error
+	srv := New(Server{Checks: map[string]Check{
+		"audit": func(context.Context) error { return auditErr },
+	}})
+	rr := do(srv.Handler(), http.MethodGet, "/v1/healthz", "", "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"audit":"ok"`) {
+		t.Fatalf("healthy: status=%d body=%s", rr.Code, rr.Body.String())
+	}
+	auditErr = errors.New("audit: file failing: disk full")
+	rr = do(srv.Handler(), http.MethodGet, "/v1/healthz", "", "")
+	if rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), "disk full") {
+		t.Fatalf("unhealthy: status=%d body=%s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	f := newFixture(t)
+	now := time.Unix(1_700_000_000, 0).UTC()
+	deny := domain.PolicyDeny{Code: domain.DenyRoleMissing}
+	_ = f.store.Write(context.Background(), domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthz,
+		domain.Identity{Subject: "sub"}, nil, domain.SignContext{Now: now.Add(-time.Minute)}, deny, nil))
+
+	rr := do(f.handler, http.MethodGet, "/v1/dashboard", "admin-token", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("dashboard: %d %s", rr.Code, rr.Body)
+	}
+	var resp dashboardResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Active) != 1 || resp.Active[0].Serial != 7 {
+		t.Fatalf("unexpected active certs: %+v", resp.Active)
+	}
+	if len(resp.Denials) != 1 || resp.Denials[0].Code != "ROLE_MISSING" || resp.Denials[0].Count != 1 {
+		t.Fatalf("unexpected denials: %+v", resp.Denials)
+	}
+	if len(resp.CAKeys) != 1 || resp.CAKeys[0].Type != "ssh-ed25519" || !strings.HasPrefix(resp.CAKeys[0].Fingerprint, "SHA256:") {
+		t.Fatalf("unexpected CA keys: %+v", resp.CAKeys)
+	}
+	if resp.WindowSeconds != 86400 || resp.Issued == nil {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	if rr := do(f.handler, http.MethodGet, "/v1/dashboard", "user-token", ""); rr.Code != http.StatusForbidden {
+		t.Fatalf("non-admin: got %d", rr.Code)
+	}
+	if rr := do(f.handler, http.MethodGet, "/v1/dashboard", "", ""); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous: got %d", rr.Code)
+	}
+}
+
+func TestUI_Mounted(t *testing.T) {
+	f := newFixture(t)
+	rr := do(f.handler, http.MethodGet, "/ui/", "", "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "<title>Kamini</title>") {
+		t.Fatalf("index: %d %s", rr.Code, rr.Body)
+	}
+	if rr := do(f.handler, http.MethodGet, "/ui", "", ""); rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != "/ui/" {
+		t.Fatalf("redirect: %d %v", rr.Code, rr.Header())
+	}
+}
+
+func TestMetrics_Mounted(t *testing.T) {
+	f := newFixture(t)
+	rr := do(f.handler, http.MethodGet, "/metrics", "", "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "go_goroutines") {
+		t.Fatalf("metrics: %d %s", rr.Code, rr.Body)
+	}
+}
+
+func TestSAML_Mounted(t *testing.T) {
+	var got string
+	srv := New(Server{SAML: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r.URL.Path })})
+	do(srv.Handler(), http.MethodPost, "/v1/saml/acs", "", "")
+	if got != "/v1/saml/acs" {
+		t.Fatalf("saml handler saw %q", got)
+	}
+}
+
+func TestTrace_WrapsRouter(t *testing.T) {
+	var pattern string
+	srv := New(Server{Trace: func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			pattern = r.Pattern
+		})
+	}})
+	rr := do(srv.Handler(), http.MethodGet, "/v1/healthz", "", "")
+	if rr.Code != http.StatusOK || rr.Header().Get("X-Request-ID") == "" {
+		t.Fatalf("healthz: %d %v", rr.Code, rr.Header())
+	}
+	if pattern != "GET /v1/healthz" {
+		t.Fatalf("trace middleware should see the matched route, got %q", pattern)
+	}
+}
+
+func TestRenew(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	login := now.Add(-time.Hour)
+	_, caPriv, _ := ed25519.GenerateKey(rand.Reader)
+	_, oldPriv, _ := ed25519.GenerateKey(rand.Reader)
+	newPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	signer := sshsigner.NewOpenSSHSigner(keySource{caPriv}, ilog.NewNop())
+	oldKey, _ := sshx.NewSignerFromKey(oldPriv)
+	oldAuthorized := strings.TrimSpace(string(sshx.MarshalAuthorizedKey(oldKey.PublicKey())))
+	raw, _, err := signer.Sign(domain.CertSpec{
+		PublicKeyAuthorized: oldAuthorized, KeyID: "7|sub|alice", Principals: []string{"alice"},
+		ValidAfter: now.Add(-time.Minute), ValidBefore: now.Add(time.Hour),
+	}, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := sshx.ParsePublicKey(raw)
+	certs := memory.NewMemoryCertStore(ilog.NewNop())
+	rec := domain.CertRecord{Serial: 7, KeyID: "7|sub|alice", Principals: []string{"alice"},
+		NotBefore: now.Add(-time.Minute), NotAfter: now.Add(time.Hour), KeyFP: sshx.FingerprintSHA256(oldKey.PublicKey())}
+	rec.SetIdentity(domain.Identity{Subject: "sub", Username: "alice", Groups: []string{"ssh-users"}}, login)
+	_ = certs.Put(context.Background(), rec)
+	aud := &captureSink{}
+	svc := usecase.NewRenewUserService(usecase.RenewUserService{
+		Log: ilog.NewNop(), Verify: signer, Signer: signer, Certs: certs, Audit: aud, Clock: fixedClock{t: now},
+		Authz:      authorize.NewOIDCAuthorizer(authorize.OIDCAuthorizerConfig{AllowGroups: []string{"ssh-users"}, Principals: []string{"{username}"}, DefaultTTL: 4 * time.Hour, MaxTTL: 8 * time.Hour}),
+		Seq:        memory.NewMemorySerialStore(ilog.NewNop()),
+		TTL:        domain.TTL{Default: time.Hour, Max: 8 * time.Hour},
+		MaxSession: 3 * time.Hour,
+	})
+	h := New(Server{Renew: svc, Log: ilog.NewNop()}).Handler()
+
+	sshPub, _ := sshx.NewPublicKey(newPub)
+	pub := strings.TrimSpace(string(sshx.MarshalAuthorizedKey(sshPub)))
+	body := func(signWith sshx.Signer) string {
+		sig, err := signWith.Sign(rand.Reader, domain.RenewalChallenge{PublicKeyAuthorized: pub, Time: now}.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := json.Marshal(map[string]any{
+			"certificate": strings.TrimSpace(string(sshx.MarshalAuthorizedKey(cert))),
+			"public_key":  pub,
+			"timestamp":   now.Unix(),
+			"signature":   base64.StdEncoding.EncodeToString(sshx.Marshal(sig)),
+		})
+		return string(b)
+	}
+
+	rr := do(h, http.MethodPost, "/v1/certs/user/renew", "", body(oldKey))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("renew: %d %s", rr.Code, rr.Body)
+	}
+	var got renewResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	end := login.Add(3 * time.Hour)
+	if got.RenewedFrom != 7 || got.AuthTime != login.Unix() || got.SessionExpiresAt != end.Unix() || got.NotAfter != end.Unix() {
+		t.Fatalf("response=%+v", got)
+	}
+	parsed, _, _, _, err := sshx.ParseAuthorizedKey([]byte(got.CertificateAuthorizedKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := parsed.(*sshx.Certificate); c.Serial != got.Serial || !bytes.Equal(c.Key.Marshal(), sshPub.Marshal()) || c.ValidPrincipals[0] != "alice" {
+		t.Fatalf("certificate serial=%d principals=%v", c.Serial, c.ValidPrincipals)
+	}
+	if len(aud.events) != 1 || aud.events[0].Action != domain.ActionRenewUserCert || !aud.events[0].Success() {
+		t.Fatalf("audit=%+v", aud.events)
+	}
+
+	// A signature by any other key does not prove possession.
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	other, _ := sshx.NewSignerFromKey(otherPriv)
+	rr = do(h, http.MethodPost, "/v1/certs/user/renew", "", body(other))
+	if rr.Code != http.StatusUnauthorized || decodeError(t, rr).Code != string(domain.CodeInvalidCertificate) {
+		t.Fatalf("wrong key: %d %s", rr.Code, rr.Body)
+	}
+
+	// Past the maximum session only a fresh login helps.
+	svc.MaxSession = 30 * time.Minute
+	rr = do(h, http.MethodPost, "/v1/certs/user/renew", "", body(oldKey))
+	if rr.Code != http.StatusUnauthorized || decodeError(t, rr).Code != string(domain.CodeSessionExpired) {
+		t.Fatalf("session expired: %d %s", rr.Code, rr.Body)
+	}
+	if rr := do(h, http.MethodPost, "/v1/certs/user/renew", "", `{"signature":"%%"}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("bad signature encoding: %d", rr.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/audit/auditjson"
+	"github.com/haukened/kamini/internal/domain"
+)
+
+type auditEventJSON struct {
+	ID uint64 `json:"id"`
+	auditjson.Record
+}
+
+// AuditPage is one page of audit records, newest first. Next is the cursor for
+// the following page (pass it as AuditQuery.Before), or 0 on the last page.
+type AuditPage struct {
+	Records []domain.AuditRecord
+	Next    uint64
+}
+
+// ListAudit searches the audit log (admin only).
+func (c *Client) ListAudit(ctx context.Context, q domain.AuditQuery) (AuditPage, error) {
+	v := url.Values{}
+	set := func(k, val string) {
+		if val != "" {
+			v.Set(k, val)
+		}
+	}
+	set("subject", q.Subject)
+	set("principal", q.Principal)
+	set("action", string(q.Action))
+	set("outcome", string(q.Outcome))
+	if q.Serial != 0 {
+		v.Set("serial", strconv.FormatUint(q.Serial, 10))
+	}
+	if !q.Since.IsZero() {
+		v.Set("since", q.Since.UTC().Format(time.RFC3339))
+	}
+	if !q.Until.IsZero() {
+		v.Set("until", q.Until.UTC().Format(time.RFC3339))
+	}
+	if q.Before != 0 {
+		v.Set("cursor", strconv.FormatUint(q.Before, 10))
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	var resp struct {
+		Events     []auditEventJSON `json:"events"`
+		NextCursor string           `json:"next_cursor"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/audit", v, nil, &resp); err != nil {
+		return AuditPage{}, err
+	}
+	page := AuditPage{Records: make([]domain.AuditRecord, 0, len(resp.Events))}
+	for _, e := range resp.Events {
+		page.Records = append(page.Records, domain.AuditRecord{ID: e.ID, AuditEvent: e.Record.Event()})
+	}
+	if resp.NextCursor != "" {
+		n, err := strconv.ParseUint(resp.NextCursor, 10, 64)
+		if err != nil {
+			return AuditPage{}, err
+		}
+		page.Next = n
+	}
+	return page, nil
+}
@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+type blockEntryJSON struct {
+	Kind    string `json:"kind"`
+	Value   string `json:"value"`
+	Reason  string `json:"reason,omitempty"`
+	AddedBy string `json:"added_by,omitempty"`
+	AddedAt int64  `json:"added_at,omitempty"`
+}
+
+func (e blockEntryJSON) toDomain() domain.BlockEntry {
+	out := domain.BlockEntry{Kind: domain.BlockKind(e.Kind), Value: e.Value, Reason: e.Reason, AddedBy: e.AddedBy}
+	if e.AddedAt != 0 {
+		out.Time = time.Unix(e.AddedAt, 0).UTC()
+	}
+	return out
+}
+
+// ListBlocklist returns all blocklist entries (admin only).
+func (c *Client) ListBlocklist(ctx context.Context) ([]domain.BlockEntry, error) {
+	var resp struct {
+		Entries []blockEntryJSON `json:"entries"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/blocklist", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	out := make([]domain.BlockEntry, 0, len(resp.Entries))
+	for _, e := range resp.Entries {
+		out = append(out, e.toDomain())
+	}
+	return out, nil
+}
+
+// AddBlock blocks a key fingerprint or subject (admin only).
+func (c *Client) AddBlock(ctx context.Context, kind domain.BlockKind, value, reason string) (domain.BlockEntry, error) {
+	var resp blockEntryJSON
+	req := blockEntryJSON{Kind: string(kind), Value: value, Reason: reason}
+	if err := c.do(ctx, http.MethodPost, "/v1/blocklist", nil, req, &resp); err != nil {
+		return domain.BlockEntry{}, err
+	}
+	return resp.toDomain(), nil
+}
+
+// RemoveBlock lifts a block (admin only).
+func (c *Client) RemoveBlock(ctx context.Context, kind domain.BlockKind, value, reason string) (domain.BlockEntry, error) {
+	q := url.Values{"kind": {string(kind)}, "value": {value}}
+	if reason != "" {
+		q.Set("reason", reason)
+	}
+	var resp blockEntryJSON
+	if err := c.do(ctx, http.MethodDelete, "/v1/blocklist", q, nil, &resp); err != nil {
+		return domain.BlockEntry{}, err
+	}
+	return resp.toDomain(), nil
+}
@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func TestBlocklistRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("missing bearer: %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"entries":[{"kind":"SUBJECT","value":"mallory","added_by":"admin","added_at":1700000000}]}`))
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"kind":"SUBJECT","value":"mallory"}`))
+		case http.MethodDelete:
+			if r.URL.Query().Get("value") != "mallory" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"BLOCKLIST_ENTRY_NOT_FOUND","message":"not found","retryable":false,"trace_id":"t1"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"/", "tok")
+	ctx := context.Background()
+	list, err := c.ListBlocklist(ctx)
+	if err != nil || len(list) != 1 || list[0].Kind != domain.BlockSubject || list[0].Time.Unix() != 1_700_000_000 {
+		t.Fatalf("ListBlocklist = %+v, %v", list, err)
+	}
+	if _, err := c.AddBlock(ctx, domain.BlockSubject, "mallory", "offboarded"); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	_, err = c.RemoveBlock(ctx, domain.BlockSubject, "mallory", "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Code != "BLOCKLIST_ENTRY_NOT_FOUND" || apiErr.TraceID != "t1" {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+}
+
+func TestListAudit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v1/audit" || q.Get("serial") != "42" || q.Get("since") != "2025-01-02T03:04:05Z" || q.Get("cursor") != "9" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"events":[{"id":8,"time":"2025-01-02T03:04:05Z","action":"ISSUE_USER_CERT","stage":"SIGN","success":true,"subject":"alice","principals":["alice"],"serial":42}],"next_cursor":"8"}`))
+	}))
+	defer srv.Close()
+
+	since := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	page, err := New(srv.URL, "tok").ListAudit(context.Background(), domain.AuditQuery{Serial: 42, Since: since, Before: 9})
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(page.Records) != 1 || page.Next != 8 || page.Records[0].ID != 8 || *page.Records[0].Serial != 42 || !page.Records[0].Success() {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// RenewedCert is a certificate issued by renewal.
+type RenewedCert struct {
+	CertificateAuthorizedKey string
+	Serial                   uint64
+	NotBefore                time.Time
+	NotAfter                 time.Time
+	Principals               []string
+	RenewedFrom              uint64
+	AuthTime                 time.Time // original IdP login
+	SessionExpiresAt         time.Time // renew no later than this; then log in again
+}
+
+// RenewUserCert exchanges cert (authorized-key form) for a new certificate on
+// pub without a bearer token. sign must return the SSH wire-format signature
+// of msg by the certificate's private key.
+func (c *Client) RenewUserCert(ctx context.Context, cert, pub string, ttl time.Duration, sign func(msg []byte) ([]byte, error)) (RenewedCert, error) {
+	now := time.Now()
+	sig, err := sign(domain.RenewalChallenge{PublicKeyAuthorized: pub, Time: now}.Bytes())
+	if err != nil {
+		return RenewedCert{}, err
+	}
+	req := map[string]any{
+		"certificate": cert,
+		"public_key":  pub,
+		"timestamp":   now.Unix(),
+		"signature":   base64.StdEncoding.EncodeToString(sig),
+		"ttl_seconds": int64(ttl / time.Second),
+	}
+	var resp struct {
+		CertificateAuthorizedKey string   `json:"certificate_authorized_key"`
+		Serial                   uint64   `json:"serial"`
+		NotBefore                int64    `json:"not_before"`
+		NotAfter                 int64    `json:"not_after"`
+		Principals               []string `json:"principals"`
+		RenewedFrom              uint64   `json:"renewed_from"`
+		AuthTime                 int64    `json:"auth_time"`
+		SessionExpiresAt         int64    `json:"session_expires_at"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/certs/user/renew", nil, req, &resp); err != nil {
+		return RenewedCert{}, err
+	}
+	return RenewedCert{
+		CertificateAuthorizedKey: resp.CertificateAuthorizedKey,
+		Serial:                   resp.Serial,
+		NotBefore:                time.Unix(resp.NotBefore, 0).UTC(),
+		NotAfter:                 time.Unix(resp.NotAfter, 0).UTC(),
+		Principals:               resp.Principals,
+		RenewedFrom:              resp.RenewedFrom,
+		AuthTime:                 time.Unix(resp.AuthTime, 0).UTC(),
+		SessionExpiresAt:         time.Unix(resp.SessionExpiresAt, 0).UTC(),
+	}, nil
+}
@@ -0,0 +1,182 @@
+// Package metrics exports Prometheus metrics for issuance, denials, dependency
+// latency, CA key health and audit delivery. Usecases stay unaware of it: the
+// ports they depend on are wrapped (Authenticator, Signer, ...) and outcomes are
+// read from the audit events they already emit.
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/haukened/kamini/internal/adapters/audit/fanout"
+)
+
+const namespace = "kamini"
+
+// Dependency operations timed by the wrappers.
+const (
+	OpAuthenticate = "authenticate"
+	OpAuthorize    = "authorize"
+	OpSerial       = "serial"
+	OpSign         = "sign"
+)
+
+// Metrics owns a registry and the collectors registered in it. A nil *Metrics
+// stands for disabled metrics: the wrappers return their argument unchanged,
+// the fanout.Observer methods do nothing and Handler returns nil.
+type Metrics struct {
+	reg *prometheus.Registry
+	now func() time.Time
+
+	issued      *prometheus.CounterVec
+	failures    *prometheus.CounterVec
+	latency     *prometheus.HistogramVec
+	caKeyErrors prometheus.Counter
+
+	auditWrites  *prometheus.CounterVec
+	auditQueue   *prometheus.GaugeVec
+	auditDropped *prometheus.CounterVec
+
+	rotateOnce sync.Once
+	rotateAt   atomic.Int64 // Unix nanoseconds; 0 = not scheduled
+}
+
+var _ fanout.Observer = (*Metrics)(nil)
+
+// New creates the collectors in a fresh registry, together with the standard
+// Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		reg: prometheus.NewRegistry(),
+		now: time.Now,
+		issued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "certs_issued_total",
+			Help:      "User certificates issued, by authentication provider and number of principals.",
+		}, []string{"provider", "principals"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "issuance_failures_total",
+			Help:      "Refused or failed certificate requests, by audit stage, error code and policy deny code.",
+		}, []string{"stage", "error_code", "deny_code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "dependency_duration_seconds",
+			Help:      "Latency of issuance dependencies (authenticate, authorize, serial, sign).",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"op", "result"}),
+		caKeyErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ca_key_load_errors_total",
+			Help:      "Failures loading CA private key material.",
+		}),
+		auditWrites: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "audit_writes_total",
+			Help:      "Audit write attempts per sink, including retries.",
+		}, []string{"sink", "result"}),
+		auditQueue: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "audit_queue_depth",
+			Help:      "Audit events waiting for retry per sink.",
+		}, []string{"sink"}),
+		auditDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "audit_dropped_total",
+			Help:      "Audit events dropped because a sink's retry queue was full.",
+		}, []string{"sink"}),
+	}
+	m.reg.MustRegister(
+		m.issued, m.failures, m.latency, m.caKeyErrors,
+		m.auditWrites, m.auditQueue, m.auditDropped,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return nil
+	}
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+// Registry exposes the registry, e.g. to add collectors owned by other adapters.
+func (m *Metrics) Registry() *prometheus.Registry { return m.reg }
+
+// SetCAKeyRotation records when the active CA key is scheduled to be replaced.
+// From the first call on, kamini_ca_key_rotation_seconds reports the time left
+// (negative once overdue). A zero t clears the schedule.
+func (m *Metrics) SetCAKeyRotation(t time.Time) {
+	if t.IsZero() {
+		m.rotateAt.Store(0)
+	} else {
+		m.rotateAt.Store(t.UnixNano())
+	}
+	m.rotateOnce.Do(func() {
+		m.reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "ca_key_rotation_seconds",
+			Help:      "Seconds until the active CA key is scheduled to rotate; NaN when no rotation is scheduled.",
+		}, func() float64 {
+			at := m.rotateAt.Load()
+			if at == 0 {
+				return math.NaN()
+			}
+			return time.Unix(0, at).Sub(m.now()).Seconds()
+		}))
+	})
+}
+
+// AuditWrite implements fanout.Observer.
+func (m *Metrics) AuditWrite(sink string, err error) {
+	if m == nil {
+		return
+	}
+	m.auditWrites.WithLabelValues(sink, result(err)).Inc()
+}
+
+// AuditQueue implements fanout.Observer.
+func (m *Metrics) AuditQueue(sink string, depth int) {
+	if m == nil {
+		return
+	}
+	m.auditQueue.WithLabelValues(sink).Set(float64(depth))
+}
+
+// AuditDropped implements fanout.Observer.
+func (m *Metrics) AuditDropped(sink string) {
+	if m == nil {
+		return
+	}
+	m.auditDropped.WithLabelValues(sink).Inc()
+}
+
+func (m *Metrics) observe(op string, start time.Time, err error) {
+	m.latency.WithLabelValues(op, result(err)).Observe(m.now().Sub(start).Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// principalBucket keeps the principals label bounded.
+func principalBucket(n int) string {
+	if n >= 5 {
+		return "5+"
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+type okSink struct{ err error }
+
+func (s okSink) Write(context.Context, domain.AuditEvent) error { return s.err }
+
+type stubAuth struct{ err error }
+
+func (a stubAuth) Authenticate(context.Context, string) (domain.Identity, error) {
+	return domain.Identity{Subject: "s"}, a.err
+}
+
+type stubKeys struct{ err error }
+
+func (k stubKeys) Load(context.Context) (crypto.Signer, error) { return nil, k.err }
+
+func issued(principals ...string) domain.AuditEvent {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	return domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "s"}, principals, 1, now, now.Add(time.Hour),
+		domain.SignContext{Now: now}, map[string]string{"auth_provider": "oidc"})
+}
+
+func TestAuditSink_CountsOutcomes(t *testing.T) {
+	m := New()
+	ctx := context.Background()
+	s := m.AuditSink(okSink{})
+	_ = s.Write(ctx, issued("alice"))
+	_ = s.Write(ctx, issued("alice", "root", "ops", "db", "web", "ci"))
+	_ = s.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthz, domain.Identity{}, nil,
+		domain.SignContext{}, domain.PolicyDeny{Code: domain.DenyRoleMissing}, nil))
+	_ = s.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthn, domain.Identity{}, nil,
+		domain.SignContext{}, domain.ErrInvalidToken, nil))
+	_ = s.Write(ctx, domain.NewAuditFailure(domain.ActionRevokeCert, domain.StageRevoke, domain.Identity{}, nil,
+		domain.SignContext{}, domain.ErrCertNotFound, nil))
+	if err := m.AuditSink(okSink{err: errors.New("down")}).Write(ctx, issued("alice")); err == nil {
+		t.Fatalf("sink error not returned")
+	}
+
+	if v := testutil.ToFloat64(m.issued.WithLabelValues("oidc", "1")); v != 1 {
+		t.Fatalf("issued 1 principal = %v", v)
+	}
+	if v := testutil.ToFloat64(m.issued.WithLabelValues("oidc", "5+")); v != 1 {
+		t.Fatalf("issued 5+ principals = %v", v)
+	}
+	if v := testutil.ToFloat64(m.failures.WithLabelValues("AUTHZ", "POLICY_DENIED", "ROLE_MISSING")); v != 1 {
+		t.Fatalf("policy denials = %v", v)
+	}
+	if v := testutil.ToFloat64(m.failures.WithLabelValues("AUTHN", "AUTH_INVALID_TOKEN", "")); v != 1 {
+		t.Fatalf("authn failures = %v", v)
+	}
+	if v := testutil.ToFloat64(m.failures.WithLabelValues("SIGN", "AUDIT_UNAVAILABLE", "")); v != 1 {
+		t.Fatalf("unaudited issuances = %v", v)
+	}
+	if n := testutil.CollectAndCount(m.failures); n != 3 {
+		t.Fatalf("revocation failures must not be counted; series = %d", n)
+	}
+}
+
+func TestWrappers(t *testing.T) {
+	m := New()
+	ctx := context.Background()
+	_, _ = m.Authenticator(stubAuth{}).Authenticate(ctx, "t")
+	_, _ = m.Authenticator(stubAuth{err: errors.New("bad")}).Authenticate(ctx, "t")
+	if n := testutil.CollectAndCount(m.latency); n != 2 {
+		t.Fatalf("latency series = %d, want ok and error", n)
+	}
+	_, _ = m.CAKeySource(stubKeys{err: errors.New("perm")}).Load(ctx)
+	_, _ = m.CAKeySource(stubKeys{}).Load(ctx)
+	if v := testutil.ToFloat64(m.caKeyErrors); v != 1 {
+		t.Fatalf("CA key errors = %v", v)
+	}
+}
+
+func TestCAKeyRotation(t *testing.T) {
+	m := New()
+	now := time.Unix(1_700_000_000, 0)
+	m.now = func() time.Time { return now }
+	m.SetCAKeyRotation(now.Add(90 * time.Second))
+	m.SetCAKeyRotation(now.Add(time.Hour)) // re-scheduling must not re-register
+	body := scrape(t, m)
+	if !strings.Contains(body, "kamini_ca_key_rotation_seconds 3600") {
+		t.Fatalf("rotation gauge missing:\n%s", body)
+	}
+	m.SetCAKeyRotation(time.Time{})
+	if g := gauge(t, m, "kamini_ca_key_rotation_seconds"); !math.IsNaN(g) {
+		t.Fatalf("cleared schedule = %v, want NaN", g)
+	}
+}
+
+func TestHandler_Exposition(t *testing.T) {
+	m := New()
+	m.AuditWrite("file", nil)
+	m.AuditQueue("webhook", 3)
+	m.AuditDropped("webhook")
+	body := scrape(t, m)
+	for _, want := range []string{
+		`kamini_audit_writes_total{result="ok",sink="file"} 1`,
+		`kamini_audit_queue_depth{sink="webhook"} 3`,
+		`kamini_audit_dropped_total{sink="webhook"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+
+func gauge(t *testing.T, m *Metrics, name string) float64 {
+	t.Helper()
+	mfs, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() == name {
+			return mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("%s not found", name)
+	return 0
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"crypto"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// Authenticator times a usecase.Authenticator.
+func (m *Metrics) Authenticator(next usecase.Authenticator) usecase.Authenticator {
+	if m == nil {
+		return next
+	}
+	return authenticator{m, next}
+}
+
+type authenticator struct {
+	m    *Metrics
+	next usecase.Authenticator
+}
+
+func (a authenticator) Authenticate(ctx context.Context, bearer string) (domain.Identity, error) {
+	start := a.m.now()
+	id, err := a.next.Authenticate(ctx, bearer)
+	a.m.observe(OpAuthenticate, start, err)
+	return id, err
+}
+
+// Authorizer times a usecase.Authorizer. Policy denials are recorded with
+// result "error"; the failures counter tells them apart by deny code.
+func (m *Metrics) Authorizer(next usecase.Authorizer) usecase.Authorizer {
+	if m == nil {
+		return next
+	}
+	return authorizer{m, next}
+}
+
+type authorizer struct {
+	m    *Metrics
+	next usecase.Authorizer
+}
+
+func (a authorizer) Decide(id domain.Identity, ctx domain.SignContext) (domain.PolicyDecision, error) {
+	start := a.m.now()
+	dec, err := a.next.Decide(id, ctx)
+	a.m.observe(OpAuthorize, start, err)
+	return dec, err
+}
+
+// SerialStore times a usecase.SerialStore.
+func (m *Metrics) SerialStore(next usecase.SerialStore) usecase.SerialStore {
+	if m == nil {
+		return next
+	}
+	return serialStore{m, next}
+}
+
+type serialStore struct {
+	m    *Metrics
+	next usecase.SerialStore
+}
+
+func (s serialStore) Next(ctx context.Context) (uint64, error) {
+	start := s.m.now()
+	n, err := s.next.Next(ctx)
+	s.m.observe(OpSerial, start, err)
+	return n, err
+}
+
+// Signer times a usecase.Signer.
+func (m *Metrics) Signer(next usecase.Signer) usecase.Signer {
+	if m == nil {
+		return next
+	}
+	return signer{m, next}
+}
+
+type signer struct {
+	m    *Metrics
+	next usecase.Signer
+}
+
+func (s signer) Sign(spec domain.CertSpec, serial uint64) ([]byte, string, error) {
+	start := s.m.now()
+	cert, fp, err := s.next.Sign(spec, serial)
+	s.m.observe(OpSign, start, err)
+	return cert, fp, err
+}
+
+// CAKeySource counts load failures of a usecase.CAKeySource.
+func (m *Metrics) CAKeySource(next usecase.CAKeySource) usecase.CAKeySource {
+	if m == nil {
+		return next
+	}
+	return caKeySource{m, next}
+}
+
+type caKeySource struct {
+	m    *Metrics
+	next usecase.CAKeySource
+}
+
+func (k caKeySource) Load(ctx context.Context) (crypto.Signer, error) {
+	s, err := k.next.Load(ctx)
+	if err != nil {
+		k.m.caKeyErrors.Inc()
+	}
+	return s, err
+}
+
+// AuditSink counts issuances (renewals included) and failures from the events
+// passing through to next. An issuance next refuses is counted as an AUDIT_UNAVAILABLE failure,
+// since under the fail-closed policy that certificate is not handed out. The
+// wrapper only has Write; keep next for Close and Check.
+func (m *Metrics) AuditSink(next usecase.AuditSink) usecase.AuditSink {
+	if m == nil {
+		return next
+	}
+	return auditSink{m, next}
+}
+
+type auditSink struct {
+	m    *Metrics
+	next usecase.AuditSink
+}
+
+func (a auditSink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	err := a.next.Write(ctx, ev)
+	if ev.Action != domain.ActionIssueUserCert && ev.Action != domain.ActionRenewUserCert {
+		return err
+	}
+	switch {
+	case !ev.Success():
+		a.m.failures.WithLabelValues(string(ev.Stage), string(ev.ErrorCode), string(ev.DenyCode())).Inc()
+	case err != nil:
+		a.m.failures.WithLabelValues(string(ev.Stage), string(domain.CodeAuditUnavailable), "").Inc()
+	default:
+		a.m.issued.WithLabelValues(ev.Attrs["auth_provider"], principalBucket(len(ev.Principals))).Inc()
+	}
+	return err
+}
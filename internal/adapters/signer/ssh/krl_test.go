@@ -0,0 +1,122 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func TestKRLGenerator_Header(t *testing.T) {
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewKRLGenerator(fakeKeySource{key: caPriv}, nopLogger{})
+	krl, err := g.Generate(context.Background(), domain.KRLSpec{Version: 7, Generated: time.Unix(1_700_000_000, 0), Comment: "kamini", Serials: []uint64{1, 2}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !bytes.HasPrefix(krl, []byte("SSHKRL\n\x00")) {
+		t.Fatalf("missing KRL magic: %q", krl[:8])
+	}
+	if v := binary.BigEndian.Uint32(krl[8:12]); v != krlFormatVersion {
+		t.Fatalf("format version=%d", v)
+	}
+	if v := binary.BigEndian.Uint64(krl[12:20]); v != 7 {
+		t.Fatalf("krl version=%d", v)
+	}
+
+	_, err = g.Generate(context.Background(), domain.KRLSpec{KeyFPs: []string{"SHA256:short"}})
+	if err == nil {
+		t.Fatalf("expected error for malformed fingerprint")
+	}
+}
+
+// TestKRLGenerator_SSHKeygen checks the KRL with OpenSSH itself when ssh-keygen is available.
+func TestKRLGenerator_SSHKeygen(t *testing.T) {
+	keygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newUser := func() sshx.PublicKey {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := sshx.NewPublicKey(priv.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pub
+	}
+	alice, bob := newUser(), newUser()
+
+	signer := NewOpenSSHSigner(fakeKeySource{key: caPriv}, nopLogger{})
+	dir := t.TempDir()
+	now := time.Now()
+	issue := func(pub sshx.PublicKey, keyID string, serial uint64) string {
+		raw, _, err := signer.Sign(domain.CertSpec{
+			PublicKeyAuthorized: string(sshx.MarshalAuthorizedKey(pub)),
+			KeyID:               keyID,
+			Principals:          []string{"u"},
+			ValidAfter:          now.Add(-time.Minute),
+			ValidBefore:         now.Add(time.Hour),
+		}, serial)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		cert, err := sshx.ParsePublicKey(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(dir, keyID+"-cert.pub")
+		if err := os.WriteFile(p, sshx.MarshalAuthorizedKey(cert), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	bySerial := issue(alice, "k1", 5)
+	byKeyID := issue(alice, "k2", 6)
+	byKey := issue(bob, "k3", 7)
+	untouched := issue(alice, "k4", 8)
+
+	g := NewKRLGenerator(fakeKeySource{key: caPriv}, nopLogger{})
+	krl, err := g.Generate(context.Background(), domain.KRLSpec{
+		Version:   1,
+		Generated: now,
+		Comment:   "kamini",
+		Serials:   []uint64{5},
+		KeyIDs:    []string{"k2"},
+		KeyFPs:    []string{sshx.FingerprintSHA256(bob)},
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	krlPath := filepath.Join(dir, "krl")
+	if err := os.WriteFile(krlPath, krl, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, revoked := range map[string]bool{bySerial: true, byKeyID: true, byKey: true, untouched: false} {
+		out, _ := exec.Command(keygen, "-Q", "-f", krlPath, path).CombinedOutput()
+		if got := strings.Contains(string(out), "REVOKED"); got != revoked {
+			t.Fatalf("%s: revoked=%v, want %v (output: %s)", filepath.Base(path), got, revoked, out)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+var _ usecase.CertVerifier = (*OpenSSHSigner)(nil)
+
+// VerifyPossession checks that cert is a user certificate signed by this CA and
+// valid at now, and that sig (SSH wire format) is its key's signature over msg.
+// Critical options are not enforced here: renewal re-evaluates policy.
+func (s *OpenSSHSigner) VerifyPossession(ctx context.Context, cert string, msg, sig []byte, now time.Time) (uint64, string, error) {
+	pub, _, _, _, err := sshx.ParseAuthorizedKey([]byte(cert))
+	if err != nil {
+		return 0, "", fmt.Errorf("%w: %w", domain.ErrInvalidCertificate, err)
+	}
+	c, ok := pub.(*sshx.Certificate)
+	if !ok || c.CertType != sshx.UserCert {
+		return 0, "", fmt.Errorf("%w: not a user certificate", domain.ErrInvalidCertificate)
+	}
+
+	priv, err := s.keys.Load(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	if priv == nil {
+		return 0, "", errors.New("keystore returned nil signer")
+	}
+	ca, err := sshx.NewPublicKey(priv.Public())
+	if err != nil {
+		return 0, "", err
+	}
+	if !bytes.Equal(c.SignatureKey.Marshal(), ca.Marshal()) {
+		return 0, "", fmt.Errorf("%w: not signed by this CA", domain.ErrInvalidCertificate)
+	}
+	// CheckCert verifies the CA signature, validity window and principal.
+	checker := sshx.CertChecker{
+		SupportedCriticalOptions: slices.Collect(maps.Keys(c.CriticalOptions)),
+		Clock:                    func() time.Time { return now },
+	}
+	var principal string
+	if len(c.ValidPrincipals) > 0 {
+		principal = c.ValidPrincipals[0]
+	}
+	if err := checker.CheckCert(principal, c); err != nil {
+		return 0, "", fmt.Errorf("%w: %w", domain.ErrInvalidCertificate, err)
+	}
+
+	var signature sshx.Signature
+	if err := sshx.Unmarshal(sig, &signature); err != nil {
+		return 0, "", fmt.Errorf("%w: malformed signature", domain.ErrInvalidCertificate)
+	}
+	if err := c.Key.Verify(msg, &signature); err != nil {
+		return 0, "", fmt.Errorf("%w: proof of possession: %w", domain.ErrInvalidCertificate, err)
+	}
+	return c.Serial, sshx.FingerprintSHA256(c.Key), nil
+}
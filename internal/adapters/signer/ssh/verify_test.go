@@ -0,0 +1,78 @@
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	sshx "golang.org/x/crypto/ssh"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func TestOpenSSHSigner_VerifyPossession(t *testing.T) {
+	_, caPriv, _ := ed25519.GenerateKey(rand.Reader)
+	_, userPriv, _ := ed25519.GenerateKey(rand.Reader)
+	userSigner, err := sshx.NewSignerFromSigner(userPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewOpenSSHSigner(fakeKeySource{key: caPriv}, nopLogger{})
+	now := time.Now()
+	issue := func(s *OpenSSHSigner, serial uint64) string {
+		raw, _, err := s.Sign(domain.CertSpec{
+			PublicKeyAuthorized: string(sshx.MarshalAuthorizedKey(userSigner.PublicKey())),
+			KeyID:               "kid",
+			Principals:          []string{"alice"},
+			ValidAfter:          now.Add(-time.Minute),
+			ValidBefore:         now.Add(time.Hour),
+			CriticalOptions:     map[string]string{"source-address": "10.0.0.0/8"},
+		}, serial)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		pk, _ := sshx.ParsePublicKey(raw)
+		return string(sshx.MarshalAuthorizedKey(pk))
+	}
+	cert := issue(s, 7)
+	msg := []byte("challenge")
+	sig, err := userSigner.Sign(rand.Reader, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	serial, fp, err := s.VerifyPossession(ctx, cert, msg, sshx.Marshal(sig), now)
+	if err != nil {
+		t.Fatalf("VerifyPossession: %v", err)
+	}
+	if serial != 7 || fp != sshx.FingerprintSHA256(userSigner.PublicKey()) {
+		t.Fatalf("serial=%d fp=%s", serial, fp)
+	}
+
+	_, otherCA, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherUser, _ := ed25519.GenerateKey(rand.Reader)
+	otherSigner, _ := sshx.NewSignerFromSigner(otherUser)
+	otherSig, _ := otherSigner.Sign(rand.Reader, msg)
+	for name, tc := range map[string]struct {
+		cert string
+		msg  []byte
+		sig  []byte
+		now  time.Time
+	}{
+		"expired":       {cert, msg, sshx.Marshal(sig), now.Add(2 * time.Hour)},
+		"other CA":      {issue(NewOpenSSHSigner(fakeKeySource{key: otherCA}, nopLogger{}), 7), msg, sshx.Marshal(sig), now},
+		"plain key":     {string(sshx.MarshalAuthorizedKey(userSigner.PublicKey())), msg, sshx.Marshal(sig), now},
+		"wrong message": {cert, []byte("other"), sshx.Marshal(sig), now},
+		"wrong key":     {cert, msg, sshx.Marshal(otherSig), now},
+		"garbage sig":   {cert, msg, []byte("sig"), now},
+		"garbage cert":  {"nope", msg, sshx.Marshal(sig), now},
+	} {
+		if _, _, err := s.VerifyPossession(ctx, tc.cert, tc.msg, tc.sig, tc.now); !errors.Is(err, domain.ErrInvalidCertificate) {
+			t.Fatalf("%s: expected ErrInvalidCertificate, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package file
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// acquireLock creates lockPath exclusively (O_CREATE|O_EXCL) to coordinate writers
+// across processes. The returned func removes the lock file. what names the
+// protected resource in error messages.
+func acquireLock(lockPath, what string) (func() error, error) {
+	lf, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("%s locked: %s", what, lockPath)
+		}
+		return nil, fmt.Errorf("create lock: %w", err)
+	}
+	// best effort info
+	_, _ = io.WriteString(lf, fmt.Sprintf("pid=%d\n", os.Getpid()))
+	_ = lf.Close()
+	return func() error { return os.Remove(lockPath) }, nil
+}
+
+// writeFileAtomic writes data to a temp file next to path, fsyncs it, and renames it
+// over path so readers never observe partial writes.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	// Create a secure temp file in the same directory to allow atomic rename.
+	fd, err := os.CreateTemp(dir, base+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("open tmp: %w", err)
+	}
+	tmp := fd.Name()
+	// Ensure permissions are strict (in case of umask differences).
+	_ = os.Chmod(tmp, 0o600)
+	if _, err := fd.Write(data); err != nil {
+		_ = fd.Close()
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := fd.Sync(); err != nil {
+		_ = fd.Close()
+		return fmt.Errorf("fsync: %w", err)
+	}
+	if err := fd.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	// optional: fsync dir for stronger guarantees
+	if dfd, err := os.Open(dir); err == nil {
+		_ = dfd.Sync()
+		_ = dfd.Close()
+	}
+	return nil
+}
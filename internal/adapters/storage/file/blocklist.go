@@ -0,0 +1,116 @@
+package file
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// FileBlocklist persists blocklist entries to a JSON document using the same
+// locking and atomic-write scheme as FileCertStore.
+type FileBlocklist struct {
+	doc *jsonDoc[blockDoc]
+}
+
+var _ usecase.Blocklist = (*FileBlocklist)(nil)
+
+// NewFileBlocklist creates a file-backed blocklist at the given file path.
+func NewFileBlocklist(path string, l usecase.Logger) (*FileBlocklist, error) {
+	doc, err := newJSONDoc[blockDoc](path, "blocklist", l)
+	if err != nil {
+		return nil, err
+	}
+	return &FileBlocklist{doc: doc}, nil
+}
+
+// blockDoc is the on-disk layout. Field names are part of the file format.
+type blockDoc struct {
+	Entries []blockJSON `json:"entries"`
+}
+
+type blockJSON struct {
+	Kind    string    `json:"kind"`
+	Value   string    `json:"value"`
+	Reason  string    `json:"reason,omitempty"`
+	AddedBy string    `json:"added_by,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Check returns a domain.BlockedError when subject or keyFP is blocked.
+func (f *FileBlocklist) Check(ctx context.Context, subject, keyFP string) error {
+	doc, err := f.doc.view()
+	if err != nil {
+		return err
+	}
+	for _, e := range doc.Entries {
+		kind := domain.BlockKind(e.Kind)
+		if (kind == domain.BlockSubject && subject != "" && e.Value == subject) ||
+			(kind == domain.BlockKey && keyFP != "" && e.Value == keyFP) {
+			return domain.BlockedError{Kind: kind}
+		}
+	}
+	return nil
+}
+
+// Add inserts or replaces the entry for (Kind, Value).
+func (f *FileBlocklist) Add(ctx context.Context, e domain.BlockEntry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	return f.doc.update(ctx, func(doc *blockDoc) error {
+		j := blockJSON{Kind: string(e.Kind), Value: e.Value, Reason: e.Reason, AddedBy: e.AddedBy, Time: e.Time.UTC()}
+		if i := doc.index(e.Kind, e.Value); i >= 0 {
+			doc.Entries[i] = j
+		} else {
+			doc.Entries = append(doc.Entries, j)
+		}
+		return nil
+	})
+}
+
+// Remove deletes and returns the entry, or returns domain.ErrBlockNotFound.
+func (f *FileBlocklist) Remove(ctx context.Context, kind domain.BlockKind, value string) (domain.BlockEntry, error) {
+	var removed domain.BlockEntry
+	err := f.doc.update(ctx, func(doc *blockDoc) error {
+		i := doc.index(kind, value)
+		if i < 0 {
+			return domain.ErrBlockNotFound
+		}
+		removed = doc.Entries[i].toDomain()
+		doc.Entries = slices.Delete(doc.Entries, i, i+1)
+		return nil
+	})
+	return removed, err
+}
+
+// List returns all entries in insertion order.
+func (f *FileBlocklist) List(ctx context.Context) ([]domain.BlockEntry, error) {
+	doc, err := f.doc.view()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.BlockEntry, 0, len(doc.Entries))
+	for _, e := range doc.Entries {
+		out = append(out, e.toDomain())
+	}
+	return out, nil
+}
+
+func (d blockDoc) index(kind domain.BlockKind, value string) int {
+	return slices.IndexFunc(d.Entries, func(e blockJSON) bool {
+		return e.Kind == string(kind) && e.Value == value
+	})
+}
+
+func (e blockJSON) toDomain() domain.BlockEntry {
+	return domain.BlockEntry{
+		Kind:    domain.BlockKind(e.Kind),
+		Value:   e.Value,
+		Reason:  e.Reason,
+		AddedBy: e.AddedBy,
+		Time:    e.Time,
+	}
+}
@@ -0,0 +1,60 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestFileBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.json")
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0).UTC()
+
+	b, err := NewFileBlocklist(path, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewFileBlocklist: %v", err)
+	}
+	if err := b.Add(ctx, domain.BlockEntry{Kind: domain.BlockKey, Value: "SHA256:glTDKakoUPbVOd03b0gW7idkUX2l4CNVFK9DMWRIDXo", AddedBy: "admin", Time: now}); err != nil {
+		t.Fatalf("Add key: %v", err)
+	}
+	if err := b.Add(ctx, domain.BlockEntry{Kind: domain.BlockSubject, Value: "sub", Time: now}); err != nil {
+		t.Fatalf("Add subject: %v", err)
+	}
+	if err := b.Add(ctx, domain.BlockEntry{Kind: domain.BlockKey, Value: "nope"}); !errors.Is(err, domain.ErrInvalidBlockEntry) {
+		t.Fatalf("expected ErrInvalidBlockEntry, got %v", err)
+	}
+
+	// Re-open to verify persistence.
+	b2, err := NewFileBlocklist(path, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewFileBlocklist(second): %v", err)
+	}
+	var be domain.BlockedError
+	if err := b2.Check(ctx, "other", "SHA256:glTDKakoUPbVOd03b0gW7idkUX2l4CNVFK9DMWRIDXo"); !errors.As(err, &be) || be.Kind != domain.BlockKey {
+		t.Fatalf("expected key block, got %v", err)
+	}
+	if err := b2.Check(ctx, "sub", "SHA256:2SmKENGwc1g33EvYXaxkGw887yekfl1TpU8vP1svz/o"); !errors.As(err, &be) || be.Kind != domain.BlockSubject {
+		t.Fatalf("expected subject block, got %v", err)
+	}
+	if err := b2.Check(ctx, "other", "SHA256:2SmKENGwc1g33EvYXaxkGw887yekfl1TpU8vP1svz/o"); err != nil {
+		t.Fatalf("unexpected block: %v", err)
+	}
+
+	removed, err := b2.Remove(ctx, domain.BlockKey, "SHA256:glTDKakoUPbVOd03b0gW7idkUX2l4CNVFK9DMWRIDXo")
+	if err != nil || removed.AddedBy != "admin" {
+		t.Fatalf("Remove = %+v, %v", removed, err)
+	}
+	if _, err := b2.Remove(ctx, domain.BlockKey, "SHA256:glTDKakoUPbVOd03b0gW7idkUX2l4CNVFK9DMWRIDXo"); !errors.Is(err, domain.ErrBlockNotFound) {
+		t.Fatalf("expected ErrBlockNotFound, got %v", err)
+	}
+	list, err := b2.List(ctx)
+	if err != nil || len(list) != 1 || list[0].Value != "sub" {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+}
@@ -0,0 +1,223 @@
+package file
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// FileCertStore persists certificate records and revocations to a single JSON
+// document, rewritten atomically (fsync+rename) under a lock file on every change.
+type FileCertStore struct {
+	doc *jsonDoc[certDoc]
+}
+
+var _ usecase.CertStore = (*FileCertStore)(nil)
+
+// NewFileCertStore creates a file-backed cert store at the given file path.
+func NewFileCertStore(path string, l usecase.Logger) (*FileCertStore, error) {
+	doc, err := newJSONDoc[certDoc](path, "cert store", l)
+	if err != nil {
+		return nil, err
+	}
+	return &FileCertStore{doc: doc}, nil
+}
+
+// certDoc is the on-disk layout. Field names are part of the file format.
+type certDoc struct {
+	Certs       []certJSON       `json:"certs"`
+	Revocations []revocationJSON `json:"revocations"`
+}
+
+type certJSON struct {
+	Serial      uint64    `json:"serial"`
+	KeyID       string    `json:"key_id"`
+	Subject     string    `json:"subject"`
+	Principals  []string  `json:"principals,omitempty"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	KeyFP       string    `json:"key_fp,omitempty"`
+	PluginAuth  string    `json:"plugin_auth,omitempty"`
+	PluginAuthz string    `json:"plugin_authz,omitempty"`
+	RequestIP   string    `json:"request_ip,omitempty"`
+	Username    string    `json:"username,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	Roles       []string  `json:"roles,omitempty"`
+	Groups      []string  `json:"groups,omitempty"`
+	Issuer      string    `json:"issuer,omitempty"`
+	AMR         []string  `json:"amr,omitempty"`
+	ACR         string    `json:"acr,omitempty"`
+	AuthTime    time.Time `json:"auth_time,omitzero"`
+}
+
+type revocationJSON struct {
+	Kind      string    `json:"kind"`
+	Serial    uint64    `json:"serial,omitempty"`
+	KeyID     string    `json:"key_id,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	KeyFP     string    `json:"key_fp,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	RevokedBy string    `json:"revoked_by,omitempty"`
+	Time      time.Time `json:"time"`
+	NotAfter  time.Time `json:"not_after,omitzero"`
+	Seq       uint64    `json:"seq,omitempty"` // absent in files written before KRL sequence numbers
+}
+
+// Put records (or replaces) the certificate with rec.Serial.
+func (f *FileCertStore) Put(ctx context.Context, rec domain.CertRecord) error {
+	return f.doc.update(ctx, func(doc *certDoc) error {
+		c := toCertJSON(rec)
+		if i := slices.IndexFunc(doc.Certs, func(x certJSON) bool { return x.Serial == rec.Serial }); i >= 0 {
+			doc.Certs[i] = c
+		} else {
+			doc.Certs = append(doc.Certs, c)
+		}
+		return nil
+	})
+}
+
+// Get returns the record for serial or domain.ErrCertNotFound.
+func (f *FileCertStore) Get(ctx context.Context, serial uint64) (domain.CertRecord, error) {
+	doc, err := f.doc.view()
+	if err != nil {
+		return domain.CertRecord{}, err
+	}
+	for _, c := range doc.Certs {
+		if c.Serial == serial {
+			return c.toDomain(), nil
+		}
+	}
+	return domain.CertRecord{}, domain.ErrCertNotFound
+}
+
+// List returns records matching flt ordered by serial.
+func (f *FileCertStore) List(ctx context.Context, flt domain.CertFilter) ([]domain.CertRecord, error) {
+	doc, err := f.doc.view()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.CertRecord, 0, len(doc.Certs))
+	for _, c := range doc.Certs {
+		if rec := c.toDomain(); flt.Match(rec) {
+			out = append(out, rec)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.CertRecord) int {
+		switch {
+		case a.Serial < b.Serial:
+			return -1
+		case a.Serial > b.Serial:
+			return 1
+		}
+		return 0
+	})
+	return out, nil
+}
+
+// Revoke appends a validated revocation entry, assigning its Seq.
+func (f *FileCertStore) Revoke(ctx context.Context, rev domain.Revocation) error {
+	if err := rev.Validate(); err != nil {
+		return err
+	}
+	return f.doc.update(ctx, func(doc *certDoc) error {
+		existing := make([]domain.Revocation, 0, len(doc.Revocations))
+		for _, r := range doc.Revocations {
+			existing = append(existing, r.toDomain())
+		}
+		rev.Seq = domain.NextRevocationSeq(existing)
+		doc.Revocations = append(doc.Revocations, toRevocationJSON(rev))
+		return nil
+	})
+}
+
+// Revocations returns all revocation entries in insertion order.
+func (f *FileCertStore) Revocations(ctx context.Context) ([]domain.Revocation, error) {
+	doc, err := f.doc.view()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.Revocation, 0, len(doc.Revocations))
+	for _, r := range doc.Revocations {
+		out = append(out, r.toDomain())
+	}
+	return out, nil
+}
+
+func toCertJSON(r domain.CertRecord) certJSON {
+	return certJSON{
+		Serial:      r.Serial,
+		KeyID:       r.KeyID,
+		Subject:     r.Subject,
+		Principals:  r.Principals,
+		NotBefore:   r.NotBefore.UTC(),
+		NotAfter:    r.NotAfter.UTC(),
+		KeyFP:       r.KeyFP,
+		PluginAuth:  r.PluginAuth,
+		PluginAuthz: r.PluginAuthz,
+		RequestIP:   r.RequestIP,
+		Username:    r.Username,
+		Email:       r.Email,
+		Roles:       r.Roles,
+		Groups:      r.Groups,
+		Issuer:      r.Issuer,
+		AMR:         r.AMR,
+		ACR:         r.ACR,
+		AuthTime:    r.AuthTime.UTC(),
+	}
+}
+
+func (c certJSON) toDomain() domain.CertRecord {
+	return domain.CertRecord{
+		Serial:      c.Serial,
+		KeyID:       c.KeyID,
+		Subject:     c.Subject,
+		Principals:  c.Principals,
+		NotBefore:   c.NotBefore,
+		NotAfter:    c.NotAfter,
+		KeyFP:       c.KeyFP,
+		PluginAuth:  c.PluginAuth,
+		PluginAuthz: c.PluginAuthz,
+		RequestIP:   c.RequestIP,
+		Username:    c.Username,
+		Email:       c.Email,
+		Roles:       c.Roles,
+		Groups:      c.Groups,
+		Issuer:      c.Issuer,
+		AMR:         c.AMR,
+		ACR:         c.ACR,
+		AuthTime:    c.AuthTime,
+	}
+}
+
+func toRevocationJSON(r domain.Revocation) revocationJSON {
+	return revocationJSON{
+		Kind:      string(r.Kind),
+		Serial:    r.Serial,
+		KeyID:     r.KeyID,
+		Subject:   r.Subject,
+		KeyFP:     r.KeyFP,
+		Reason:    r.Reason,
+		RevokedBy: r.RevokedBy,
+		Time:      r.Time.UTC(),
+		NotAfter:  r.NotAfter.UTC(),
+		Seq:       r.Seq,
+	}
+}
+
+func (r revocationJSON) toDomain() domain.Revocation {
+	return domain.Revocation{
+		Kind:      domain.RevocationKind(r.Kind),
+		Serial:    r.Serial,
+		KeyID:     r.KeyID,
+		Subject:   r.Subject,
+		KeyFP:     r.KeyFP,
+		Reason:    r.Reason,
+		RevokedBy: r.RevokedBy,
+		Time:      r.Time,
+		NotAfter:  r.NotAfter,
+		Seq:       r.Seq,
+	}
+}
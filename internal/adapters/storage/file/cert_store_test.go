@@ -0,0 +1,80 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestFileCertStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "certs.json")
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0).UTC()
+
+	s, err := NewFileCertStore(path, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewFileCertStore: %v", err)
+	}
+	rec := domain.CertRecord{Serial: 1, KeyID: "1|sub|alice", Subject: "sub", Principals: []string{"alice"}, NotBefore: now, NotAfter: now.Add(time.Hour), KeyFP: "SHA256:x",
+		Username: "alice", Groups: []string{"ssh-users"}, AMR: []string{"mfa"}, AuthTime: now.Add(-time.Hour)}
+	if err := s.Put(ctx, rec); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Put(ctx, domain.CertRecord{Serial: 2, Subject: "other", NotBefore: now, NotAfter: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rev := domain.Revocation{Kind: domain.RevokeSerial, Serial: 1, Reason: "stolen", RevokedBy: "admin", Time: now, NotAfter: rec.NotAfter}
+	if err := s.Revoke(ctx, rev); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := s.Revoke(ctx, domain.Revocation{Kind: domain.RevokeSubject, Subject: "other", Time: now}); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	// Re-open and verify everything survived.
+	s2, err := NewFileCertStore(path, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewFileCertStore(second): %v", err)
+	}
+	got, err := s2.Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.KeyID != rec.KeyID || !got.NotAfter.Equal(rec.NotAfter) || got.KeyFP != rec.KeyFP || len(got.Principals) != 1 ||
+		got.Username != "alice" || len(got.Groups) != 1 || len(got.AMR) != 1 || !got.AuthTime.Equal(rec.AuthTime) {
+		t.Fatalf("record mismatch: %+v", got)
+	}
+	if _, err := s2.Get(ctx, 3); !errors.Is(err, domain.ErrCertNotFound) {
+		t.Fatalf("expected ErrCertNotFound, got %v", err)
+	}
+	list, err := s2.List(ctx, domain.CertFilter{Subject: "sub", ActiveAt: now})
+	if err != nil || len(list) != 1 || list[0].Serial != 1 {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	revs, err := s2.Revocations(ctx)
+	if err != nil || len(revs) != 2 {
+		t.Fatalf("Revocations = %+v, %v", revs, err)
+	}
+	// Same second, distinct KRL versions.
+	if revs[1].Seq != revs[0].Seq+1 {
+		t.Fatalf("seqs=%d,%d", revs[0].Seq, revs[1].Seq)
+	}
+	if revs[0].Kind != domain.RevokeSerial || revs[0].Serial != 1 || revs[0].RevokedBy != "admin" || !revs[0].NotAfter.Equal(rec.NotAfter) {
+		t.Fatalf("revocation mismatch: %+v", revs[0])
+	}
+}
+
+func TestFileCertStoreRejectsInvalidRevocation(t *testing.T) {
+	s, err := NewFileCertStore(filepath.Join(t.TempDir(), "certs.json"), ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewFileCertStore: %v", err)
+	}
+	if err := s.Revoke(context.Background(), domain.Revocation{Kind: domain.RevokeKey}); !errors.Is(err, domain.ErrInvalidRevocation) {
+		t.Fatalf("expected ErrInvalidRevocation, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// jsonDoc guards a JSON document of type T on disk. Reads take the in-process
+// mutex; updates additionally hold <path>.lock and rewrite the file atomically.
+type jsonDoc[T any] struct {
+	path     string
+	lockPath string
+	what     string // names the store in errors/logs
+	L        usecase.Logger
+	mu       sync.Mutex
+}
+
+func newJSONDoc[T any](path, what string, l usecase.Logger) (*jsonDoc[T], error) {
+	if path == "" {
+		return nil, errors.New("path required")
+	}
+	path = filepath.Clean(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+	return &jsonDoc[T]{path: path, lockPath: path + ".lock", what: what, L: l}, nil
+}
+
+// view reads the current document.
+func (d *jsonDoc[T]) view() (T, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.read()
+}
+
+// update performs a locked read-modify-write of the document. If mutate returns
+// an error nothing is written.
+func (d *jsonDoc[T]) update(ctx context.Context, mutate func(doc *T) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	unlock, err := acquireLock(d.lockPath, d.what)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := unlock(); err != nil && d.L != nil {
+			d.L.Warn(ctx, "release lock failed", "error", err)
+		}
+	}()
+
+	doc, err := d.read()
+	if err != nil {
+		if d.L != nil {
+			d.L.Error(ctx, "read "+d.what+" failed", "error", err)
+		}
+		return err
+	}
+	if err := mutate(&doc); err != nil {
+		return err
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	if err := writeFileAtomic(d.path, b); err != nil {
+		if d.L != nil {
+			d.L.Error(ctx, "write "+d.what+" failed", "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *jsonDoc[T]) read() (T, error) {
+	var doc T
+	b, err := os.ReadFile(d.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return doc, nil
+		}
+		return doc, err
+	}
+	if len(b) == 0 {
+		return doc, nil
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return doc, fmt.Errorf("parse: %w", err)
+	}
+	return doc, nil
+}
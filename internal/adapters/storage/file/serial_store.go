@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -71,17 +70,7 @@ func (f *FileSerialStore) Next(ctx context.Context) (uint64, error) {
 }
 
 func (f *FileSerialStore) acquireLock() (func() error, error) {
-	lf, err := os.OpenFile(f.lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
-	if err != nil {
-		if os.IsExist(err) {
-			return nil, fmt.Errorf("serial store locked: %s", f.lockPath)
-		}
-		return nil, fmt.Errorf("create lock: %w", err)
-	}
-	// best effort info
-	_, _ = io.WriteString(lf, fmt.Sprintf("pid=%d\n", os.Getpid()))
-	_ = lf.Close()
-	return func() error { return os.Remove(f.lockPath) }, nil
+	return acquireLock(f.lockPath, "serial store")
 }
 
 func (f *FileSerialStore) read() (uint64, error) {
@@ -112,34 +101,5 @@ func (f *FileSerialStore) read() (uint64, error) {
 }
 
 func (f *FileSerialStore) write(v uint64) error {
-	dir := filepath.Dir(f.path)
-	base := filepath.Base(f.path)
-	// Create a secure temp file in the same directory to allow atomic rename.
-	fd, err := os.CreateTemp(dir, base+".tmp-*")
-	if err != nil {
-		return fmt.Errorf("open tmp: %w", err)
-	}
-	tmp := fd.Name()
-	// Ensure permissions are strict (in case of umask differences).
-	_ = os.Chmod(tmp, 0o600)
-	if _, err := io.WriteString(fd, strconv.FormatUint(v, 10)+"\n"); err != nil {
-		_ = fd.Close()
-		return fmt.Errorf("write: %w", err)
-	}
-	if err := fd.Sync(); err != nil {
-		_ = fd.Close()
-		return fmt.Errorf("fsync: %w", err)
-	}
-	if err := fd.Close(); err != nil {
-		return fmt.Errorf("close: %w", err)
-	}
-	if err := os.Rename(tmp, f.path); err != nil {
-		return fmt.Errorf("rename: %w", err)
-	}
-	// optional: fsync dir for stronger guarantees
-	if dfd, err := os.Open(filepath.Dir(f.path)); err == nil {
-		_ = dfd.Sync()
-		_ = dfd.Close()
-	}
-	return nil
+	return writeFileAtomic(f.path, []byte(strconv.FormatUint(v, 10)+"\n"))
 }
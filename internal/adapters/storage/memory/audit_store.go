@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// MemoryAuditStore keeps audit events in memory and answers queries over them.
+// Not durable; suitable for unit tests and local dev only.
+type MemoryAuditStore struct {
+	mu   sync.RWMutex
+	recs []domain.AuditRecord
+	L    usecase.Logger
+}
+
+var _ usecase.AuditStore = (*MemoryAuditStore)(nil)
+
+// NewMemoryAuditStore creates an empty audit store.
+func NewMemoryAuditStore(l usecase.Logger) *MemoryAuditStore {
+	return &MemoryAuditStore{L: l}
+}
+
+// Write appends ev with the next ID.
+func (m *MemoryAuditStore) Write(ctx context.Context, ev domain.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ev.Principals = append([]string(nil), ev.Principals...)
+	m.recs = append(m.recs, domain.AuditRecord{ID: uint64(len(m.recs) + 1), AuditEvent: ev})
+	return nil
+}
+
+// Query returns records matching q, newest first.
+func (m *MemoryAuditStore) Query(ctx context.Context, q domain.AuditQuery) ([]domain.AuditRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []domain.AuditRecord
+	for i := len(m.recs) - 1; i >= 0; i-- {
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+		if q.Match(m.recs[i]) {
+			out = append(out, m.recs[i])
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestMemoryAuditStore_Query(t *testing.T) {
+	s := NewMemoryAuditStore(ilog.NewNop())
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0).UTC()
+	for i := uint64(1); i <= 3; i++ {
+		ev := domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "alice"}, []string{"alice"}, i, now, now.Add(time.Hour), domain.SignContext{Now: now}, nil)
+		if err := s.Write(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = s.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, domain.Identity{Subject: "bob"}, nil, domain.SignContext{Now: now}, domain.ErrPolicyDenied, nil))
+
+	got, _ := s.Query(ctx, domain.AuditQuery{Subject: "alice", Limit: 2})
+	if len(got) != 2 || got[0].ID != 3 || got[1].ID != 2 {
+		t.Fatalf("unexpected page: %+v", got)
+	}
+	got, _ = s.Query(ctx, domain.AuditQuery{Subject: "alice", Before: 2})
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("unexpected cursor page: %+v", got)
+	}
+	got, _ = s.Query(ctx, domain.AuditQuery{Outcome: domain.OutcomeFailure})
+	if len(got) != 1 || got[0].Subject != "bob" {
+		t.Fatalf("unexpected failures: %+v", got)
+	}
+}
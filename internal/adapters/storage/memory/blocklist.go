@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type blockKey struct {
+	kind  domain.BlockKind
+	value string
+}
+
+// MemoryBlocklist keeps blocklist entries in memory.
+// Not durable; suitable for unit tests and local dev only.
+type MemoryBlocklist struct {
+	mu      sync.RWMutex
+	entries map[blockKey]domain.BlockEntry
+	order   []blockKey
+	L       usecase.Logger
+}
+
+var _ usecase.Blocklist = (*MemoryBlocklist)(nil)
+
+// NewMemoryBlocklist creates an empty blocklist.
+func NewMemoryBlocklist(l usecase.Logger) *MemoryBlocklist {
+	return &MemoryBlocklist{entries: map[blockKey]domain.BlockEntry{}, L: l}
+}
+
+// Check returns a domain.BlockedError when subject or keyFP is blocked.
+func (m *MemoryBlocklist) Check(ctx context.Context, subject, keyFP string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.entries[blockKey{domain.BlockSubject, subject}]; ok && subject != "" {
+		return domain.BlockedError{Kind: domain.BlockSubject}
+	}
+	if _, ok := m.entries[blockKey{domain.BlockKey, keyFP}]; ok && keyFP != "" {
+		return domain.BlockedError{Kind: domain.BlockKey}
+	}
+	return nil
+}
+
+// Add inserts or replaces the entry for (Kind, Value).
+func (m *MemoryBlocklist) Add(ctx context.Context, e domain.BlockEntry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	k := blockKey{e.Kind, e.Value}
+	m.mu.Lock()
+	if _, ok := m.entries[k]; !ok {
+		m.order = append(m.order, k)
+	}
+	m.entries[k] = e
+	m.mu.Unlock()
+	if m.L != nil {
+		m.L.Debug(ctx, "blocklist(memory): added", "kind", e.Kind)
+	}
+	return nil
+}
+
+// Remove deletes and returns the entry, or returns domain.ErrBlockNotFound.
+func (m *MemoryBlocklist) Remove(ctx context.Context, kind domain.BlockKind, value string) (domain.BlockEntry, error) {
+	k := blockKey{kind, value}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[k]
+	if !ok {
+		return domain.BlockEntry{}, domain.ErrBlockNotFound
+	}
+	delete(m.entries, k)
+	for i, o := range m.order {
+		if o == k {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return e, nil
+}
+
+// List returns all entries in insertion order.
+func (m *MemoryBlocklist) List(ctx context.Context) ([]domain.BlockEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]domain.BlockEntry, 0, len(m.order))
+	for _, k := range m.order {
+		out = append(out, m.entries[k])
+	}
+	return out, nil
+}
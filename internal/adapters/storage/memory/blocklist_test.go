@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestMemoryBlocklist(t *testing.T) {
+	b := NewMemoryBlocklist(ilog.NewNop())
+	ctx := context.Background()
+
+	if err := b.Add(ctx, domain.BlockEntry{Kind: domain.BlockSubject, Value: "sub"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := b.Add(ctx, domain.BlockEntry{Kind: domain.BlockKey, Value: "SHA256:glTDKakoUPbVOd03b0gW7idkUX2l4CNVFK9DMWRIDXo"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := b.Check(ctx, "sub", ""); !errors.Is(err, domain.ErrBlocked) {
+		t.Fatalf("expected subject blocked, got %v", err)
+	}
+	if err := b.Check(ctx, "x", "SHA256:glTDKakoUPbVOd03b0gW7idkUX2l4CNVFK9DMWRIDXo"); !errors.Is(err, domain.ErrBlocked) {
+		t.Fatalf("expected key blocked, got %v", err)
+	}
+	if err := b.Check(ctx, "", ""); err != nil {
+		t.Fatalf("empty subject/key must not match: %v", err)
+	}
+	if _, err := b.Remove(ctx, domain.BlockSubject, "sub"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := b.Remove(ctx, domain.BlockSubject, "sub"); !errors.Is(err, domain.ErrBlockNotFound) {
+		t.Fatalf("expected ErrBlockNotFound, got %v", err)
+	}
+	list, _ := b.List(ctx)
+	if len(list) != 1 || list[0].Kind != domain.BlockKey {
+		t.Fatalf("List = %+v", list)
+	}
+}
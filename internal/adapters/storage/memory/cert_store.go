@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// MemoryCertStore keeps issued certificate records and revocations in memory.
+// Not durable; suitable for unit tests and local dev only.
+type MemoryCertStore struct {
+	mu   sync.RWMutex
+	recs map[uint64]domain.CertRecord
+	revs []domain.Revocation
+	L    usecase.Logger
+}
+
+var _ usecase.CertStore = (*MemoryCertStore)(nil)
+
+// NewMemoryCertStore creates an empty store.
+func NewMemoryCertStore(l usecase.Logger) *MemoryCertStore {
+	return &MemoryCertStore{recs: map[uint64]domain.CertRecord{}, L: l}
+}
+
+// Put records (or replaces) the certificate with rec.Serial.
+func (m *MemoryCertStore) Put(ctx context.Context, rec domain.CertRecord) error {
+	m.mu.Lock()
+	m.recs[rec.Serial] = rec
+	m.mu.Unlock()
+	if m.L != nil {
+		m.L.Debug(ctx, "certs(memory): stored", "serial", rec.Serial)
+	}
+	return nil
+}
+
+// Get returns the record for serial or domain.ErrCertNotFound.
+func (m *MemoryCertStore) Get(ctx context.Context, serial uint64) (domain.CertRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rec, ok := m.recs[serial]
+	if !ok {
+		return domain.CertRecord{}, domain.ErrCertNotFound
+	}
+	return rec, nil
+}
+
+// List returns records matching f ordered by serial.
+func (m *MemoryCertStore) List(ctx context.Context, f domain.CertFilter) ([]domain.CertRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]domain.CertRecord, 0, len(m.recs))
+	for _, rec := range m.recs {
+		if f.Match(rec) {
+			out = append(out, rec)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.CertRecord) int {
+		switch {
+		case a.Serial < b.Serial:
+			return -1
+		case a.Serial > b.Serial:
+			return 1
+		}
+		return 0
+	})
+	return out, nil
+}
+
+// Revoke appends a validated revocation entry, assigning its Seq.
+func (m *MemoryCertStore) Revoke(ctx context.Context, rev domain.Revocation) error {
+	if err := rev.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	rev.Seq = domain.NextRevocationSeq(m.revs)
+	m.revs = append(m.revs, rev)
+	m.mu.Unlock()
+	if m.L != nil {
+		m.L.Debug(ctx, "certs(memory): revoked", "kind", rev.Kind)
+	}
+	return nil
+}
+
+// Revocations returns a copy of all revocation entries.
+func (m *MemoryCertStore) Revocations(ctx context.Context) ([]domain.Revocation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]domain.Revocation(nil), m.revs...), nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+)
+
+func TestMemoryCertStorePutGetList(t *testing.T) {
+	s := NewMemoryCertStore(ilog.NewNop())
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0).UTC()
+
+	for i, sub := range []string{"alice", "bob", "alice"} {
+		rec := domain.CertRecord{Serial: uint64(3 - i), Subject: sub, NotBefore: now, NotAfter: now.Add(time.Hour)}
+		if err := s.Put(ctx, rec); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	rec, err := s.Get(ctx, 2)
+	if err != nil || rec.Subject != "bob" {
+		t.Fatalf("Get(2) = %+v, %v", rec, err)
+	}
+	if _, err := s.Get(ctx, 9); !errors.Is(err, domain.ErrCertNotFound) {
+		t.Fatalf("expected ErrCertNotFound, got %v", err)
+	}
+	got, err := s.List(ctx, domain.CertFilter{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(got) != 2 || got[0].Serial != 1 || got[1].Serial != 3 {
+		t.Fatalf("List = %+v", got)
+	}
+}
+
+func TestMemoryCertStoreRevoke(t *testing.T) {
+	s := NewMemoryCertStore(ilog.NewNop())
+	ctx := context.Background()
+	if err := s.Revoke(ctx, domain.Revocation{Kind: domain.RevokeSerial}); !errors.Is(err, domain.ErrInvalidRevocation) {
+		t.Fatalf("expected ErrInvalidRevocation, got %v", err)
+	}
+	if err := s.Revoke(ctx, domain.Revocation{Kind: domain.RevokeSerial, Serial: 4}); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	revs, err := s.Revocations(ctx)
+	if err != nil || len(revs) != 1 || revs[0].Serial != 4 || revs[0].Seq != 1 {
+		t.Fatalf("Revocations = %+v, %v", revs, err)
+	}
+}
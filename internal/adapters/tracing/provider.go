@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Config selects the OTLP/HTTP collector and sampling.
+type Config struct {
+	// Endpoint is host[:port] of the collector; empty defers to the standard
+	// OTEL_EXPORTER_OTLP_* environment variables (default localhost:4318).
+	Endpoint string
+	// Insecure sends spans over plain HTTP.
+	Insecure bool
+	// ServiceName is reported as service.name.
+	ServiceName string
+	// SampleRatio is the fraction of new traces sampled (0..1); a sampled
+	// parent from traceparent is always honored.
+	SampleRatio float64
+}
+
+// NewProvider creates a TracerProvider that batches spans to an OTLP/HTTP
+// collector. Shut it down on exit to flush pending spans.
+func NewProvider(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exp, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	), nil
+}
@@ -0,0 +1,95 @@
+// Package tracing implements usecase.Tracer with OpenTelemetry and provides the
+// HTTP middleware that continues W3C trace context from incoming requests, so
+// usecase stage spans nest under the request's server span.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// ScopeName identifies kamini's spans to the OpenTelemetry SDK.
+const ScopeName = "github.com/haukened/kamini"
+
+// Tracer adapts an OpenTelemetry TracerProvider to usecase.Tracer.
+type Tracer struct {
+	tracer trace.Tracer
+	prop   propagation.TextMapPropagator
+}
+
+var _ usecase.Tracer = (*Tracer)(nil)
+
+// New creates a Tracer on tp. Incoming requests are read with the W3C
+// traceparent and baggage propagators.
+func New(tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: tp.Tracer(ScopeName),
+		prop:   propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+}
+
+// Start implements usecase.Tracer.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, usecase.Span) {
+	ctx, s := t.tracer.Start(ctx, name)
+	return ctx, span{s}
+}
+
+type span struct{ s trace.Span }
+
+func (s span) SetAttr(key string, value any) {
+	s.s.SetAttributes(attr(key, value))
+}
+
+// End marks the outcome: kamini.outcome is "ok" or "error", and failures carry
+// the stable error code (and deny code for policy denials) with an error status.
+func (s span) End(err error) {
+	if err == nil {
+		s.s.SetAttributes(attribute.String("kamini.outcome", "ok"))
+		s.s.End()
+		return
+	}
+	code, msg := domain.ClassifyError(err)
+	attrs := []attribute.KeyValue{
+		attribute.String("kamini.outcome", "error"),
+		attribute.String("kamini.error_code", string(code)),
+	}
+	var pd domain.PolicyDeny
+	if errors.As(err, &pd) && pd.Code != "" {
+		attrs = append(attrs, attribute.String("kamini.deny_code", string(pd.Code)))
+	}
+	s.s.SetAttributes(attrs...)
+	// The public message only: wrapped errors may carry details not meant for
+	// a tracing backend.
+	s.s.SetStatus(codes.Error, msg)
+	s.s.End()
+}
+
+func attr(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case uint64:
+		// Serials are opaque identifiers; a string keeps values above MaxInt64 intact.
+		return attribute.String(key, strconv.FormatUint(v, 10))
+	case []string:
+		return attribute.StringSlice(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
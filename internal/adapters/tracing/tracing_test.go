@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type fakeAuth struct{ id domain.Identity }
+
+func (f fakeAuth) Authenticate(context.Context, string) (domain.Identity, error) { return f.id, nil }
+
+type fakeAuthz struct {
+	dec domain.PolicyDecision
+	err error
+}
+
+func (f fakeAuthz) Decide(domain.Identity, domain.SignContext) (domain.PolicyDecision, error) {
+	return f.dec, f.err
+}
+
+type fakeSeq struct{}
+
+func (fakeSeq) Next(context.Context) (uint64, error) { return 42, nil }
+
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(domain.CertSpec, uint64) ([]byte, string, error) {
+	return []byte("cert"), "SHA256:ca", nil
+}
+
+type fakeAudit struct{}
+
+func (fakeAudit) Write(context.Context, domain.AuditEvent) error { return nil }
+
+type fakeClock struct{}
+
+func (fakeClock) Now() time.Time { return time.Unix(1_700_000_000, 0).UTC() }
+
+func newService(tr usecase.Tracer, authz usecase.Authorizer) *usecase.SignUserService {
+	return usecase.NewSignUserService(usecase.SignUserService{
+		Auth:   fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}},
+		Authz:  authz,
+		Seq:    fakeSeq{},
+		Signer: fakeSigner{},
+		Audit:  fakeAudit{},
+		Clock:  fakeClock{},
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+		Trace:  tr,
+	})
+}
+
+func newTracer() (*Tracer, *tracetest.InMemoryExporter) {
+	exp := tracetest.NewInMemoryExporter()
+	return New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))), exp
+}
+
+func attrs(s tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	m := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestMiddleware_SignUserSpans(t *testing.T) {
+	tr, exp := newTracer()
+	svc := newService(tr, fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}})
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/sign", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := svc.Execute(r.Context(), usecase.SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA", TraceID: "req-1"}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	const parentTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/v1/sign", nil)
+	req.Header.Set("traceparent", "00-"+parentTrace+"-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	tr.Middleware(mux).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rr.Code, rr.Body.String())
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exp.GetSpans() {
+		spans[s.Name] = s
+		if got := s.SpanContext.TraceID().String(); got != parentTrace {
+			t.Fatalf("span %s trace=%s, want propagated %s", s.Name, got, parentTrace)
+		}
+	}
+	server, ok := spans["POST /v1/sign"]
+	if !ok {
+		t.Fatalf("missing server span named after the route: %v", spans)
+	}
+	if a := attrs(server); a["http.response.status_code"].AsInt64() != 200 || a["http.route"].AsString() != "POST /v1/sign" {
+		t.Fatalf("server attrs: %v", a)
+	}
+	root, ok := spans["kamini.sign_user"]
+	if !ok || root.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Fatalf("sign_user span must be a child of the server span: %+v", root)
+	}
+	if a := attrs(root); a["kamini.serial"].AsString() != "42" || a["kamini.outcome"].AsString() != "ok" || a["kamini.trace_id"].AsString() != "req-1" {
+		t.Fatalf("root attrs: %v", a)
+	}
+	for _, stage := range []string{"authn", "authz", "serial", "spec", "sign", "audit"} {
+		s, ok := spans[stage]
+		if !ok {
+			t.Fatalf("missing %s span", stage)
+		}
+		if s.Parent.SpanID() != root.SpanContext.SpanID() {
+			t.Fatalf("%s span is not a child of kamini.sign_user", stage)
+		}
+		if attrs(s)["kamini.outcome"].AsString() != "ok" {
+			t.Fatalf("%s outcome: %v", stage, attrs(s))
+		}
+	}
+	if attrs(spans["serial"])["kamini.serial"].AsString() != "42" {
+		t.Fatalf("serial span attrs: %v", attrs(spans["serial"]))
+	}
+}
+
+func TestSpan_Denied(t *testing.T) {
+	tr, exp := newTracer()
+	svc := newService(tr, fakeAuthz{err: domain.PolicyDeny{Code: domain.DenyDefault, Message: "no matching rule"}})
+	if _, err := svc.Execute(context.Background(), usecase.SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"}); err == nil {
+		t.Fatalf("expected deny")
+	}
+	spans := exp.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("want authn, authz and root spans, got %d", len(spans))
+	}
+	authz := spans[1]
+	a := attrs(authz)
+	if authz.Name != "authz" || authz.Status.Code != codes.Error || a["kamini.outcome"].AsString() != "error" ||
+		a["kamini.error_code"].AsString() != string(domain.CodePolicyDenied) || a["kamini.deny_code"].AsString() != string(domain.DenyDefault) {
+		t.Fatalf("authz span: %s %+v %v", authz.Name, authz.Status, a)
+	}
+	if spans[2].Name != "kamini.sign_user" || spans[2].Status.Code != codes.Error {
+		t.Fatalf("root span: %+v", spans[2])
+	}
+}
+
+func TestMiddleware_ServerError(t *testing.T) {
+	tr, exp := newTracer()
+	h := tr.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/x", nil))
+	spans := exp.GetSpans()
+	if len(spans) != 1 || spans[0].Name != http.MethodGet || spans[0].Status.Code != codes.Error {
+		t.Fatalf("spans: %+v", spans)
+	}
+	if spans[0].Parent.IsValid() {
+		t.Fatalf("request without traceparent must start a new trace")
+	}
+
+	var disabled *Tracer
+	rec := httptest.NewRecorder()
+	disabled.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/x", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("nil Tracer must pass the request through, got %d", rec.Code)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tp, err := NewProvider(context.Background(), Config{Endpoint: "127.0.0.1:4318", Insecure: true, ServiceName: "kamini-test", SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
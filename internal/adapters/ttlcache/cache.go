@@ -0,0 +1,82 @@
+// Package ttlcache is a small bounded cache whose entries expire at a time set
+// per entry. Adapters use it for responses from identity providers (group
+// memberships, introspection, userinfo) that are reused for a short while.
+package ttlcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache maps keys to values until each entry's expiry. When full, adding a key
+// evicts the least recently used entry. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	max int
+
+	mu    sync.Mutex
+	order *list.List // of *entry[K, V], most recently used first
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// New creates a cache holding at most size entries (at least one).
+func New[K comparable, V any](size int) *Cache[K, V] {
+	return &Cache[K, V]{max: max(size, 1), order: list.New(), items: map[K]*list.Element{}}
+}
+
+// Get returns the value for key if it has not expired at now. Expired entries
+// are removed.
+func (c *Cache[K, V]) Get(key K, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if !now.Before(e.expires) {
+		c.remove(el)
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Set stores value for key until expires, evicting the least recently used
+// entry when the cache is full.
+func (c *Cache[K, V]) Set(key K, value V, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	for c.order.Len() >= c.max {
+		c.remove(c.order.Back())
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+}
+
+// Len returns the number of entries, expired ones included until they are
+// looked up or evicted.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove drops el. Callers hold c.mu.
+func (c *Cache[K, V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[K, V]).key)
+}
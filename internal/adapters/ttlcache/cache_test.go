@@ -0,0 +1,47 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_Expiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := New[string, int](10)
+	c.Set("a", 1, now.Add(time.Minute))
+	if v, ok := c.Get("a", now); !ok || v != 1 {
+		t.Fatalf("Get(a) = %d, %v", v, ok)
+	}
+	if _, ok := c.Get("a", now.Add(time.Minute)); ok {
+		t.Fatalf("entry must expire at its expiry time")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expired entry not removed on lookup")
+	}
+	c.Set("b", 2, now.Add(time.Minute))
+	c.Set("b", 3, now.Add(time.Hour))
+	if v, ok := c.Get("b", now.Add(2*time.Minute)); !ok || v != 3 {
+		t.Fatalf("Set must replace value and expiry: %d, %v", v, ok)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	exp := now.Add(time.Hour)
+	c := New[string, int](2)
+	c.Set("a", 1, exp)
+	c.Set("b", 2, exp)
+	c.Get("a", now) // b is now the least recently used
+	c.Set("c", 3, exp)
+	if c.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", c.Len())
+	}
+	if _, ok := c.Get("b", now); ok {
+		t.Fatalf("least recently used entry must be evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.Get(k, now); !ok {
+			t.Fatalf("%s evicted; only one entry should go", k)
+		}
+	}
+}
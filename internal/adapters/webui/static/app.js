@@ -0,0 +1,131 @@
+// Kamini admin UI: fetches /v1/dashboard with the operator's bearer token and
+// renders it. All values are inserted as text, never as HTML.
+"use strict";
+
+const TOKEN_KEY = "kamini.token";
+const $ = (id) => document.getElementById(id);
+
+function show(el, visible) {
+  el.hidden = !visible;
+}
+
+function fmtTime(s) {
+  if (!s) return "";
+  const d = new Date(s);
+  return isNaN(d) ? s : d.toISOString().replace("T", " ").replace(/\.\d+Z$/, "Z");
+}
+
+function fmtDuration(seconds) {
+  if (seconds % 86400 === 0) return seconds / 86400 + "d";
+  if (seconds % 3600 === 0) return seconds / 3600 + "h";
+  return Math.round(seconds / 60) + "m";
+}
+
+// fill replaces the rows of tbody; each row is an array of cell texts.
+function fill(tbody, rows, empty, numeric = []) {
+  tbody.replaceChildren();
+  if (rows.length === 0) {
+    const tr = tbody.insertRow();
+    const td = tr.insertCell();
+    td.colSpan = tbody.parentElement.tHead.rows[0].cells.length;
+    td.className = "empty";
+    td.textContent = empty;
+    return;
+  }
+  for (const row of rows) {
+    const tr = tbody.insertRow();
+    row.forEach((text, i) => {
+      const td = tr.insertCell();
+      td.textContent = text == null ? "" : String(text);
+      if (numeric.includes(i)) td.className = "num";
+    });
+  }
+}
+
+function render(d) {
+  $("generated").textContent = "as of " + fmtTime(d.generated_at);
+  $("window").textContent = "(last " + fmtDuration(d.window_seconds) + ")";
+  fill($("ca-keys"), d.ca_keys.map((k) => [k.type, k.fingerprint]), "No CA key configured");
+  fill($("denials"), d.denials.map((x) => [x.code, x.count, fmtTime(x.last)]), "No denials", [1]);
+  show($("denials-truncated"), !!d.denials_truncated);
+  fill(
+    $("active"),
+    d.active.map((c) => [c.serial, c.subject, (c.principals || []).join(", "), c.key_fp, fmtTime(c.not_after)]),
+    "No valid certificates",
+    [0],
+  );
+  fill(
+    $("issued"),
+    d.issued.map((e) => [fmtTime(e.time), e.serial, e.subject, (e.principals || []).join(", "), e.source_ip]),
+    "No issuances recorded",
+    [1],
+  );
+}
+
+function signedIn(yes) {
+  show($("signin"), !yes);
+  show($("dashboard"), yes);
+  show($("refresh"), yes);
+  show($("signout"), yes);
+}
+
+function fail(msg) {
+  $("error").textContent = msg;
+  show($("error"), !!msg);
+}
+
+async function load() {
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  if (!token) {
+    signedIn(false);
+    return;
+  }
+  let resp;
+  try {
+    resp = await fetch("../v1/dashboard", {
+      headers: { Authorization: "Bearer " + token },
+      cache: "no-store",
+      credentials: "omit",
+    });
+  } catch (e) {
+    fail("Cannot reach the server: " + e.message);
+    return;
+  }
+  if (!resp.ok) {
+    let msg = resp.status + " " + resp.statusText;
+    try {
+      const body = await resp.json();
+      if (body.error) msg = body.error.code + ": " + body.error.message;
+    } catch (_) {
+      // not a JSON error envelope
+    }
+    if (resp.status === 401 || resp.status === 403) {
+      sessionStorage.removeItem(TOKEN_KEY);
+      signedIn(false);
+    }
+    fail(msg);
+    return;
+  }
+  fail("");
+  render(await resp.json());
+  signedIn(true);
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("signin-form").addEventListener("submit", (ev) => {
+    ev.preventDefault();
+    const token = $("token").value.trim().replace(/^bearer\s+/i, "");
+    $("token").value = "";
+    if (token) {
+      sessionStorage.setItem(TOKEN_KEY, token);
+      load();
+    }
+  });
+  $("refresh").addEventListener("click", load);
+  $("signout").addEventListener("click", () => {
+    sessionStorage.removeItem(TOKEN_KEY);
+    fail("");
+    signedIn(false);
+  });
+  load();
+});
@@ -0,0 +1,39 @@
+// Package webui serves the read-only admin web UI: static files embedded in the
+// binary that render GET /v1/dashboard in the browser. Nothing is loaded from
+// third-party hosts, and the content security policy forbids it.
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// Prefix is the path the UI is mounted under.
+const Prefix = "/ui/"
+
+//go:embed static
+var static embed.FS
+
+// csp allows only same-origin scripts, styles and API calls.
+const csp = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self'; connect-src 'self'; " +
+	"base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// Handler serves the UI below Prefix. The files themselves are public; the data
+// comes from admin-only API calls made with the operator's bearer token.
+func Handler() http.Handler {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // the embedded tree is fixed at build time
+	}
+	files := http.StripPrefix(Prefix, http.FileServerFS(sub))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Security-Policy", csp)
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
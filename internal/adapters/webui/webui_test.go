@@ -0,0 +1,58 @@
+package webui
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHandler_ServesAssets(t *testing.T) {
+	h := Handler()
+	for path, ctype := range map[string]string{
+		"/ui/":          "text/html",
+		"/ui/app.js":    "text/javascript",
+		"/ui/style.css": "text/css",
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, rr.Code)
+		}
+		if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, ctype) {
+			t.Fatalf("%s: content type %q", path, got)
+		}
+		if csp := rr.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self'") {
+			t.Fatalf("%s: missing CSP, got %q", path, csp)
+		}
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ui/missing.js", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("missing asset: status %d", rr.Code)
+	}
+}
+
+// The UI must work on networks without internet access: no CDN or other
+// absolute URLs in the embedded files.
+func TestAssets_NoExternalReferences(t *testing.T) {
+	external := regexp.MustCompile(`(?i)(https?:)?//[a-z0-9.-]+\.[a-z]{2,}`)
+	err := fs.WalkDir(static, "static", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := static.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if m := external.Find(b); m != nil {
+			t.Errorf("%s references %s", path, m)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,222 @@
+// Package bootstrap wires adapters from configuration.
+package bootstrap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/haukened/kamini/internal/adapters/audit/chain"
+	"github.com/haukened/kamini/internal/adapters/audit/fanout"
+	auditfile "github.com/haukened/kamini/internal/adapters/audit/file"
+	"github.com/haukened/kamini/internal/adapters/audit/sqlstore"
+	"github.com/haukened/kamini/internal/adapters/audit/stdout"
+	"github.com/haukened/kamini/internal/adapters/audit/syslog"
+	"github.com/haukened/kamini/internal/adapters/audit/webhook"
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/domain"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// NewAuditSink builds the sinks listed in cfg.Sinks (or cfg.Sink) behind a
+// fan-out sink that applies cfg.Fanout's failure policy. Close the result on
+// shutdown; its Check method backs the readiness probe. For the file and chain
+// sinks, SIGHUP re-opens the file until ctx is done. keys signs chain
+// checkpoints and may be nil otherwise; store backs the sql sink and may be nil
+// if that sink is not listed; obs may be nil. The caller keeps ownership of
+// store and closes it after the sink.
+func NewAuditSink(ctx context.Context, cfg config.AuditConfig, keys usecase.CAKeySource, store usecase.AuditStore, obs fanout.Observer, l usecase.Logger) (*fanout.Sink, error) {
+	names := cfg.Sinks
+	if len(names) == 0 {
+		names = []string{cfg.Sink}
+	}
+	if slices.Contains(names, "file") && slices.Contains(names, "chain") {
+		return nil, fmt.Errorf("audit: file and chain sinks share audit.file.path; use one of them")
+	}
+	for _, r := range cfg.Fanout.Required {
+		if !slices.Contains(names, r) {
+			return nil, fmt.Errorf("audit: required sink %q is not configured", r)
+		}
+	}
+	var targets []fanout.Target
+	closeAll := func() {
+		for _, t := range targets {
+			if c, ok := t.Sink.(io.Closer); ok {
+				_ = c.Close()
+			}
+		}
+	}
+	for _, name := range names {
+		s, err := newAuditTarget(ctx, name, cfg, keys, store, l)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		targets = append(targets, fanout.Target{Name: name, Sink: s, Required: slices.Contains(cfg.Fanout.Required, name)})
+	}
+	fs, err := fanout.New(targets, fanout.Config{
+		Policy:           fanout.Policy(cfg.Fanout.Policy),
+		QueueSize:        cfg.Fanout.QueueSize,
+		RetryInterval:    cfg.Fanout.RetryInterval,
+		MaxRetryInterval: cfg.Fanout.MaxRetryInterval,
+	}, obs, l)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return fs, nil
+}
+
+// NewAuditStore opens the database configured in cfg, or returns nil when no
+// driver is set. The store serves GET /v1/audit and, listed as the sql sink,
+// receives events.
+func NewAuditStore(ctx context.Context, cfg config.AuditStoreConfig) (*sqlstore.Store, error) {
+	if cfg.Driver == "" {
+		return nil, nil
+	}
+	return sqlstore.Open(ctx, sqlstore.Config{Driver: cfg.Driver, DSN: cfg.DSN})
+}
+
+func newAuditTarget(ctx context.Context, name string, cfg config.AuditConfig, keys usecase.CAKeySource, store usecase.AuditStore, l usecase.Logger) (usecase.AuditSink, error) {
+	switch name {
+	case "", "stdout":
+		return stdout.New(l), nil
+	case "file":
+		return newAuditFile(ctx, cfg.File, l)
+	case "syslog":
+		return newAuditSyslog(cfg.Syslog, l)
+	case "webhook":
+		return newAuditWebhook(cfg.Webhook, l)
+	case "sql":
+		if store == nil {
+			return nil, fmt.Errorf("audit: sql sink needs audit.store.driver and audit.store.dsn")
+		}
+		// Hide Close: the store outlives the fan-out, which closes its targets.
+		return struct{ usecase.AuditSink }{store}, nil
+	case "chain":
+		// A crash mid-write leaves a torn line that would break the next link.
+		if n, err := auditfile.TrimPartialLine(cfg.File.Path); err != nil {
+			return nil, fmt.Errorf("audit chain: repair last record: %w", err)
+		} else if n > 0 && l != nil {
+			l.Warn(ctx, "audit chain: dropped partial last record", "path", cfg.File.Path, "bytes", n)
+		}
+		last, err := auditfile.LastLine(cfg.File.Path)
+		if err != nil {
+			return nil, fmt.Errorf("audit chain: read last record: %w", err)
+		}
+		f, err := newAuditFile(ctx, cfg.File, l)
+		if err != nil {
+			return nil, err
+		}
+		s, err := chain.New(f, chain.Config{CheckpointEvery: cfg.Chain.CheckpointEvery, HeadPath: cfg.File.Path + ".head"}, last, keys, nil, l)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("audit: unknown sink %q (want stdout, file, chain, syslog, webhook or sql)", name)
+	}
+}
+
+func newAuditFile(ctx context.Context, cfg config.AuditFileConfig, l usecase.Logger) (*auditfile.Sink, error) {
+	s, err := auditfile.New(auditfile.Config{
+		Path:          cfg.Path,
+		Fsync:         auditfile.FsyncPolicy(cfg.Fsync),
+		FsyncInterval: cfg.FsyncInterval,
+		MaxSize:       int64(cfg.MaxSizeMB) << 20,
+		RotateEvery:   cfg.RotateEvery,
+		MaxBackups:    cfg.MaxBackups,
+		MaxAge:        cfg.MaxAge,
+	}, nil, l)
+	if err != nil {
+		return nil, err
+	}
+	s.ReopenOnSignal(ctx)
+	return s, nil
+}
+
+func newAuditSyslog(cfg config.AuditSyslogConfig, l usecase.Logger) (*syslog.Sink, error) {
+	facility, ok := syslog.ParseFacility(cfg.Facility)
+	if !ok {
+		return nil, fmt.Errorf("audit syslog: unknown facility %q", cfg.Facility)
+	}
+	sc := syslog.Config{
+		Network:   cfg.Network,
+		Address:   cfg.Address,
+		Facility:  facility,
+		Hostname:  cfg.Hostname,
+		AppName:   cfg.AppName,
+		SDID:      cfg.SDID,
+		QueueSize: cfg.QueueSize,
+	}
+	if cfg.Network == "tls" {
+		tc, err := syslogTLS(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sc.TLS = tc
+	}
+	return syslog.New(sc, l)
+}
+
+func syslogTLS(cfg config.AuditSyslogConfig) (*tls.Config, error) {
+	tc := &tls.Config{ServerName: cfg.TLSServerName, MinVersion: tls.VersionTLS12}
+	if tc.ServerName == "" {
+		tc.ServerName, _, _ = net.SplitHostPort(cfg.Address)
+	}
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("audit syslog: read CA file: %w", err)
+		}
+		tc.RootCAs = x509.NewCertPool()
+		if !tc.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("audit syslog: no certificates in %s", cfg.TLSCAFile)
+		}
+	}
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("audit syslog: load client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
+
+func newAuditWebhook(cfg config.AuditWebhookConfig, l usecase.Logger) (*webhook.Sink, error) {
+	secret := cfg.Secret
+	if cfg.SecretFile != "" {
+		b, err := os.ReadFile(cfg.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("audit webhook: read secret file: %w", err)
+		}
+		secret = strings.TrimSpace(string(b))
+	}
+	actions := make([]domain.AuditAction, 0, len(cfg.Actions))
+	for _, a := range cfg.Actions {
+		actions = append(actions, domain.AuditAction(strings.ToUpper(a)))
+	}
+	return webhook.New(webhook.Config{
+		URLs:             cfg.URLs,
+		Secret:           []byte(secret),
+		Actions:          actions,
+		Outcome:          webhook.Outcome(cfg.Outcome),
+		BatchSize:        cfg.BatchSize,
+		FlushInterval:    cfg.FlushInterval,
+		QueueSize:        cfg.QueueSize,
+		Timeout:          cfg.Timeout,
+		MaxAttempts:      cfg.MaxAttempts,
+		RetryInterval:    cfg.RetryInterval,
+		MaxRetryInterval: cfg.MaxRetryInterval,
+		SpoolDir:         cfg.SpoolDir,
+		MaxSpoolBytes:    int64(cfg.SpoolMaxMB) << 20,
+	}, l)
+}
@@ -0,0 +1,260 @@
+package bootstrap
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/audit/chain"
+	"github.com/haukened/kamini/internal/adapters/audit/webhook"
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+func failureEvent() domain.AuditEvent {
+	return domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthn, domain.Identity{}, nil,
+		domain.SignContext{Now: time.Unix(1, 0).UTC()}, domain.ErrInvalidToken, nil)
+}
+
+func TestNewAuditSink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := NewAuditSink(ctx, config.AuditConfig{Sink: "stdout"}, nil, nil, nil, ilog.NewNop())
+	if err != nil || s.Close() != nil {
+		t.Fatalf("stdout sink: %v", err)
+	}
+	bad := []config.AuditConfig{
+		{Sink: "carrier-pigeon"},
+		{Sinks: []string{"file", "chain"}},
+		{Sinks: []string{"stdout"}, Fanout: config.AuditFanoutConfig{Required: []string{"file"}}},
+		{Sink: "stdout", Fanout: config.AuditFanoutConfig{Policy: "fail_sideways"}},
+		{Sink: "syslog", Syslog: config.AuditSyslogConfig{Network: "udp", Address: "127.0.0.1:514", Facility: "nope"}},
+		{Sink: "syslog", Syslog: config.AuditSyslogConfig{Network: "tls", Address: "127.0.0.1:6514", Facility: "auth", TLSCAFile: "/does/not/exist"}},
+		{Sink: "webhook", Webhook: config.AuditWebhookConfig{URLs: []string{"https://alerts.example.com"}}},
+		{Sink: "webhook", Webhook: config.AuditWebhookConfig{URLs: []string{"https://alerts.example.com"}, SecretFile: "/does/not/exist"}},
+		{Sink: "sql"},
+	}
+	for _, cfg := range bad {
+		if _, err := NewAuditSink(ctx, cfg, nil, nil, nil, ilog.NewNop()); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewAuditSink(ctx, config.AuditConfig{
+		Sinks: []string{"stdout", "file"},
+		File:  config.AuditFileConfig{Path: path},
+	}, nil, nil, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("file sink: %v", err)
+	}
+	if err := sink.Write(ctx, failureEvent()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := sink.Check(ctx); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if b, _ := os.ReadFile(path); len(b) == 0 {
+		t.Fatalf("expected audit file to contain the event")
+	}
+}
+
+func TestNewAuditSink_ChainResumes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := config.AuditConfig{Sink: "chain", File: config.AuditFileConfig{Path: filepath.Join(t.TempDir(), "audit.jsonl")}}
+
+	for run := 0; run < 2; run++ {
+		sink, err := NewAuditSink(ctx, cfg, nil, nil, nil, ilog.NewNop())
+		if err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		if err := sink.Write(ctx, failureEvent()); err != nil {
+			t.Fatalf("run %d: Write: %v", run, err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("run %d: close: %v", run, err)
+		}
+	}
+	f, err := os.Open(cfg.File.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	v := chain.NewVerifier(nil)
+	if err := v.Feed(cfg.File.Path, f); err != nil {
+		t.Fatalf("chain broken across restarts: %v", err)
+	}
+	if rep := v.Report(); rep.Events != 2 || rep.LastSeq != 2 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+}
+
+func TestNewAuditSink_ChainSurvivesCrashAndLogrotate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := config.AuditConfig{Sink: "chain", File: config.AuditFileConfig{Path: filepath.Join(t.TempDir(), "audit.jsonl")}}
+	write := func(run int, l usecase.Logger) {
+		t.Helper()
+		sink, err := NewAuditSink(ctx, cfg, nil, nil, nil, l)
+		if err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		if err := sink.Write(ctx, failureEvent()); err != nil {
+			t.Fatalf("run %d: Write: %v", run, err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatalf("run %d: close: %v", run, err)
+		}
+	}
+
+	write(0, ilog.NewNop())
+	// A crash mid-write leaves a torn line; startup drops it, with or without a logger.
+	f, err := os.OpenFile(cfg.File.Path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"seq":2,"prev":"ab`)
+	_ = f.Close()
+	write(1, nil)
+	// logrotate moves the file to a name the sink does not know.
+	rotated := cfg.File.Path + ".1"
+	if err := os.Rename(cfg.File.Path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	write(2, ilog.NewNop())
+
+	v := chain.NewVerifier(nil)
+	for _, p := range []string{rotated, cfg.File.Path} {
+		f, err := os.Open(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = v.Feed(p, f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatalf("chain broken: %v", err)
+		}
+	}
+	if rep := v.Report(); rep.Events != 3 || rep.FirstSeq != 1 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+}
+
+func TestNewAuditSink_Syslog(t *testing.T) {
+	ctx := context.Background()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	sink, err := NewAuditSink(ctx, config.AuditConfig{
+		Sink:   "syslog",
+		Syslog: config.AuditSyslogConfig{Network: "udp", Address: pc.LocalAddr().String(), Facility: "local0"},
+	}, nil, nil, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("syslog sink: %v", err)
+	}
+	defer sink.Close()
+	if err := sink.Write(ctx, failureEvent()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 8192)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read datagram: %v", err)
+	}
+	// local0 (16) * 8 + warning (4)
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<132>1 ") {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
+
+func TestNewAuditSink_Webhook(t *testing.T) {
+	ctx := context.Background()
+	got := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify([]byte("k3y"), r.Header.Get(webhook.TimestampHeader), body, r.Header.Get(webhook.SignatureHeader), time.Now(), time.Minute) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		got <- string(body)
+	}))
+	defer srv.Close()
+	secretFile := filepath.Join(t.TempDir(), "webhook.key")
+	if err := os.WriteFile(secretFile, []byte("k3y\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sink, err := NewAuditSink(ctx, config.AuditConfig{
+		Sink: "webhook",
+		Webhook: config.AuditWebhookConfig{
+			URLs:          []string{srv.URL},
+			SecretFile:    secretFile,
+			Actions:       []string{"issue_user_cert"},
+			FlushInterval: 10 * time.Millisecond,
+		},
+	}, nil, nil, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("webhook sink: %v", err)
+	}
+	defer sink.Close()
+	if err := sink.Write(ctx, failureEvent()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	select {
+	case body := <-got:
+		if !strings.Contains(body, `"action":"ISSUE_USER_CERT"`) {
+			t.Fatalf("unexpected body: %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no webhook delivery")
+	}
+}
+
+func TestNewAuditSink_SQL(t *testing.T) {
+	ctx := context.Background()
+	if s, err := NewAuditStore(ctx, config.AuditStoreConfig{}); s != nil || err != nil {
+		t.Fatalf("no driver: got %v, %v", s, err)
+	}
+	if _, err := NewAuditStore(ctx, config.AuditStoreConfig{Driver: "oracle", DSN: "x"}); err == nil {
+		t.Fatalf("expected unknown driver error")
+	}
+	store, err := NewAuditStore(ctx, config.AuditStoreConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "audit.db")})
+	if err != nil {
+		t.Fatalf("NewAuditStore: %v", err)
+	}
+	defer store.Close()
+
+	sink, err := NewAuditSink(ctx, config.AuditConfig{Sinks: []string{"stdout", "sql"}}, nil, store, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("sql sink: %v", err)
+	}
+	if err := sink.Write(ctx, failureEvent()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	// Closing the fan-out leaves the store open for queries.
+	recs, err := store.Query(ctx, domain.AuditQuery{Outcome: domain.OutcomeFailure})
+	if err != nil || len(recs) != 1 || recs[0].ErrorCode != domain.CodeInvalidToken {
+		t.Fatalf("Query: %+v, %v", recs, err)
+	}
+}
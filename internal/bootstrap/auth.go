@@ -0,0 +1,231 @@
+package bootstrap
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/auth"
+	"github.com/haukened/kamini/internal/adapters/graph"
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// defaultIssuerName labels the top-level auth.oidc issuer when further
+// issuers are configured.
+const defaultIssuerName = "default"
+
+// Authentication modes (auth.mode).
+const (
+	AuthModeOIDC = "oidc"
+	AuthModeDev  = "dev"
+)
+
+// NewAuthenticator builds the authenticator selected by auth.mode: OIDC, or
+// the development authenticator when auth.mode is explicitly "dev". With a
+// SAML service provider (see NewSAML), its session tokens are accepted too,
+// and on their own when no OIDC issuer is configured.
+func NewAuthenticator(ctx context.Context, ac config.AuthConfig, saml *auth.SAMLServiceProvider, l usecase.Logger) (usecase.Authenticator, error) {
+	var (
+		base usecase.Authenticator
+		err  error
+	)
+	switch ac.Mode {
+	case "", AuthModeOIDC:
+		if saml != nil && ac.OIDC.IssuerURL == "" && ac.OIDC.Profile == "" && len(ac.OIDC.Issuers) == 0 {
+			return saml, nil
+		}
+		base, err = newOIDCAuthenticator(ctx, ac, l)
+	case AuthModeDev:
+		base, err = auth.NewDevAuthenticator(ctx, auth.DevAuthConfig{KeyPath: ac.Dev.KeyPath}, l)
+	default:
+		return nil, fmt.Errorf("auth.mode %q: want %s or %s", ac.Mode, AuthModeOIDC, AuthModeDev)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if saml == nil {
+		return base, nil
+	}
+	return auth.IssuerRouter{Routes: map[string]usecase.Authenticator{auth.SAMLSessionIssuer: saml}, Default: base}, nil
+}
+
+// NewSAML builds the SAML service provider when auth.saml is enabled, nil
+// otherwise. Its Handler serves the browser login; pass it to
+// NewAuthenticator so the session tokens it mints are accepted.
+func NewSAML(ctx context.Context, cfg config.SAMLConfig, l usecase.Logger) (*auth.SAMLServiceProvider, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	metadata := []byte(cfg.IdPMetadata)
+	if cfg.IdPMetadataFile != "" {
+		b, err := os.ReadFile(cfg.IdPMetadataFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth saml: read idp metadata: %w", err)
+		}
+		metadata = b
+	}
+	return auth.NewSAMLServiceProvider(ctx, auth.SAMLConfig{
+		RootURL:           cfg.RootURL,
+		EntityID:          cfg.EntityID,
+		IdPMetadata:       metadata,
+		NameIDFormat:      cfg.NameIDFormat,
+		UsernameAttribute: cfg.AttrUsername,
+		EmailAttribute:    cfg.AttrEmail,
+		RolesAttribute:    cfg.AttrRoles,
+		GroupsAttribute:   cfg.AttrGroups,
+		SessionKeyPath:    cfg.SessionKeyPath,
+		SessionTTL:        cfg.SessionTTL,
+		LoginTTL:          cfg.LoginTTL,
+	}, l)
+}
+
+// newOIDCAuthenticator builds the OIDC authenticator: a single verifier for
+// auth.oidc.issuer_url, or one per issuer routed by the token's "iss" when
+// auth.oidc.issuers is set. Discovery runs at startup for every issuer without
+// static keys (jwks_file, jwks); with lazy_discovery a failure is retried on use
+// instead of failing startup. With auth.graph enabled, every issuer resolves
+// Entra ID groups overages via Graph.
+func newOIDCAuthenticator(ctx context.Context, ac config.AuthConfig, l usecase.Logger) (usecase.Authenticator, error) {
+	cfg := ac.OIDC
+	groups, err := newGroupResolver(ac.Graph)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: cfg.HTTPTimeout}
+	intro, err := introspection(cfg.IntrospectionURL, cfg.IntrospectionClientID, cfg.IntrospectionClientSecret, cfg.IntrospectionClientSecretFile, cfg.IntrospectionCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	top := auth.OIDCAuthConfig{
+		IssuerURL:         cfg.IssuerURL,
+		DiscoveryURL:      cfg.DiscoveryURL,
+		JWKSFile:          cfg.JWKSFile,
+		JWKS:              cfg.JWKS,
+		LazyDiscovery:     cfg.LazyDiscovery,
+		DiscoveryRetry:    cfg.DiscoveryRetry,
+		ClientID:          cfg.ClientID,
+		SkipClientIDCheck: cfg.SkipClientIDCheck,
+		TenantIDs:         cfg.TenantIDs,
+		AuthorizedParties: cfg.AuthorizedParties,
+		RequiredAMR:       cfg.RequiredAMR,
+		AllowedACR:        cfg.AllowedACR,
+		UsernameClaim:     cfg.ClaimsUsername,
+		EmailClaim:        cfg.ClaimsEmail,
+		RolesClaim:        cfg.ClaimsRoles,
+		GroupsClaim:       cfg.ClaimsGroups,
+		AccessTokens:      cfg.AccessTokens,
+		Introspection:     intro,
+		UserInfo:          userInfo(cfg.UserInfoClaims, cfg.UserInfoCacheTTL),
+		Profile:           cfg.Profile,
+		GroupResolver:     groups,
+		HTTPClient:        client,
+	}
+	if len(cfg.Issuers) == 0 {
+		if cfg.IssuerURL == "" && cfg.Profile == "" {
+			return nil, errors.New("auth.oidc: issuer_url or issuers required")
+		}
+		return auth.NewOIDCAuthenticator(ctx, top, l)
+	}
+	var cfgs []auth.OIDCAuthConfig
+	if cfg.IssuerURL != "" || cfg.Profile != "" {
+		top.Name = defaultIssuerName
+		cfgs = append(cfgs, top)
+	}
+	for _, iss := range cfg.Issuers {
+		intro, err := introspection(iss.IntrospectionURL, iss.IntrospectionClientID, iss.IntrospectionClientSecret, iss.IntrospectionClientSecretFile, iss.IntrospectionCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("issuer %q: %w", iss.Name, err)
+		}
+		cfgs = append(cfgs, auth.OIDCAuthConfig{
+			Name:              iss.Name,
+			IssuerURL:         iss.IssuerURL,
+			DiscoveryURL:      iss.DiscoveryURL,
+			JWKSFile:          iss.JWKSFile,
+			JWKS:              iss.JWKS,
+			LazyDiscovery:     cfg.LazyDiscovery,
+			DiscoveryRetry:    cfg.DiscoveryRetry,
+			ClientID:          iss.ClientID,
+			Audiences:         iss.Audiences,
+			SkipClientIDCheck: iss.SkipClientIDCheck,
+			TenantIDs:         iss.TenantIDs,
+			AuthorizedParties: iss.AuthorizedParties,
+			RequiredAMR:       iss.RequiredAMR,
+			AllowedACR:        iss.AllowedACR,
+			UsernameClaim:     cmp.Or(iss.ClaimsUsername, cfg.ClaimsUsername),
+			EmailClaim:        cmp.Or(iss.ClaimsEmail, cfg.ClaimsEmail),
+			RolesClaim:        cmp.Or(iss.ClaimsRoles, cfg.ClaimsRoles),
+			GroupsClaim:       cmp.Or(iss.ClaimsGroups, cfg.ClaimsGroups),
+			AccessTokens:      iss.AccessTokens,
+			Introspection:     intro,
+			UserInfo:          userInfo(iss.UserInfoClaims, iss.UserInfoCacheTTL),
+			Profile:           iss.Profile,
+			GroupResolver:     groups,
+			HTTPClient:        client,
+		})
+	}
+	return auth.NewMultiOIDCAuthenticator(ctx, cfgs, l)
+}
+
+// newGroupResolver returns nil (no resolver) unless auth.graph is enabled.
+func newGroupResolver(cfg config.GraphConfig) (usecase.GroupResolver, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	secret, err := readSecret(cfg.ClientSecret, cfg.ClientSecretFile)
+	if err != nil {
+		return nil, fmt.Errorf("auth graph: %w", err)
+	}
+	g, err := graph.New(graph.Config{
+		TenantID:            cfg.TenantID,
+		ClientID:            cfg.ClientID,
+		ClientSecret:        secret,
+		AuthorityURL:        cfg.AuthorityURL,
+		GraphURL:            cfg.GraphURL,
+		SecurityEnabledOnly: cfg.SecurityEnabledOnly,
+		CacheTTL:            cfg.CacheTTL,
+		HTTP:                &http.Client{Timeout: cfg.Timeout},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// introspection returns nil (opaque tokens rejected) unless a client ID is set.
+func introspection(url, clientID, secret, secretFile string, ttl time.Duration) (*auth.IntrospectionConfig, error) {
+	if clientID == "" {
+		return nil, nil
+	}
+	secret, err := readSecret(secret, secretFile)
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	return &auth.IntrospectionConfig{URL: url, ClientID: clientID, ClientSecret: secret, CacheTTL: ttl}, nil
+}
+
+// userInfo returns nil (no enrichment) unless claims are listed.
+func userInfo(claims []string, ttl time.Duration) *auth.UserInfoConfig {
+	if len(claims) == 0 {
+		return nil
+	}
+	return &auth.UserInfoConfig{Claims: claims, CacheTTL: ttl}
+}
+
+// readSecret returns the contents of file (surrounding whitespace trimmed) when
+// set, else value.
+func readSecret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("read client secret file: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,191 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/xml"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+
+	"github.com/haukened/kamini/internal/adapters/auth"
+	"github.com/haukened/kamini/internal/config"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/oidctest"
+)
+
+func TestNewAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	ac := config.DEFAULT_CONFIG.Auth
+	cfg := &ac.OIDC
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil || !strings.Contains(err.Error(), "issuer_url or issuers") {
+		t.Fatalf("expected missing issuer error, got %v", err)
+	}
+
+	cfg.IssuerURL, cfg.ClientID = oidctest.New(t).URL, "kamini"
+	a, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("single issuer: %v", err)
+	}
+	if _, ok := a.(*auth.OIDCAuthenticator); !ok {
+		t.Fatalf("single issuer: got %T", a)
+	}
+
+	cfg.Issuers = []config.OIDCIssuerConfig{
+		{Name: "entra", IssuerURL: oidctest.New(t).URL, ClientID: "kamini"},
+		{Name: "okta", IssuerURL: oidctest.New(t).URL, Audiences: []string{"api://kamini"}},
+	}
+	a, err = NewAuthenticator(ctx, ac, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("multiple issuers: %v", err)
+	}
+	if _, ok := a.(*auth.MultiOIDCAuthenticator); !ok {
+		t.Fatalf("multiple issuers: got %T", a)
+	}
+
+	cfg.Issuers = append(cfg.Issuers, config.OIDCIssuerConfig{Name: "default", IssuerURL: oidctest.New(t).URL, ClientID: "x"})
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil {
+		t.Fatalf("expected clash with the top-level issuer's name")
+	}
+}
+
+func TestNewAuthenticator_Modes(t *testing.T) {
+	ctx := context.Background()
+	ac := config.DEFAULT_CONFIG.Auth
+	ac.Mode = "dev"
+	ac.Dev.KeyPath = filepath.Join(t.TempDir(), "dev.key")
+	a, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("dev mode: %v", err)
+	}
+	if _, ok := a.(*auth.DevAuthenticator); !ok {
+		t.Fatalf("dev mode: got %T", a)
+	}
+
+	ac.Mode = "ldap"
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil || !strings.Contains(err.Error(), "auth.mode") {
+		t.Fatalf("expected unknown mode error, got %v", err)
+	}
+}
+
+func TestNewAuthenticator_Graph(t *testing.T) {
+	ctx := context.Background()
+	ac := config.DEFAULT_CONFIG.Auth
+	ac.OIDC.IssuerURL, ac.OIDC.ClientID = oidctest.New(t).URL, "kamini"
+	ac.Graph.Enabled = true
+	ac.Graph.ClientID = "graph-app"
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil || !strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected missing secret error, got %v", err)
+	}
+	ac.Graph.ClientSecretFile = filepath.Join(t.TempDir(), "missing")
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil {
+		t.Fatalf("expected unreadable secret file error")
+	}
+	if err := os.WriteFile(ac.Graph.ClientSecretFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err != nil {
+		t.Fatalf("graph enabled: %v", err)
+	}
+}
+
+func TestNewAuthenticator_Introspection(t *testing.T) {
+	ctx := context.Background()
+	ac := config.DEFAULT_CONFIG.Auth
+	ac.OIDC.IssuerURL, ac.OIDC.ClientID = oidctest.New(t).URL, "api://kamini"
+	ac.OIDC.AccessTokens = true
+	ac.OIDC.IntrospectionClientID = "kamini"
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil || !strings.Contains(err.Error(), "introspection") {
+		t.Fatalf("expected missing introspection endpoint error, got %v", err)
+	}
+	ac.OIDC.IntrospectionURL = ac.OIDC.IssuerURL + "/introspect"
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err != nil {
+		t.Fatalf("introspection: %v", err)
+	}
+	ac.OIDC.UserInfoClaims = []string{"email"}
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil || !strings.Contains(err.Error(), "userinfo") {
+		t.Fatalf("expected missing userinfo endpoint error, got %v", err)
+	}
+	ac.OIDC.UserInfoClaims = nil
+
+	ac.OIDC.Issuers = []config.OIDCIssuerConfig{{
+		Name: "keycloak", IssuerURL: oidctest.New(t).URL, ClientID: "api://kamini",
+		IntrospectionClientID: "kamini", IntrospectionClientSecretFile: filepath.Join(t.TempDir(), "missing"),
+	}}
+	if _, err := NewAuthenticator(ctx, ac, nil, ilog.NewNop()); err == nil || !strings.Contains(err.Error(), "keycloak") {
+		t.Fatalf("expected unreadable secret error for the issuer, got %v", err)
+	}
+}
+
+func TestNewSAML(t *testing.T) {
+	ctx := context.Background()
+	ac := config.DEFAULT_CONFIG.Auth
+	if sp, err := NewSAML(ctx, ac.SAML, ilog.NewNop()); sp != nil || err != nil {
+		t.Fatalf("disabled: %v %v", sp, err)
+	}
+	ac.SAML.Enabled = true
+	ac.SAML.RootURL = "https://kamini.example.com"
+	ac.SAML.IdPMetadataFile = filepath.Join(t.TempDir(), "idp.xml")
+	if _, err := NewSAML(ctx, ac.SAML, ilog.NewNop()); err == nil || !strings.Contains(err.Error(), "idp metadata") {
+		t.Fatalf("expected unreadable metadata error, got %v", err)
+	}
+	if err := os.WriteFile(ac.SAML.IdPMetadataFile, idpMetadata(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sp, err := NewSAML(ctx, ac.SAML, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewSAML: %v", err)
+	}
+
+	// SAML alone, or beside the OIDC or dev authenticator.
+	a, err := NewAuthenticator(ctx, ac, sp, ilog.NewNop())
+	if err != nil || a != sp {
+		t.Fatalf("saml only: %T %v", a, err)
+	}
+	ac.OIDC.IssuerURL, ac.OIDC.ClientID = oidctest.New(t).URL, "kamini"
+	a, err = NewAuthenticator(ctx, ac, sp, ilog.NewNop())
+	if r, ok := a.(auth.IssuerRouter); err != nil || !ok || r.Routes[auth.SAMLSessionIssuer] != sp {
+		t.Fatalf("saml and oidc: %T %v", a, err)
+	}
+	ac.Mode = "dev"
+	ac.Dev.KeyPath = filepath.Join(t.TempDir(), "dev.key")
+	if a, err := NewAuthenticator(ctx, ac, sp, ilog.NewNop()); err != nil {
+		t.Fatalf("saml and dev: %T %v", a, err)
+	} else if _, ok := a.(auth.IssuerRouter).Default.(*auth.DevAuthenticator); !ok {
+		t.Fatalf("saml and dev: default %T", a.(auth.IssuerRouter).Default)
+	}
+}
+
+// idpMetadata is the metadata of an IdP with a throwaway signing key.
+func idpMetadata(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	idp := saml.IdentityProvider{
+		Key:         key,
+		Certificate: cert,
+		MetadataURL: url.URL{Scheme: "https", Host: "idp.example.com", Path: "/metadata"},
+		SSOURL:      url.URL{Scheme: "https", Host: "idp.example.com", Path: "/sso"},
+	}
+	buf, err := xml.Marshal(idp.Metadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
@@ -0,0 +1,51 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"github.com/haukened/kamini/internal/adapters/authorize"
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// NewAuthorizer builds the role/group authorizer, including per-principal
+// authentication requirements and CI workload rules, from the authorize section.
+func NewAuthorizer(cfg config.AuthorizeConfig) (*authorize.OIDCAuthorizer, error) {
+	reqs := make([]domain.AuthRequirement, 0, len(cfg.Principal.Requirements))
+	for _, r := range cfg.Principal.Requirements {
+		reqs = append(reqs, domain.AuthRequirement{
+			Principals: r.Principals,
+			AMR:        r.AMR,
+			ACR:        r.ACR,
+			MaxAge:     r.MaxAge,
+		})
+	}
+	workloads := make([]domain.WorkloadRule, 0, len(cfg.Workloads))
+	for i, w := range cfg.Workloads {
+		r := domain.WorkloadRule{
+			Platform:    w.Platform,
+			Repository:  w.Repository,
+			Ref:         w.Ref,
+			Environment: w.Environment,
+			Workflow:    w.Workflow,
+			Principals:  w.Principals,
+			TTL:         w.TTL,
+		}
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("authorize.workloads[%d]: %w", i, err)
+		}
+		workloads = append(workloads, r)
+	}
+	return authorize.NewOIDCAuthorizer(authorize.OIDCAuthorizerConfig{
+		AllowRoles:   cfg.Allow.Roles,
+		AllowGroups:  cfg.Allow.Groups,
+		Principals:   cfg.Principal.Templates,
+		DefaultTTL:   cfg.Default.TTL,
+		MaxTTL:       cfg.Max.TTL,
+		SourceCIDRs:  cfg.Source.CIDRs,
+		AdminRoles:   cfg.Admin.Roles,
+		AdminGroups:  cfg.Admin.Groups,
+		Requirements: reqs,
+		Workloads:    workloads,
+	}), nil
+}
@@ -0,0 +1,54 @@
+package bootstrap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func TestNewAuthorizer(t *testing.T) {
+	cfg := config.DEFAULT_CONFIG.Authorize
+	cfg.Allow.Roles = []string{"dev"}
+	cfg.Principal.Templates = []string{"prod-admin"}
+	cfg.Principal.Requirements = []config.AuthorizeRequirement{{Principals: []string{"prod-admin"}, AMR: []string{"mfa"}}}
+	a, err := NewAuthorizer(cfg)
+	if err != nil {
+		t.Fatalf("NewAuthorizer: %v", err)
+	}
+
+	now := time.Unix(1_700_000_000, 0)
+	id := domain.Identity{Subject: "s", Username: "alice", Roles: []string{"dev"}, Claims: map[string]any{"amr": []string{"pwd"}}}
+	_, err = a.Decide(id, domain.SignContext{Now: now})
+	var pd domain.PolicyDeny
+	if !errors.As(err, &pd) || pd.Code != domain.DenyAuthStrength {
+		t.Fatalf("expected DenyAuthStrength, got %v", err)
+	}
+	id.Claims["amr"] = []string{"pwd", "mfa"}
+	dec, err := a.Decide(id, domain.SignContext{Now: now})
+	if err != nil || len(dec.Principals) != 1 || dec.TTL != time.Hour {
+		t.Fatalf("Decide: %+v, %v", dec, err)
+	}
+}
+
+func TestNewAuthorizer_Workloads(t *testing.T) {
+	cfg := config.DEFAULT_CONFIG.Authorize
+	cfg.Workloads = []config.AuthorizeWorkload{{Platform: "github-actions", Repository: "org/app", Ref: "refs/heads/main", Principals: []string{"deploy"}, TTL: 10 * time.Minute}}
+	a, err := NewAuthorizer(cfg)
+	if err != nil {
+		t.Fatalf("NewAuthorizer: %v", err)
+	}
+	w := domain.Workload{Platform: "github-actions", Repository: "org/app", Ref: "refs/heads/main"}
+	dec, err := a.Decide(domain.Identity{Subject: "repo:org/app", Claims: map[string]any{"workload": w}}, domain.SignContext{})
+	if err != nil || len(dec.Principals) != 1 || dec.Principals[0] != "deploy" || dec.TTL != 10*time.Minute {
+		t.Fatalf("Decide: %+v, %v", dec, err)
+	}
+
+	cfg.Workloads = append(cfg.Workloads, config.AuthorizeWorkload{Ref: "refs/heads/main", Principals: []string{"deploy"}})
+	if _, err := NewAuthorizer(cfg); err == nil || !strings.Contains(err.Error(), "workloads[1]") {
+		t.Fatalf("expected error for a rule without repository, got %v", err)
+	}
+}
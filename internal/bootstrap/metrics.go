@@ -0,0 +1,28 @@
+package bootstrap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/haukened/kamini/internal/adapters/metrics"
+	"github.com/haukened/kamini/internal/config"
+)
+
+// NewMetrics creates the Prometheus collectors, or returns nil when
+// server.metrics is disabled. Wrap the issuance ports and the audit sink with
+// the result and pass it to NewAuditSink as the fan-out observer either way: a
+// nil *metrics.Metrics passes everything through unchanged.
+func NewMetrics(cfg config.Root) (*metrics.Metrics, error) {
+	if !cfg.Server.Metrics.Enabled {
+		return nil, nil
+	}
+	m := metrics.New()
+	if cfg.Signer.CA.RotateAt != "" {
+		at, err := time.Parse(time.RFC3339, cfg.Signer.CA.RotateAt)
+		if err != nil {
+			return nil, fmt.Errorf("signer.ca.rotate_at: %w", err)
+		}
+		m.SetCAKeyRotation(at)
+	}
+	return m, nil
+}
@@ -0,0 +1,111 @@
+package bootstrap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/domain"
+	ilog "github.com/haukened/kamini/internal/log"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+type stubAuth struct{}
+
+func (stubAuth) Authenticate(context.Context, string) (domain.Identity, error) {
+	return domain.Identity{Subject: "sub", Username: "alice"}, nil
+}
+
+type stubAuthz struct{}
+
+func (stubAuthz) Decide(domain.Identity, domain.SignContext) (domain.PolicyDecision, error) {
+	return domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}, nil
+}
+
+type stubSeq struct{}
+
+func (stubSeq) Next(context.Context) (uint64, error) { return 7, nil }
+
+type stubSigner struct{}
+
+func (stubSigner) Sign(domain.CertSpec, uint64) ([]byte, string, error) {
+	return []byte("cert"), "SHA256:ca", nil
+}
+
+// signUserService wires a SignUserService the way the server does: every port
+// wrapped by NewMetrics(cfg), the fan-out observed by it, and spans sent to tr.
+func signUserService(t *testing.T, cfg config.Root, tr usecase.Tracer) *usecase.SignUserService {
+	t.Helper()
+	m, err := NewMetrics(cfg)
+	if err != nil {
+		t.Fatalf("NewMetrics: %v", err)
+	}
+	fan, err := NewAuditSink(context.Background(), config.AuditConfig{Sink: "stdout"}, nil, nil, m, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuditSink: %v", err)
+	}
+	t.Cleanup(func() { _ = fan.Close() })
+	return usecase.NewSignUserService(usecase.SignUserService{
+		Log:    ilog.NewNop(),
+		Auth:   m.Authenticator(stubAuth{}),
+		Authz:  m.Authorizer(stubAuthz{}),
+		Seq:    m.SerialStore(stubSeq{}),
+		Signer: m.Signer(stubSigner{}),
+		Audit:  m.AuditSink(fan),
+		Clock:  domain.SystemClock(),
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+		Trace:  tr,
+	})
+}
+
+func TestNewMetrics(t *testing.T) {
+	cfg := config.DEFAULT_CONFIG
+	cfg.Server.Metrics.Enabled = false
+	m, err := NewMetrics(cfg)
+	if m != nil || err != nil {
+		t.Fatalf("disabled: got %v, %v", m, err)
+	}
+	if h := m.Handler(); h != nil {
+		t.Fatalf("disabled metrics must not serve /metrics")
+	}
+	// Disabled metrics are wired like enabled ones and must pass everything through.
+	out, err := signUserService(t, cfg, nil).Execute(context.Background(), usecase.SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"})
+	if err != nil || out.Serial != 7 {
+		t.Fatalf("disabled metrics: Execute = %+v, %v", out, err)
+	}
+
+	cfg.Server.Metrics.Enabled = true
+	cfg.Signer.CA.RotateAt = "next tuesday"
+	if _, err := NewMetrics(cfg); err == nil {
+		t.Fatalf("expected rotate_at parse error")
+	}
+
+	cfg.Signer.CA.RotateAt = "2030-01-01T00:00:00Z"
+	m, err = NewMetrics(cfg)
+	if err != nil {
+		t.Fatalf("NewMetrics: %v", err)
+	}
+	// The metrics double as the fan-out observer.
+	sink, err := NewAuditSink(context.Background(), config.AuditConfig{Sink: "stdout"}, nil, nil, m, ilog.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuditSink: %v", err)
+	}
+	defer sink.Close()
+	if err := m.AuditSink(sink).Write(context.Background(), failureEvent()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	found := map[string]bool{}
+	mfs, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		found[mf.GetName()] = true
+	}
+	for _, name := range []string{"kamini_ca_key_rotation_seconds", "kamini_audit_writes_total", "kamini_issuance_failures_total"} {
+		if !found[name] {
+			t.Fatalf("%s not exported", name)
+		}
+	}
+}
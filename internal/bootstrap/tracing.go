@@ -0,0 +1,36 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/haukened/kamini/internal/adapters/tracing"
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+// NewTracing creates the OpenTelemetry tracer exporting over OTLP/HTTP. Pass t
+// as the usecases' Trace and middleware as httpapi.Server.Trace; both are nil
+// when server.tracing is disabled, which those fields take to mean no tracing.
+// Call shutdown on exit to flush pending spans (it is a no-op when disabled).
+func NewTracing(ctx context.Context, cfg config.TracingConfig) (t usecase.Tracer, middleware func(http.Handler) http.Handler, shutdown func(context.Context) error, err error) {
+	shutdown = func(context.Context) error { return nil }
+	if !cfg.Enabled {
+		return nil, nil, shutdown, nil
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, nil, shutdown, fmt.Errorf("server.tracing.sample_ratio: %v is outside 0..1", cfg.SampleRatio)
+	}
+	tp, err := tracing.NewProvider(ctx, tracing.Config{
+		Endpoint:    cfg.Endpoint,
+		Insecure:    cfg.Insecure,
+		ServiceName: cfg.ServiceName,
+		SampleRatio: cfg.SampleRatio,
+	})
+	if err != nil {
+		return nil, nil, shutdown, fmt.Errorf("tracing: %w", err)
+	}
+	tr := tracing.New(tp)
+	return tr, tr.Middleware, tp.Shutdown, nil
+}
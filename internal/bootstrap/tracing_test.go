@@ -0,0 +1,47 @@
+package bootstrap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/haukened/kamini/internal/adapters/httpapi"
+	"github.com/haukened/kamini/internal/config"
+	"github.com/haukened/kamini/internal/usecase"
+)
+
+func TestNewTracing(t *testing.T) {
+	cfg := config.DEFAULT_CONFIG.Server.Tracing
+	tr, mw, shutdown, err := NewTracing(context.Background(), cfg)
+	if tr != nil || mw != nil || err != nil || shutdown(context.Background()) != nil {
+		t.Fatalf("disabled: got %v, %v", tr, err)
+	}
+	// Disabled tracing is wired like enabled tracing and must not get in the way.
+	svc := signUserService(t, config.DEFAULT_CONFIG, tr)
+	if _, err := svc.Execute(context.Background(), usecase.SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"}); err != nil {
+		t.Fatalf("disabled tracing: Execute: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	httpapi.New(httpapi.Server{Trace: mw}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("disabled tracing: healthz = %d", rec.Code)
+	}
+
+	cfg.Enabled = true
+	cfg.SampleRatio = 1.5
+	if _, _, _, err := NewTracing(context.Background(), cfg); err == nil {
+		t.Fatalf("expected sample_ratio error")
+	}
+
+	cfg.SampleRatio = 0.5
+	cfg.Endpoint = "127.0.0.1:4318"
+	cfg.Insecure = true
+	tr, mw, shutdown, err = NewTracing(context.Background(), cfg)
+	if err != nil || tr == nil || mw == nil {
+		t.Fatalf("NewTracing: %v, %v", tr, err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
@@ -26,6 +26,31 @@ type Root struct {
 type ServerConfig struct {
 	Addr    string        `koanf:"addr"`
 	Request ServerRequest `koanf:"request"`
+	UI      UIConfig      `koanf:"ui"`
+	Metrics MetricsConfig `koanf:"metrics"`
+	Tracing TracingConfig `koanf:"tracing"`
+}
+
+// TracingConfig controls OpenTelemetry span export over OTLP/HTTP.
+type TracingConfig struct {
+	Enabled     bool    `koanf:"enabled"`
+	Endpoint    string  `koanf:"endpoint"`     // collector host:port; empty uses OTEL_EXPORTER_OTLP_* env
+	Insecure    bool    `koanf:"insecure"`     // plain HTTP to the collector
+	ServiceName string  `koanf:"service_name"` // reported as service.name
+	SampleRatio float64 `koanf:"sample_ratio"` // fraction of new traces sampled (0..1)
+}
+
+// MetricsConfig controls the Prometheus endpoint at /metrics. It is off by
+// default because the endpoint is unauthenticated.
+type MetricsConfig struct {
+	Enabled bool `koanf:"enabled"`
+}
+
+// UIConfig controls the read-only admin web UI served under /ui/.
+type UIConfig struct {
+	Enabled bool          `koanf:"enabled"`
+	Window  time.Duration `koanf:"window"` // denials are grouped over this period
+	Recent  int           `koanf:"recent"` // issuances listed
 }
 
 type ServerRequest struct {
@@ -38,7 +63,56 @@ type LogConfig struct {
 }
 
 type AuthConfig struct {
-	OIDC OIDCConfig `koanf:"oidc"`
+	// Mode selects the authenticator: "oidc" (default) or "dev", which accepts
+	// tokens from `kamini-server dev token` signed with auth.dev.key_path and
+	// must never be used in production.
+	Mode  string        `koanf:"mode"`
+	OIDC  OIDCConfig    `koanf:"oidc"`
+	Graph GraphConfig   `koanf:"graph"`
+	Dev   DevAuthConfig `koanf:"dev"`
+	SAML  SAMLConfig    `koanf:"saml"`
+}
+
+// DevAuthConfig configures auth.mode "dev".
+type DevAuthConfig struct {
+	KeyPath string `koanf:"key_path"` // ed25519 dev signing key; created if missing
+}
+
+// SAMLConfig enables browser logins through a SAML 2.0 IdP. The server then
+// serves /v1/saml/login, /v1/saml/acs and /v1/saml/metadata, and accepts the
+// short-lived session tokens handed to `kamini token` alongside auth.mode's
+// tokens (alone when no OIDC issuer is configured). Attributes are matched by
+// Name or FriendlyName and take the same mapping stages as OIDC claims.
+type SAMLConfig struct {
+	Enabled         bool          `koanf:"enabled"`
+	RootURL         string        `koanf:"root_url"`          // external base URL, e.g. https://kamini.example.com
+	EntityID        string        `koanf:"entity_id"`         // default: <root_url>/v1/saml/metadata
+	IdPMetadataFile string        `koanf:"idp_metadata_file"` // IdP metadata XML
+	IdPMetadata     string        `koanf:"idp_metadata"`      // inline alternative to idp_metadata_file
+	NameIDFormat    string        `koanf:"name_id_format"`    // persistent | email | unspecified; never transient
+	AttrUsername    string        `koanf:"attr_username"`
+	AttrEmail       string        `koanf:"attr_email"`
+	AttrRoles       string        `koanf:"attr_roles"`
+	AttrGroups      string        `koanf:"attr_groups"`
+	SessionKeyPath  string        `koanf:"session_key_path"` // ed25519, created if missing; share it between replicas
+	SessionTTL      time.Duration `koanf:"session_ttl"`      // session token lifetime
+	LoginTTL        time.Duration `koanf:"login_ttl"`        // time allowed at the IdP
+}
+
+// GraphConfig resolves Entra ID group membership through Microsoft Graph when a
+// token omits "groups" because of an overage. The app registration needs the
+// GroupMember.Read.All application permission.
+type GraphConfig struct {
+	Enabled             bool          `koanf:"enabled"`
+	TenantID            string        `koanf:"tenant_id"`     // used when a token carries no tid
+	ClientID            string        `koanf:"client_id"`     // client credentials of the Graph app
+	ClientSecret        string        `koanf:"client_secret"` // prefer client_secret_file
+	ClientSecretFile    string        `koanf:"client_secret_file"`
+	AuthorityURL        string        `koanf:"authority_url"` // default https://login.microsoftonline.com
+	GraphURL            string        `koanf:"graph_url"`     // default https://graph.microsoft.com/v1.0
+	SecurityEnabledOnly bool          `koanf:"security_enabled_only"`
+	CacheTTL            time.Duration `koanf:"cache_ttl"` // memberships reused per user for this long
+	Timeout             time.Duration `koanf:"timeout"`
 }
 
 type OIDCConfig struct {
@@ -46,19 +120,119 @@ type OIDCConfig struct {
 	ClientID          string        `koanf:"client_id"`
 	SkipClientIDCheck bool          `koanf:"skip_client_id_check"`
 	HTTPTimeout       time.Duration `koanf:"http_timeout"`
-	// Flattened claim key names to align with two-underscore mapping
+	// Flattened claim key names to align with two-underscore mapping. Values are
+	// claim specs: a name or path plus optional mapping stages (see auth.OIDCAuthConfig).
 	ClaimsUsername string `koanf:"claims_username"`
 	ClaimsEmail    string `koanf:"claims_email"`
 	ClaimsRoles    string `koanf:"claims_roles"`
 	ClaimsGroups   string `koanf:"claims_groups"`
+	// Multi-tenant issuers: issuer_url may contain {tenantid} (see auth.TenantPlaceholder);
+	// discovery_url then defaults to it with {tenantid} replaced by "common".
+	DiscoveryURL string `koanf:"discovery_url"`
+	// jwks_file or jwks (an inline JWKS document) verify with static keys
+	// instead of discovery (air-gapped IdPs, a cluster's /openid/v1/jwks); the
+	// keys are never refreshed.
+	JWKSFile string `koanf:"jwks_file"`
+	JWKS     string `koanf:"jwks"`
+	// LazyDiscovery starts the server while an issuer's discovery fails;
+	// tokens from it get AUTH_ISSUER_UNAVAILABLE and discovery is retried at
+	// most once per discovery_retry. Applies to every issuer.
+	LazyDiscovery  bool          `koanf:"lazy_discovery"`
+	DiscoveryRetry time.Duration `koanf:"discovery_retry"`
+	// Required claims; empty lists skip the check.
+	TenantIDs         []string `koanf:"tenant_ids"`         // allowed tid values
+	AuthorizedParties []string `koanf:"authorized_parties"` // allowed azp (or appid) values
+	RequiredAMR       []string `koanf:"required_amr"`       // amr must contain all, e.g. ["mfa"]
+	AllowedACR        []string `koanf:"allowed_acr"`        // acr must be one of these
+	// Access tokens: access_tokens verifies RFC 9068 JWT access tokens (typ
+	// at+jwt) instead of ID tokens, with client_id/audiences naming Kamini's API.
+	// Setting introspection_client_id validates opaque tokens via RFC 7662;
+	// introspection_url defaults to the discovered introspection_endpoint.
+	AccessTokens                  bool          `koanf:"access_tokens"`
+	IntrospectionURL              string        `koanf:"introspection_url"`
+	IntrospectionClientID         string        `koanf:"introspection_client_id"`
+	IntrospectionClientSecret     string        `koanf:"introspection_client_secret"` // prefer introspection_client_secret_file
+	IntrospectionClientSecretFile string        `koanf:"introspection_client_secret_file"`
+	IntrospectionCacheTTL         time.Duration `koanf:"introspection_cache_ttl"` // active responses reused up to this long
+	// Listing userinfo_claims fills those claims, when a token lacks them, from
+	// the userinfo endpoint (needs access tokens); responses are cached per subject.
+	UserInfoClaims   []string      `koanf:"userinfo_claims"`
+	UserInfoCacheTTL time.Duration `koanf:"userinfo_cache_ttl"`
+	// Profile maps CI job or Kubernetes ServiceAccount tokens to workload
+	// identities: github-actions or gitlab-ci (issuer_url then defaults to the
+	// platform's issuer), or kubernetes (issuer_url and an audience required).
+	Profile string `koanf:"profile"`
+	// Issuers trusts further issuers, each with its own client ID, audiences
+	// and claim mappings (YAML only). A token picks its verifier by "iss"; the
+	// top-level issuer_url, when set, is trusted alongside them.
+	Issuers []OIDCIssuerConfig `koanf:"issuers"`
+}
+
+// OIDCIssuerConfig is one trusted issuer. Empty claim names fall back to the
+// top-level auth.oidc claims_* settings; required claims do not, since tenants
+// and client IDs differ per issuer.
+type OIDCIssuerConfig struct {
+	Name              string   `koanf:"name"` // labels identities and audit events, e.g. "entra"
+	IssuerURL         string   `koanf:"issuer_url"`
+	ClientID          string   `koanf:"client_id"`
+	Audiences         []string `koanf:"audiences"` // accepted in addition to client_id
+	SkipClientIDCheck bool     `koanf:"skip_client_id_check"`
+	ClaimsUsername    string   `koanf:"claims_username"`
+	ClaimsEmail       string   `koanf:"claims_email"`
+	ClaimsRoles       string   `koanf:"claims_roles"`
+	ClaimsGroups      string   `koanf:"claims_groups"`
+	DiscoveryURL      string   `koanf:"discovery_url"`
+	JWKSFile          string   `koanf:"jwks_file"`
+	JWKS              string   `koanf:"jwks"`
+	TenantIDs         []string `koanf:"tenant_ids"`
+	AuthorizedParties []string `koanf:"authorized_parties"`
+	RequiredAMR       []string `koanf:"required_amr"`
+	AllowedACR        []string `koanf:"allowed_acr"`
+	// Access tokens and introspection, as for the top-level issuer (at most one
+	// issuer may introspect opaque tokens).
+	AccessTokens                  bool          `koanf:"access_tokens"`
+	IntrospectionURL              string        `koanf:"introspection_url"`
+	IntrospectionClientID         string        `koanf:"introspection_client_id"`
+	IntrospectionClientSecret     string        `koanf:"introspection_client_secret"`
+	IntrospectionClientSecretFile string        `koanf:"introspection_client_secret_file"`
+	IntrospectionCacheTTL         time.Duration `koanf:"introspection_cache_ttl"`
+	UserInfoClaims                []string      `koanf:"userinfo_claims"`
+	UserInfoCacheTTL              time.Duration `koanf:"userinfo_cache_ttl"`
+	Profile                       string        `koanf:"profile"` // github-actions | gitlab-ci | kubernetes
 }
 
 type AuthorizeConfig struct {
-	Allow     AuthorizeAllow     `koanf:"allow"`
-	Principal AuthorizePrincipal `koanf:"principal"`
-	Source    AuthorizeSource    `koanf:"source"`
-	Default   AuthorizeTTL       `koanf:"default"`
-	Max       AuthorizeTTL       `koanf:"max"`
+	Allow     AuthorizeAllow      `koanf:"allow"`
+	Admin     AuthorizeAllow      `koanf:"admin"`
+	Principal AuthorizePrincipal  `koanf:"principal"`
+	Source    AuthorizeSource     `koanf:"source"`
+	Default   AuthorizeTTL        `koanf:"default"`
+	Max       AuthorizeTTL        `koanf:"max"`
+	Renewal   AuthorizeRenewal    `koanf:"renewal"`
+	Workloads []AuthorizeWorkload `koanf:"workloads"` // YAML only
+}
+
+// AuthorizeRenewal controls POST /v1/certs/user/renew, where a valid
+// certificate is exchanged for a new one without the IdP. Policy is
+// re-evaluated on every renewal.
+type AuthorizeRenewal struct {
+	// MaxSession bounds renewal (and certificate lifetime) from the original
+	// IdP login; 0 disables renewal.
+	MaxSession time.Duration `koanf:"max_session"`
+}
+
+// AuthorizeWorkload grants principals to CI jobs and pods authenticated
+// through an issuer with a profile. Platform and repository are required;
+// platform is matched exactly, the other fields are glob patterns
+// ("refs/tags/v*") and match anything when empty.
+type AuthorizeWorkload struct {
+	Platform    string        `koanf:"platform"`   // github-actions | gitlab-ci | kubernetes
+	Repository  string        `koanf:"repository"` // kubernetes: namespace/serviceaccount
+	Ref         string        `koanf:"ref"`        // full ref, e.g. refs/heads/main
+	Environment string        `koanf:"environment"`
+	Workflow    string        `koanf:"workflow"` // e.g. .github/workflows/deploy.yml
+	Principals  []string      `koanf:"principals"`
+	TTL         time.Duration `koanf:"ttl"` // certificate lifetime cap; 0 = authorize.max.ttl
 }
 
 type AuthorizeAllow struct {
@@ -67,7 +241,17 @@ type AuthorizeAllow struct {
 }
 
 type AuthorizePrincipal struct {
-	Templates []string `koanf:"templates"`
+	Templates    []string               `koanf:"templates"`
+	Requirements []AuthorizeRequirement `koanf:"requirements"` // YAML only
+}
+
+// AuthorizeRequirement gates principals on how the user authenticated; a
+// principal whose requirement is unmet is left out of the certificate.
+type AuthorizeRequirement struct {
+	Principals []string      `koanf:"principals"`
+	AMR        []string      `koanf:"amr"`     // any of these methods, e.g. ["mfa", "hwk"]
+	ACR        []string      `koanf:"acr"`     // acr must be one of these
+	MaxAge     time.Duration `koanf:"max_age"` // auth_time no older than this
 }
 
 type AuthorizeSource struct {
@@ -83,7 +267,8 @@ type SignerConfig struct {
 }
 
 type SignerCA struct {
-	KeyPath string `koanf:"key_path"`
+	KeyPath  string `koanf:"key_path"`
+	RotateAt string `koanf:"rotate_at"` // RFC 3339 time the key is due to be replaced; exported as a metric
 }
 
 type CAKeyConfig struct {
@@ -91,15 +276,97 @@ type CAKeyConfig struct {
 }
 
 type StorageConfig struct {
-	Serial SerialConfig `koanf:"serial"`
+	Serial    SerialConfig    `koanf:"serial"`
+	Certs     CertsConfig     `koanf:"certs"`
+	Blocklist BlocklistConfig `koanf:"blocklist"`
 }
 
 type SerialConfig struct {
 	FilePath string `koanf:"file_path"`
 }
 
+type CertsConfig struct {
+	FilePath string `koanf:"file_path"`
+}
+
+type BlocklistConfig struct {
+	FilePath string `koanf:"file_path"`
+}
+
 type AuditConfig struct {
-	Sink string `koanf:"sink"`
+	Sink    string             `koanf:"sink"`  // stdout | file | chain | syslog | webhook | sql
+	Sinks   []string           `koanf:"sinks"` // several of the above; overrides sink
+	Store   AuditStoreConfig   `koanf:"store"`
+	Fanout  AuditFanoutConfig  `koanf:"fanout"`
+	File    AuditFileConfig    `koanf:"file"`
+	Chain   AuditChainConfig   `koanf:"chain"`
+	Syslog  AuditSyslogConfig  `koanf:"syslog"`
+	Webhook AuditWebhookConfig `koanf:"webhook"`
+}
+
+// AuditStoreConfig configures the queryable audit database behind GET /v1/audit;
+// the sql sink writes to it.
+type AuditStoreConfig struct {
+	Driver string `koanf:"driver"` // sqlite | postgres; empty disables the store
+	DSN    string `koanf:"dsn"`    // sqlite file path or postgres connection URL
+}
+
+// AuditFanoutConfig sets the failure policy applied across audit sinks.
+type AuditFanoutConfig struct {
+	Policy           string        `koanf:"policy"`             // fail_closed (refuse issuance) | fail_open (queue and retry)
+	Required         []string      `koanf:"required"`           // sinks that must succeed under fail_closed; empty = all
+	QueueSize        int           `koanf:"queue_size"`         // per-sink retry queue
+	RetryInterval    time.Duration `koanf:"retry_interval"`     // first retry delay
+	MaxRetryInterval time.Duration `koanf:"max_retry_interval"` // backoff cap
+}
+
+// AuditChainConfig configures the hash-chained audit log (written to audit.file.*).
+type AuditChainConfig struct {
+	CheckpointEvery int `koanf:"checkpoint_every"` // CA-signed checkpoint every N events; 0 = only on shutdown
+}
+
+// AuditSyslogConfig configures the RFC 5424 syslog audit sink.
+type AuditSyslogConfig struct {
+	Network       string `koanf:"network"`         // udp | tcp | tls
+	Address       string `koanf:"address"`         // host:port of the collector
+	Facility      string `koanf:"facility"`        // syslog facility name (e.g. authpriv, local0)
+	Hostname      string `koanf:"hostname"`        // HOSTNAME field; default os.Hostname()
+	AppName       string `koanf:"app_name"`        // APP-NAME field
+	SDID          string `koanf:"sd_id"`           // structured data element ID (name@enterprise-number)
+	QueueSize     int    `koanf:"queue_size"`      // messages buffered while the collector is unreachable
+	TLSCAFile     string `koanf:"tls_ca_file"`     // PEM roots for network=tls; default system roots
+	TLSServerName string `koanf:"tls_server_name"` // default host from address
+	TLSCertFile   string `koanf:"tls_cert_file"`   // client certificate for mutual TLS
+	TLSKeyFile    string `koanf:"tls_key_file"`
+}
+
+// AuditWebhookConfig configures the HTTP webhook audit sink.
+type AuditWebhookConfig struct {
+	URLs             []string      `koanf:"urls"`               // every batch is POSTed to each URL
+	Secret           string        `koanf:"secret"`             // HMAC-SHA256 key; prefer secret_file
+	SecretFile       string        `koanf:"secret_file"`        // file holding the key (surrounding whitespace trimmed)
+	Actions          []string      `koanf:"actions"`            // forward only these actions (e.g. ISSUE_USER_CERT, DENY); empty = all
+	Outcome          string        `koanf:"outcome"`            // success | failure; empty = all
+	BatchSize        int           `koanf:"batch_size"`         // events per request
+	FlushInterval    time.Duration `koanf:"flush_interval"`     // longest wait before a partial batch is sent
+	QueueSize        int           `koanf:"queue_size"`         // batches held in memory per URL
+	Timeout          time.Duration `koanf:"timeout"`            // per request
+	MaxAttempts      int           `koanf:"max_attempts"`       // attempts before a batch is spooled to disk
+	RetryInterval    time.Duration `koanf:"retry_interval"`     // first retry delay
+	MaxRetryInterval time.Duration `koanf:"max_retry_interval"` // backoff cap
+	SpoolDir         string        `koanf:"spool_dir"`          // undelivered batches survive restarts here; empty = memory only
+	SpoolMaxMB       int           `koanf:"spool_max_mb"`       // per URL; 0 = unlimited
+}
+
+// AuditFileConfig configures the JSON-lines audit file sink.
+type AuditFileConfig struct {
+	Path          string        `koanf:"path"`
+	Fsync         string        `koanf:"fsync"`          // always | interval | never
+	FsyncInterval time.Duration `koanf:"fsync_interval"` // for fsync=interval
+	MaxSizeMB     int           `koanf:"max_size_mb"`    // rotate by size; 0 disables
+	RotateEvery   time.Duration `koanf:"rotate_every"`   // rotate by time (e.g. 24h); 0 disables
+	MaxBackups    int           `koanf:"max_backups"`    // rotated files to keep; 0 keeps all
+	MaxAge        time.Duration `koanf:"max_age"`        // delete rotated files older than this; 0 keeps all
 }
 
 // Defaults returns an opinionated default configuration.
@@ -109,20 +376,59 @@ var DEFAULT_CONFIG = Root{
 		Request: ServerRequest{
 			Timeout: 15 * time.Second,
 		},
+		UI:      UIConfig{Enabled: true, Window: 24 * time.Hour, Recent: 50},
+		Metrics: MetricsConfig{Enabled: false},
+		Tracing: TracingConfig{ServiceName: "kamini", SampleRatio: 1},
 	},
 	Log: LogConfig{Level: "info", Format: "json"},
-	Auth: AuthConfig{OIDC: OIDCConfig{
-		HTTPTimeout:    10 * time.Second,
-		ClaimsUsername: "preferred_username",
-		ClaimsEmail:    "email",
-		ClaimsRoles:    "roles",
-		ClaimsGroups:   "groups",
-	}},
+	Auth: AuthConfig{
+		Mode: "oidc",
+		Dev:  DevAuthConfig{KeyPath: "kamini-dev.key"},
+		OIDC: OIDCConfig{
+			HTTPTimeout:           10 * time.Second,
+			DiscoveryRetry:        30 * time.Second,
+			IntrospectionCacheTTL: time.Minute,
+			UserInfoCacheTTL:      time.Minute,
+			ClaimsUsername:        "preferred_username",
+			ClaimsEmail:           "email",
+			ClaimsRoles:           "roles",
+			ClaimsGroups:          "groups",
+		},
+		Graph: GraphConfig{CacheTTL: 10 * time.Minute, Timeout: 10 * time.Second},
+		SAML: SAMLConfig{
+			AttrUsername: "uid",
+			AttrEmail:    "mail",
+			AttrRoles:    "roles",
+			AttrGroups:   "groups",
+			SessionTTL:   5 * time.Minute,
+			LoginTTL:     10 * time.Minute,
+		},
+	},
 	Authorize: AuthorizeConfig{
 		Default: AuthorizeTTL{TTL: 1 * time.Hour},
 		Max:     AuthorizeTTL{TTL: 8 * time.Hour},
 	},
-	Audit: AuditConfig{Sink: "stdout"},
+	Audit: AuditConfig{
+		Sink: "stdout",
+		Fanout: AuditFanoutConfig{
+			Policy:           "fail_closed",
+			QueueSize:        1000,
+			RetryInterval:    time.Second,
+			MaxRetryInterval: time.Minute,
+		},
+		File:   AuditFileConfig{Fsync: "always", FsyncInterval: time.Second},
+		Chain:  AuditChainConfig{CheckpointEvery: 1000},
+		Syslog: AuditSyslogConfig{Network: "tcp", Facility: "authpriv", AppName: "kamini", QueueSize: 1000},
+		Webhook: AuditWebhookConfig{
+			BatchSize:        100,
+			FlushInterval:    time.Second,
+			QueueSize:        100,
+			Timeout:          10 * time.Second,
+			MaxAttempts:      3,
+			RetryInterval:    time.Second,
+			MaxRetryInterval: time.Minute,
+		},
+	},
 }
 
 // Load loads configuration from defaults, then optional YAML file, then env overrides.
@@ -188,16 +494,45 @@ var envLoader = func(k *koanf.Koanf) error {
 	listKeys := map[string]struct{}{
 		"authorize.allow.roles":         {},
 		"authorize.allow.groups":        {},
+		"authorize.admin.roles":         {},
+		"authorize.admin.groups":        {},
 		"authorize.principal.templates": {},
 		"authorize.source.cidrs":        {},
+		"audit.sinks":                   {},
+		"audit.fanout.required":         {},
+		"audit.webhook.urls":            {},
+		"audit.webhook.actions":         {},
+		"auth.oidc.tenant_ids":          {},
+		"auth.oidc.authorized_parties":  {},
+		"auth.oidc.required_amr":        {},
+		"auth.oidc.allowed_acr":         {},
+		"auth.oidc.userinfo_claims":     {},
 	}
 
 	// Keys whose values are durations (parsed via time.ParseDuration when sourced from env)
 	durationKeys := map[string]struct{}{
-		"server.request.timeout": {},
-		"auth.oidc.http_timeout": {},
-		"authorize.default.ttl":  {},
-		"authorize.max.ttl":      {},
+		"server.request.timeout":            {},
+		"server.ui.window":                  {},
+		"auth.oidc.http_timeout":            {},
+		"auth.oidc.discovery_retry":         {},
+		"auth.oidc.introspection_cache_ttl": {},
+		"auth.oidc.userinfo_cache_ttl":      {},
+		"auth.graph.cache_ttl":              {},
+		"auth.graph.timeout":                {},
+		"auth.saml.session_ttl":             {},
+		"auth.saml.login_ttl":               {},
+		"authorize.default.ttl":             {},
+		"authorize.max.ttl":                 {},
+		"authorize.renewal.max_session":     {},
+		"audit.file.fsync_interval":         {},
+		"audit.file.rotate_every":           {},
+		"audit.file.max_age":                {},
+		"audit.fanout.retry_interval":       {},
+		"audit.fanout.max_retry_interval":   {},
+		"audit.webhook.flush_interval":      {},
+		"audit.webhook.timeout":             {},
+		"audit.webhook.retry_interval":      {},
+		"audit.webhook.max_retry_interval":  {},
 	}
 
 	return k.Load(env.Provider(".", env.Opt{
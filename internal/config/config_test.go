@@ -69,6 +69,39 @@ authorize:
 	}
 }
 
+func TestLoad_EnvStaticKeysAndLazyDiscovery(t *testing.T) {
+	jwks := `{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`
+	t.Setenv("KAMINI_AUTH_OIDC_JWKS", jwks) // commas must not split it
+	t.Setenv("KAMINI_AUTH_OIDC_LAZY_DISCOVERY", "true")
+	t.Setenv("KAMINI_AUTH_OIDC_DISCOVERY_RETRY", "1m")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	o := cfg.Auth.OIDC
+	if o.JWKS != jwks || !o.LazyDiscovery || o.DiscoveryRetry != time.Minute {
+		t.Fatalf("jwks=%q lazy=%v retry=%v", o.JWKS, o.LazyDiscovery, o.DiscoveryRetry)
+	}
+}
+
+func TestLoad_EnvSAML(t *testing.T) {
+	t.Setenv("KAMINI_AUTH_SAML_ENABLED", "true")
+	t.Setenv("KAMINI_AUTH_SAML_ROOT_URL", "https://kamini.example.com")
+	t.Setenv("KAMINI_AUTH_SAML_ATTR_GROUPS", "memberOf | regex:^CN=([^,]+)")
+	t.Setenv("KAMINI_AUTH_SAML_SESSION_TTL", "2m")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	s := cfg.Auth.SAML
+	if !s.Enabled || s.RootURL != "https://kamini.example.com" || s.AttrGroups != "memberOf | regex:^CN=([^,]+)" ||
+		s.SessionTTL != 2*time.Minute || s.LoginTTL != 10*time.Minute || s.AttrUsername != "uid" {
+		t.Fatalf("saml=%+v", s)
+	}
+}
+
 func TestLoad_EnvOverridesAndParsing(t *testing.T) {
 	t.Setenv("KAMINI_SERVER_ADDR", ":9090")
 	t.Setenv("KAMINI_SERVER_REQUEST_TIMEOUT", "22s")               // duration
@@ -78,6 +111,7 @@ func TestLoad_EnvOverridesAndParsing(t *testing.T) {
 	t.Setenv("KAMINI_STORAGE_SERIAL_FILE_PATH", "/tmp/serial2.db") // nested prefix mapping
 	t.Setenv("KAMINI_LOG_LEVEL", "warn")                           // fallback mapping (single section)
 	t.Setenv("KAMINI_LOG_FORMAT", "json,extra")                    // not a list key; should remain string
+	t.Setenv("KAMINI_AUTHORIZE_RENEWAL_MAX_SESSION", "12h")        // nested duration
 
 	cfg, err := Load("")
 	if err != nil {
@@ -96,6 +130,9 @@ func TestLoad_EnvOverridesAndParsing(t *testing.T) {
 	if l := len(cfg.Authorize.Allow.Roles); l != 3 || cfg.Authorize.Allow.Roles[0] != "admin" || cfg.Authorize.Allow.Roles[1] != "ops" || cfg.Authorize.Allow.Roles[2] != "dev" {
 		t.Fatalf("Authorize.Allow.Roles = %+v, want [admin ops dev]", cfg.Authorize.Allow.Roles)
 	}
+	if cfg.Authorize.Renewal.MaxSession != 12*time.Hour {
+		t.Fatalf("Authorize.Renewal.MaxSession = %v, want 12h", cfg.Authorize.Renewal.MaxSession)
+	}
 	if cfg.Signer.CA.KeyPath != "/tmp/dev_ca" {
 		t.Fatalf("Signer.CA.KeyPath = %q, want %q", cfg.Signer.CA.KeyPath, "/tmp/dev_ca")
 	}
@@ -172,3 +209,291 @@ func TestLoad_EnvError(t *testing.T) {
 		t.Fatalf("expected load env error, got %v", err)
 	}
 }
+
+func TestLoad_EnvAdminAndCertStore(t *testing.T) {
+	t.Setenv("KAMINI_AUTHORIZE_ADMIN_GROUPS", "ssh-admins, secops")
+	t.Setenv("KAMINI_STORAGE_CERTS_FILE_PATH", "/tmp/certs.json")
+	t.Setenv("KAMINI_STORAGE_BLOCKLIST_FILE_PATH", "/tmp/blocklist.json")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if g := cfg.Authorize.Admin.Groups; len(g) != 2 || g[0] != "ssh-admins" || g[1] != "secops" {
+		t.Fatalf("Authorize.Admin.Groups = %+v, want [ssh-admins secops]", g)
+	}
+	if cfg.Storage.Certs.FilePath != "/tmp/certs.json" {
+		t.Fatalf("Storage.Certs.FilePath = %q, want %q", cfg.Storage.Certs.FilePath, "/tmp/certs.json")
+	}
+	if cfg.Storage.Blocklist.FilePath != "/tmp/blocklist.json" {
+		t.Fatalf("Storage.Blocklist.FilePath = %q, want %q", cfg.Storage.Blocklist.FilePath, "/tmp/blocklist.json")
+	}
+}
+
+func TestLoad_EnvAuditFile(t *testing.T) {
+	t.Setenv("KAMINI_AUDIT_SINK", "file")
+	t.Setenv("KAMINI_AUDIT_FILE_PATH", "/var/log/kamini/audit.jsonl")
+	t.Setenv("KAMINI_AUDIT_FILE_MAX_SIZE_MB", "100")
+	t.Setenv("KAMINI_AUDIT_FILE_ROTATE_EVERY", "24h")
+	t.Setenv("KAMINI_AUDIT_FILE_MAX_AGE", "720h")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	f := cfg.Audit.File
+	if cfg.Audit.Sink != "file" || f.Path != "/var/log/kamini/audit.jsonl" || f.MaxSizeMB != 100 {
+		t.Fatalf("unexpected audit config: %+v", cfg.Audit)
+	}
+	if f.RotateEvery != 24*time.Hour || f.MaxAge != 720*time.Hour || f.Fsync != "always" {
+		t.Fatalf("unexpected audit file config: %+v", f)
+	}
+}
+
+func TestLoad_EnvAuditFanout(t *testing.T) {
+	t.Setenv("KAMINI_AUDIT_SINKS", "stdout,chain")
+	t.Setenv("KAMINI_AUDIT_FANOUT_POLICY", "fail_open")
+	t.Setenv("KAMINI_AUDIT_FANOUT_REQUIRED", "chain")
+	t.Setenv("KAMINI_AUDIT_FANOUT_QUEUE_SIZE", "50")
+	t.Setenv("KAMINI_AUDIT_FANOUT_MAX_RETRY_INTERVAL", "30s")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	a := cfg.Audit
+	if len(a.Sinks) != 2 || a.Sinks[1] != "chain" || a.Fanout.Policy != "fail_open" {
+		t.Fatalf("unexpected audit config: %+v", a)
+	}
+	if len(a.Fanout.Required) != 1 || a.Fanout.QueueSize != 50 || a.Fanout.MaxRetryInterval != 30*time.Second || a.Fanout.RetryInterval != time.Second {
+		t.Fatalf("unexpected fanout config: %+v", a.Fanout)
+	}
+}
+
+func TestLoad_EnvAuditSyslog(t *testing.T) {
+	t.Setenv("KAMINI_AUDIT_SINK", "syslog")
+	t.Setenv("KAMINI_AUDIT_SYSLOG_NETWORK", "tls")
+	t.Setenv("KAMINI_AUDIT_SYSLOG_ADDRESS", "siem.example.com:6514")
+	t.Setenv("KAMINI_AUDIT_SYSLOG_TLS_CA_FILE", "/etc/kamini/siem-ca.pem")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	s := cfg.Audit.Syslog
+	if cfg.Audit.Sink != "syslog" || s.Network != "tls" || s.Address != "siem.example.com:6514" || s.TLSCAFile != "/etc/kamini/siem-ca.pem" {
+		t.Fatalf("unexpected syslog config: %+v", s)
+	}
+	if s.Facility != "authpriv" || s.AppName != "kamini" || s.QueueSize != 1000 {
+		t.Fatalf("unexpected syslog defaults: %+v", s)
+	}
+}
+
+func TestLoad_EnvUI(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if ui := cfg.Server.UI; !ui.Enabled || ui.Window != 24*time.Hour || ui.Recent != 50 {
+		t.Fatalf("unexpected UI defaults: %+v", ui)
+	}
+	t.Setenv("KAMINI_SERVER_UI_ENABLED", "false")
+	t.Setenv("KAMINI_SERVER_UI_WINDOW", "168h")
+	if cfg, err = Load(""); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if ui := cfg.Server.UI; ui.Enabled || ui.Window != 168*time.Hour {
+		t.Fatalf("unexpected UI config: %+v", ui)
+	}
+}
+
+func TestLoad_EnvMetrics(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.Server.Metrics.Enabled {
+		t.Fatalf("/metrics is unauthenticated and must be off by default")
+	}
+	t.Setenv("KAMINI_SERVER_METRICS_ENABLED", "true")
+	t.Setenv("KAMINI_SIGNER_CA_ROTATE_AT", "2030-01-01T00:00:00Z")
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !cfg.Server.Metrics.Enabled || cfg.Signer.CA.RotateAt != "2030-01-01T00:00:00Z" {
+		t.Fatalf("unexpected metrics config: %+v / %+v", cfg.Server.Metrics, cfg.Signer.CA)
+	}
+}
+
+func TestLoad_FileOIDCIssuers(t *testing.T) {
+	fp := writeTempYAML(t, `
+auth:
+  oidc:
+    issuers:
+      - name: entra
+        issuer_url: "https://login.microsoftonline.com/tenant/v2.0"
+        client_id: "kamini"
+        audiences: ["api://kamini"]
+      - name: okta
+        issuer_url: "https://example.okta.com"
+        client_id: "0oa-kamini"
+        claims_username: "login"
+`)
+	cfg, err := Load(fp)
+	if err != nil {
+		t.Fatalf("Load(file) error: %v", err)
+	}
+	iss := cfg.Auth.OIDC.Issuers
+	if len(iss) != 2 || iss[0].Name != "entra" || len(iss[0].Audiences) != 1 || iss[1].ClaimsUsername != "login" {
+		t.Fatalf("unexpected issuers: %+v", iss)
+	}
+}
+
+func TestLoad_EnvOIDCRequiredClaims(t *testing.T) {
+	t.Setenv("KAMINI_AUTH_OIDC_ISSUER_URL", "https://login.microsoftonline.com/{tenantid}/v2.0")
+	t.Setenv("KAMINI_AUTH_OIDC_TENANT_IDS", "tenant-a, tenant-b")
+	t.Setenv("KAMINI_AUTH_OIDC_REQUIRED_AMR", "mfa")
+	t.Setenv("KAMINI_AUTH_OIDC_ALLOWED_ACR", "c2,c3")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	o := cfg.Auth.OIDC
+	if len(o.TenantIDs) != 2 || o.TenantIDs[1] != "tenant-b" || len(o.RequiredAMR) != 1 || len(o.AllowedACR) != 2 {
+		t.Fatalf("unexpected oidc config: %+v", o)
+	}
+}
+
+func TestLoad_FileAuthorizeRequirements(t *testing.T) {
+	fp := writeTempYAML(t, `
+authorize:
+  principal:
+    templates: ["{username}", "prod-admin"]
+    requirements:
+      - principals: ["prod-admin"]
+        amr: ["mfa", "hwk"]
+        max_age: 15m
+`)
+	cfg, err := Load(fp)
+	if err != nil {
+		t.Fatalf("Load(file) error: %v", err)
+	}
+	reqs := cfg.Authorize.Principal.Requirements
+	if len(reqs) != 1 || reqs[0].Principals[0] != "prod-admin" || len(reqs[0].AMR) != 2 || reqs[0].MaxAge != 15*time.Minute {
+		t.Fatalf("unexpected requirements: %+v", reqs)
+	}
+}
+
+func TestLoad_EnvTracing(t *testing.T) {
+	t.Setenv("KAMINI_SERVER_TRACING_ENABLED", "true")
+	t.Setenv("KAMINI_SERVER_TRACING_ENDPOINT", "otel-collector:4318")
+	t.Setenv("KAMINI_SERVER_TRACING_SAMPLE_RATIO", "0.25")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	tr := cfg.Server.Tracing
+	if !tr.Enabled || tr.Endpoint != "otel-collector:4318" || tr.SampleRatio != 0.25 || tr.ServiceName != "kamini" {
+		t.Fatalf("unexpected tracing config: %+v", tr)
+	}
+}
+
+func TestLoad_EnvAuditStore(t *testing.T) {
+	t.Setenv("KAMINI_AUDIT_SINKS", "file,sql")
+	t.Setenv("KAMINI_AUDIT_STORE_DRIVER", "postgres")
+	t.Setenv("KAMINI_AUDIT_STORE_DSN", "postgres://kamini@db/kamini?sslmode=verify-full")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if s := cfg.Audit.Store; s.Driver != "postgres" || s.DSN != "postgres://kamini@db/kamini?sslmode=verify-full" {
+		t.Fatalf("unexpected store config: %+v", s)
+	}
+	if len(cfg.Audit.Sinks) != 2 || cfg.Audit.Sinks[1] != "sql" {
+		t.Fatalf("unexpected sinks: %v", cfg.Audit.Sinks)
+	}
+}
+
+func TestLoad_EnvAuditWebhook(t *testing.T) {
+	t.Setenv("KAMINI_AUDIT_SINKS", "stdout,webhook")
+	t.Setenv("KAMINI_AUDIT_WEBHOOK_URLS", "https://a.example.com/hook, https://b.example.com/hook")
+	t.Setenv("KAMINI_AUDIT_WEBHOOK_SECRET_FILE", "/etc/kamini/webhook.key")
+	t.Setenv("KAMINI_AUDIT_WEBHOOK_ACTIONS", "ISSUE_USER_CERT,DENY")
+	t.Setenv("KAMINI_AUDIT_WEBHOOK_FLUSH_INTERVAL", "5s")
+	t.Setenv("KAMINI_AUDIT_WEBHOOK_SPOOL_DIR", "/var/lib/kamini/webhook")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	w := cfg.Audit.Webhook
+	if len(w.URLs) != 2 || w.URLs[1] != "https://b.example.com/hook" || len(w.Actions) != 2 || w.SecretFile != "/etc/kamini/webhook.key" {
+		t.Fatalf("unexpected webhook config: %+v", w)
+	}
+	if w.FlushInterval != 5*time.Second || w.SpoolDir != "/var/lib/kamini/webhook" || w.BatchSize != 100 || w.MaxAttempts != 3 {
+		t.Fatalf("unexpected webhook settings: %+v", w)
+	}
+}
+
+func TestLoad_EnvGraph(t *testing.T) {
+	t.Setenv("KAMINI_AUTH_GRAPH_ENABLED", "true")
+	t.Setenv("KAMINI_AUTH_GRAPH_CLIENT_ID", "graph-app")
+	t.Setenv("KAMINI_AUTH_GRAPH_CLIENT_SECRET_FILE", "/etc/kamini/graph.secret")
+	t.Setenv("KAMINI_AUTH_GRAPH_CACHE_TTL", "30m")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	g := cfg.Auth.Graph
+	if !g.Enabled || g.ClientID != "graph-app" || g.ClientSecretFile != "/etc/kamini/graph.secret" || g.CacheTTL != 30*time.Minute || g.Timeout != 10*time.Second {
+		t.Fatalf("unexpected graph config: %+v", g)
+	}
+}
+
+func TestLoad_EnvOIDCAccessTokens(t *testing.T) {
+	t.Setenv("KAMINI_AUTH_OIDC_ACCESS_TOKENS", "true")
+	t.Setenv("KAMINI_AUTH_OIDC_INTROSPECTION_CLIENT_ID", "kamini")
+	t.Setenv("KAMINI_AUTH_OIDC_INTROSPECTION_CACHE_TTL", "30s")
+	t.Setenv("KAMINI_AUTH_OIDC_USERINFO_CLAIMS", "email, groups")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	o := cfg.Auth.OIDC
+	if !o.AccessTokens || o.IntrospectionClientID != "kamini" || o.IntrospectionCacheTTL != 30*time.Second {
+		t.Fatalf("unexpected access token config: %+v", o)
+	}
+	if len(o.UserInfoClaims) != 2 || o.UserInfoClaims[1] != "groups" || o.UserInfoCacheTTL != time.Minute {
+		t.Fatalf("unexpected userinfo config: %v %v", o.UserInfoClaims, o.UserInfoCacheTTL)
+	}
+}
+
+func TestLoad_FileWorkloads(t *testing.T) {
+	fp := writeTempYAML(t, `
+auth:
+  oidc:
+    issuers:
+      - name: github
+        profile: github-actions
+        audiences: ["kamini"]
+      - name: prod
+        profile: kubernetes
+        issuer_url: "https://kubernetes.default.svc"
+        jwks_file: "/etc/kamini/prod-jwks.json"
+        audiences: ["kamini"]
+authorize:
+  workloads:
+    - platform: github-actions
+      repository: "org/app"
+      ref: "refs/heads/main"
+      principals: ["deploy"]
+      ttl: 10m
+`)
+	cfg, err := Load(fp)
+	if err != nil {
+		t.Fatalf("Load(file) error: %v", err)
+	}
+	if iss := cfg.Auth.OIDC.Issuers; len(iss) != 2 || iss[0].Profile != "github-actions" || iss[0].IssuerURL != "" || iss[1].JWKSFile != "/etc/kamini/prod-jwks.json" {
+		t.Fatalf("unexpected issuers: %+v", iss)
+	}
+	w := cfg.Authorize.Workloads
+	if len(w) != 1 || w[0].Platform != "github-actions" || w[0].Repository != "org/app" || w[0].Ref != "refs/heads/main" || w[0].Principals[0] != "deploy" || w[0].TTL != 10*time.Minute {
+		t.Fatalf("unexpected workloads: %+v", w)
+	}
+}
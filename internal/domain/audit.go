@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"strconv"
 	"time"
 )
 
@@ -11,22 +12,42 @@ type AuditAction string
 const (
 	// ActionIssueUserCert is emitted when attempting/issuing a user certificate.
 	ActionIssueUserCert AuditAction = "ISSUE_USER_CERT"
+	// ActionRenewUserCert is emitted when attempting/issuing a user certificate
+	// by renewal (proof of possession of a previous certificate).
+	ActionRenewUserCert AuditAction = "RENEW_USER_CERT"
 	// ActionDeny is emitted when a request is denied by policy/authorization.
 	ActionDeny AuditAction = "DENY"
 	// ActionError is emitted for unexpected/unhandled errors.
 	ActionError AuditAction = "ERROR"
+	// ActionRevokeCert is emitted when attempting/recording a certificate revocation.
+	ActionRevokeCert AuditAction = "REVOKE_CERT"
+	// ActionBlocklistAdd is emitted when a key or subject is added to the blocklist.
+	ActionBlocklistAdd AuditAction = "BLOCKLIST_ADD"
+	// ActionBlocklistRemove is emitted when a key or subject is removed from the blocklist.
+	ActionBlocklistRemove AuditAction = "BLOCKLIST_REMOVE"
 )
 
+// certBearing reports whether successful events of this action describe a certificate.
+func (a AuditAction) certBearing() bool {
+	switch a {
+	case ActionBlocklistAdd, ActionBlocklistRemove:
+		return false
+	}
+	return true
+}
+
 // AuditStage identifies where in the flow an event occurred.
 type AuditStage string
 
 const (
-	StageUnknown AuditStage = "UNKNOWN"
-	StageAuthn   AuditStage = "AUTHN"
-	StageAuthz   AuditStage = "AUTHZ"
-	StagePolicy  AuditStage = "POLICY"
-	StageSign    AuditStage = "SIGN"
-	StageInput   AuditStage = "INPUT" // request validation/normalization
+	StageUnknown   AuditStage = "UNKNOWN"
+	StageAuthn     AuditStage = "AUTHN"
+	StageAuthz     AuditStage = "AUTHZ"
+	StagePolicy    AuditStage = "POLICY"
+	StageSign      AuditStage = "SIGN"
+	StageInput     AuditStage = "INPUT" // request validation/normalization
+	StageRevoke    AuditStage = "REVOKE"
+	StageBlocklist AuditStage = "BLOCKLIST" // blocklist checks and changes
 )
 
 // AuditEvent is a pure fact. Adapters serialize/ship it; usecases emit it.
@@ -54,10 +75,14 @@ type AuditEvent struct {
 // (i.e., no error code was recorded).
 func (e AuditEvent) Success() bool { return e.ErrorCode == "" }
 
-// Validate enforces success/failure invariants.
+// DenyCode returns the policy denial reason recorded on a failure, if any.
+func (e AuditEvent) DenyCode() DenyCode { return DenyCode(e.Attrs["deny_code"]) }
+
+// Validate enforces success/failure invariants. Certificate actions (issue/revoke)
+// must carry a serial and validity window on success; blocklist changes need not.
 func (e AuditEvent) Validate() error {
 	if e.Success() {
-		if e.Serial == nil || e.NotBefore == nil || e.NotAfter == nil {
+		if e.Action.certBearing() && (e.Serial == nil || e.NotBefore == nil || e.NotAfter == nil) {
 			return errors.New("success event requires serial and validity window")
 		}
 		if e.ErrorCode != "" || e.ErrorMessage != "" {
@@ -76,22 +101,50 @@ func (e AuditEvent) Validate() error {
 type ErrorCode string
 
 const (
-	CodeMissingPublicKey ErrorCode = "MISSING_PUBLIC_KEY"
-	CodeNoPrincipals     ErrorCode = "NO_PRINCIPALS"
-	CodeInvalidValidity  ErrorCode = "INVALID_VALIDITY"
-	CodePolicyDenied     ErrorCode = "POLICY_DENIED"
-	CodeUnknownError     ErrorCode = "UNKNOWN_ERROR"
+	CodeMissingPublicKey   ErrorCode = "MISSING_PUBLIC_KEY"
+	CodeNoPrincipals       ErrorCode = "NO_PRINCIPALS"
+	CodeInvalidValidity    ErrorCode = "INVALID_VALIDITY"
+	CodePolicyDenied       ErrorCode = "POLICY_DENIED"
+	CodeInvalidToken       ErrorCode = "AUTH_INVALID_TOKEN"
+	CodeIssuerMismatch     ErrorCode = "AUTH_ISSUER_MISMATCH"
+	CodeAudienceMismatch   ErrorCode = "AUTH_AUDIENCE_MISMATCH"
+	CodeTenantMismatch     ErrorCode = "AUTH_TENANT_MISMATCH"
+	CodePartyMismatch      ErrorCode = "AUTH_AZP_MISMATCH"
+	CodeMFARequired        ErrorCode = "AUTH_MFA_REQUIRED"
+	CodeACRInsufficient    ErrorCode = "AUTH_ACR_INSUFFICIENT"
+	CodeGroupsUnavailable  ErrorCode = "AUTH_GROUPS_UNAVAILABLE"
+	CodeIssuerUnavailable  ErrorCode = "AUTH_ISSUER_UNAVAILABLE"
+	CodeInvalidCertificate ErrorCode = "AUTH_INVALID_CERTIFICATE"
+	CodeSessionExpired     ErrorCode = "AUTH_SESSION_EXPIRED"
+	CodeInvalidPublicKey   ErrorCode = "INVALID_PUBLIC_KEY"
+	CodeCertNotFound       ErrorCode = "CERT_NOT_FOUND"
+	CodeInvalidRevocation  ErrorCode = "INVALID_REVOCATION"
+	CodeBlocklisted        ErrorCode = "BLOCKLISTED"
+	CodeInvalidBlockEntry  ErrorCode = "INVALID_BLOCKLIST_ENTRY"
+	CodeBlockNotFound      ErrorCode = "BLOCKLIST_ENTRY_NOT_FOUND"
+	CodeAuditUnavailable   ErrorCode = "AUDIT_UNAVAILABLE"
+	CodeInvalidAuditQuery  ErrorCode = "INVALID_AUDIT_QUERY"
+	CodeUnknownError       ErrorCode = "UNKNOWN_ERROR"
 )
 
 // NewAuditFailure creates a failure event with best-effort error classification.
-// - action: the high-level action attempted (e.g., ActionIssueUserCert).
-// - stage: where it failed (AUTHN/AUTHZ/POLICY/SIGN/INPUT).
-// - id/principals: identity context and any candidate principals (may be empty).
-// - ctx: request context (used for Time and SourceIP).
-// - err: the error that caused the failure (classified to a stable code/message).
-// - attrs: optional extra attributes (merged into event).
+//   - action: the high-level action attempted (e.g., ActionIssueUserCert).
+//   - stage: where it failed (AUTHN/AUTHZ/POLICY/SIGN/INPUT).
+//   - id/principals: identity context and any candidate principals (may be empty).
+//   - ctx: request context (used for Time and SourceIP).
+//   - err: the error that caused the failure (classified to a stable code/message);
+//     a PolicyDeny also records its DenyCode (see DenyAttrs).
+//   - attrs: optional extra attributes (merged into event).
 func NewAuditFailure(action AuditAction, stage AuditStage, id Identity, principals []string, ctx SignContext, err error, attrs map[string]string) AuditEvent {
 	code, msg := ClassifyError(err)
+	var pd PolicyDeny
+	if errors.As(err, &pd) && pd.Code != "" {
+		merged := DenyAttrs(pd)
+		for k, v := range attrs {
+			merged[k] = v
+		}
+		attrs = merged
+	}
 	return AuditEvent{
 		Time:         ctx.Now,
 		Action:       action,
@@ -106,6 +159,19 @@ func NewAuditFailure(action AuditAction, stage AuditStage, id Identity, principa
 	}
 }
 
+// NewAuditWithheld creates the failure event for a certificate that was already
+// audited as issued but then withheld because a later step failed. The
+// attributes withheld=after_audit and withheld_serial name the success event it
+// cancels, so the trail does not show the certificate as both issued and
+// refused without saying why.
+func NewAuditWithheld(action AuditAction, stage AuditStage, id Identity, principals []string, serial uint64, ctx SignContext, err error, attrs map[string]string) AuditEvent {
+	merged := map[string]string{"withheld": "after_audit", "withheld_serial": strconv.FormatUint(serial, 10)}
+	for k, v := range attrs {
+		merged[k] = v
+	}
+	return NewAuditFailure(action, stage, id, principals, ctx, err, merged)
+}
+
 // NewAuditSuccess creates a success event with serial/validity filled in.
 // - action: the high-level action performed (e.g., ActionIssueUserCert).
 // - id/principals: identity context and the issued principals.
@@ -128,6 +194,67 @@ func NewAuditSuccess(action AuditAction, id Identity, principals []string, seria
 	}
 }
 
+// NewAuditRevocation creates a success event recording that rec was revoked.
+// The event's Subject is the certificate holder; the acting admin is kept in Attrs.
+// - admin: identity of the operator performing the revocation.
+// - rec: the certificate record the revocation was requested for (only Subject or KeyFP when direct).
+// - rev: the persisted revocation (kind, reason).
+// - ctx: request context (used for Time and SourceIP).
+func NewAuditRevocation(admin Identity, rec CertRecord, rev Revocation, ctx SignContext) AuditEvent {
+	attrs := map[string]string{
+		"revoke_kind": string(rev.Kind),
+		"revoked_by":  admin.Subject,
+	}
+	if rev.Reason != "" {
+		attrs["reason"] = rev.Reason
+	}
+	ev := AuditEvent{
+		Time:       ctx.Now,
+		Action:     ActionRevokeCert,
+		Stage:      StageRevoke,
+		TraceID:    ctx.TraceID,
+		Subject:    rec.Subject,
+		Principals: cloneStringSlice(rec.Principals),
+		KeyFP:      rec.KeyFP,
+		KeyID:      rec.KeyID,
+		SourceIP:   ctx.SourceIP,
+		Attrs:      attrs,
+	}
+	// Subject and key revocations may name no certificate.
+	if rec.Serial != 0 {
+		serial := rec.Serial
+		nb, na := rec.NotBefore, rec.NotAfter
+		ev.Serial, ev.NotBefore, ev.NotAfter = &serial, &nb, &na
+	}
+	return ev
+}
+
+// NewAuditBlocklist creates a success event for a blocklist change by admin.
+// Subject entries populate Subject; key entries populate KeyFP.
+func NewAuditBlocklist(action AuditAction, admin Identity, entry BlockEntry, ctx SignContext) AuditEvent {
+	ev := AuditEvent{
+		Time:     ctx.Now,
+		Action:   action,
+		Stage:    StageBlocklist,
+		TraceID:  ctx.TraceID,
+		SourceIP: ctx.SourceIP,
+		Attrs: map[string]string{
+			"block_kind": string(entry.Kind),
+			"changed_by": admin.Subject,
+		},
+	}
+	if entry.Reason != "" {
+		ev.Attrs["reason"] = entry.Reason
+	}
+	switch entry.Kind {
+	case BlockSubject:
+		ev.Subject = entry.Value
+	case BlockKey:
+		ev.KeyFP = entry.Value
+	}
+	return ev
+}
+
 // ClassifyError maps known domain errors to stable codes and public messages.
 // Unknown errors return ("UNKNOWN_ERROR", err.Error()). The message should be
 // safe to log; callers remain responsible for avoiding secrets in wrapped errors.
@@ -135,6 +262,10 @@ func ClassifyError(err error) (code ErrorCode, publicMessage string) {
 	if err == nil {
 		return "", ""
 	}
+	var be BlockedError
+	if errors.As(err, &be) {
+		return CodeBlocklisted, be.Error()
+	}
 	var pd PolicyDeny
 	if errors.As(err, &pd) {
 		if pd.Message == "" {
@@ -151,6 +282,45 @@ func ClassifyError(err error) (code ErrorCode, publicMessage string) {
 		return CodeInvalidValidity, "invalid validity window"
 	case errors.Is(err, ErrPolicyDenied):
 		return CodePolicyDenied, "policy denied issuance"
+	// Specific token checks before ErrInvalidToken, which callers may also wrap.
+	case errors.Is(err, ErrIssuerMismatch):
+		return CodeIssuerMismatch, "token issuer not trusted"
+	case errors.Is(err, ErrAudienceMismatch):
+		return CodeAudienceMismatch, "token audience not allowed"
+	case errors.Is(err, ErrTenantMismatch):
+		return CodeTenantMismatch, "token tenant not allowed"
+	case errors.Is(err, ErrPartyMismatch):
+		return CodePartyMismatch, "token authorized party not allowed"
+	case errors.Is(err, ErrMFARequired):
+		return CodeMFARequired, "multi-factor authentication required"
+	case errors.Is(err, ErrACRInsufficient):
+		return CodeACRInsufficient, "authentication context class not allowed"
+	case errors.Is(err, ErrInvalidToken):
+		return CodeInvalidToken, "invalid token"
+	case errors.Is(err, ErrGroupsUnavailable):
+		return CodeGroupsUnavailable, "group membership unavailable"
+	case errors.Is(err, ErrIssuerUnavailable):
+		return CodeIssuerUnavailable, "token issuer unavailable"
+	case errors.Is(err, ErrInvalidCertificate):
+		return CodeInvalidCertificate, "invalid certificate"
+	case errors.Is(err, ErrSessionExpired):
+		return CodeSessionExpired, "session expired; authenticate again"
+	case errors.Is(err, ErrInvalidPublicKey):
+		return CodeInvalidPublicKey, "invalid public key"
+	case errors.Is(err, ErrCertNotFound):
+		return CodeCertNotFound, "certificate not found"
+	case errors.Is(err, ErrInvalidRevocation):
+		return CodeInvalidRevocation, "invalid revocation"
+	case errors.Is(err, ErrBlocked):
+		return CodeBlocklisted, "blocklisted"
+	case errors.Is(err, ErrInvalidBlockEntry):
+		return CodeInvalidBlockEntry, "invalid blocklist entry"
+	case errors.Is(err, ErrBlockNotFound):
+		return CodeBlockNotFound, "blocklist entry not found"
+	case errors.Is(err, ErrAuditUnavailable):
+		return CodeAuditUnavailable, "audit unavailable"
+	case errors.Is(err, ErrInvalidAuditQuery):
+		return CodeInvalidAuditQuery, "invalid audit query"
 	default:
 		return CodeUnknownError, "unexpected error"
 	}
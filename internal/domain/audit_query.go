@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AuditOutcome filters audit events by result.
+type AuditOutcome string
+
+const (
+	OutcomeAny     AuditOutcome = ""
+	OutcomeSuccess AuditOutcome = "success"
+	OutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditRecord is a stored audit event. ID is assigned by the store, increases
+// with insertion order and serves as the pagination cursor.
+type AuditRecord struct {
+	ID uint64
+	AuditEvent
+}
+
+// AuditQuery narrows a search of stored audit events. Zero fields match everything.
+type AuditQuery struct {
+	Subject   string       // exact subject match
+	Principal string       // events whose principals include this one
+	Serial    uint64       // certificate serial; 0 = any
+	Action    AuditAction  // exact action match
+	Outcome   AuditOutcome // success or failure
+	Since     time.Time    // events at or after this instant
+	Until     time.Time    // events before this instant
+	Before    uint64       // records with ID < Before (pagination cursor); 0 = newest
+	Limit     int          // page size; stores treat <= 0 as unlimited
+}
+
+// Validate rejects queries that cannot match anything useful.
+func (q AuditQuery) Validate() error {
+	switch q.Outcome {
+	case OutcomeAny, OutcomeSuccess, OutcomeFailure:
+	default:
+		return fmt.Errorf("%w: unknown outcome %q", ErrInvalidAuditQuery, q.Outcome)
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && !q.Since.Before(q.Until) {
+		return fmt.Errorf("%w: since must be before until", ErrInvalidAuditQuery)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("%w: negative limit", ErrInvalidAuditQuery)
+	}
+	return nil
+}
+
+// Match reports whether rec satisfies the filter fields of q (Limit is ignored).
+func (q AuditQuery) Match(rec AuditRecord) bool {
+	switch {
+	case q.Subject != "" && rec.Subject != q.Subject,
+		q.Principal != "" && !slices.Contains(rec.Principals, q.Principal),
+		q.Serial != 0 && (rec.Serial == nil || *rec.Serial != q.Serial),
+		q.Action != "" && rec.Action != q.Action,
+		q.Outcome == OutcomeSuccess && !rec.Success(),
+		q.Outcome == OutcomeFailure && rec.Success(),
+		!q.Since.IsZero() && rec.Time.Before(q.Since),
+		!q.Until.IsZero() && !rec.Time.Before(q.Until),
+		q.Before != 0 && rec.ID >= q.Before:
+		return false
+	}
+	return true
+}
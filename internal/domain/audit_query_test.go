@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAuditQueryMatch(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := NewAuditSuccess(ActionIssueUserCert, Identity{Subject: "alice"}, []string{"alice", "ops"}, 42, now, now.Add(time.Hour), SignContext{Now: now}, nil)
+	rec := AuditRecord{ID: 7, AuditEvent: ev}
+
+	matches := []AuditQuery{
+		{},
+		{Subject: "alice", Principal: "ops", Serial: 42, Action: ActionIssueUserCert, Outcome: OutcomeSuccess},
+		{Since: now, Until: now.Add(time.Second), Before: 8},
+	}
+	for _, q := range matches {
+		if !q.Match(rec) {
+			t.Fatalf("expected %+v to match", q)
+		}
+	}
+	misses := []AuditQuery{
+		{Subject: "bob"},
+		{Principal: "root"},
+		{Serial: 43},
+		{Action: ActionRevokeCert},
+		{Outcome: OutcomeFailure},
+		{Since: now.Add(time.Second)},
+		{Until: now},
+		{Before: 7},
+	}
+	for _, q := range misses {
+		if q.Match(rec) {
+			t.Fatalf("unexpected match for %+v", q)
+		}
+	}
+}
+
+func TestAuditQueryValidate(t *testing.T) {
+	now := time.Now()
+	if err := (AuditQuery{Since: now, Until: now.Add(time.Hour), Outcome: OutcomeFailure}).Validate(); err != nil {
+		t.Fatalf("valid query rejected: %v", err)
+	}
+	bad := []AuditQuery{
+		{Outcome: "maybe"},
+		{Since: now, Until: now},
+		{Limit: -1},
+	}
+	for _, q := range bad {
+		if err := q.Validate(); !errors.Is(err, ErrInvalidAuditQuery) {
+			t.Fatalf("expected ErrInvalidAuditQuery for %+v, got %v", q, err)
+		}
+	}
+}
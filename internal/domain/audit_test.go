@@ -7,6 +7,18 @@ import (
 	"time"
 )
 
+func TestNewAuditFailure_DenyCode(t *testing.T) {
+	ctx := SignContext{Now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	deny := PolicyDeny{Code: DenyPrincipalNotAllowed, Message: "principal root not allowed"}
+	ev := NewAuditFailure(ActionIssueUserCert, StageAuthz, Identity{Subject: "sub"}, nil, ctx, deny, map[string]string{"authz": "opa"})
+	if ev.ErrorCode != CodePolicyDenied || ev.DenyCode() != DenyPrincipalNotAllowed || ev.Attrs["authz"] != "opa" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if ev := NewAuditFailure(ActionIssueUserCert, StageAuthn, Identity{}, nil, ctx, ErrInvalidToken, nil); ev.DenyCode() != "" || ev.Attrs != nil {
+		t.Fatalf("non-policy failure got deny attrs: %+v", ev)
+	}
+}
+
 func TestNewAuditFailure(t *testing.T) {
 	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
 	ctx := SignContext{Now: now, SourceIP: "1.2.3.4"}
@@ -33,6 +45,17 @@ func TestNewAuditFailure(t *testing.T) {
 	}
 }
 
+func TestNewAuditWithheld(t *testing.T) {
+	ctx := SignContext{Now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	ev := NewAuditWithheld(ActionRenewUserCert, StageSign, Identity{Subject: "sub"}, []string{"alice"}, 42, ctx, errors.New("disk full"), map[string]string{"renewed_from": "7"})
+	if ev.Success() || ev.Validate() != nil {
+		t.Fatalf("expected a valid failure event: %+v", ev)
+	}
+	if ev.Attrs["withheld"] != "after_audit" || ev.Attrs["withheld_serial"] != "42" || ev.Attrs["renewed_from"] != "7" {
+		t.Fatalf("bad attrs: %+v", ev.Attrs)
+	}
+}
+
 func TestNewAuditSuccessAndSuccessMethod(t *testing.T) {
 	now := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
 	ctx := SignContext{Now: now, SourceIP: "5.6.7.8"}
@@ -116,6 +139,96 @@ func TestClassifyError(t *testing.T) {
 			wantCode: "POLICY_DENIED",
 			wantMsg:  "policy denied",
 		},
+		{
+			name:     "wrapped ErrInvalidToken",
+			err:      fmt.Errorf("%w: %w", ErrInvalidToken, errors.New("expired")),
+			wantCode: "AUTH_INVALID_TOKEN",
+			wantMsg:  "invalid token",
+		},
+		{
+			name:     "ErrTenantMismatch wrapped as invalid token",
+			err:      fmt.Errorf("%w: %w", ErrInvalidToken, fmt.Errorf("%w: tid \"x\"", ErrTenantMismatch)),
+			wantCode: "AUTH_TENANT_MISMATCH",
+			wantMsg:  "token tenant not allowed",
+		},
+		{
+			name:     "ErrMFARequired",
+			err:      ErrMFARequired,
+			wantCode: "AUTH_MFA_REQUIRED",
+			wantMsg:  "multi-factor authentication required",
+		},
+		{
+			name:     "ErrIssuerMismatch",
+			err:      ErrIssuerMismatch,
+			wantCode: "AUTH_ISSUER_MISMATCH",
+			wantMsg:  "token issuer not trusted",
+		},
+		{
+			name:     "ErrAudienceMismatch",
+			err:      ErrAudienceMismatch,
+			wantCode: "AUTH_AUDIENCE_MISMATCH",
+			wantMsg:  "token audience not allowed",
+		},
+		{
+			name:     "ErrPartyMismatch",
+			err:      ErrPartyMismatch,
+			wantCode: "AUTH_AZP_MISMATCH",
+			wantMsg:  "token authorized party not allowed",
+		},
+		{
+			name:     "ErrACRInsufficient",
+			err:      ErrACRInsufficient,
+			wantCode: "AUTH_ACR_INSUFFICIENT",
+			wantMsg:  "authentication context class not allowed",
+		},
+		{
+			name:     "ErrGroupsUnavailable",
+			err:      fmt.Errorf("%w: graph: 503", ErrGroupsUnavailable),
+			wantCode: "AUTH_GROUPS_UNAVAILABLE",
+			wantMsg:  "group membership unavailable",
+		},
+		{
+			name:     "ErrIssuerUnavailable",
+			err:      fmt.Errorf("%w: https://idp.example.com: discovery pending", ErrIssuerUnavailable),
+			wantCode: "AUTH_ISSUER_UNAVAILABLE",
+			wantMsg:  "token issuer unavailable",
+		},
+		{
+			name:     "ErrInvalidCertificate",
+			err:      fmt.Errorf("%w: revoked", ErrInvalidCertificate),
+			wantCode: "AUTH_INVALID_CERTIFICATE",
+			wantMsg:  "invalid certificate",
+		},
+		{
+			name:     "ErrSessionExpired",
+			err:      ErrSessionExpired,
+			wantCode: "AUTH_SESSION_EXPIRED",
+			wantMsg:  "session expired; authenticate again",
+		},
+		{
+			name:     "ErrCertNotFound",
+			err:      ErrCertNotFound,
+			wantCode: "CERT_NOT_FOUND",
+			wantMsg:  "certificate not found",
+		},
+		{
+			name:     "ErrInvalidRevocation",
+			err:      ErrInvalidRevocation,
+			wantCode: "INVALID_REVOCATION",
+			wantMsg:  "invalid revocation",
+		},
+		{
+			name:     "wrapped ErrAuditUnavailable",
+			err:      fmt.Errorf("%w: disk full", ErrAuditUnavailable),
+			wantCode: "AUDIT_UNAVAILABLE",
+			wantMsg:  "audit unavailable",
+		},
+		{
+			name:     "wrapped ErrInvalidAuditQuery",
+			err:      fmt.Errorf("%w: since after until", ErrInvalidAuditQuery),
+			wantCode: "INVALID_AUDIT_QUERY",
+			wantMsg:  "invalid audit query",
+		},
 		{
 			name:     "unknown error",
 			err:      errors.New("something else"),
@@ -352,3 +465,24 @@ func TestAuditEvent_Validate_FailureMissingErrorCode(t *testing.T) {
 		t.Errorf("expected error for missing error code in failure event, got nil")
 	}
 }
+
+func TestNewAuditRevocation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := SignContext{Now: now, SourceIP: "9.9.9.9", TraceID: "t"}
+	rec := CertRecord{Serial: 42, KeyID: "42|sub|alice", Subject: "sub", Principals: []string{"alice"}, NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour), KeyFP: "SHA256:x"}
+	rev := Revocation{Kind: RevokeSerial, Serial: 42, Reason: "stolen", RevokedBy: "admin", Time: now}
+	ev := NewAuditRevocation(Identity{Subject: "admin"}, rec, rev, ctx)
+
+	if ev.Action != ActionRevokeCert || ev.Stage != StageRevoke || !ev.Success() {
+		t.Fatalf("bad action/stage: %+v", ev)
+	}
+	if err := ev.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if ev.Subject != "sub" || ev.KeyID != rec.KeyID || ev.KeyFP != rec.KeyFP || *ev.Serial != 42 {
+		t.Fatalf("bad cert fields: %+v", ev)
+	}
+	if ev.Attrs["revoked_by"] != "admin" || ev.Attrs["revoke_kind"] != "SERIAL" || ev.Attrs["reason"] != "stolen" {
+		t.Fatalf("bad attrs: %+v", ev.Attrs)
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// BlockKind identifies what a blocklist entry bars from issuance.
+type BlockKind string
+
+const (
+	BlockKey     BlockKind = "KEY"     // a public key, by SHA256 fingerprint
+	BlockSubject BlockKind = "SUBJECT" // an identity subject
+)
+
+// ParseBlockKind maps a case-insensitive name (key, subject) to a BlockKind.
+func ParseBlockKind(s string) (BlockKind, error) {
+	switch BlockKind(strings.ToUpper(strings.TrimSpace(s))) {
+	case BlockKey:
+		return BlockKey, nil
+	case BlockSubject:
+		return BlockSubject, nil
+	default:
+		return "", ErrInvalidBlockEntry
+	}
+}
+
+// BlockEntry bars a public key or subject from receiving certificates.
+type BlockEntry struct {
+	Kind    BlockKind
+	Value   string // "SHA256:..." for BlockKey, the subject for BlockSubject
+	Reason  string // short operator-supplied reason; avoid PII/secrets
+	AddedBy string // subject of the admin who added the entry
+	Time    time.Time
+}
+
+// Validate ensures the entry has a known kind and a well-formed value.
+func (e BlockEntry) Validate() error {
+	switch e.Kind {
+	case BlockKey:
+		if !validFingerprintSHA256(e.Value) {
+			return ErrInvalidBlockEntry
+		}
+	case BlockSubject:
+		if strings.TrimSpace(e.Value) == "" {
+			return ErrInvalidBlockEntry
+		}
+	default:
+		return ErrInvalidBlockEntry
+	}
+	return nil
+}
+
+// BlockEntryFromRevocation returns the blocklist entry implied by a key or subject
+// revocation, so revoked keys/subjects cannot simply request a new certificate.
+// ok is false for serial and KeyID revocations, which do not block re-issuance.
+func BlockEntryFromRevocation(rev Revocation) (entry BlockEntry, ok bool) {
+	entry = BlockEntry{Reason: rev.Reason, AddedBy: rev.RevokedBy, Time: rev.Time}
+	switch rev.Kind {
+	case RevokeKey:
+		entry.Kind, entry.Value = BlockKey, rev.KeyFP
+	case RevokeSubject:
+		entry.Kind, entry.Value = BlockSubject, rev.Subject
+	default:
+		return BlockEntry{}, false
+	}
+	return entry, true
+}
+
+// BlockedError reports that issuance was refused by the blocklist.
+// It matches ErrBlocked with errors.Is.
+type BlockedError struct {
+	Kind BlockKind
+}
+
+func (e BlockedError) Error() string {
+	if e.Kind == BlockSubject {
+		return "subject is blocklisted"
+	}
+	return "public key is blocklisted"
+}
+
+func (e BlockedError) Is(target error) bool { return target == ErrBlocked }
+
+// BlockAttrs returns canonical audit attributes for a blocklist refusal.
+func BlockAttrs(e BlockedError) map[string]string {
+	return map[string]string{"block_kind": string(e.Kind)}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseBlockKind(t *testing.T) {
+	if k, err := ParseBlockKind("key"); err != nil || k != BlockKey {
+		t.Fatalf("ParseBlockKind(key) = %q, %v", k, err)
+	}
+	if k, err := ParseBlockKind(" Subject "); err != nil || k != BlockSubject {
+		t.Fatalf("ParseBlockKind(subject) = %q, %v", k, err)
+	}
+	if _, err := ParseBlockKind("serial"); !errors.Is(err, ErrInvalidBlockEntry) {
+		t.Fatalf("expected ErrInvalidBlockEntry, got %v", err)
+	}
+}
+
+func TestBlockEntryValidate(t *testing.T) {
+	valid := []BlockEntry{
+		{Kind: BlockKey, Value: "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0"},
+		{Kind: BlockSubject, Value: "sub"},
+	}
+	for _, e := range valid {
+		if err := e.Validate(); err != nil {
+			t.Fatalf("Validate(%+v): %v", e, err)
+		}
+	}
+	invalid := []BlockEntry{
+		{Kind: BlockKey, Value: "abc"},
+		{Kind: BlockKey, Value: "SHA256:"},
+		{Kind: BlockKey, Value: "SHA256:abc"},                                          // not a 32-byte hash
+		{Kind: BlockKey, Value: "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0="}, // padded
+		{Kind: BlockSubject, Value: " "},
+		{Kind: "HOST", Value: "x"},
+	}
+	for _, e := range invalid {
+		if err := e.Validate(); !errors.Is(err, ErrInvalidBlockEntry) {
+			t.Fatalf("Validate(%+v) = %v, want ErrInvalidBlockEntry", e, err)
+		}
+	}
+}
+
+func TestBlockEntryFromRevocation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	e, ok := BlockEntryFromRevocation(Revocation{Kind: RevokeKey, KeyFP: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE", RevokedBy: "admin", Reason: "stolen", Time: now})
+	if !ok || e.Kind != BlockKey || e.Value != "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE" || e.AddedBy != "admin" || e.Reason != "stolen" || e.Time != now {
+		t.Fatalf("unexpected entry: %+v ok=%v", e, ok)
+	}
+	if e, ok := BlockEntryFromRevocation(Revocation{Kind: RevokeSubject, Subject: "s"}); !ok || e.Kind != BlockSubject || e.Value != "s" {
+		t.Fatalf("unexpected subject entry: %+v ok=%v", e, ok)
+	}
+	if _, ok := BlockEntryFromRevocation(Revocation{Kind: RevokeSerial, Serial: 1}); ok {
+		t.Fatalf("serial revocations must not block re-issuance")
+	}
+}
+
+func TestBlockedErrorClassification(t *testing.T) {
+	err := fmt.Errorf("check: %w", BlockedError{Kind: BlockSubject})
+	if !errors.Is(err, ErrBlocked) {
+		t.Fatalf("BlockedError should match ErrBlocked")
+	}
+	code, msg := ClassifyError(err)
+	if code != CodeBlocklisted || msg != "subject is blocklisted" {
+		t.Fatalf("ClassifyError = (%q, %q)", code, msg)
+	}
+	if BlockAttrs(BlockedError{Kind: BlockKey})["block_kind"] != "KEY" {
+		t.Fatalf("expected block_kind attr")
+	}
+}
+
+func TestNewAuditBlocklist(t *testing.T) {
+	ctx := SignContext{Now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), TraceID: "t"}
+	ev := NewAuditBlocklist(ActionBlocklistAdd, Identity{Subject: "admin"}, BlockEntry{Kind: BlockKey, Value: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE", Reason: "stolen"}, ctx)
+	if ev.Stage != StageBlocklist || ev.KeyFP != "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE" || ev.Attrs["changed_by"] != "admin" || ev.Attrs["reason"] != "stolen" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	if err := ev.Validate(); err != nil {
+		t.Fatalf("blocklist success events need no serial: %v", err)
+	}
+	ev = NewAuditBlocklist(ActionBlocklistRemove, Identity{Subject: "admin"}, BlockEntry{Kind: BlockSubject, Value: "sub"}, ctx)
+	if ev.Subject != "sub" || ev.KeyFP != "" {
+		t.Fatalf("unexpected subject event: %+v", ev)
+	}
+}
@@ -40,6 +40,78 @@ type CertRecord struct {
 	PluginAuth  string // which authenticator plugin decided identity
 	PluginAuthz string // which authorizer plugin decided policy
 	RequestIP   string
+
+	// Identity the certificate was issued to, kept so it can be renewed
+	// (policy re-evaluated) without a round trip to the IdP.
+	Username string
+	Email    string
+	Roles    []string
+	Groups   []string
+	Issuer   string
+	AMR      []string
+	ACR      string
+	// AuthTime is when the user last logged in to the IdP, carried across
+	// renewals; zero means not renewable (workloads, older records).
+	AuthTime time.Time
+}
+
+// SetIdentity copies the renewable parts of id into the record. authTime is
+// the IdP login time; pass zero to make the certificate non-renewable.
+func (r *CertRecord) SetIdentity(id Identity, authTime time.Time) {
+	r.Subject = id.Subject
+	r.Username = id.Username
+	r.Email = id.Email
+	r.Roles = cloneStringSlice(id.Roles)
+	r.Groups = cloneStringSlice(id.Groups)
+	r.Issuer = id.Issuer()
+	r.AMR = cloneStringSlice(id.AMR())
+	r.ACR = id.ACR()
+	r.PluginAuth = id.Provider
+	r.AuthTime = authTime
+}
+
+// Identity rebuilds the identity recorded with the certificate, for
+// re-evaluating policy on renewal.
+func (r CertRecord) Identity() Identity {
+	claims := map[string]any{}
+	if r.Issuer != "" {
+		claims["iss"] = r.Issuer
+	}
+	if len(r.AMR) > 0 {
+		claims["amr"] = cloneStringSlice(r.AMR)
+	}
+	if r.ACR != "" {
+		claims["acr"] = r.ACR
+	}
+	if !r.AuthTime.IsZero() {
+		claims["auth_time"] = r.AuthTime
+	}
+	return Identity{
+		Subject:  r.Subject,
+		Username: r.Username,
+		Email:    r.Email,
+		Roles:    cloneStringSlice(r.Roles),
+		Groups:   cloneStringSlice(r.Groups),
+		Claims:   claims,
+		Provider: r.PluginAuth,
+	}
+}
+
+// CertFilter narrows a listing of certificate records. Zero fields match everything.
+type CertFilter struct {
+	Subject  string    // exact subject match
+	ActiveAt time.Time // only records valid at this instant (NotBefore <= t < NotAfter)
+}
+
+// Match reports whether rec satisfies the filter.
+func (f CertFilter) Match(rec CertRecord) bool {
+	if f.Subject != "" && rec.Subject != f.Subject {
+		return false
+	}
+	if !f.ActiveAt.IsZero() && (f.ActiveAt.Before(rec.NotBefore) || !f.ActiveAt.Before(rec.NotAfter)) {
+		return false
+	}
+	return true
 }
 
 // SignRequest is the normalized request to sign a certificate from a client.
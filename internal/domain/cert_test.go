@@ -75,3 +75,20 @@ func TestBuildCertSpec_NoPrincipals(t *testing.T) {
 		t.Fatalf("expected ErrNoPrincipals, got %v", err)
 	}
 }
+
+func TestCertFilterMatch(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec := CertRecord{Subject: "alice", NotBefore: now.Add(-time.Minute), NotAfter: now.Add(time.Hour)}
+	if !(CertFilter{}).Match(rec) {
+		t.Fatalf("zero filter should match")
+	}
+	if !(CertFilter{Subject: "alice", ActiveAt: now}).Match(rec) {
+		t.Fatalf("expected active match")
+	}
+	if (CertFilter{Subject: "bob"}).Match(rec) {
+		t.Fatalf("unexpected subject match")
+	}
+	if (CertFilter{ActiveAt: now.Add(time.Hour)}).Match(rec) {
+		t.Fatalf("expired record should not match")
+	}
+}
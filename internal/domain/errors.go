@@ -3,8 +3,41 @@ package domain
 import "errors"
 
 var (
-	ErrMissingPublicKey = errors.New("missing public key")
-	ErrNoPrincipals     = errors.New("no principals")
-	ErrInvalidValidity  = errors.New("invalid validity window")
-	ErrPolicyDenied     = errors.New("policy denied issuance")
+	ErrMissingPublicKey  = errors.New("missing public key")
+	ErrNoPrincipals      = errors.New("no principals")
+	ErrInvalidValidity   = errors.New("invalid validity window")
+	ErrPolicyDenied      = errors.New("policy denied issuance")
+	ErrInvalidToken      = errors.New("invalid token")
+	ErrInvalidPublicKey  = errors.New("invalid public key")
+	ErrCertNotFound      = errors.New("certificate not found")
+	ErrInvalidRevocation = errors.New("invalid revocation")
+	ErrBlocked           = errors.New("blocklisted")
+	ErrInvalidBlockEntry = errors.New("invalid blocklist entry")
+	ErrBlockNotFound     = errors.New("blocklist entry not found")
+	ErrAuditUnavailable  = errors.New("audit unavailable")
+	ErrInvalidAuditQuery = errors.New("invalid audit query")
+
+	// Token claim checks beyond signature and expiry; each has its own code so
+	// operators can tell a wrong tenant from missing MFA.
+	ErrIssuerMismatch   = errors.New("token issuer not trusted")
+	ErrAudienceMismatch = errors.New("token audience not allowed")
+	ErrTenantMismatch   = errors.New("token tenant not allowed")
+	ErrPartyMismatch    = errors.New("token authorized party not allowed")
+	ErrMFARequired      = errors.New("multi-factor authentication required")
+	ErrACRInsufficient  = errors.New("authentication context class not allowed")
+
+	// ErrGroupsUnavailable: the token omitted group membership (e.g. Entra ID
+	// groups overage) and it could not be looked up; retryable.
+	ErrGroupsUnavailable = errors.New("group membership unavailable")
+	// ErrIssuerUnavailable: the token's issuer could not be discovered yet
+	// (its metadata endpoint is down); retryable.
+	ErrIssuerUnavailable = errors.New("token issuer unavailable")
+
+	// ErrInvalidCertificate: a certificate presented for renewal was not
+	// issued by this CA, is expired or revoked, or its key's signature over the
+	// renewal challenge does not verify.
+	ErrInvalidCertificate = errors.New("invalid certificate")
+	// ErrSessionExpired: renewal is past the maximum session length since the
+	// original IdP login (or the certificate is not renewable); log in again.
+	ErrSessionExpired = errors.New("session expired")
 )
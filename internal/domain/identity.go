@@ -1,6 +1,9 @@
 package domain
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // Identity is who the IdP says you are, normalized for our policy logic.
 // No tokens, no raw JWTs, just the distilled claims we care about.
@@ -11,6 +14,45 @@ type Identity struct {
 	Roles    []string // app roles > groups
 	Groups   []string
 	Claims   map[string]any // extra normalized claims (small, not raw token)
+	Provider string         // authenticator that verified the credential (e.g. "oidc")
+}
+
+// Issuer returns the token issuer ("iss") recorded by the authenticator, if any.
+func (i Identity) Issuer() string {
+	iss, _ := i.Claims["iss"].(string)
+	return iss
+}
+
+// Tenant returns the directory tenant ("tid", Entra ID), if any.
+func (i Identity) Tenant() string {
+	tid, _ := i.Claims["tid"].(string)
+	return tid
+}
+
+// ObjectID returns the directory object ID of the user ("oid", Entra ID),
+// which directory APIs such as Microsoft Graph address users by.
+func (i Identity) ObjectID() string {
+	oid, _ := i.Claims["oid"].(string)
+	return oid
+}
+
+// AMR returns the authentication methods ("amr", e.g. "pwd", "mfa", "hwk").
+func (i Identity) AMR() []string {
+	amr, _ := i.Claims["amr"].([]string)
+	return amr
+}
+
+// ACR returns the authentication context class ("acr"), if any.
+func (i Identity) ACR() string {
+	acr, _ := i.Claims["acr"].(string)
+	return acr
+}
+
+// AuthTime returns when the user last actively authenticated ("auth_time");
+// zero when the token did not say.
+func (i Identity) AuthTime() time.Time {
+	t, _ := i.Claims["auth_time"].(time.Time)
+	return t
 }
 
 // NormalizedUsernames returns candidate Unix usernames derived from Identity.
@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestIdentityNormalizedUsernames(t *testing.T) {
 	id := Identity{Username: "Alice", Email: "alice@example.com"}
@@ -16,6 +19,17 @@ func TestIdentityNormalizedUsernames(t *testing.T) {
 		t.Fatalf("unexpected %v", got2)
 	}
 }
+
+func TestIdentityIssuer(t *testing.T) {
+	if got := (Identity{}).Issuer(); got != "" {
+		t.Fatalf("empty identity issuer=%q", got)
+	}
+	id := Identity{Claims: map[string]any{"iss": "https://login.example.com/v2.0"}}
+	if got := id.Issuer(); got != "https://login.example.com/v2.0" {
+		t.Fatalf("issuer=%q", got)
+	}
+}
+
 func TestNewPrincipalSet(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -81,3 +95,21 @@ func TestIsValidPrincipal(t *testing.T) {
 		// IsValidPrincipal uses safeUsername directly; it will be valid if it normalizes to non-empty
 	}
 }
+
+func TestIdentityAuthClaims(t *testing.T) {
+	at := time.Unix(1_700_000_000, 0).UTC()
+	id := Identity{Claims: map[string]any{"amr": []string{"pwd", "mfa"}, "acr": "c2", "auth_time": at}}
+	if amr := id.AMR(); len(amr) != 2 || amr[1] != "mfa" {
+		t.Fatalf("amr=%v", amr)
+	}
+	if id.ACR() != "c2" || !id.AuthTime().Equal(at) {
+		t.Fatalf("acr=%q auth_time=%v", id.ACR(), id.AuthTime())
+	}
+	dir := Identity{Claims: map[string]any{"tid": "t1", "oid": "o1"}}
+	if dir.Tenant() != "t1" || dir.ObjectID() != "o1" || (Identity{}).Tenant() != "" {
+		t.Fatalf("tenant=%q oid=%q", dir.Tenant(), dir.ObjectID())
+	}
+	if (Identity{}).AMR() != nil || !(Identity{}).AuthTime().IsZero() {
+		t.Fatalf("empty identity should have no auth claims")
+	}
+}
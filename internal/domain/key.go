@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// FingerprintSHA256 returns the OpenSSH-style SHA256 fingerprint ("SHA256:<base64>")
+// of a public key in authorized_keys format ("ssh-ed25519 AAAA... comment").
+// Only the key type and blob are inspected; options prefixes are not supported.
+func FingerprintSHA256(authorizedKey string) (string, error) {
+	fields := strings.Fields(authorizedKey)
+	if len(fields) < 2 {
+		return "", ErrInvalidPublicKey
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil || len(blob) == 0 {
+		return "", ErrInvalidPublicKey
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// validFingerprintSHA256 reports whether fp has the form FingerprintSHA256
+// returns: "SHA256:" and the unpadded base64 of a 32-byte hash.
+func validFingerprintSHA256(fp string) bool {
+	b64, ok := strings.CutPrefix(fp, "SHA256:")
+	if !ok {
+		return false
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(b64)
+	return err == nil && len(raw) == sha256.Size
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFingerprintSHA256(t *testing.T) {
+	// Expected value taken from `ssh-keygen -lf` for this key.
+	key := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC+r2/7FDwBaKPXDUw7UoDa7RXGrXPD9oXeUHCOmZ3oT alice@example"
+	fp, err := FingerprintSHA256(key)
+	if err != nil {
+		t.Fatalf("FingerprintSHA256: %v", err)
+	}
+	if fp != "SHA256:7q/0GrbmHLAri6PXTvduejHcNDqTxixEPm1DESBYX7g" {
+		t.Fatalf("fp=%q", fp)
+	}
+}
+
+func TestFingerprintSHA256_Invalid(t *testing.T) {
+	for _, in := range []string{"", "ssh-ed25519", "ssh-ed25519 !!!notbase64"} {
+		if _, err := FingerprintSHA256(in); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Fatalf("FingerprintSHA256(%q) err=%v, want ErrInvalidPublicKey", in, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -20,6 +21,7 @@ type PolicyDecision struct {
 	TTL             time.Duration
 	CriticalOptions map[string]string
 	Extensions      map[string]string
+	Withheld        []string // principals left out because an AuthRequirement was not met
 }
 
 // Helper to compose KeyID deterministically (for audit/search).
@@ -39,6 +41,8 @@ const (
 	DenyIPNotAllowed        DenyCode = "IP_NOT_ALLOWED"
 	DenyRoleMissing         DenyCode = "ROLE_MISSING"
 	DenyQuotaExceeded       DenyCode = "QUOTA_EXCEEDED"
+	DenyAuthStrength        DenyCode = "AUTH_STRENGTH_INSUFFICIENT"
+	DenyAuthTooOld          DenyCode = "AUTH_TOO_OLD"
 	DenyDefault             DenyCode = "DEFAULT_DENY"
 )
 
@@ -59,3 +63,37 @@ func (e PolicyDeny) Error() string {
 func DenyAttrs(e PolicyDeny) map[string]string {
 	return map[string]string{"deny_code": string(e.Code)}
 }
+
+// AuthRequirement states how a user must have authenticated to receive the
+// listed principals. Empty fields are not checked.
+type AuthRequirement struct {
+	Principals []string
+	AMR        []string      // amr must contain one of these (e.g. "mfa", "hwk")
+	ACR        []string      // acr must be one of these
+	MaxAge     time.Duration // auth_time must be at most this old
+}
+
+// Applies reports whether the requirement covers principal. Both sides are
+// normalized, so "Prod-Admin" in configuration still guards "prod-admin".
+func (r AuthRequirement) Applies(principal string) bool {
+	p := safeUsername(principal)
+	return p != "" && slices.ContainsFunc(r.Principals, func(q string) bool { return safeUsername(q) == p })
+}
+
+// Check returns a PolicyDeny (AUTH_STRENGTH_INSUFFICIENT or AUTH_TOO_OLD) when
+// id does not meet the requirement at now.
+func (r AuthRequirement) Check(id Identity, now time.Time) error {
+	if len(r.AMR) > 0 && !slices.ContainsFunc(id.AMR(), func(m string) bool { return slices.Contains(r.AMR, m) }) {
+		return PolicyDeny{Code: DenyAuthStrength, Message: "stronger authentication method required"}
+	}
+	if len(r.ACR) > 0 && !slices.Contains(r.ACR, id.ACR()) {
+		return PolicyDeny{Code: DenyAuthStrength, Message: "stronger authentication context required"}
+	}
+	if r.MaxAge > 0 {
+		at := id.AuthTime()
+		if at.IsZero() || now.Sub(at) > r.MaxAge {
+			return PolicyDeny{Code: DenyAuthTooOld, Message: "recent sign-in required"}
+		}
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "testing"
+import (
+	"errors"
+	"testing"
+	"time"
+)
 
 func TestComposeKeyID(t *testing.T) {
 	id := Identity{Subject: "sub123", Username: "alice"}
@@ -51,3 +55,36 @@ func TestPolicyDeny_Error(t *testing.T) {
 		}
 	}
 }
+
+func TestAuthRequirement_Check(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	req := AuthRequirement{Principals: []string{"prod-admin"}, AMR: []string{"mfa", "hwk"}, MaxAge: 15 * time.Minute}
+	if !req.Applies("prod-admin") || !(AuthRequirement{Principals: []string{"Prod-Admin"}}).Applies("prod-admin") || req.Applies("alice") {
+		t.Fatalf("Applies")
+	}
+	claims := func(amr []string, authTime time.Time) Identity {
+		return Identity{Claims: map[string]any{"amr": amr, "auth_time": authTime}}
+	}
+	if err := req.Check(claims([]string{"pwd", "hwk"}, now.Add(-5*time.Minute)), now); err != nil {
+		t.Fatalf("expected pass, got %v", err)
+	}
+	tests := []struct {
+		name string
+		id   Identity
+		req  AuthRequirement
+		want DenyCode
+	}{
+		{"password only", claims([]string{"pwd"}, now), req, DenyAuthStrength},
+		{"no claims", Identity{}, req, DenyAuthStrength},
+		{"stale sign-in", claims([]string{"mfa"}, now.Add(-time.Hour)), req, DenyAuthTooOld},
+		{"no auth_time", Identity{Claims: map[string]any{"amr": []string{"mfa"}}}, req, DenyAuthTooOld},
+		{"acr", Identity{Claims: map[string]any{"acr": "c1"}}, AuthRequirement{ACR: []string{"c2"}}, DenyAuthStrength},
+	}
+	for _, tt := range tests {
+		err := tt.req.Check(tt.id, now)
+		var pd PolicyDeny
+		if !errors.As(err, &pd) || pd.Code != tt.want {
+			t.Fatalf("%s: got %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RenewalNamespace prefixes every renewal challenge so a signature made for
+// renewal cannot be replayed as, or obtained from, another use of the key.
+const RenewalNamespace = "kamini-renew-v1"
+
+// RenewalMaxSkew bounds how far a challenge's time may be from the server's
+// clock; it also bounds how long a captured request can be replayed.
+const RenewalMaxSkew = 2 * time.Minute
+
+// RenewalChallenge is the message a client signs with the private key of a
+// currently valid certificate to renew it. It binds the key to certify, so a
+// captured proof cannot certify another key.
+type RenewalChallenge struct {
+	PublicKeyAuthorized string // key to certify, "ssh-ed25519 AAAA..."
+	Time                time.Time
+}
+
+// Bytes returns the canonical signed form: the namespace, Unix time and the
+// key's type and base64 blob (comment dropped), newline-separated.
+func (c RenewalChallenge) Bytes() []byte {
+	return []byte(strings.Join([]string{
+		RenewalNamespace,
+		strconv.FormatInt(c.Time.Unix(), 10),
+		canonicalKey(c.PublicKeyAuthorized),
+	}, "\n"))
+}
+
+// Check rejects challenges whose time is more than RenewalMaxSkew from now.
+func (c RenewalChallenge) Check(now time.Time) error {
+	if d := now.Sub(c.Time); d > RenewalMaxSkew || d < -RenewalMaxSkew {
+		return fmt.Errorf("%w: challenge time outside %s of server time", ErrInvalidCertificate, RenewalMaxSkew)
+	}
+	return nil
+}
+
+// SessionEnd is when a session that started at authTime must log in again,
+// given the maximum session length. It is zero when either is zero, i.e. the
+// session is not renewable.
+func SessionEnd(authTime time.Time, maxSession time.Duration) time.Time {
+	if authTime.IsZero() || maxSession <= 0 {
+		return time.Time{}
+	}
+	return authTime.Add(maxSession)
+}
+
+// canonicalKey keeps the type and blob of an authorized key line.
+func canonicalKey(s string) string {
+	f := strings.Fields(s)
+	if len(f) > 2 {
+		f = f[:2]
+	}
+	return strings.Join(f, " ")
+}
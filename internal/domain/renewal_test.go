@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRenewalChallenge(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	c := RenewalChallenge{PublicKeyAuthorized: "ssh-ed25519 AAAA alice@laptop\n", Time: now}
+	if got := string(c.Bytes()); got != "kamini-renew-v1\n1700000000\nssh-ed25519 AAAA" {
+		t.Fatalf("Bytes=%q", got)
+	}
+	if string(c.Bytes()) != string(RenewalChallenge{PublicKeyAuthorized: "ssh-ed25519 AAAA", Time: now}.Bytes()) {
+		t.Fatalf("key comment must not change the challenge")
+	}
+	if err := c.Check(now.Add(RenewalMaxSkew)); err != nil {
+		t.Fatalf("Check within skew: %v", err)
+	}
+	for _, d := range []time.Duration{RenewalMaxSkew + time.Second, -RenewalMaxSkew - time.Second} {
+		if err := c.Check(now.Add(d)); !errors.Is(err, ErrInvalidCertificate) {
+			t.Fatalf("Check %s: expected ErrInvalidCertificate, got %v", d, err)
+		}
+	}
+}
+
+func TestSessionEnd(t *testing.T) {
+	login := time.Unix(1_700_000_000, 0).UTC()
+	if got := SessionEnd(login, 12*time.Hour); !got.Equal(login.Add(12 * time.Hour)) {
+		t.Fatalf("SessionEnd=%v", got)
+	}
+	if !SessionEnd(time.Time{}, time.Hour).IsZero() || !SessionEnd(login, 0).IsZero() {
+		t.Fatalf("expected zero without auth time or max session")
+	}
+}
+
+func TestCertRecordIdentity(t *testing.T) {
+	login := time.Unix(1_700_000_000, 0).UTC()
+	id := Identity{
+		Subject: "sub", Username: "alice", Email: "alice@example.com",
+		Roles: []string{"ops"}, Groups: []string{"ssh-users"}, Provider: "oidc:entra",
+		Claims: map[string]any{"iss": "https://idp", "amr": []string{"mfa"}, "acr": "gold", "tid": "t1"},
+	}
+	var rec CertRecord
+	rec.SetIdentity(id, login)
+	got := rec.Identity()
+	if got.Subject != "sub" || got.Username != "alice" || got.Email != "alice@example.com" || got.Provider != "oidc:entra" ||
+		!slices.Equal(got.Roles, id.Roles) || !slices.Equal(got.Groups, id.Groups) {
+		t.Fatalf("identity=%+v", got)
+	}
+	if got.Issuer() != "https://idp" || !slices.Equal(got.AMR(), []string{"mfa"}) || got.ACR() != "gold" || !got.AuthTime().Equal(login) {
+		t.Fatalf("claims=%+v", got.Claims)
+	}
+	// Only the claims policy needs are kept.
+	if _, ok := got.Claims["tid"]; ok {
+		t.Fatalf("unexpected claim carried: %+v", got.Claims)
+	}
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// RevocationKind identifies what a revocation entry matches.
+type RevocationKind string
+
+const (
+	RevokeSerial  RevocationKind = "SERIAL"  // a single certificate serial
+	RevokeKeyID   RevocationKind = "KEY_ID"  // certificates carrying a KeyID
+	RevokeSubject RevocationKind = "SUBJECT" // every certificate issued to a subject
+	RevokeKey     RevocationKind = "KEY"     // a public key, by SHA256 fingerprint
+)
+
+// ParseRevocationKind maps a case-insensitive name (serial, key_id, subject, key)
+// to a RevocationKind. An empty string defaults to RevokeSerial.
+func ParseRevocationKind(s string) (RevocationKind, error) {
+	switch RevocationKind(strings.ToUpper(strings.TrimSpace(s))) {
+	case "", RevokeSerial:
+		return RevokeSerial, nil
+	case RevokeKeyID:
+		return RevokeKeyID, nil
+	case RevokeSubject:
+		return RevokeSubject, nil
+	case RevokeKey:
+		return RevokeKey, nil
+	default:
+		return "", ErrInvalidRevocation
+	}
+}
+
+// Revocation is a persisted decision to stop trusting one or more certificates.
+// Exactly one of Serial/KeyID/Subject/KeyFP is meaningful, selected by Kind.
+type Revocation struct {
+	Kind      RevocationKind
+	Serial    uint64 // RevokeSerial
+	KeyID     string // RevokeKeyID
+	Subject   string // RevokeSubject
+	KeyFP     string // RevokeKey ("SHA256:...")
+	Reason    string // short operator-supplied reason; avoid PII/secrets
+	RevokedBy string // subject of the admin who revoked
+	Time      time.Time
+	NotAfter  time.Time // expiry of the revoked certificate; zero means never prune
+	Seq       uint64    // assigned by the CertStore (see NextRevocationSeq); versions the KRL
+}
+
+// NewRevocation derives a revocation of the given kind from a certificate record.
+func NewRevocation(kind RevocationKind, rec CertRecord, admin Identity, reason string, now time.Time) (Revocation, error) {
+	rev := Revocation{
+		Kind:      kind,
+		Reason:    reason,
+		RevokedBy: admin.Subject,
+		Time:      now,
+	}
+	switch kind {
+	case RevokeSerial:
+		rev.Serial = rec.Serial
+		rev.NotAfter = rec.NotAfter
+	case RevokeKeyID:
+		rev.KeyID = rec.KeyID
+		rev.NotAfter = rec.NotAfter
+	case RevokeSubject:
+		rev.Subject = rec.Subject
+	case RevokeKey:
+		rev.KeyFP = rec.KeyFP
+	}
+	return rev, rev.Validate()
+}
+
+// Validate ensures the revocation carries the field its Kind requires.
+func (r Revocation) Validate() error {
+	switch r.Kind {
+	case RevokeSerial:
+		if r.Serial == 0 {
+			return ErrInvalidRevocation
+		}
+	case RevokeKeyID:
+		if r.KeyID == "" {
+			return ErrInvalidRevocation
+		}
+	case RevokeSubject:
+		if r.Subject == "" {
+			return ErrInvalidRevocation
+		}
+	case RevokeKey:
+		if !validFingerprintSHA256(r.KeyFP) {
+			return ErrInvalidRevocation
+		}
+	default:
+		return ErrInvalidRevocation
+	}
+	return nil
+}
+
+// Matches reports whether rec is covered by this revocation.
+func (r Revocation) Matches(rec CertRecord) bool {
+	switch r.Kind {
+	case RevokeSerial:
+		return rec.Serial == r.Serial
+	case RevokeKeyID:
+		return rec.KeyID == r.KeyID
+	case RevokeSubject:
+		return rec.Subject == r.Subject
+	case RevokeKey:
+		return rec.KeyFP != "" && rec.KeyFP == r.KeyFP
+	}
+	return false
+}
+
+// version is Seq, or for entries recorded before sequence numbers existed,
+// the Unix time they were revoked at (the KRL version of that era).
+func (r Revocation) version() uint64 {
+	if r.Seq > 0 {
+		return r.Seq
+	}
+	if sec := r.Time.Unix(); sec > 0 {
+		return uint64(sec)
+	}
+	return 0
+}
+
+// NextRevocationSeq returns the Seq for a revocation appended after revs:
+// above every existing entry, so the KRL version increases with each
+// revocation, even several within one second.
+func NextRevocationSeq(revs []Revocation) uint64 {
+	var last uint64
+	for _, r := range revs {
+		last = max(last, r.version())
+	}
+	return last + 1
+}
+
+// Expired reports whether the revocation only covers certificates that have expired at now.
+func (r Revocation) Expired(now time.Time) bool {
+	return !r.NotAfter.IsZero() && !now.Before(r.NotAfter)
+}
+
+// KRLSpec is the content of an OpenSSH Key Revocation List, independent of wire format.
+// Lists are sorted and deduplicated.
+type KRLSpec struct {
+	Version   uint64 // monotonically increasing; the highest revocation Seq
+	Generated time.Time
+	Comment   string
+	Serials   []uint64 // certificate serials revoked under the CA
+	KeyIDs    []string // certificate KeyIDs revoked under the CA
+	KeyFPs    []string // public keys revoked outright ("SHA256:...")
+}
+
+// BuildKRLSpec folds revocations into a KRLSpec. Subject revocations expand to the
+// serials of the given records for that subject (callers pass currently valid records).
+// Revocations whose certificates have already expired are omitted.
+func BuildKRLSpec(revs []Revocation, records []CertRecord, now time.Time) KRLSpec {
+	spec := KRLSpec{Generated: now, Comment: "kamini"}
+	for _, r := range revs {
+		spec.Version = max(spec.Version, r.version())
+		if r.Expired(now) {
+			continue
+		}
+		switch r.Kind {
+		case RevokeSerial:
+			spec.Serials = append(spec.Serials, r.Serial)
+		case RevokeKeyID:
+			spec.KeyIDs = append(spec.KeyIDs, r.KeyID)
+		case RevokeKey:
+			spec.KeyFPs = append(spec.KeyFPs, r.KeyFP)
+		case RevokeSubject:
+			for _, rec := range records {
+				if r.Matches(rec) {
+					spec.Serials = append(spec.Serials, rec.Serial)
+				}
+			}
+		}
+	}
+	slices.Sort(spec.Serials)
+	spec.Serials = slices.Compact(spec.Serials)
+	slices.Sort(spec.KeyIDs)
+	spec.KeyIDs = slices.Compact(spec.KeyIDs)
+	slices.Sort(spec.KeyFPs)
+	spec.KeyFPs = slices.Compact(spec.KeyFPs)
+	return spec
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRevocationKind(t *testing.T) {
+	cases := map[string]RevocationKind{
+		"":        RevokeSerial,
+		"serial":  RevokeSerial,
+		"key_id":  RevokeKeyID,
+		"SUBJECT": RevokeSubject,
+		"key":     RevokeKey,
+	}
+	for in, want := range cases {
+		got, err := ParseRevocationKind(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseRevocationKind(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRevocationKind("host"); !errors.Is(err, ErrInvalidRevocation) {
+		t.Fatalf("expected ErrInvalidRevocation, got %v", err)
+	}
+}
+
+func TestNewRevocation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec := CertRecord{Serial: 7, KeyID: "7|sub|alice", Subject: "sub", KeyFP: "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0", NotAfter: now.Add(time.Hour)}
+	admin := Identity{Subject: "admin"}
+
+	rev, err := NewRevocation(RevokeSerial, rec, admin, "laptop stolen", now)
+	if err != nil {
+		t.Fatalf("NewRevocation: %v", err)
+	}
+	if rev.Serial != 7 || rev.NotAfter != rec.NotAfter || rev.RevokedBy != "admin" || rev.Reason != "laptop stolen" {
+		t.Fatalf("unexpected revocation: %+v", rev)
+	}
+	if rev, _ := NewRevocation(RevokeSubject, rec, admin, "", now); rev.Subject != "sub" || !rev.NotAfter.IsZero() {
+		t.Fatalf("subject revocation should not expire: %+v", rev)
+	}
+	if _, err := NewRevocation(RevokeKey, CertRecord{Serial: 1}, admin, "", now); !errors.Is(err, ErrInvalidRevocation) {
+		t.Fatalf("expected ErrInvalidRevocation for missing key fp, got %v", err)
+	}
+	for _, fp := range []string{"SHA256:abc", "SHA256:!!!", "MD5:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0"} {
+		if err := (Revocation{Kind: RevokeKey, KeyFP: fp}).Validate(); !errors.Is(err, ErrInvalidRevocation) {
+			t.Fatalf("Validate(%q) = %v, want ErrInvalidRevocation", fp, err)
+		}
+	}
+}
+
+func TestRevocationMatches(t *testing.T) {
+	rec := CertRecord{Serial: 3, KeyID: "k", Subject: "s", KeyFP: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE"}
+	for _, rev := range []Revocation{
+		{Kind: RevokeSerial, Serial: 3},
+		{Kind: RevokeKeyID, KeyID: "k"},
+		{Kind: RevokeSubject, Subject: "s"},
+		{Kind: RevokeKey, KeyFP: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE"},
+	} {
+		if !rev.Matches(rec) {
+			t.Fatalf("%s revocation should match %+v", rev.Kind, rec)
+		}
+	}
+	if (Revocation{Kind: RevokeSerial, Serial: 4}).Matches(rec) {
+		t.Fatalf("unexpected serial match")
+	}
+}
+
+func TestBuildKRLSpec(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	revs := []Revocation{
+		{Kind: RevokeSerial, Serial: 9, Time: now.Add(-2 * time.Hour), NotAfter: now.Add(time.Hour)},
+		{Kind: RevokeSerial, Serial: 2, Time: now.Add(-3 * time.Hour), NotAfter: now.Add(-time.Hour)}, // expired
+		{Kind: RevokeKeyID, KeyID: "b", Time: now.Add(-time.Hour)},
+		{Kind: RevokeKey, KeyFP: "SHA256:WU5RmuSZMSspQzt92Kl/8Gje/LqXVbbV0A6ExSTWewY", Time: now.Add(-time.Hour)},
+		{Kind: RevokeSubject, Subject: "alice", Time: now.Add(-time.Minute)},
+	}
+	records := []CertRecord{
+		{Serial: 5, Subject: "alice"},
+		{Serial: 9, Subject: "alice"},
+		{Serial: 6, Subject: "bob"},
+	}
+	spec := BuildKRLSpec(revs, records, now)
+
+	if got := spec.Serials; len(got) != 2 || got[0] != 5 || got[1] != 9 {
+		t.Fatalf("serials=%v, want [5 9]", got)
+	}
+	if len(spec.KeyIDs) != 1 || spec.KeyIDs[0] != "b" {
+		t.Fatalf("key ids=%v", spec.KeyIDs)
+	}
+	if len(spec.KeyFPs) != 1 || spec.KeyFPs[0] != "SHA256:WU5RmuSZMSspQzt92Kl/8Gje/LqXVbbV0A6ExSTWewY" {
+		t.Fatalf("key fps=%v", spec.KeyFPs)
+	}
+	if spec.Version != uint64(now.Add(-time.Minute).Unix()) {
+		t.Fatalf("version=%d", spec.Version)
+	}
+}
+
+func TestNextRevocationSeq(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Entries from before sequence numbers keep their time-based version.
+	revs := []Revocation{{Kind: RevokeSerial, Serial: 1, Time: now}}
+	for i := 0; i < 2; i++ {
+		// Two revocations within the same second still bump the version.
+		revs = append(revs, Revocation{Kind: RevokeSerial, Serial: uint64(i + 2), Time: now, Seq: NextRevocationSeq(revs)})
+	}
+	if revs[1].Seq != uint64(now.Unix())+1 || revs[2].Seq != revs[1].Seq+1 {
+		t.Fatalf("seqs=%d,%d", revs[1].Seq, revs[2].Seq)
+	}
+	if v := BuildKRLSpec(revs, nil, now).Version; v != revs[2].Seq {
+		t.Fatalf("version=%d, want %d", v, revs[2].Seq)
+	}
+	if NextRevocationSeq(nil) != 1 {
+		t.Fatalf("first seq=%d", NextRevocationSeq(nil))
+	}
+}
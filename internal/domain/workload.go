@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"time"
+)
+
+// Workload describes a CI job or Kubernetes pod that authenticated with its
+// platform's OIDC token (GitHub Actions, GitLab CI, a ServiceAccount token)
+// rather than a person.
+type Workload struct {
+	Platform    string // e.g. "github-actions", "gitlab-ci", "kubernetes"
+	Repository  string // "org/app" (GitLab: project path; Kubernetes: "namespace/serviceaccount")
+	Ref         string // full git ref, e.g. "refs/heads/main"
+	Environment string // deployment environment, if the job declared one (Kubernetes: cluster name)
+	Workflow    string // workflow file within the repository, e.g. ".github/workflows/deploy.yml"
+}
+
+// Workload returns the CI workload recorded by the authenticator, if the
+// identity is one.
+func (i Identity) Workload() (Workload, bool) {
+	w, ok := i.Claims["workload"].(Workload)
+	return w, ok
+}
+
+// WorkloadRule grants principals to CI workloads. Platform is required and
+// matched exactly, so a rule for one platform never matches a look-alike
+// repository path on another. The other fields are path.Match patterns
+// ("refs/tags/v*", "org/*"); empty ones match anything except Repository,
+// which is required so tokens from other repositories on the same platform
+// never match.
+type WorkloadRule struct {
+	Platform    string
+	Repository  string
+	Ref         string
+	Environment string
+	Workflow    string
+	Principals  []string
+	TTL         time.Duration // certificate lifetime cap; 0 = the authorizer's max
+}
+
+// Validate checks that Platform, Repository and Principals are set and that
+// every pattern compiles.
+func (r WorkloadRule) Validate() error {
+	if r.Repository == "" {
+		return errors.New("workload rule: repository required")
+	}
+	if r.Platform == "" {
+		return fmt.Errorf("workload rule %q: platform required", r.Repository)
+	}
+	if len(r.Principals) == 0 {
+		return fmt.Errorf("workload rule %q: principals required", r.Repository)
+	}
+	for _, p := range []string{r.Repository, r.Ref, r.Environment, r.Workflow} {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("workload rule %q: pattern %q: %w", r.Repository, p, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether w is on the rule's platform and satisfies every
+// pattern of the rule.
+func (r WorkloadRule) Matches(w Workload) bool {
+	if r.Platform == "" || r.Platform != w.Platform || r.Repository == "" {
+		return false
+	}
+	for _, c := range [][2]string{
+		{r.Repository, w.Repository},
+		{r.Ref, w.Ref},
+		{r.Environment, w.Environment},
+		{r.Workflow, w.Workflow},
+	} {
+		if c[0] == "" {
+			continue
+		}
+		if ok, _ := path.Match(c[0], c[1]); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "testing"
+
+func TestWorkloadRuleMatches(t *testing.T) {
+	w := Workload{Platform: "github-actions", Repository: "org/app", Ref: "refs/heads/main", Environment: "prod", Workflow: ".github/workflows/deploy.yml"}
+	tests := []struct {
+		name string
+		rule WorkloadRule
+		want bool
+	}{
+		{"repo and ref", WorkloadRule{Platform: "github-actions", Repository: "org/app", Ref: "refs/heads/main"}, true},
+		{"org wildcard", WorkloadRule{Platform: "github-actions", Repository: "org/*", Environment: "prod"}, true},
+		{"other ref", WorkloadRule{Platform: "github-actions", Repository: "org/app", Ref: "refs/heads/dev"}, false},
+		{"star stops at slash", WorkloadRule{Platform: "github-actions", Repository: "org/app", Ref: "refs/*"}, false},
+		{"tags only", WorkloadRule{Platform: "github-actions", Repository: "org/app", Ref: "refs/tags/v*"}, false},
+		{"platform", WorkloadRule{Platform: "gitlab-ci", Repository: "org/app"}, false},
+		{"workflow", WorkloadRule{Platform: "github-actions", Repository: "org/app", Workflow: ".github/workflows/deploy.yml"}, true},
+		{"environment required", WorkloadRule{Platform: "github-actions", Repository: "org/app", Environment: "staging"}, false},
+		{"no repository never matches", WorkloadRule{Platform: "github-actions", Ref: "refs/heads/main"}, false},
+		{"no platform never matches", WorkloadRule{Repository: "org/app"}, false},
+		{"platform is not a pattern", WorkloadRule{Platform: "*", Repository: "org/app"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Matches(w); got != tt.want {
+			t.Fatalf("%s: got %v want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// The same "org/app" path names a GitHub repository, a GitLab project and a
+// Kubernetes ServiceAccount; a rule for one must not admit the others.
+func TestWorkloadRuleMatchesAcrossPlatforms(t *testing.T) {
+	rule := WorkloadRule{Platform: "github-actions", Repository: "org/app", Principals: []string{"deploy"}}
+	for _, platform := range []string{"gitlab-ci", "kubernetes", ""} {
+		if rule.Matches(Workload{Platform: platform, Repository: "org/app"}) {
+			t.Fatalf("github-actions rule matched a %q workload", platform)
+		}
+	}
+	if !rule.Matches(Workload{Platform: "github-actions", Repository: "org/app"}) {
+		t.Fatalf("rule must match its own platform")
+	}
+}
+
+func TestWorkloadRuleValidate(t *testing.T) {
+	if err := (WorkloadRule{Platform: "github-actions", Repository: "org/app", Principals: []string{"deploy"}}).Validate(); err != nil {
+		t.Fatalf("valid rule: %v", err)
+	}
+	for _, r := range []WorkloadRule{
+		{Platform: "github-actions", Principals: []string{"deploy"}},
+		{Repository: "org/app", Principals: []string{"deploy"}},
+		{Platform: "github-actions", Repository: "org/app"},
+		{Platform: "github-actions", Repository: "org/app", Ref: "refs/[", Principals: []string{"deploy"}},
+	} {
+		if err := r.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", r)
+		}
+	}
+}
+
+func TestIdentityWorkload(t *testing.T) {
+	w := Workload{Repository: "org/app"}
+	if got, ok := (Identity{Claims: map[string]any{"workload": w}}).Workload(); !ok || got != w {
+		t.Fatalf("workload=%+v ok=%v", got, ok)
+	}
+	if _, ok := (Identity{}).Workload(); ok {
+		t.Fatalf("person should not be a workload")
+	}
+}
@@ -0,0 +1,224 @@
+// Package oidctest runs an in-process OIDC provider for tests: discovery,
+// JWKS with key rotation, and device authorization and token endpoints, plus
+// helpers to mint ID and access tokens with arbitrary claims. It lets the
+// server and CLI be exercised end to end without a network or a real IdP.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Paths of the endpoints advertised in discovery.
+const (
+	PathDiscovery = "/.well-known/openid-configuration"
+	PathJWKS      = "/keys"
+	PathDevice    = "/device/code"
+	PathToken     = "/token"
+)
+
+// Provider is a fake OIDC provider served by httptest. Its issuer is URL.
+// Exported fields may be changed before tokens are requested.
+type Provider struct {
+	URL string // issuer and base of every endpoint
+
+	// TokenTTL is the lifetime of minted tokens; default 1h.
+	TokenTTL time.Duration
+	// AccessTokenAudience is the aud of access tokens issued by the token
+	// endpoint; default: the requesting client_id.
+	AccessTokenAudience string
+
+	t      testing.TB
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	mu      sync.Mutex
+	keys    []signingKey // keys[0] signs; all are published
+	meta    map[string]any
+	devices map[string]*deviceGrant // by device_code
+	refresh map[string]grant        // by refresh_token
+}
+
+type signingKey struct {
+	priv *rsa.PrivateKey
+	kid  string
+}
+
+// New starts a provider with one signing key; it is closed when t ends.
+func New(t testing.TB) *Provider {
+	t.Helper()
+	p := &Provider{
+		t:       t,
+		mux:     http.NewServeMux(),
+		devices: map[string]*deviceGrant{},
+		refresh: map[string]grant{},
+	}
+	p.server = httptest.NewServer(p.mux)
+	t.Cleanup(p.server.Close)
+	p.URL = p.server.URL
+	p.meta = map[string]any{
+		"issuer":                                p.URL,
+		"jwks_uri":                              p.URL + PathJWKS,
+		"token_endpoint":                        p.URL + PathToken,
+		"device_authorization_endpoint":         p.URL + PathDevice,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"grant_types_supported": []string{
+			"urn:ietf:params:oauth:grant-type:device_code", "refresh_token", "client_credentials",
+		},
+	}
+	p.RotateKey()
+	p.mux.HandleFunc(PathDiscovery, func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		writeJSON(w, http.StatusOK, p.meta)
+	})
+	p.mux.HandleFunc(PathJWKS, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(p.JWKS())
+	})
+	p.mux.HandleFunc("POST "+PathDevice, p.handleDevice)
+	p.mux.HandleFunc("POST "+PathToken, p.handleToken)
+	return p
+}
+
+// HandleFunc serves an extra endpoint (e.g. introspection or userinfo) on the
+// provider; advertise it with SetMetadata.
+func (p *Provider) HandleFunc(pattern string, h http.HandlerFunc) { p.mux.HandleFunc(pattern, h) }
+
+// SetMetadata sets a discovery document field, e.g.
+// SetMetadata("userinfo_endpoint", p.URL+"/userinfo"); nil removes it.
+func (p *Provider) SetMetadata(key string, v any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if v == nil {
+		delete(p.meta, key)
+		return
+	}
+	p.meta[key] = v
+}
+
+// RotateKey generates a new signing key and returns its kid. Earlier keys stay
+// published until RetireKeys, like a provider's rollover window.
+func (p *Provider) RotateKey() string {
+	p.t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		p.t.Fatalf("oidctest: keygen: %v", err)
+	}
+	sum := sha256.Sum256(priv.N.Bytes())
+	k := signingKey{priv: priv, kid: base64.RawURLEncoding.EncodeToString(sum[:8])}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append([]signingKey{k}, p.keys...)
+	return k.kid
+}
+
+// RetireKeys stops publishing every key but the current one, so tokens signed
+// before the last RotateKey no longer verify.
+func (p *Provider) RetireKeys() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = p.keys[:1]
+}
+
+// KeyID returns the kid of the current signing key.
+func (p *Provider) KeyID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.keys[0].kid
+}
+
+// JWKS returns the published key set, e.g. to configure static keys.
+func (p *Provider) JWKS() []byte {
+	p.mu.Lock()
+	set := jose.JSONWebKeySet{}
+	for _, k := range p.keys {
+		set.Keys = append(set.Keys, jose.JSONWebKey{Key: &k.priv.PublicKey, KeyID: k.kid, Algorithm: "RS256", Use: "sig"})
+	}
+	p.mu.Unlock()
+	b, err := json.Marshal(set)
+	if err != nil {
+		p.t.Fatalf("oidctest: marshal jwks: %v", err)
+	}
+	return b
+}
+
+// Mint signs claims verbatim with the current key; typ sets the JWT header
+// (e.g. "at+jwt"; empty leaves the default "JWT").
+func (p *Provider) Mint(typ string, claims map[string]any) string {
+	p.t.Helper()
+	p.mu.Lock()
+	k := p.keys[0]
+	p.mu.Unlock()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims(claims))
+	tok.Header["kid"] = k.kid
+	if typ != "" {
+		tok.Header["typ"] = typ
+	}
+	s, err := tok.SignedString(k.priv)
+	if err != nil {
+		p.t.Fatalf("oidctest: sign token: %v", err)
+	}
+	return s
+}
+
+// IDToken mints an ID token from this issuer for aud and sub, valid for
+// TokenTTL; claims are added and override the defaults (e.g. "exp").
+func (p *Provider) IDToken(aud, sub string, claims map[string]any) string {
+	p.t.Helper()
+	return p.Mint("", p.standardClaims(aud, sub, claims))
+}
+
+// AccessToken mints an RFC 9068 JWT access token (typ "at+jwt") like
+// IDToken does.
+func (p *Provider) AccessToken(aud, sub string, claims map[string]any) string {
+	p.t.Helper()
+	c := p.standardClaims(aud, sub, nil)
+	c["jti"] = rand.Text()
+	for k, v := range claims {
+		c[k] = v
+	}
+	return p.Mint("at+jwt", c)
+}
+
+func (p *Provider) standardClaims(aud, sub string, claims map[string]any) map[string]any {
+	now := time.Now()
+	c := map[string]any{
+		"iss": p.URL,
+		"aud": aud,
+		"sub": sub,
+		"iat": now.Unix(),
+		"exp": now.Add(p.tokenTTL()).Unix(),
+	}
+	for k, v := range claims {
+		c[k] = v
+	}
+	return c
+}
+
+func (p *Provider) tokenTTL() time.Duration {
+	if p.TokenTTL > 0 {
+		return p.TokenTTL
+	}
+	return time.Hour
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,129 @@
+package oidctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	oidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+func TestProvider_TokensVerifyWithGoOIDC(t *testing.T) {
+	p := New(t)
+	ctx := context.Background()
+	provider, err := oidc.NewProvider(ctx, p.URL)
+	if err != nil {
+		t.Fatalf("discovery: %v", err)
+	}
+	v := provider.Verifier(&oidc.Config{ClientID: "kamini"})
+
+	idt, err := v.Verify(ctx, p.IDToken("kamini", "alice", map[string]any{"groups": []string{"ssh-users"}}))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	var c struct {
+		Groups []string `json:"groups"`
+	}
+	if err := idt.Claims(&c); err != nil || idt.Subject != "alice" || len(c.Groups) != 1 {
+		t.Fatalf("sub=%q claims=%+v err=%v", idt.Subject, c, err)
+	}
+	if _, err := v.Verify(ctx, p.IDToken("kamini", "alice", map[string]any{"exp": time.Now().Add(-time.Minute).Unix()})); err == nil {
+		t.Fatalf("expected expired token to fail")
+	}
+
+	// Rotation: old and new keys verify until the old one is retired.
+	old := p.IDToken("kamini", "alice", nil)
+	if kid := p.RotateKey(); kid == "" || kid != p.KeyID() {
+		t.Fatalf("kid=%q current=%q", kid, p.KeyID())
+	}
+	if _, err := v.Verify(ctx, p.IDToken("kamini", "alice", nil)); err != nil {
+		t.Fatalf("verify with rotated key: %v", err)
+	}
+	if _, err := v.Verify(ctx, old); err != nil {
+		t.Fatalf("verify with previous key: %v", err)
+	}
+	p.RetireKeys()
+	fresh, err := oidc.NewProvider(ctx, p.URL)
+	if err != nil {
+		t.Fatalf("discovery: %v", err)
+	}
+	if _, err := fresh.Verifier(&oidc.Config{ClientID: "kamini"}).Verify(ctx, old); err == nil {
+		t.Fatalf("expected retired key to fail with a fresh key set")
+	}
+}
+
+func TestProvider_DeviceFlow(t *testing.T) {
+	p := New(t)
+	ctx := context.Background()
+	cfg := oauth2.Config{
+		ClientID: "kamini-cli",
+		Endpoint: oauth2.Endpoint{DeviceAuthURL: p.URL + PathDevice, TokenURL: p.URL + PathToken, AuthStyle: oauth2.AuthStyleInParams},
+		Scopes:   []string{"openid"},
+	}
+	da, err := cfg.DeviceAuth(ctx)
+	if err != nil {
+		t.Fatalf("device auth: %v", err)
+	}
+	if got := p.PendingUserCodes(); len(got) != 1 || got[0] != da.UserCode {
+		t.Fatalf("pending=%v want %q", got, da.UserCode)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond) // let the client poll while pending
+		_ = p.Approve(da.UserCode, "alice", map[string]any{"preferred_username": "alice"})
+	}()
+	tok, err := cfg.DeviceAccessToken(ctx, da)
+	if err != nil {
+		t.Fatalf("device token: %v", err)
+	}
+	raw, _ := tok.Extra("id_token").(string)
+	provider, err := oidc.NewProvider(ctx, p.URL)
+	if err != nil {
+		t.Fatalf("discovery: %v", err)
+	}
+	idt, err := provider.Verifier(&oidc.Config{ClientID: "kamini-cli"}).Verify(ctx, raw)
+	if err != nil || idt.Subject != "alice" {
+		t.Fatalf("id token: sub=%v err=%v", idt, err)
+	}
+
+	// Refresh returns new tokens for the same user.
+	refreshed, err := cfg.TokenSource(ctx, &oauth2.Token{RefreshToken: tok.RefreshToken}).Token()
+	if err != nil || refreshed.AccessToken == "" {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	// Denied and unknown authorizations fail.
+	da, err = cfg.DeviceAuth(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Deny(da.UserCode); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.DeviceAccessToken(ctx, da); err == nil {
+		t.Fatalf("expected access_denied")
+	}
+	if err := p.Approve("NOPE-NOPE", "alice", nil); err == nil {
+		t.Fatalf("expected unknown user code error")
+	}
+}
+
+func TestProvider_ClientCredentials(t *testing.T) {
+	p := New(t)
+	p.AccessTokenAudience = "api://kamini"
+	ctx := context.Background()
+	cfg := clientcredentials.Config{ClientID: "ci", ClientSecret: "s", TokenURL: p.URL + PathToken}
+	tok, err := cfg.Token(ctx)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	provider, err := oidc.NewProvider(ctx, p.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at, err := provider.Verifier(&oidc.Config{ClientID: "api://kamini"}).Verify(ctx, tok.AccessToken)
+	if err != nil || at.Subject != "ci" {
+		t.Fatalf("access token: %v %v", at, err)
+	}
+}
@@ -0,0 +1,187 @@
+package oidctest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GrantTypeDeviceCode is the RFC 8628 grant type for polling the token endpoint.
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// grant is who a token response is for.
+type grant struct {
+	clientID string
+	sub      string
+	claims   map[string]any
+}
+
+type deviceGrant struct {
+	userCode string
+	clientID string
+	expires  time.Time
+	state    string // "pending", "approved", "denied"
+	grant    grant
+}
+
+// DeviceExpiry bounds how long a device code can be polled.
+const DeviceExpiry = 10 * time.Minute
+
+// handleDevice starts an RFC 8628 device authorization.
+func (p *Provider) handleDevice(w http.ResponseWriter, r *http.Request) {
+	clientID := formClientID(r)
+	if clientID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	deviceCode := rand.Text()
+	userCode := strings.ToUpper(rand.Text()[:4] + "-" + rand.Text()[:4])
+	p.mu.Lock()
+	p.devices[deviceCode] = &deviceGrant{userCode: userCode, clientID: clientID, expires: time.Now().Add(DeviceExpiry), state: "pending"}
+	p.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"device_code":               deviceCode,
+		"user_code":                 userCode,
+		"verification_uri":          p.URL + "/device",
+		"verification_uri_complete": p.URL + "/device?user_code=" + userCode,
+		"expires_in":                int(DeviceExpiry.Seconds()),
+		"interval":                  1,
+	})
+}
+
+// PendingUserCodes returns the user codes of device authorizations awaiting
+// Approve or Deny, sorted.
+func (p *Provider) PendingUserCodes() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []string
+	for _, d := range p.devices {
+		if d.state == "pending" {
+			out = append(out, d.userCode)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Approve completes the device authorization for userCode as sub; the next
+// poll receives tokens carrying claims.
+func (p *Provider) Approve(userCode, sub string, claims map[string]any) error {
+	return p.decide(userCode, "approved", grant{sub: sub, claims: claims})
+}
+
+// Deny rejects the device authorization for userCode (access_denied).
+func (p *Provider) Deny(userCode string) error {
+	return p.decide(userCode, "denied", grant{})
+}
+
+func (p *Provider) decide(userCode, state string, g grant) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, d := range p.devices {
+		if d.userCode == userCode && d.state == "pending" {
+			g.clientID = d.clientID
+			d.state, d.grant = state, g
+			return nil
+		}
+	}
+	return fmt.Errorf("oidctest: no pending device authorization %q", userCode)
+}
+
+// handleToken serves the device_code, refresh_token and client_credentials
+// grants. Client secrets are not checked.
+func (p *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	clientID := formClientID(r)
+	switch r.PostFormValue("grant_type") {
+	case GrantTypeDeviceCode:
+		p.mu.Lock()
+		d, ok := p.devices[r.PostFormValue("device_code")]
+		var state string
+		if ok {
+			state = d.state
+			if time.Now().After(d.expires) {
+				state = "expired"
+			}
+			if state != "pending" {
+				delete(p.devices, r.PostFormValue("device_code"))
+			}
+		}
+		p.mu.Unlock()
+		switch {
+		case !ok || d.clientID != clientID:
+			tokenError(w, "invalid_grant")
+		case state == "pending":
+			tokenError(w, "authorization_pending")
+		case state == "denied":
+			tokenError(w, "access_denied")
+		case state == "expired":
+			tokenError(w, "expired_token")
+		default:
+			p.issue(w, d.grant, true)
+		}
+	case "refresh_token":
+		p.mu.Lock()
+		g, ok := p.refresh[r.PostFormValue("refresh_token")]
+		p.mu.Unlock()
+		if !ok || g.clientID != clientID {
+			tokenError(w, "invalid_grant")
+			return
+		}
+		p.issue(w, g, true)
+	case "client_credentials":
+		if clientID == "" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+		p.issue(w, grant{clientID: clientID, sub: clientID}, false)
+	default:
+		tokenError(w, "unsupported_grant_type")
+	}
+}
+
+// issue writes a token response for g: an access token, plus an ID token and a
+// refresh token for user grants.
+func (p *Provider) issue(w http.ResponseWriter, g grant, user bool) {
+	aud := p.AccessTokenAudience
+	if aud == "" {
+		aud = g.clientID
+	}
+	resp := map[string]any{
+		"access_token": p.AccessToken(aud, g.sub, withClaim(g.claims, "client_id", g.clientID)),
+		"token_type":   "Bearer",
+		"expires_in":   int(p.tokenTTL().Seconds()),
+	}
+	if user {
+		resp["id_token"] = p.IDToken(g.clientID, g.sub, withClaim(g.claims, "azp", g.clientID))
+		rt := rand.Text()
+		p.mu.Lock()
+		p.refresh[rt] = g
+		p.mu.Unlock()
+		resp["refresh_token"] = rt
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// withClaim returns a copy of claims with k set unless already present.
+func withClaim(claims map[string]any, k string, v any) map[string]any {
+	out := map[string]any{k: v}
+	for ck, cv := range claims {
+		out[ck] = cv
+	}
+	return out
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+// formClientID reads client_id from HTTP Basic authentication or the form.
+func formClientID(r *http.Request) string {
+	if id, _, ok := r.BasicAuth(); ok {
+		return id
+	}
+	return r.PostFormValue("client_id")
+}
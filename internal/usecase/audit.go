@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// recordAudit writes ev and logs a sink error instead of returning it. Use it
+// where the outcome must not depend on the audit trail: failures (the request
+// is already failing) and admin changes that have already been applied and
+// only tighten access (revocations, new blocklist entries). Changes that
+// loosen access, such as removing a blocklist entry, use requireAudit.
+func recordAudit(ctx context.Context, sink AuditSink, log Logger, ev domain.AuditEvent) {
+	if err := sink.Write(ctx, ev); err != nil && log != nil {
+		log.Error(ctx, "audit write failed", "action", ev.Action, "stage", ev.Stage, "error_code", ev.ErrorCode, "error", err)
+	}
+}
+
+// requireAudit writes ev and returns domain.ErrAuditUnavailable when the sink
+// refuses it, so the caller can withhold what it was about to hand out. Whether
+// a sink refuses is its failure policy: fail-closed returns the error, fail-open
+// queues the event and returns nil.
+func requireAudit(ctx context.Context, sink AuditSink, log Logger, ev domain.AuditEvent) error {
+	if err := sink.Write(ctx, ev); err != nil {
+		if log != nil {
+			log.Error(ctx, "audit write failed", "action", ev.Action, "error", err)
+		}
+		return fmt.Errorf("%w: %w", domain.ErrAuditUnavailable, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// Page sizes for audit queries.
+const (
+	DefaultAuditPageSize = 100
+	MaxAuditPageSize     = 1000
+)
+
+// AuditQueryInput carries the normalized inputs for searching the audit log.
+type AuditQueryInput struct {
+	Bearer   string
+	Query    domain.AuditQuery
+	SourceIP string
+	TraceID  string
+}
+
+// AuditQueryOutput is one page of records, newest first. Next is the cursor for
+// the following page (AuditQuery.Before), or 0 on the last page.
+type AuditQueryOutput struct {
+	Records []domain.AuditRecord
+	Next    uint64
+}
+
+// AuditQueryService lets admins search stored audit events. Queries are reads
+// and are not audited, like blocklist listings.
+type AuditQueryService struct {
+	Log   Logger
+	Auth  Authenticator
+	Admin AdminAuthorizer
+	Store AuditStore
+}
+
+func NewAuditQueryService(deps AuditQueryService) *AuditQueryService { return &deps }
+
+// Execute authenticates an admin and returns one page of matching records.
+// A zero Limit uses DefaultAuditPageSize; larger limits are capped at MaxAuditPageSize.
+func (svc *AuditQueryService) Execute(ctx context.Context, in AuditQueryInput) (AuditQueryOutput, error) {
+	if in.Bearer == "" {
+		return AuditQueryOutput{}, fmt.Errorf("%w: missing bearer", domain.ErrInvalidToken)
+	}
+	admin, err := svc.Auth.Authenticate(ctx, in.Bearer)
+	if err != nil {
+		return AuditQueryOutput{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
+	}
+	if err := svc.Admin.AuthorizeAdmin(admin); err != nil {
+		return AuditQueryOutput{}, err
+	}
+	q := in.Query
+	if err := q.Validate(); err != nil {
+		return AuditQueryOutput{}, err
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultAuditPageSize
+	}
+	q.Limit = min(q.Limit, MaxAuditPageSize)
+
+	// Fetch one extra record to learn whether another page exists.
+	page := q.Limit
+	q.Limit++
+	recs, err := svc.Store.Query(ctx, q)
+	if err != nil {
+		if svc.Log != nil {
+			svc.Log.Error(ctx, "audit query failed", "error", err, "trace_id", in.TraceID)
+		}
+		return AuditQueryOutput{}, fmt.Errorf("audit query: %w", err)
+	}
+	out := AuditQueryOutput{Records: recs}
+	if len(recs) > page {
+		out.Records = recs[:page]
+		out.Next = recs[page-1].ID
+	}
+	if svc.Log != nil {
+		svc.Log.Debug(ctx, "audit queried", "by", admin.Subject, "results", len(out.Records))
+	}
+	return out, nil
+}
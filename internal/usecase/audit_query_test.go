@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// fakeAuditStore keeps records in insertion order and filters with AuditQuery.Match.
+type fakeAuditStore struct{ recs []domain.AuditRecord }
+
+func (f *fakeAuditStore) Write(ctx context.Context, ev domain.AuditEvent) error {
+	f.recs = append(f.recs, domain.AuditRecord{ID: uint64(len(f.recs) + 1), AuditEvent: ev})
+	return nil
+}
+
+func (f *fakeAuditStore) Query(ctx context.Context, q domain.AuditQuery) ([]domain.AuditRecord, error) {
+	var out []domain.AuditRecord
+	for i := len(f.recs) - 1; i >= 0 && (q.Limit <= 0 || len(out) < q.Limit); i-- {
+		if q.Match(f.recs[i]) {
+			out = append(out, f.recs[i])
+		}
+	}
+	return out, nil
+}
+
+func newAuditQuerySvc(admin AdminAuthorizer, store AuditStore) *AuditQueryService {
+	return NewAuditQueryService(AuditQueryService{
+		Log:   nolog{},
+		Auth:  fakeAuth{id: domain.Identity{Subject: "admin"}},
+		Admin: admin,
+		Store: store,
+	})
+}
+
+func TestAuditQuery_Pagination(t *testing.T) {
+	store := &fakeAuditStore{}
+	now := time.Unix(1_700_000_000, 0).UTC()
+	for i := uint64(1); i <= 5; i++ {
+		ev := domain.NewAuditSuccess(domain.ActionIssueUserCert, domain.Identity{Subject: "alice"}, []string{"alice"}, i, now, now.Add(time.Hour), domain.SignContext{Now: now}, nil)
+		_ = store.Write(context.Background(), ev)
+	}
+	svc := newAuditQuerySvc(fakeAdmin{}, store)
+	ctx := context.Background()
+
+	var serials []uint64
+	in := AuditQueryInput{Bearer: "t", Query: domain.AuditQuery{Subject: "alice", Limit: 2}}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("pagination does not terminate")
+		}
+		out, err := svc.Execute(ctx, in)
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		for _, r := range out.Records {
+			serials = append(serials, *r.Serial)
+		}
+		if out.Next == 0 {
+			break
+		}
+		in.Query.Before = out.Next
+	}
+	if len(serials) != 5 || serials[0] != 5 || serials[4] != 1 {
+		t.Fatalf("unexpected serials across pages: %v", serials)
+	}
+}
+
+func TestAuditQuery_Errors(t *testing.T) {
+	ctx := context.Background()
+	svc := newAuditQuerySvc(fakeAdmin{}, &fakeAuditStore{})
+	if _, err := svc.Execute(ctx, AuditQueryInput{}); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+	if _, err := svc.Execute(ctx, AuditQueryInput{Bearer: "t", Query: domain.AuditQuery{Outcome: "maybe"}}); !errors.Is(err, domain.ErrInvalidAuditQuery) {
+		t.Fatalf("expected ErrInvalidAuditQuery, got %v", err)
+	}
+	denied := newAuditQuerySvc(fakeAdmin{err: domain.PolicyDeny{Code: "ADMIN_REQUIRED"}}, &fakeAuditStore{})
+	var pd domain.PolicyDeny
+	if _, err := denied.Execute(ctx, AuditQueryInput{Bearer: "t"}); !errors.As(err, &pd) {
+		t.Fatalf("expected PolicyDeny, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// BlocklistInput carries the normalized inputs for blocklist administration.
+// Kind/Value/Reason are ignored by List.
+type BlocklistInput struct {
+	Bearer   string
+	Kind     domain.BlockKind
+	Value    string
+	Reason   string
+	SourceIP string
+	TraceID  string
+}
+
+// BlocklistService lets admins list, add and remove blocklist entries.
+// Every change is audited; reads are not.
+type BlocklistService struct {
+	Log   Logger
+	Auth  Authenticator
+	Admin AdminAuthorizer
+	Block Blocklist
+	Audit AuditSink
+	Clock Clock
+}
+
+func NewBlocklistService(deps BlocklistService) *BlocklistService { return &deps }
+
+// List returns all blocklist entries.
+func (svc *BlocklistService) List(ctx context.Context, in BlocklistInput) ([]domain.BlockEntry, error) {
+	if _, _, err := svc.authorize(ctx, in, ""); err != nil {
+		return nil, err
+	}
+	return svc.Block.List(ctx)
+}
+
+// Add blocks a key fingerprint or subject from further issuance.
+func (svc *BlocklistService) Add(ctx context.Context, in BlocklistInput) (domain.BlockEntry, error) {
+	admin, signCtx, err := svc.authorize(ctx, in, domain.ActionBlocklistAdd)
+	if err != nil {
+		return domain.BlockEntry{}, err
+	}
+	entry := domain.BlockEntry{
+		Kind:    in.Kind,
+		Value:   strings.TrimSpace(in.Value),
+		Reason:  in.Reason,
+		AddedBy: admin.Subject,
+		Time:    signCtx.Now,
+	}
+	if err := entry.Validate(); err != nil {
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionBlocklistAdd, domain.StageInput, admin, nil, signCtx, err, nil))
+		return domain.BlockEntry{}, err
+	}
+	if err := svc.Block.Add(ctx, entry); err != nil {
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionBlocklistAdd, domain.StageBlocklist, admin, nil, signCtx, err, nil))
+		return domain.BlockEntry{}, err
+	}
+	recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditBlocklist(domain.ActionBlocklistAdd, admin, entry, signCtx))
+	if svc.Log != nil {
+		svc.Log.Info(ctx, "blocklist entry added", "kind", entry.Kind, "added_by", admin.Subject)
+	}
+	return entry, nil
+}
+
+// Remove lifts a block on a key fingerprint or subject. Unblocking loosens
+// access, so it is audited before the entry is removed and refused with
+// domain.ErrAuditUnavailable when the audit trail rejects it.
+func (svc *BlocklistService) Remove(ctx context.Context, in BlocklistInput) (domain.BlockEntry, error) {
+	admin, signCtx, err := svc.authorize(ctx, in, domain.ActionBlocklistRemove)
+	if err != nil {
+		return domain.BlockEntry{}, err
+	}
+	value := strings.TrimSpace(in.Value)
+	entry, err := svc.find(ctx, in.Kind, value)
+	if err != nil {
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionBlocklistRemove, domain.StageBlocklist, admin, nil, signCtx, err, nil))
+		return domain.BlockEntry{}, err
+	}
+	entry.Reason = in.Reason
+	if err := requireAudit(ctx, svc.Audit, svc.Log, domain.NewAuditBlocklist(domain.ActionBlocklistRemove, admin, entry, signCtx)); err != nil {
+		return domain.BlockEntry{}, err
+	}
+	if _, err := svc.Block.Remove(ctx, in.Kind, value); err != nil {
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionBlocklistRemove, domain.StageBlocklist, admin, nil, signCtx, err, nil))
+		return domain.BlockEntry{}, err
+	}
+	if svc.Log != nil {
+		svc.Log.Info(ctx, "blocklist entry removed", "kind", entry.Kind, "removed_by", admin.Subject)
+	}
+	return entry, nil
+}
+
+// find returns the entry for (kind, value), or domain.ErrBlockNotFound.
+func (svc *BlocklistService) find(ctx context.Context, kind domain.BlockKind, value string) (domain.BlockEntry, error) {
+	entries, err := svc.Block.List(ctx)
+	if err != nil {
+		return domain.BlockEntry{}, err
+	}
+	for _, e := range entries {
+		if e.Kind == kind && e.Value == value {
+			return e, nil
+		}
+	}
+	return domain.BlockEntry{}, domain.ErrBlockNotFound
+}
+
+// authorize authenticates the caller and requires an admin. Failures are audited
+// under action; an empty action (reads) skips auditing.
+func (svc *BlocklistService) authorize(ctx context.Context, in BlocklistInput, action domain.AuditAction) (domain.Identity, domain.SignContext, error) {
+	signCtx := domain.SignContext{
+		SourceIP: in.SourceIP,
+		Now:      svc.Clock.Now(),
+		TraceID:  in.TraceID,
+	}
+	fail := func(stage domain.AuditStage, id domain.Identity, err error) (domain.Identity, domain.SignContext, error) {
+		if action != "" {
+			recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(action, stage, id, nil, signCtx, err, nil))
+		}
+		return domain.Identity{}, signCtx, err
+	}
+	if in.Bearer == "" {
+		return fail(domain.StageAuthn, domain.Identity{}, fmt.Errorf("%w: missing bearer", domain.ErrInvalidToken))
+	}
+	admin, err := svc.Auth.Authenticate(ctx, in.Bearer)
+	if err != nil {
+		return fail(domain.StageAuthn, domain.Identity{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err))
+	}
+	if err := svc.Admin.AuthorizeAdmin(admin); err != nil {
+		return fail(domain.StageAuthz, admin, err)
+	}
+	return admin, signCtx, nil
+}
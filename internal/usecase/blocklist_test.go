@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+type fakeBlock struct {
+	entries map[domain.BlockKind]map[string]domain.BlockEntry
+}
+
+func (f *fakeBlock) Check(ctx context.Context, subject, keyFP string) error {
+	if _, ok := f.entries[domain.BlockSubject][subject]; ok {
+		return domain.BlockedError{Kind: domain.BlockSubject}
+	}
+	if _, ok := f.entries[domain.BlockKey][keyFP]; ok {
+		return domain.BlockedError{Kind: domain.BlockKey}
+	}
+	return nil
+}
+
+func (f *fakeBlock) Add(ctx context.Context, e domain.BlockEntry) error {
+	if f.entries == nil {
+		f.entries = map[domain.BlockKind]map[string]domain.BlockEntry{}
+	}
+	if f.entries[e.Kind] == nil {
+		f.entries[e.Kind] = map[string]domain.BlockEntry{}
+	}
+	f.entries[e.Kind][e.Value] = e
+	return nil
+}
+
+func (f *fakeBlock) Remove(ctx context.Context, kind domain.BlockKind, value string) (domain.BlockEntry, error) {
+	e, ok := f.entries[kind][value]
+	if !ok {
+		return domain.BlockEntry{}, domain.ErrBlockNotFound
+	}
+	delete(f.entries[kind], value)
+	return e, nil
+}
+
+func (f *fakeBlock) List(ctx context.Context) ([]domain.BlockEntry, error) {
+	var out []domain.BlockEntry
+	for _, m := range f.entries {
+		for _, e := range m {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func newBlocklistSvc(admin AdminAuthorizer, block Blocklist, aud AuditSink) *BlocklistService {
+	return NewBlocklistService(BlocklistService{
+		Log:   nolog{},
+		Auth:  fakeAuth{id: domain.Identity{Subject: "admin"}},
+		Admin: admin,
+		Block: block,
+		Audit: aud,
+		Clock: fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+	})
+}
+
+func TestBlocklist_AddListRemove(t *testing.T) {
+	block := &fakeBlock{}
+	aud := &sink{}
+	svc := newBlocklistSvc(fakeAdmin{}, block, aud)
+	ctx := context.Background()
+
+	e, err := svc.Add(ctx, BlocklistInput{Bearer: "t", Kind: domain.BlockKey, Value: " SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0 ", Reason: "leaked"})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if e.Value != "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0" || e.AddedBy != "admin" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if aud.last.Action != domain.ActionBlocklistAdd || !aud.last.Success() || aud.last.KeyFP != "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0" {
+		t.Fatalf("expected add audit, got: %+v", aud.last)
+	}
+	list, err := svc.List(ctx, BlocklistInput{Bearer: "t"})
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List = %+v, %v", list, err)
+	}
+	if _, err := svc.Remove(ctx, BlocklistInput{Bearer: "t", Kind: domain.BlockKey, Value: "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0"}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if aud.last.Action != domain.ActionBlocklistRemove || !aud.last.Success() {
+		t.Fatalf("expected remove audit, got: %+v", aud.last)
+	}
+	if _, err := svc.Remove(ctx, BlocklistInput{Bearer: "t", Kind: domain.BlockKey, Value: "SHA256:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0"}); !errors.Is(err, domain.ErrBlockNotFound) {
+		t.Fatalf("expected ErrBlockNotFound, got %v", err)
+	}
+}
+
+// Unblocking loosens access, so it is refused when it cannot be audited.
+func TestBlocklist_RemoveAuditRequired(t *testing.T) {
+	block := &fakeBlock{}
+	_ = block.Add(context.Background(), domain.BlockEntry{Kind: domain.BlockSubject, Value: "mallory"})
+	svc := newBlocklistSvc(fakeAdmin{}, block, failSink{err: errors.New("audit disk full")})
+	if _, err := svc.Remove(context.Background(), BlocklistInput{Bearer: "t", Kind: domain.BlockSubject, Value: "mallory"}); !errors.Is(err, domain.ErrAuditUnavailable) {
+		t.Fatalf("expected ErrAuditUnavailable, got %v", err)
+	}
+	if err := block.Check(context.Background(), "mallory", ""); !errors.Is(err, domain.ErrBlocked) {
+		t.Fatalf("unaudited removal lifted the block: %v", err)
+	}
+}
+
+func TestBlocklist_InvalidEntry(t *testing.T) {
+	aud := &sink{}
+	svc := newBlocklistSvc(fakeAdmin{}, &fakeBlock{}, aud)
+	_, err := svc.Add(context.Background(), BlocklistInput{Bearer: "t", Kind: domain.BlockKey, Value: "not-a-fingerprint"})
+	if !errors.Is(err, domain.ErrInvalidBlockEntry) {
+		t.Fatalf("expected ErrInvalidBlockEntry, got %v", err)
+	}
+	if aud.last.Stage != domain.StageInput || aud.last.ErrorCode != domain.CodeInvalidBlockEntry {
+		t.Fatalf("expected INPUT failure audit, got: %+v", aud.last)
+	}
+}
+
+func TestBlocklist_RequiresAdmin(t *testing.T) {
+	aud := &sink{}
+	svc := newBlocklistSvc(fakeAdmin{err: domain.PolicyDeny{Code: domain.DenyRoleMissing}}, &fakeBlock{}, aud)
+	if _, err := svc.List(context.Background(), BlocklistInput{Bearer: "t"}); err == nil {
+		t.Fatalf("expected deny for list")
+	}
+	if _, err := svc.Add(context.Background(), BlocklistInput{Bearer: "t", Kind: domain.BlockSubject, Value: "s"}); err == nil {
+		t.Fatalf("expected deny for add")
+	}
+	if aud.last.Action != domain.ActionBlocklistAdd || aud.last.Stage != domain.StageAuthz {
+		t.Fatalf("expected AUTHZ failure audit, got: %+v", aud.last)
+	}
+}
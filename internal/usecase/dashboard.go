@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"crypto"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// Dashboard defaults.
+const (
+	DefaultDashboardWindow = 24 * time.Hour
+	DefaultDashboardRecent = 50
+	// maxDashboardDenials bounds how many failures are grouped per request.
+	maxDashboardDenials = 10 * MaxAuditPageSize
+)
+
+// issueActions are the audit actions that hand out a certificate.
+var issueActions = []domain.AuditAction{domain.ActionIssueUserCert, domain.ActionRenewUserCert}
+
+// DashboardInput carries the caller's credentials.
+type DashboardInput struct {
+	Bearer   string
+	SourceIP string
+	TraceID  string
+}
+
+// DenialCount is the number of refused issuances sharing a reason: the policy
+// DenyCode when one was recorded, else the error code (e.g. AUTH_INVALID_TOKEN).
+type DenialCount struct {
+	Code  string
+	Count int
+	Last  time.Time
+}
+
+// DashboardOutput is a read-only snapshot for operators.
+type DashboardOutput struct {
+	Now    time.Time
+	Window time.Duration // Denials cover [Now-Window, Now)
+
+	Issued           []domain.AuditRecord // recent successful issuances and renewals, newest first
+	Denials          []DenialCount        // most frequent first
+	DenialsTruncated bool                 // more failures than were grouped
+	Active           []domain.CertRecord  // valid at Now, unrevoked, ordered by serial
+	CAKeys           []crypto.PublicKey   // signing keys; adapters fingerprint them
+}
+
+// DashboardService gathers recent audit activity, live certificates and CA keys
+// for the admin web UI. Like other reads it is not audited.
+type DashboardService struct {
+	Log   Logger
+	Clock Clock
+	Auth  Authenticator
+	Admin AdminAuthorizer
+	Audit AuditStore    // optional; without it Issued and Denials stay empty
+	Certs CertStore     // optional
+	Keys  []CAKeySource // optional
+
+	Window time.Duration // zero uses DefaultDashboardWindow
+	Recent int           // zero uses DefaultDashboardRecent
+}
+
+func NewDashboardService(deps DashboardService) *DashboardService { return &deps }
+
+// Execute authenticates an admin and assembles the snapshot.
+func (svc *DashboardService) Execute(ctx context.Context, in DashboardInput) (DashboardOutput, error) {
+	if in.Bearer == "" {
+		return DashboardOutput{}, fmt.Errorf("%w: missing bearer", domain.ErrInvalidToken)
+	}
+	admin, err := svc.Auth.Authenticate(ctx, in.Bearer)
+	if err != nil {
+		return DashboardOutput{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
+	}
+	if err := svc.Admin.AuthorizeAdmin(admin); err != nil {
+		return DashboardOutput{}, err
+	}
+
+	out := DashboardOutput{Now: svc.Clock.Now().UTC(), Window: svc.Window}
+	if out.Window <= 0 {
+		out.Window = DefaultDashboardWindow
+	}
+	if svc.Audit != nil {
+		recent := svc.Recent
+		if recent <= 0 {
+			recent = DefaultDashboardRecent
+		}
+		out.Issued, err = svc.issued(ctx, min(recent, MaxAuditPageSize))
+		if err != nil {
+			return DashboardOutput{}, svc.fail(ctx, in, "dashboard: recent issuances", err)
+		}
+		if err := svc.denials(ctx, &out); err != nil {
+			return DashboardOutput{}, svc.fail(ctx, in, "dashboard: denials", err)
+		}
+	}
+	if svc.Certs != nil {
+		out.Active, err = svc.active(ctx, out.Now)
+		if err != nil {
+			return DashboardOutput{}, svc.fail(ctx, in, "dashboard: certificates", err)
+		}
+	}
+	for _, k := range svc.Keys {
+		signer, err := k.Load(ctx)
+		if err != nil {
+			return DashboardOutput{}, svc.fail(ctx, in, "dashboard: CA key", err)
+		}
+		out.CAKeys = append(out.CAKeys, signer.Public())
+	}
+	if svc.Log != nil {
+		svc.Log.Debug(ctx, "dashboard viewed", "by", admin.Subject)
+	}
+	return out, nil
+}
+
+// issued returns the newest limit successful issuances and renewals. The store
+// matches one action per query, so each is queried and the results merged by ID.
+func (svc *DashboardService) issued(ctx context.Context, limit int) ([]domain.AuditRecord, error) {
+	var out []domain.AuditRecord
+	for _, action := range issueActions {
+		recs, err := svc.Audit.Query(ctx, domain.AuditQuery{Action: action, Outcome: domain.OutcomeSuccess, Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, recs...)
+	}
+	slices.SortFunc(out, func(a, b domain.AuditRecord) int { return cmp.Compare(b.ID, a.ID) })
+	return out[:min(len(out), limit)], nil
+}
+
+// denials groups failed issuances and renewals in the window, paging through
+// the store.
+func (svc *DashboardService) denials(ctx context.Context, out *DashboardOutput) error {
+	byCode := map[string]*DenialCount{}
+	seen := 0
+	for _, action := range issueActions {
+		if err := svc.groupDenials(ctx, out, action, byCode, &seen); err != nil {
+			return err
+		}
+	}
+	for _, c := range byCode {
+		out.Denials = append(out.Denials, *c)
+	}
+	sort.Slice(out.Denials, func(i, j int) bool {
+		a, b := out.Denials[i], out.Denials[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Code < b.Code
+	})
+	return nil
+}
+
+// groupDenials adds the failures of one action to byCode until seen reaches
+// maxDashboardDenials.
+func (svc *DashboardService) groupDenials(ctx context.Context, out *DashboardOutput, action domain.AuditAction, byCode map[string]*DenialCount, seen *int) error {
+	q := domain.AuditQuery{
+		Action:  action,
+		Outcome: domain.OutcomeFailure,
+		Since:   out.Now.Add(-out.Window),
+		Until:   out.Now,
+		Limit:   MaxAuditPageSize,
+	}
+	for {
+		if *seen >= maxDashboardDenials {
+			out.DenialsTruncated = true
+			return nil
+		}
+		recs, err := svc.Audit.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		for _, r := range recs {
+			code := string(r.DenyCode())
+			if code == "" {
+				code = string(r.ErrorCode)
+			}
+			c := byCode[code]
+			if c == nil {
+				c = &DenialCount{Code: code}
+				byCode[code] = c
+			}
+			c.Count++
+			if r.Time.After(c.Last) {
+				c.Last = r.Time
+			}
+		}
+		*seen += len(recs)
+		if len(recs) < q.Limit {
+			return nil
+		}
+		q.Before = recs[len(recs)-1].ID
+	}
+}
+
+// active returns certificates valid at now that no revocation covers.
+func (svc *DashboardService) active(ctx context.Context, now time.Time) ([]domain.CertRecord, error) {
+	recs, err := svc.Certs.List(ctx, domain.CertFilter{ActiveAt: now})
+	if err != nil {
+		return nil, err
+	}
+	revs, err := svc.Certs.Revocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(recs, func(rec domain.CertRecord) bool {
+		return slices.ContainsFunc(revs, func(r domain.Revocation) bool { return r.Matches(rec) })
+	}), nil
+}
+
+func (svc *DashboardService) fail(ctx context.Context, in DashboardInput, what string, err error) error {
+	if svc.Log != nil {
+		svc.Log.Error(ctx, what+" failed", "error", err, "trace_id", in.TraceID)
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
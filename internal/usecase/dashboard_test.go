@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+func TestDashboard_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	store := &fakeAuditStore{}
+	at := func(d time.Duration) domain.SignContext { return domain.SignContext{Now: now.Add(-d)} }
+	alice := domain.Identity{Subject: "alice"}
+	for i := uint64(1); i <= 3; i++ {
+		_ = store.Write(ctx, domain.NewAuditSuccess(domain.ActionIssueUserCert, alice, []string{"alice"}, i, now, now.Add(time.Hour), at(time.Minute), nil))
+	}
+	_ = store.Write(ctx, domain.NewAuditSuccess(domain.ActionRenewUserCert, alice, []string{"alice"}, 4, now, now.Add(time.Hour), at(time.Minute), nil))
+	deny := domain.PolicyDeny{Code: domain.DenyPrincipalNotAllowed}
+	_ = store.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthz, alice, nil, at(48*time.Hour), deny, nil)) // outside the window
+	_ = store.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthz, alice, nil, at(2*time.Hour), deny, nil))
+	_ = store.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthz, alice, nil, at(time.Hour), deny, nil))
+	_ = store.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthn, domain.Identity{}, nil, at(time.Hour), domain.ErrInvalidToken, nil))
+	_ = store.Write(ctx, domain.NewAuditFailure(domain.ActionRenewUserCert, domain.StageAuthn, domain.Identity{}, nil, at(time.Hour), domain.ErrSessionExpired, nil))
+
+	certs := &fakeCerts{}
+	for _, rec := range []domain.CertRecord{
+		{Serial: 1, Subject: "alice", NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+		{Serial: 2, Subject: "alice", NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+		{Serial: 3, Subject: "bob", NotBefore: now.Add(-2 * time.Hour), NotAfter: now.Add(-time.Hour)},
+	} {
+		_ = certs.Put(ctx, rec)
+	}
+	certs.revs = []domain.Revocation{{Kind: domain.RevokeSerial, Serial: 2}}
+
+	_, priv, _ := ed25519.GenerateKey(nil)
+	svc := NewDashboardService(DashboardService{
+		Log:    nolog{},
+		Clock:  fakeClock{t: now},
+		Auth:   fakeAuth{id: domain.Identity{Subject: "admin"}},
+		Admin:  fakeAdmin{},
+		Audit:  store,
+		Certs:  certs,
+		Keys:   []CAKeySource{fakeCAKeySource{s: priv}},
+		Recent: 2,
+	})
+	out, err := svc.Execute(ctx, DashboardInput{Bearer: "t"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	// Renewals hand out certificates too and are listed with issuances.
+	if len(out.Issued) != 2 || *out.Issued[0].Serial != 4 || *out.Issued[1].Serial != 3 {
+		t.Fatalf("unexpected issued: %+v", out.Issued)
+	}
+	if len(out.Denials) != 3 || out.Denials[0].Code != string(domain.DenyPrincipalNotAllowed) || out.Denials[0].Count != 2 ||
+		!out.Denials[0].Last.Equal(now.Add(-time.Hour)) || !slices.ContainsFunc(out.Denials, func(d DenialCount) bool { return d.Code == string(domain.CodeSessionExpired) }) {
+		t.Fatalf("unexpected denials: %+v", out.Denials)
+	}
+	if len(out.Active) != 1 || out.Active[0].Serial != 1 {
+		t.Fatalf("unexpected active certs: %+v", out.Active)
+	}
+	if len(out.CAKeys) != 1 || !priv.Public().(ed25519.PublicKey).Equal(out.CAKeys[0]) {
+		t.Fatalf("unexpected CA keys: %v", out.CAKeys)
+	}
+}
+
+func TestDashboard_RequiresAdmin(t *testing.T) {
+	ctx := context.Background()
+	svc := NewDashboardService(DashboardService{
+		Clock: fakeClock{t: time.Unix(0, 0)},
+		Auth:  fakeAuth{id: domain.Identity{Subject: "dev"}},
+		Admin: fakeAdmin{err: domain.PolicyDeny{Code: domain.DenyRoleMissing}},
+	})
+	if _, err := svc.Execute(ctx, DashboardInput{}); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+	var pd domain.PolicyDeny
+	if _, err := svc.Execute(ctx, DashboardInput{Bearer: "t"}); !errors.As(err, &pd) {
+		t.Fatalf("expected PolicyDeny, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// GetKRLOutput is a binary OpenSSH KRL and its version.
+type GetKRLOutput struct {
+	KRL     []byte
+	Version uint64
+}
+
+// GetKRLService builds the current Key Revocation List from persisted revocations.
+type GetKRLService struct {
+	Certs CertStore
+	KRL   KRLGenerator
+	Clock Clock
+	Log   Logger
+}
+
+func NewGetKRLService(certs CertStore, krl KRLGenerator, clk Clock, log Logger) *GetKRLService {
+	return &GetKRLService{Certs: certs, KRL: krl, Clock: clk, Log: log}
+}
+
+func (s *GetKRLService) Execute(ctx context.Context) (GetKRLOutput, error) {
+	now := s.Clock.Now()
+	revs, err := s.Certs.Revocations(ctx)
+	if err != nil {
+		return GetKRLOutput{}, err
+	}
+	// Subject revocations expand to the serials of certificates that are still valid.
+	active, err := s.Certs.List(ctx, domain.CertFilter{ActiveAt: now})
+	if err != nil {
+		return GetKRLOutput{}, err
+	}
+	spec := domain.BuildKRLSpec(revs, active, now)
+	krl, err := s.KRL.Generate(ctx, spec)
+	if err != nil {
+		return GetKRLOutput{}, err
+	}
+	if len(krl) == 0 {
+		return GetKRLOutput{}, errors.New("krl generator returned empty krl")
+	}
+	return GetKRLOutput{KRL: krl, Version: spec.Version}, nil
+}
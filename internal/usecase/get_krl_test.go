@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+type fakeKRL struct{ spec domain.KRLSpec }
+
+func (f *fakeKRL) Generate(ctx context.Context, spec domain.KRLSpec) ([]byte, error) {
+	f.spec = spec
+	return []byte("SSHKRL"), nil
+}
+
+func TestGetKRLService(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	certs := &fakeCerts{
+		recs: map[uint64]domain.CertRecord{
+			1: {Serial: 1, Subject: "alice", NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)},
+			2: {Serial: 2, Subject: "alice", NotBefore: now.Add(-3 * time.Hour), NotAfter: now.Add(-time.Hour)},
+		},
+		revs: []domain.Revocation{{Kind: domain.RevokeSubject, Subject: "alice", Time: now}},
+	}
+	gen := &fakeKRL{}
+	svc := NewGetKRLService(certs, gen, fakeClock{t: now}, nopLog{})
+	out, err := svc.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if string(out.KRL) != "SSHKRL" || out.Version != uint64(now.Unix()) {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if len(gen.spec.Serials) != 1 || gen.spec.Serials[0] != 1 {
+		t.Fatalf("expected only the active serial, got %v", gen.spec.Serials)
+	}
+}
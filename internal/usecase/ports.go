@@ -25,6 +25,21 @@ type Logger interface {
 	WithGroup(name string) Logger
 }
 
+// Tracer starts spans around the stages of a usecase. Implementations live in
+// adapters (e.g., OpenTelemetry); services treat a nil Tracer as disabled.
+type Tracer interface {
+	// Start opens a span named name as a child of any span in ctx and returns
+	// a context carrying it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced stage. Attribute values are strings, bools, ints, uint64s
+// or string slices. End records err (nil = success) as the stage outcome.
+type Span interface {
+	SetAttr(key string, value any)
+	End(err error)
+}
+
 // Authorizer decides principals/ttl/options based on identity and context.
 // Implementations live in adapters.
 type Authorizer interface {
@@ -41,18 +56,75 @@ type Signer interface {
 	Sign(spec domain.CertSpec, serial uint64) (cert []byte, keyFP string, err error)
 }
 
+// CertVerifier checks certificates presented back to Kamini, for renewal.
+// Implementations live in adapters (e.g., signer/ssh, which holds the CA key).
+type CertVerifier interface {
+	// VerifyPossession checks that cert (authorized-key form) is a user
+	// certificate signed by this CA and valid at now, and that sig is its key's
+	// signature over msg. It returns the certificate serial and the SHA256
+	// fingerprint of its key. Failed checks wrap domain.ErrInvalidCertificate.
+	VerifyPossession(ctx context.Context, cert string, msg, sig []byte, now time.Time) (serial uint64, keyFP string, err error)
+}
+
 // Authenticator verifies client credentials (e.g., OIDC bearer) and yields a normalized Identity.
 // Implementations live in adapters (e.g., go-oidc based).
 type Authenticator interface {
 	Authenticate(ctx context.Context, bearer string) (domain.Identity, error)
 }
 
+// GroupResolver looks up group memberships a token could not carry (e.g. Entra
+// ID groups overage). Authenticators call it; implementations live in adapters
+// (e.g., Microsoft Graph) and should cache per subject.
+type GroupResolver interface {
+	ResolveGroups(ctx context.Context, id domain.Identity) ([]string, error)
+}
+
 // SerialStore provides monotonically increasing certificate serial numbers.
 // Implementations may be in-memory for dev, sqlite/postgres for prod.
 type SerialStore interface {
 	Next(ctx context.Context) (uint64, error)
 }
 
+// CertStore persists issued certificate records and revocations.
+// Implementations may be in-memory for dev, file or sqlite/postgres for prod.
+type CertStore interface {
+	// Put records a freshly issued certificate.
+	Put(ctx context.Context, rec domain.CertRecord) error
+	// Get returns the record for serial, or domain.ErrCertNotFound.
+	Get(ctx context.Context, serial uint64) (domain.CertRecord, error)
+	// List returns records matching the filter, ordered by serial.
+	List(ctx context.Context, f domain.CertFilter) ([]domain.CertRecord, error)
+	// Revoke persists a revocation entry, setting its Seq to
+	// domain.NextRevocationSeq of the entries already stored.
+	Revoke(ctx context.Context, rev domain.Revocation) error
+	// Revocations returns all persisted revocation entries.
+	Revocations(ctx context.Context) ([]domain.Revocation, error)
+}
+
+// Blocklist bars public keys (by SHA256 fingerprint) and subjects from issuance.
+// Implementations may be in-memory for dev, file or sqlite/postgres for prod.
+type Blocklist interface {
+	// Check returns a domain.BlockedError when subject or keyFP is blocked.
+	Check(ctx context.Context, subject, keyFP string) error
+	// Add inserts or replaces the entry for (Kind, Value).
+	Add(ctx context.Context, e domain.BlockEntry) error
+	// Remove deletes and returns the entry, or returns domain.ErrBlockNotFound.
+	Remove(ctx context.Context, kind domain.BlockKind, value string) (domain.BlockEntry, error)
+	// List returns all entries.
+	List(ctx context.Context) ([]domain.BlockEntry, error)
+}
+
+// AdminAuthorizer decides whether an identity may use administrative operations
+// (revocation, blocklists). Returns a domain.PolicyDeny when not permitted.
+type AdminAuthorizer interface {
+	AuthorizeAdmin(id domain.Identity) error
+}
+
+// KRLGenerator encodes a KRLSpec as a binary OpenSSH Key Revocation List signed by the CA.
+type KRLGenerator interface {
+	Generate(ctx context.Context, spec domain.KRLSpec) ([]byte, error)
+}
+
 // CAKeySource provides access to CA private key material for signing.
 // Adapters implement this to retrieve keys from disk or KMS.
 // For MVP we support unencrypted ed25519 keys.
@@ -65,6 +137,13 @@ type AuditSink interface {
 	Write(ctx context.Context, ev domain.AuditEvent) error
 }
 
+// AuditStore is an AuditSink that can be searched (sqlite/postgres).
+type AuditStore interface {
+	AuditSink
+	// Query returns records matching q, newest (highest ID) first, at most q.Limit.
+	Query(ctx context.Context, q domain.AuditQuery) ([]domain.AuditRecord, error)
+}
+
 // AgentLoader loads a keypair + certificate into an SSH agent for a bounded lifetime (CLI-side).
 // Implementations live in adapters and should avoid persisting secrets unless explicitly requested.
 type AgentLoader interface {
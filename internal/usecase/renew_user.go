@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// RenewUserInput carries a renewal request: a currently valid certificate and
+// its key's signature over the domain.RenewalChallenge for the key to certify.
+type RenewUserInput struct {
+	Certificate         string    // presented certificate, authorized-key form
+	PublicKeyAuthorized string    // key to certify (may be the certificate's own)
+	Time                time.Time // challenge time chosen by the client
+	Signature           []byte    // SSH wire-format signature over the challenge
+	RequestedTTL        time.Duration
+	SourceIP            string
+	TraceID             string
+}
+
+// RenewUserOutput is the issued certificate plus where the session stands.
+type RenewUserOutput struct {
+	SignUserOutput
+	RenewedFrom uint64    // serial of the presented certificate
+	AuthTime    time.Time // original IdP login
+	SessionEnd  time.Time // no renewal (or certificate) reaches past this
+}
+
+// RenewUserService orchestrates Proof -> Lookup -> Session -> Blocklist -> AuthZ
+// -> Serial -> Spec -> Sign -> Audit -> Record, issuing a certificate for the
+// identity recorded with a presented one, without the IdP. Policy is
+// re-evaluated against that identity, and certificates never outlive
+// MaxSession since the original IdP login.
+type RenewUserService struct {
+	Log        Logger
+	Verify     CertVerifier
+	Authz      Authorizer
+	Seq        SerialStore
+	Signer     Signer
+	Certs      CertStore // required: the presented certificate's record holds the identity
+	Block      Blocklist // optional; when set, blocklisted keys/subjects are refused
+	Audit      AuditSink
+	Clock      Clock
+	TTL        domain.TTL    // policy TTL (default, max)
+	MaxSession time.Duration // since the original IdP login; 0 disables renewal
+}
+
+func NewRenewUserService(deps RenewUserService) *RenewUserService { return &deps }
+
+// Execute verifies the proof of possession and issues the renewed certificate.
+func (svc *RenewUserService) Execute(ctx context.Context, in RenewUserInput) (RenewUserOutput, error) {
+	now := svc.Clock.Now()
+	signCtx := domain.SignContext{
+		RequestedTTL: in.RequestedTTL,
+		SourceIP:     in.SourceIP,
+		Now:          now,
+		TraceID:      in.TraceID,
+	}
+	var id domain.Identity
+	fail := func(stage domain.AuditStage, principals []string, err error, attrs map[string]string) (RenewUserOutput, error) {
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionRenewUserCert, stage, id, principals, signCtx, err, attrs))
+		return RenewUserOutput{}, err
+	}
+
+	if in.Certificate == "" || len(in.Signature) == 0 {
+		return fail(domain.StageAuthn, nil, fmt.Errorf("%w: missing certificate or signature", domain.ErrInvalidCertificate), nil)
+	}
+	if in.PublicKeyAuthorized == "" {
+		return fail(domain.StageInput, nil, domain.ErrMissingPublicKey, nil)
+	}
+	keyFP, err := domain.FingerprintSHA256(in.PublicKeyAuthorized)
+	if err != nil {
+		return fail(domain.StageInput, nil, err, nil)
+	}
+	challenge := domain.RenewalChallenge{PublicKeyAuthorized: in.PublicKeyAuthorized, Time: in.Time}
+	if err := challenge.Check(now); err != nil {
+		return fail(domain.StageAuthn, nil, err, nil)
+	}
+
+	// 1) Proof of possession of a certificate this CA issued
+	serial, certFP, err := svc.Verify.VerifyPossession(ctx, in.Certificate, challenge.Bytes(), in.Signature, now)
+	if err != nil {
+		return fail(domain.StageAuthn, nil, err, nil)
+	}
+	from := map[string]string{"renewed_from": strconv.FormatUint(serial, 10)}
+
+	// 2) Lookup the identity it was issued to; revoked certificates do not renew
+	rec, err := svc.Certs.Get(ctx, serial)
+	if errors.Is(err, domain.ErrCertNotFound) {
+		err = fmt.Errorf("%w: serial %d not recorded", domain.ErrInvalidCertificate, serial)
+	}
+	if err != nil {
+		return fail(domain.StageAuthn, nil, err, from)
+	}
+	if rec.KeyFP != "" && rec.KeyFP != certFP {
+		return fail(domain.StageAuthn, nil, fmt.Errorf("%w: key does not match serial %d", domain.ErrInvalidCertificate, serial), from)
+	}
+	id = rec.Identity()
+	revs, err := svc.Certs.Revocations(ctx)
+	if err != nil {
+		return fail(domain.StageAuthn, nil, err, from)
+	}
+	for _, rev := range revs {
+		if rev.Matches(rec) {
+			return fail(domain.StageAuthn, nil, fmt.Errorf("%w: revoked (%s)", domain.ErrInvalidCertificate, rev.Kind), from)
+		}
+	}
+
+	// 3) Session: renewal stops MaxSession after the original IdP login
+	end := domain.SessionEnd(rec.AuthTime, svc.MaxSession)
+	if end.IsZero() || !now.Before(end) {
+		return fail(domain.StageAuthn, nil, domain.ErrSessionExpired, from)
+	}
+
+	// 4) Blocklist: the new key, and the key of the certificate being renewed
+	if svc.Block != nil {
+		fps := []string{keyFP}
+		if certFP != keyFP {
+			fps = append(fps, certFP)
+		}
+		for _, fp := range fps {
+			if err := svc.Block.Check(ctx, id.Subject, fp); err != nil {
+				var be domain.BlockedError
+				if errors.As(err, &be) {
+					attrs := domain.BlockAttrs(be)
+					attrs["renewed_from"] = from["renewed_from"]
+					return fail(domain.StageBlocklist, nil, err, attrs)
+				}
+				return fail(domain.StageBlocklist, nil, err, from)
+			}
+		}
+	}
+
+	// 5) Re-evaluate policy for the recorded identity
+	dec, err := svc.Authz.Decide(id, signCtx)
+	if err != nil {
+		return fail(domain.StageAuthz, nil, err, from)
+	}
+
+	// 6) Serial and spec, clamped to the session end
+	newSerial, err := svc.Seq.Next(ctx)
+	if err != nil {
+		return fail(domain.StagePolicy, dec.Principals, err, from)
+	}
+	keyID := domain.ComposeKeyID(id, newSerial)
+	spec, err := domain.BuildCertSpec(id, dec, svc.TTL, svc.Clock, keyID)
+	if err != nil {
+		return fail(domain.StagePolicy, dec.Principals, err, from)
+	}
+	if spec.ValidBefore.After(end) {
+		spec.ValidBefore = end
+	}
+	spec.PublicKeyAuthorized = in.PublicKeyAuthorized
+
+	// 7) Sign
+	cert, fp, err := svc.Signer.Sign(spec, newSerial)
+	if err != nil {
+		return fail(domain.StageSign, dec.Principals, err, from)
+	}
+
+	// 8) Audit success; an unaudited certificate is never handed out or recorded
+	attrs := issueAttrs(id, rec.AuthTime, dec, keyID, fp)
+	attrs["renewed_from"] = from["renewed_from"]
+	if err := requireAudit(ctx, svc.Audit, svc.Log, domain.NewAuditSuccess(domain.ActionRenewUserCert, id, dec.Principals, newSerial, spec.ValidAfter, spec.ValidBefore, signCtx, attrs)); err != nil {
+		return RenewUserOutput{}, err
+	}
+
+	// 9) Record it, carrying the identity and original login time forward
+	newRec := domain.CertRecord{
+		Serial:     newSerial,
+		KeyID:      keyID,
+		Principals: spec.Principals,
+		NotBefore:  spec.ValidAfter,
+		NotAfter:   spec.ValidBefore,
+		KeyFP:      keyFP,
+		RequestIP:  in.SourceIP,
+	}
+	newRec.SetIdentity(id, rec.AuthTime)
+	if err := svc.Certs.Put(ctx, newRec); err != nil {
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditWithheld(domain.ActionRenewUserCert, domain.StageSign, id, dec.Principals, newSerial, signCtx, err, from))
+		return RenewUserOutput{}, err
+	}
+
+	if svc.Log != nil {
+		svc.Log.Info(ctx, "renewed user cert", "serial", newSerial, "renewed_from", serial, "principals", dec.Principals, "na", spec.ValidBefore, "session_end", end)
+	}
+
+	return RenewUserOutput{
+		SignUserOutput: SignUserOutput{
+			Serial:        newSerial,
+			Certificate:   cert,
+			NotBefore:     spec.ValidAfter,
+			NotAfter:      spec.ValidBefore,
+			Principals:    dec.Principals,
+			KeyID:         keyID,
+			CAFingerprint: fp,
+		},
+		RenewedFrom: serial,
+		AuthTime:    rec.AuthTime,
+		SessionEnd:  end,
+	}, nil
+}
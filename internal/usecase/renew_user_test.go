@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+const renewPub = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC+r2/7FDwBaKPXDUw7UoDa7RXGrXPD9oXeUHCOmZ3oT"
+
+// fakeVerifier accepts any proof over the expected message for serial.
+type fakeVerifier struct {
+	serial uint64
+	keyFP  string
+	err    error
+	msg    []byte
+}
+
+func (f *fakeVerifier) VerifyPossession(ctx context.Context, cert string, msg, sig []byte, now time.Time) (uint64, string, error) {
+	f.msg = msg
+	return f.serial, f.keyFP, f.err
+}
+
+// captureAuthz records the identity policy was evaluated for.
+type captureAuthz struct {
+	fakeAuthz
+	got domain.Identity
+}
+
+func (c *captureAuthz) Decide(id domain.Identity, ctx domain.SignContext) (domain.PolicyDecision, error) {
+	c.got = id
+	return c.dec, c.err
+}
+
+type renewFixture struct {
+	svc    *RenewUserService
+	certs  *fakeCerts
+	verify *fakeVerifier
+	authz  *captureAuthz
+	audit  *sink
+	now    time.Time
+	login  time.Time
+}
+
+func newRenewFixture() renewFixture {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	login := now.Add(-10 * time.Hour)
+	certs := &fakeCerts{}
+	rec := domain.CertRecord{Serial: 7, KeyID: "7|sub|alice", Principals: []string{"alice"}, NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour), KeyFP: "SHA256:old"}
+	rec.SetIdentity(domain.Identity{Subject: "sub", Username: "alice", Groups: []string{"ssh-users"}, Provider: "oidc:entra", Claims: map[string]any{"iss": "https://idp", "amr": []string{"mfa"}}}, login)
+	_ = certs.Put(context.Background(), rec)
+	f := renewFixture{
+		certs:  certs,
+		verify: &fakeVerifier{serial: 7, keyFP: "SHA256:old"},
+		authz:  &captureAuthz{fakeAuthz: fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: 4 * time.Hour}}},
+		audit:  &sink{},
+		now:    now,
+		login:  login,
+	}
+	f.svc = NewRenewUserService(RenewUserService{
+		Log:        nolog{},
+		Verify:     f.verify,
+		Authz:      f.authz,
+		Seq:        &fakeSeq{v: 100},
+		Signer:     fakeSigner{cert: []byte("cert"), fp: "SHA256:ca"},
+		Certs:      certs,
+		Audit:      f.audit,
+		Clock:      fakeClock{t: now},
+		TTL:        domain.TTL{Default: time.Hour, Max: 8 * time.Hour},
+		MaxSession: 12 * time.Hour,
+	})
+	return f
+}
+
+func (f renewFixture) input() RenewUserInput {
+	return RenewUserInput{Certificate: "cert", PublicKeyAuthorized: renewPub, Time: f.now, Signature: []byte("sig"), SourceIP: "1.2.3.4", TraceID: "trace"}
+}
+
+func TestRenewUser_Success(t *testing.T) {
+	f := newRenewFixture()
+	out, err := f.svc.Execute(context.Background(), f.input())
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if string(f.verify.msg) != string(domain.RenewalChallenge{PublicKeyAuthorized: renewPub, Time: f.now}.Bytes()) {
+		t.Fatalf("verified message %q", f.verify.msg)
+	}
+	// Policy sees the recorded identity, including how and when the user logged in.
+	if id := f.authz.got; id.Subject != "sub" || !slices.Equal(id.Groups, []string{"ssh-users"}) || !slices.Equal(id.AMR(), []string{"mfa"}) || !id.AuthTime().Equal(f.login) {
+		t.Fatalf("policy identity=%+v", id)
+	}
+	// 4h requested by policy, but the session ends 2h from now.
+	end := f.login.Add(12 * time.Hour)
+	if out.Serial != 101 || out.RenewedFrom != 7 || !out.NotAfter.Equal(end) || !out.SessionEnd.Equal(end) || !out.AuthTime.Equal(f.login) {
+		t.Fatalf("output=%+v", out)
+	}
+	rec := f.certs.recs[101]
+	if rec.Subject != "sub" || rec.Username != "alice" || rec.PluginAuth != "oidc:entra" || !rec.AuthTime.Equal(f.login) ||
+		rec.KeyFP != "SHA256:7q/0GrbmHLAri6PXTvduejHcNDqTxixEPm1DESBYX7g" || rec.RequestIP != "1.2.3.4" {
+		t.Fatalf("record=%+v", rec)
+	}
+	ev := f.audit.last
+	if ev.Action != domain.ActionRenewUserCert || !ev.Success() || ev.Attrs["renewed_from"] != "7" ||
+		ev.Attrs["auth_time"] != f.login.Format(time.RFC3339) || ev.Attrs["auth_provider"] != "oidc:entra" {
+		t.Fatalf("audit=%+v", ev)
+	}
+
+	// The renewed certificate renews in turn, still bounded by the first login.
+	f.verify.serial, f.verify.keyFP = 101, rec.KeyFP
+	out, err = f.svc.Execute(context.Background(), f.input())
+	if err != nil || out.RenewedFrom != 101 || !out.AuthTime.Equal(f.login) {
+		t.Fatalf("second renewal: %+v, %v", out, err)
+	}
+}
+
+func TestRenewUser_Refused(t *testing.T) {
+	cases := map[string]struct {
+		mutate func(f *renewFixture, in *RenewUserInput)
+		want   error
+		stage  domain.AuditStage
+	}{
+		"no signature":    {func(f *renewFixture, in *RenewUserInput) { in.Signature = nil }, domain.ErrInvalidCertificate, domain.StageAuthn},
+		"no key":          {func(f *renewFixture, in *RenewUserInput) { in.PublicKeyAuthorized = "" }, domain.ErrMissingPublicKey, domain.StageInput},
+		"stale challenge": {func(f *renewFixture, in *RenewUserInput) { in.Time = f.now.Add(-time.Hour) }, domain.ErrInvalidCertificate, domain.StageAuthn},
+		"bad proof": {func(f *renewFixture, in *RenewUserInput) {
+			f.verify.err = domain.ErrInvalidCertificate
+		}, domain.ErrInvalidCertificate, domain.StageAuthn},
+		"not recorded": {func(f *renewFixture, in *RenewUserInput) { f.verify.serial = 8 }, domain.ErrInvalidCertificate, domain.StageAuthn},
+		"key mismatch": {func(f *renewFixture, in *RenewUserInput) { f.verify.keyFP = "SHA256:other" }, domain.ErrInvalidCertificate, domain.StageAuthn},
+		"revoked": {func(f *renewFixture, in *RenewUserInput) {
+			_ = f.certs.Revoke(context.Background(), domain.Revocation{Kind: domain.RevokeSubject, Subject: "sub"})
+		}, domain.ErrInvalidCertificate, domain.StageAuthn},
+		"session over": {func(f *renewFixture, in *RenewUserInput) { f.svc.MaxSession = 10 * time.Hour }, domain.ErrSessionExpired, domain.StageAuthn},
+		"disabled":     {func(f *renewFixture, in *RenewUserInput) { f.svc.MaxSession = 0 }, domain.ErrSessionExpired, domain.StageAuthn},
+		"not renewable": {func(f *renewFixture, in *RenewUserInput) {
+			rec := f.certs.recs[7]
+			rec.AuthTime = time.Time{}
+			f.certs.recs[7] = rec
+		}, domain.ErrSessionExpired, domain.StageAuthn},
+		"blocked": {func(f *renewFixture, in *RenewUserInput) {
+			block := &fakeBlock{}
+			_ = block.Add(context.Background(), domain.BlockEntry{Kind: domain.BlockSubject, Value: "sub"})
+			f.svc.Block = block
+		}, domain.ErrBlocked, domain.StageBlocklist},
+		"old key blocked": {func(f *renewFixture, in *RenewUserInput) {
+			block := &fakeBlock{}
+			_ = block.Add(context.Background(), domain.BlockEntry{Kind: domain.BlockKey, Value: "SHA256:old"})
+			f.svc.Block = block
+		}, domain.ErrBlocked, domain.StageBlocklist},
+		"policy now denies": {func(f *renewFixture, in *RenewUserInput) { f.authz.err = domain.ErrPolicyDenied }, domain.ErrPolicyDenied, domain.StageAuthz},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newRenewFixture()
+			in := f.input()
+			tc.mutate(&f, &in)
+			if _, err := f.svc.Execute(context.Background(), in); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if len(f.certs.recs) != 1 {
+				t.Fatalf("certificate recorded on failure")
+			}
+			if ev := f.audit.last; ev.Success() || ev.Action != domain.ActionRenewUserCert || ev.Stage != tc.stage {
+				t.Fatalf("audit=%+v", ev)
+			}
+		})
+	}
+}
+
+func TestRenewUser_RecordFailureAfterAudit(t *testing.T) {
+	f := newRenewFixture()
+	f.certs.putErr = errors.New("store down")
+	if _, err := f.svc.Execute(context.Background(), f.input()); err == nil {
+		t.Fatalf("expected the renewal to fail")
+	}
+	if len(f.audit.events) != 2 || !f.audit.events[0].Success() {
+		t.Fatalf("expected success then withheld events, got %+v", f.audit.events)
+	}
+	if ev := f.audit.last; ev.Success() || ev.Attrs["withheld"] != "after_audit" || ev.Attrs["withheld_serial"] != strconv.FormatUint(*f.audit.events[0].Serial, 10) || ev.Attrs["renewed_from"] != "7" {
+		t.Fatalf("withheld event=%+v", ev)
+	}
+}
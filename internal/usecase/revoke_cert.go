@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+// RevokeCertInput carries the normalized inputs for revoking a certificate.
+type RevokeCertInput struct {
+	Bearer   string
+	Serial   uint64
+	Kind     domain.RevocationKind // what to revoke, derived from the serial's record
+	Subject  string                // RevokeSubject without a serial: the subject to revoke
+	KeyFP    string                // RevokeKey without a serial: the key to revoke ("SHA256:...")
+	Reason   string
+	SourceIP string
+	TraceID  string
+}
+
+// RevokeCertOutput is the persisted revocation.
+type RevokeCertOutput struct {
+	Revocation domain.Revocation
+}
+
+// RevokeCertService orchestrates AuthN -> Admin AuthZ -> Lookup -> Revoke -> Audit -> Block.
+type RevokeCertService struct {
+	Log   Logger
+	Auth  Authenticator
+	Admin AdminAuthorizer
+	Certs CertStore
+	Block Blocklist // optional; key/subject revocations also bar re-issuance
+	Audit AuditSink
+	Clock Clock
+}
+
+func NewRevokeCertService(deps RevokeCertService) *RevokeCertService { return &deps }
+
+// Execute revokes the certificate identified by in.Serial (or, depending on in.Kind,
+// its KeyID, subject or public key) and records an audit event. Subject and key
+// revocations may name in.Subject or in.KeyFP instead of a serial.
+func (svc *RevokeCertService) Execute(ctx context.Context, in RevokeCertInput) (RevokeCertOutput, error) {
+	now := svc.Clock.Now()
+	signCtx := domain.SignContext{
+		SourceIP: in.SourceIP,
+		Now:      now,
+		TraceID:  in.TraceID,
+	}
+	fail := func(stage domain.AuditStage, id domain.Identity, err error) (RevokeCertOutput, error) {
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionRevokeCert, stage, id, nil, signCtx, err, nil))
+		return RevokeCertOutput{}, err
+	}
+
+	if in.Bearer == "" {
+		return fail(domain.StageAuthn, domain.Identity{}, fmt.Errorf("%w: missing bearer", domain.ErrInvalidToken))
+	}
+	direct := (in.Kind == domain.RevokeSubject && in.Subject != "") || (in.Kind == domain.RevokeKey && in.KeyFP != "")
+	if in.Serial == 0 && !direct {
+		return fail(domain.StageInput, domain.Identity{}, fmt.Errorf("%w: missing serial", domain.ErrInvalidRevocation))
+	}
+
+	// 1) Authenticate
+	admin, err := svc.Auth.Authenticate(ctx, in.Bearer)
+	if err != nil {
+		return fail(domain.StageAuthn, domain.Identity{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err))
+	}
+
+	// 2) Admin only
+	if err := svc.Admin.AuthorizeAdmin(admin); err != nil {
+		return fail(domain.StageAuthz, admin, err)
+	}
+
+	// 3) Lookup the issued certificate, unless the subject or key is named directly
+	rec := domain.CertRecord{Subject: in.Subject, KeyFP: in.KeyFP}
+	if in.Serial != 0 {
+		if rec, err = svc.Certs.Get(ctx, in.Serial); err != nil {
+			return fail(domain.StageRevoke, admin, err)
+		}
+	}
+
+	// 4) Persist the revocation
+	kind := in.Kind
+	if kind == "" {
+		kind = domain.RevokeSerial
+	}
+	rev, err := domain.NewRevocation(kind, rec, admin, in.Reason, now)
+	if err != nil {
+		return fail(domain.StageInput, admin, err)
+	}
+	if err := svc.Certs.Revoke(ctx, rev); err != nil {
+		return fail(domain.StageRevoke, admin, err)
+	}
+
+	// 5) Audit success: the revocation stands from here on
+	recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditRevocation(admin, rec, rev, signCtx))
+
+	// 6) Key/subject revocations also block re-issuance. A failure here is
+	// audited on its own; it does not undo or fail the revocation.
+	if svc.Block != nil {
+		if entry, ok := domain.BlockEntryFromRevocation(rev); ok {
+			if err := svc.Block.Add(ctx, entry); err != nil {
+				recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionBlocklistAdd, domain.StageBlocklist, admin, nil, signCtx, err,
+					map[string]string{"revoke_kind": string(rev.Kind)}))
+				if svc.Log != nil {
+					svc.Log.Error(ctx, "revoked cert but could not blocklist it", "kind", rev.Kind, "error", err)
+				}
+			}
+		}
+	}
+
+	if svc.Log != nil {
+		svc.Log.Info(ctx, "revoked cert", "serial", rec.Serial, "kind", rev.Kind, "revoked_by", admin.Subject)
+	}
+	return RevokeCertOutput{Revocation: rev}, nil
+}
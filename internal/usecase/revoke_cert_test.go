@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haukened/kamini/internal/domain"
+)
+
+type fakeAdmin struct{ err error }
+
+func (f fakeAdmin) AuthorizeAdmin(id domain.Identity) error { return f.err }
+
+type fakeCerts struct {
+	recs   map[uint64]domain.CertRecord
+	revs   []domain.Revocation
+	putErr error
+}
+
+func (f *fakeCerts) Put(ctx context.Context, rec domain.CertRecord) error {
+	if f.putErr != nil {
+		return f.putErr
+	}
+	if f.recs == nil {
+		f.recs = map[uint64]domain.CertRecord{}
+	}
+	f.recs[rec.Serial] = rec
+	return nil
+}
+
+func (f *fakeCerts) Get(ctx context.Context, serial uint64) (domain.CertRecord, error) {
+	rec, ok := f.recs[serial]
+	if !ok {
+		return domain.CertRecord{}, domain.ErrCertNotFound
+	}
+	return rec, nil
+}
+
+func (f *fakeCerts) List(ctx context.Context, flt domain.CertFilter) ([]domain.CertRecord, error) {
+	var out []domain.CertRecord
+	for _, rec := range f.recs {
+		if flt.Match(rec) {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeCerts) Revoke(ctx context.Context, rev domain.Revocation) error {
+	f.revs = append(f.revs, rev)
+	return nil
+}
+
+func (f *fakeCerts) Revocations(ctx context.Context) ([]domain.Revocation, error) {
+	return f.revs, nil
+}
+
+func newRevokeSvc(auth Authenticator, admin AdminAuthorizer, certs CertStore, aud AuditSink) *RevokeCertService {
+	return NewRevokeCertService(RevokeCertService{
+		Log:   nolog{},
+		Auth:  auth,
+		Admin: admin,
+		Certs: certs,
+		Audit: aud,
+		Clock: fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+	})
+}
+
+func TestRevokeCert_Success(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	certs := &fakeCerts{recs: map[uint64]domain.CertRecord{
+		7: {Serial: 7, KeyID: "7|sub|alice", Subject: "sub", KeyFP: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE", NotBefore: now.Add(-time.Minute), NotAfter: now.Add(time.Hour)},
+	}}
+	aud := &sink{}
+	svc := newRevokeSvc(fakeAuth{id: domain.Identity{Subject: "admin"}}, fakeAdmin{}, certs, aud)
+
+	out, err := svc.Execute(context.Background(), RevokeCertInput{Bearer: "t", Serial: 7, Kind: domain.RevokeKey, Reason: "stolen"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if out.Revocation.Kind != domain.RevokeKey || out.Revocation.KeyFP != "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE" || out.Revocation.RevokedBy != "admin" {
+		t.Fatalf("unexpected revocation: %+v", out.Revocation)
+	}
+	if len(certs.revs) != 1 {
+		t.Fatalf("expected persisted revocation, got %d", len(certs.revs))
+	}
+	if aud.last.Action != domain.ActionRevokeCert || !aud.last.Success() || aud.last.Subject != "sub" {
+		t.Fatalf("expected revoke success audit, got: %+v", aud.last)
+	}
+}
+
+func TestRevokeCert_NotAdmin(t *testing.T) {
+	certs := &fakeCerts{}
+	aud := &sink{}
+	svc := newRevokeSvc(fakeAuth{id: domain.Identity{Subject: "u"}}, fakeAdmin{err: domain.PolicyDeny{Code: domain.DenyRoleMissing}}, certs, aud)
+
+	_, err := svc.Execute(context.Background(), RevokeCertInput{Bearer: "t", Serial: 7})
+	var pd domain.PolicyDeny
+	if !errors.As(err, &pd) {
+		t.Fatalf("expected PolicyDeny, got %v", err)
+	}
+	if aud.last.Action != domain.ActionRevokeCert || aud.last.Stage != domain.StageAuthz || aud.last.Success() {
+		t.Fatalf("expected AUTHZ failure audit, got: %+v", aud.last)
+	}
+	if len(certs.revs) != 0 {
+		t.Fatalf("unexpected revocation persisted")
+	}
+}
+
+func TestRevokeCert_AuthnFail(t *testing.T) {
+	aud := &sink{}
+	svc := newRevokeSvc(fakeAuth{err: errors.New("expired")}, fakeAdmin{}, &fakeCerts{}, aud)
+	_, err := svc.Execute(context.Background(), RevokeCertInput{Bearer: "t", Serial: 7})
+	if !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+	if aud.last.Stage != domain.StageAuthn || aud.last.ErrorCode != domain.CodeInvalidToken {
+		t.Fatalf("expected AUTHN failure audit, got: %+v", aud.last)
+	}
+}
+
+func TestRevokeCert_NotFound(t *testing.T) {
+	aud := &sink{}
+	svc := newRevokeSvc(fakeAuth{id: domain.Identity{Subject: "admin"}}, fakeAdmin{}, &fakeCerts{}, aud)
+	_, err := svc.Execute(context.Background(), RevokeCertInput{Bearer: "t", Serial: 99})
+	if !errors.Is(err, domain.ErrCertNotFound) {
+		t.Fatalf("expected ErrCertNotFound, got %v", err)
+	}
+	if aud.last.Stage != domain.StageRevoke || aud.last.ErrorCode != domain.CodeCertNotFound {
+		t.Fatalf("expected REVOKE failure audit, got: %+v", aud.last)
+	}
+}
+
+func TestRevokeCert_BlocksKeyAndSubject(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	rec := domain.CertRecord{Serial: 7, KeyID: "k", Subject: "sub", KeyFP: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE", NotBefore: now, NotAfter: now.Add(time.Hour)}
+	for kind, want := range map[domain.RevocationKind]int{domain.RevokeSerial: 0, domain.RevokeKey: 1, domain.RevokeSubject: 1} {
+		block := &fakeBlock{}
+		svc := newRevokeSvc(fakeAuth{id: domain.Identity{Subject: "admin"}}, fakeAdmin{}, &fakeCerts{recs: map[uint64]domain.CertRecord{7: rec}}, &sink{})
+		svc.Block = block
+		if _, err := svc.Execute(context.Background(), RevokeCertInput{Bearer: "t", Serial: 7, Kind: kind}); err != nil {
+			t.Fatalf("%s: Execute: %v", kind, err)
+		}
+		if got, _ := block.List(context.Background()); len(got) != want {
+			t.Fatalf("%s: blocklist entries=%d, want %d", kind, len(got), want)
+		}
+	}
+}
+
+func TestRevokeCert_WithoutSerial(t *testing.T) {
+	admin := fakeAuth{id: domain.Identity{Subject: "admin"}}
+	certs, aud, block := &fakeCerts{}, &sink{}, &fakeBlock{}
+	svc := newRevokeSvc(admin, fakeAdmin{}, certs, aud)
+	svc.Block = block
+	out, err := svc.Execute(context.Background(), RevokeCertInput{Bearer: "t", Kind: domain.RevokeKey, KeyFP: "SHA256:gj2Vqaco8U1ibfmolHBd2idBe4knQSNkJVtRXp8qwuI"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if out.Revocation.KeyFP != "SHA256:gj2Vqaco8U1ibfmolHBd2idBe4knQSNkJVtRXp8qwuI" || len(certs.revs) != 1 || aud.last.Serial != nil || !aud.last.Success() {
+		t.Fatalf("revocation=%+v audit=%+v", out.Revocation, aud.last)
+	}
+	if got, _ := block.List(context.Background()); len(got) != 1 {
+		t.Fatalf("blocklist entries=%d", len(got))
+	}
+
+	// Serial and KeyID revocations still need a certificate.
+	for _, in := range []RevokeCertInput{{Bearer: "t", Kind: domain.RevokeSerial}, {Bearer: "t", Kind: domain.RevokeKeyID}, {Bearer: "t", Kind: domain.RevokeSubject}} {
+		if _, err := svc.Execute(context.Background(), in); !errors.Is(err, domain.ErrInvalidRevocation) {
+			t.Fatalf("%s: expected ErrInvalidRevocation, got %v", in.Kind, err)
+		}
+	}
+}
+
+// brokenBlock fails every Add.
+type brokenBlock struct{ fakeBlock }
+
+func (brokenBlock) Add(ctx context.Context, e domain.BlockEntry) error {
+	return errors.New("disk full")
+}
+
+func TestRevokeCert_BlocklistFailureKeepsRevocation(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0).UTC()
+	certs := &fakeCerts{recs: map[uint64]domain.CertRecord{7: {Serial: 7, Subject: "sub", KeyFP: "SHA256:LXEWQrcmsEQBYnyp+6wy9chTD7GQPMTbAiWHF5IaSIE", NotAfter: now.Add(time.Hour)}}}
+	aud := &sink{}
+	svc := newRevokeSvc(fakeAuth{id: domain.Identity{Subject: "admin"}}, fakeAdmin{}, certs, aud)
+	svc.Block = &brokenBlock{}
+	if _, err := svc.Execute(context.Background(), RevokeCertInput{Bearer: "t", Serial: 7, Kind: domain.RevokeKey}); err != nil {
+		t.Fatalf("revocation reported as failed: %v", err)
+	}
+	if len(certs.revs) != 1 {
+		t.Fatalf("revocation not persisted")
+	}
+	if ev := aud.last; ev.Action != domain.ActionBlocklistAdd || ev.Success() || ev.Stage != domain.StageBlocklist {
+		t.Fatalf("expected separate blocklist failure audit, got %+v", ev)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/haukened/kamini/internal/domain"
@@ -29,22 +30,53 @@ type SignUserOutput struct {
 	CAFingerprint string // for logs/audit; adapters may ignore
 }
 
-// SignUserService orchestrates AuthN -> AuthZ -> Serial -> Spec -> Sign -> Audit.
+// SignUserService orchestrates AuthN -> Blocklist -> AuthZ -> Serial -> Spec -> Sign -> Audit -> Record.
+// With a Tracer, the request and each stage get a span named after it.
 type SignUserService struct {
 	Log    Logger
 	Auth   Authenticator
 	Authz  Authorizer
 	Seq    SerialStore
 	Signer Signer
+	Certs  CertStore // optional; when set, issued certs are recorded for revocation
+	Block  Blocklist // optional; when set, blocklisted keys/subjects are refused
 	Audit  AuditSink
 	Clock  Clock
 	TTL    domain.TTL // policy TTL (default, max)
+	Trace  Tracer     // optional; spans the request and each stage
 }
 
 func NewSignUserService(deps SignUserService) *SignUserService { return &deps }
 
 // Execute performs the end-to-end flow to issue a user certificate.
 func (svc *SignUserService) Execute(ctx context.Context, in SignUserInput) (SignUserOutput, error) {
+	ctx, span := svc.span(ctx, "kamini.sign_user")
+	if in.TraceID != "" {
+		span.SetAttr("kamini.trace_id", in.TraceID)
+	}
+	out, err := svc.execute(ctx, in)
+	if err == nil {
+		span.SetAttr("kamini.serial", out.Serial)
+		span.SetAttr("kamini.principals", len(out.Principals))
+	}
+	span.End(err)
+	return out, err
+}
+
+// span starts a child span when a Tracer is wired.
+func (svc *SignUserService) span(ctx context.Context, name string) (context.Context, Span) {
+	if svc.Trace == nil {
+		return ctx, noSpan{}
+	}
+	return svc.Trace.Start(ctx, name)
+}
+
+type noSpan struct{}
+
+func (noSpan) SetAttr(string, any) {}
+func (noSpan) End(error)           {}
+
+func (svc *SignUserService) execute(ctx context.Context, in SignUserInput) (SignUserOutput, error) {
 	now := svc.Clock.Now()
 	signCtx := domain.SignContext{
 		RequestedTTL: in.RequestedTTL,
@@ -56,56 +88,135 @@ func (svc *SignUserService) Execute(ctx context.Context, in SignUserInput) (Sign
 	// Basic input validation
 	if in.Bearer == "" {
 		err := errors.New("missing bearer")
-		_ = svc.Audit.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthn, domain.Identity{}, nil, signCtx, err, nil))
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthn, domain.Identity{}, nil, signCtx, err, nil))
 		return SignUserOutput{}, err
 	}
 	if in.PublicKeyAuthorized == "" {
-		_ = svc.Audit.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageInput, domain.Identity{}, nil, signCtx, domain.ErrMissingPublicKey, nil))
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageInput, domain.Identity{}, nil, signCtx, domain.ErrMissingPublicKey, nil))
 		return SignUserOutput{}, domain.ErrMissingPublicKey
 	}
 
 	// 1) Authenticate
-	id, err := svc.Auth.Authenticate(ctx, in.Bearer)
+	sctx, span := svc.span(ctx, "authn")
+	id, err := svc.Auth.Authenticate(sctx, in.Bearer)
+	span.End(err)
 	if err != nil {
-		_ = svc.Audit.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthn, domain.Identity{}, nil, signCtx, err, nil))
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthn, domain.Identity{}, nil, signCtx, err, nil))
 		return SignUserOutput{}, err
 	}
 
+	// 1b) Blocklist: refuse keys/subjects that were revoked or explicitly barred
+	var keyFP string
+	if svc.Block != nil {
+		sctx, span := svc.span(ctx, "blocklist")
+		keyFP, err = domain.FingerprintSHA256(in.PublicKeyAuthorized)
+		if err != nil {
+			span.End(err)
+			recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageInput, id, nil, signCtx, err, nil))
+			return SignUserOutput{}, err
+		}
+		err = svc.Block.Check(sctx, id.Subject, keyFP)
+		span.End(err)
+		if err != nil {
+			var attrs map[string]string
+			var be domain.BlockedError
+			if errors.As(err, &be) {
+				attrs = domain.BlockAttrs(be)
+			}
+			recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageBlocklist, id, nil, signCtx, err, attrs))
+			return SignUserOutput{}, err
+		}
+	}
+
 	// 2) Authorize / policy decision
+	_, span = svc.span(ctx, "authz")
 	dec, err := svc.Authz.Decide(id, signCtx)
+	if err == nil {
+		span.SetAttr("kamini.principals", len(dec.Principals))
+	}
+	span.End(err)
 	if err != nil {
-		_ = svc.Audit.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthz, id, nil, signCtx, err, nil))
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageAuthz, id, nil, signCtx, err, nil))
 		return SignUserOutput{}, err
 	}
 
 	// 3) Serial
-	serial, err := svc.Seq.Next(ctx)
+	sctx, span = svc.span(ctx, "serial")
+	serial, err := svc.Seq.Next(sctx)
+	if err == nil {
+		span.SetAttr("kamini.serial", serial)
+	}
+	span.End(err)
 	if err != nil {
-		_ = svc.Audit.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, id, dec.Principals, signCtx, err, nil))
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, id, dec.Principals, signCtx, err, nil))
 		return SignUserOutput{}, err
 	}
 
 	// 4) Build cert spec with TTL clamp and key ID
+	_, span = svc.span(ctx, "spec")
 	keyID := domain.ComposeKeyID(id, serial)
 	spec, err := domain.BuildCertSpec(id, dec, svc.TTL, svc.Clock, keyID)
+	span.End(err)
 	if err != nil {
-		_ = svc.Audit.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, id, dec.Principals, signCtx, err, nil))
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StagePolicy, id, dec.Principals, signCtx, err, nil))
 		return SignUserOutput{}, err
 	}
 	spec.PublicKeyAuthorized = in.PublicKeyAuthorized
 
 	// 5) Sign
+	_, span = svc.span(ctx, "sign")
+	span.SetAttr("kamini.serial", serial)
 	cert, fp, err := svc.Signer.Sign(spec, serial)
+	span.End(err)
 	if err != nil {
-		_ = svc.Audit.Write(ctx, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageSign, id, dec.Principals, signCtx, err, nil))
+		recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditFailure(domain.ActionIssueUserCert, domain.StageSign, id, dec.Principals, signCtx, err, nil))
 		return SignUserOutput{}, err
 	}
 
-	// 6) Audit success
-	_ = svc.Audit.Write(ctx, domain.NewAuditSuccess(domain.ActionIssueUserCert, id, dec.Principals, serial, spec.ValidAfter, spec.ValidBefore, signCtx, map[string]string{
-		"ca_fp":  fp,
-		"key_id": keyID,
-	}))
+	// 6) Audit success; an unaudited certificate is never handed out or recorded
+	attrs := issueAttrs(id, authTime(id, now), dec, keyID, fp)
+	if w, ok := id.Workload(); ok {
+		for k, v := range map[string]string{
+			"workload_repository":  w.Repository,
+			"workload_ref":         w.Ref,
+			"workload_environment": w.Environment,
+			"workload_workflow":    w.Workflow,
+		} {
+			if v != "" {
+				attrs[k] = v
+			}
+		}
+	}
+	sctx, span = svc.span(ctx, "audit")
+	err = requireAudit(sctx, svc.Audit, svc.Log, domain.NewAuditSuccess(domain.ActionIssueUserCert, id, dec.Principals, serial, spec.ValidAfter, spec.ValidBefore, signCtx, attrs))
+	span.End(err)
+	if err != nil {
+		return SignUserOutput{}, err
+	}
+
+	// 7) Record the issued certificate so it can be revoked later
+	if svc.Certs != nil {
+		if keyFP == "" {
+			keyFP, _ = domain.FingerprintSHA256(in.PublicKeyAuthorized)
+		}
+		rec := domain.CertRecord{
+			Serial:     serial,
+			KeyID:      keyID,
+			Principals: spec.Principals,
+			NotBefore:  spec.ValidAfter,
+			NotAfter:   spec.ValidBefore,
+			KeyFP:      keyFP,
+			RequestIP:  in.SourceIP,
+		}
+		rec.SetIdentity(id, authTime(id, now))
+		sctx, span := svc.span(ctx, "record")
+		err := svc.Certs.Put(sctx, rec)
+		span.End(err)
+		if err != nil {
+			recordAudit(ctx, svc.Audit, svc.Log, domain.NewAuditWithheld(domain.ActionIssueUserCert, domain.StageSign, id, dec.Principals, serial, signCtx, err, nil))
+			return SignUserOutput{}, err
+		}
+	}
 
 	if svc.Log != nil {
 		svc.Log.Info(ctx, "issued user cert", "serial", serial, "principals", dec.Principals, "nb", spec.ValidAfter, "na", spec.ValidBefore)
@@ -122,6 +233,39 @@ func (svc *SignUserService) Execute(ctx context.Context, in SignUserInput) (Sign
 	}, nil
 }
 
+// authTime is the IdP login time recorded for id: its auth_time claim, or now
+// when the token did not say. Workloads get zero, so they never renew.
+func authTime(id domain.Identity, now time.Time) time.Time {
+	if _, ok := id.Workload(); ok {
+		return time.Time{}
+	}
+	if t := id.AuthTime(); !t.IsZero() {
+		return t
+	}
+	return now
+}
+
+// issueAttrs returns the audit attributes of a successful issuance or renewal.
+func issueAttrs(id domain.Identity, authTime time.Time, dec domain.PolicyDecision, keyID, caFP string) map[string]string {
+	attrs := map[string]string{
+		"ca_fp":  caFP,
+		"key_id": keyID,
+	}
+	if id.Provider != "" {
+		attrs["auth_provider"] = id.Provider
+	}
+	if iss := id.Issuer(); iss != "" {
+		attrs["auth_issuer"] = iss
+	}
+	if !authTime.IsZero() {
+		attrs["auth_time"] = authTime.UTC().Format(time.RFC3339)
+	}
+	if len(dec.Withheld) > 0 {
+		attrs["withheld_principals"] = strings.Join(dec.Withheld, ",")
+	}
+	return attrs
+}
+
 // String returns a concise description useful in logs.
 func (svc SignUserService) String() string {
 	return fmt.Sprintf("signuser ttl=%s/%s", svc.TTL.Default, svc.TTL.Max)
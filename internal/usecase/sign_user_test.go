@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"errors"
+	"slices"
+	"strconv"
 	"testing"
 	"time"
 
@@ -54,9 +56,16 @@ func (f fakeSigner) Sign(spec domain.CertSpec, serial uint64) ([]byte, string, e
 	return f.cert, f.fp, f.err
 }
 
-type sink struct{ last domain.AuditEvent }
+type sink struct {
+	last   domain.AuditEvent
+	events []domain.AuditEvent
+}
 
-func (s *sink) Write(ctx context.Context, ev domain.AuditEvent) error { s.last = ev; return nil }
+func (s *sink) Write(ctx context.Context, ev domain.AuditEvent) error {
+	s.last = ev
+	s.events = append(s.events, ev)
+	return nil
+}
 
 type nolog struct{}
 
@@ -69,8 +78,8 @@ func (nolog) WithGroup(string) Logger               { return nolog{} }
 
 func TestSignUser_Success(t *testing.T) {
 	fc := fakeClock{t: time.Unix(1_700_000_000, 0).UTC()}
-	a := fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}}
-	az := fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}}
+	a := fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice", Provider: "oidc:entra", Claims: map[string]any{"iss": "https://login.example.com/v2.0"}}}
+	az := fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour, Withheld: []string{"prod-admin"}}}
 	seq := &fakeSeq{}
 	signer := fakeSigner{cert: []byte("ssh-ed25519-cert-v01@openssh.com AAAA"), fp: "SHA256:xyz"}
 	aud := &sink{}
@@ -109,8 +118,40 @@ func TestSignUser_Success(t *testing.T) {
 	if aud.last.Serial == nil || *aud.last.Serial != out.Serial {
 		t.Fatalf("expected serial in audit event")
 	}
-	if aud.last.Attrs == nil || aud.last.Attrs["key_id"] == "" || aud.last.Attrs["ca_fp"] != "SHA256:xyz" {
-		t.Fatalf("expected audit attrs with key_id and ca_fp, got: %+v", aud.last.Attrs)
+	if aud.last.Attrs == nil || aud.last.Attrs["key_id"] == "" || aud.last.Attrs["ca_fp"] != "SHA256:xyz" || aud.last.Attrs["auth_provider"] != "oidc:entra" ||
+		aud.last.Attrs["auth_issuer"] != "https://login.example.com/v2.0" {
+		t.Fatalf("expected audit attrs with key_id, ca_fp, auth_provider and auth_issuer, got: %+v", aud.last.Attrs)
+	}
+	if aud.last.Attrs["withheld_principals"] != "prod-admin" {
+		t.Fatalf("expected withheld principals in audit attrs, got: %+v", aud.last.Attrs)
+	}
+}
+
+func TestSignUser_WorkloadAudit(t *testing.T) {
+	w := domain.Workload{Platform: "github-actions", Repository: "org/app", Ref: "refs/heads/main", Workflow: ".github/workflows/deploy.yml"}
+	aud := &sink{}
+	svc := NewSignUserService(SignUserService{
+		Log:    nolog{},
+		Auth:   fakeAuth{id: domain.Identity{Subject: "repo:org/app:ref:refs/heads/main", Provider: "github-actions", Claims: map[string]any{"workload": w}}},
+		Authz:  fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"deploy"}, TTL: 10 * time.Minute}},
+		Seq:    &fakeSeq{},
+		Signer: fakeSigner{cert: []byte("cert"), fp: "SHA256:ca"},
+		Audit:  aud,
+		Clock:  fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+	})
+	if _, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	a := aud.last.Attrs
+	if a["workload_repository"] != "org/app" || a["workload_ref"] != "refs/heads/main" || a["workload_workflow"] != ".github/workflows/deploy.yml" {
+		t.Fatalf("expected workload audit attrs, got: %+v", a)
+	}
+	if _, ok := a["workload_environment"]; ok {
+		t.Fatalf("empty workload fields should be omitted: %+v", a)
+	}
+	if _, ok := a["auth_time"]; ok {
+		t.Fatalf("workloads have no renewable session: %+v", a)
 	}
 }
 
@@ -248,3 +289,216 @@ func TestSignUserService_String(t *testing.T) {
 		t.Errorf("String() = %q, want %q", got, want)
 	}
 }
+
+func TestSignUser_RecordsCert(t *testing.T) {
+	certs := &fakeCerts{}
+	svc := NewSignUserService(SignUserService{
+		Log:    nolog{},
+		Auth:   fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}},
+		Authz:  fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}},
+		Seq:    &fakeSeq{},
+		Signer: fakeSigner{cert: []byte("cert"), fp: "SHA256:ca"},
+		Certs:  certs,
+		Audit:  &sink{},
+		Clock:  fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+	})
+	out, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC+r2/7FDwBaKPXDUw7UoDa7RXGrXPD9oXeUHCOmZ3oT", SourceIP: "1.2.3.4"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	rec, ok := certs.recs[out.Serial]
+	if !ok {
+		t.Fatalf("expected record for serial %d", out.Serial)
+	}
+	if rec.Subject != "sub" || rec.KeyID != out.KeyID || rec.KeyFP != "SHA256:7q/0GrbmHLAri6PXTvduejHcNDqTxixEPm1DESBYX7g" || rec.RequestIP != "1.2.3.4" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	// Without an auth_time claim the login is taken to be now; renewal needs it.
+	if rec.Username != "alice" || !rec.AuthTime.Equal(time.Unix(1_700_000_000, 0)) {
+		t.Fatalf("expected identity snapshot in record: %+v", rec)
+	}
+}
+
+func TestSignUser_RecordFail(t *testing.T) {
+	aud := &sink{}
+	svc := NewSignUserService(SignUserService{
+		Log:    nolog{},
+		Auth:   fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}},
+		Authz:  fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}},
+		Seq:    &fakeSeq{},
+		Signer: fakeSigner{cert: []byte("cert"), fp: "SHA256:ca"},
+		Certs:  &fakeCerts{putErr: errors.New("disk full")},
+		Audit:  aud,
+		Clock:  fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+	})
+	if _, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"}); err == nil {
+		t.Fatalf("expected error when cert record cannot be stored")
+	}
+	if aud.last.Success() || aud.last.Stage != domain.StageSign {
+		t.Fatalf("expected SIGN failure audit, got: %+v", aud.last)
+	}
+}
+
+func TestSignUser_Blocklisted(t *testing.T) {
+	const pub = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC+r2/7FDwBaKPXDUw7UoDa7RXGrXPD9oXeUHCOmZ3oT"
+	cases := []struct {
+		name  string
+		entry domain.BlockEntry
+	}{
+		{"key", domain.BlockEntry{Kind: domain.BlockKey, Value: "SHA256:7q/0GrbmHLAri6PXTvduejHcNDqTxixEPm1DESBYX7g"}},
+		{"subject", domain.BlockEntry{Kind: domain.BlockSubject, Value: "sub"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			block := &fakeBlock{}
+			_ = block.Add(context.Background(), tc.entry)
+			seq := &fakeSeq{}
+			aud := &sink{}
+			svc := NewSignUserService(SignUserService{
+				Log:    nolog{},
+				Auth:   fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}},
+				Authz:  fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}},
+				Seq:    seq,
+				Signer: fakeSigner{cert: []byte("cert")},
+				Block:  block,
+				Audit:  aud,
+				Clock:  fakeClock{t: time.Now().UTC()},
+				TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+			})
+			_, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: pub})
+			if !errors.Is(err, domain.ErrBlocked) {
+				t.Fatalf("expected ErrBlocked, got %v", err)
+			}
+			if aud.last.Stage != domain.StageBlocklist || aud.last.ErrorCode != domain.CodeBlocklisted || aud.last.Attrs["block_kind"] != string(tc.entry.Kind) {
+				t.Fatalf("expected BLOCKLIST failure audit, got: %+v", aud.last)
+			}
+			if seq.v != 0 {
+				t.Fatalf("serial must not be allocated for blocked requests")
+			}
+		})
+	}
+}
+
+type failSink struct{ err error }
+
+func (f failSink) Write(context.Context, domain.AuditEvent) error { return f.err }
+
+func TestSignUser_AuditRequired(t *testing.T) {
+	certs := &fakeCerts{}
+	svc := NewSignUserService(SignUserService{
+		Certs:  certs,
+		Log:    nolog{},
+		Auth:   fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}},
+		Authz:  fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}},
+		Seq:    &fakeSeq{},
+		Signer: fakeSigner{cert: []byte("cert"), fp: "SHA256:ca"},
+		Audit:  failSink{err: errors.New("audit disk full")},
+		Clock:  fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+	})
+	out, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"})
+	if !errors.Is(err, domain.ErrAuditUnavailable) {
+		t.Fatalf("expected ErrAuditUnavailable, got %v", err)
+	}
+	if out.Certificate != nil {
+		t.Fatalf("certificate must be withheld when the issuance cannot be audited")
+	}
+	if len(certs.recs) != 0 {
+		t.Fatalf("unaudited certificate was recorded: %v", certs.recs)
+	}
+}
+
+func TestSignUser_RecordFailureAfterAudit(t *testing.T) {
+	aud := &sink{}
+	svc := NewSignUserService(SignUserService{
+		Certs:  &fakeCerts{putErr: errors.New("store down")},
+		Log:    nolog{},
+		Auth:   fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}},
+		Authz:  fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}},
+		Seq:    &fakeSeq{},
+		Signer: fakeSigner{cert: []byte("cert"), fp: "SHA256:ca"},
+		Audit:  aud,
+		Clock:  fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+	})
+	out, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"})
+	if err == nil || out.Certificate != nil {
+		t.Fatalf("expected the certificate to be withheld, got %+v, %v", out, err)
+	}
+	if len(aud.events) != 2 || !aud.events[0].Success() || aud.events[0].Serial == nil {
+		t.Fatalf("expected success then withheld events, got %+v", aud.events)
+	}
+	withheld := aud.events[1]
+	if withheld.Success() || withheld.Attrs["withheld"] != "after_audit" || withheld.Attrs["withheld_serial"] != strconv.FormatUint(*aud.events[0].Serial, 10) {
+		t.Fatalf("expected a withheld event naming serial %d, got %+v", *aud.events[0].Serial, withheld)
+	}
+}
+
+// fakeTracer records spans in the order they end.
+type fakeTracer struct{ ended []*fakeSpan }
+
+type fakeSpan struct {
+	t     *fakeTracer
+	name  string
+	attrs map[string]any
+	err   error
+}
+
+func (f *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, &fakeSpan{t: f, name: name, attrs: map[string]any{}}
+}
+
+func (s *fakeSpan) SetAttr(key string, value any) { s.attrs[key] = value }
+func (s *fakeSpan) End(err error)                 { s.err = err; s.t.ended = append(s.t.ended, s) }
+
+func TestSignUser_Traced(t *testing.T) {
+	tr := &fakeTracer{}
+	svc := NewSignUserService(SignUserService{
+		Log:    nolog{},
+		Auth:   fakeAuth{id: domain.Identity{Subject: "sub", Username: "alice"}},
+		Authz:  fakeAuthz{dec: domain.PolicyDecision{Principals: []string{"alice"}, TTL: time.Hour}},
+		Seq:    &fakeSeq{},
+		Signer: fakeSigner{cert: []byte("cert"), fp: "SHA256:ca"},
+		Audit:  &sink{},
+		Clock:  fakeClock{t: time.Unix(1_700_000_000, 0).UTC()},
+		TTL:    domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+		Trace:  tr,
+	})
+	if _, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA", TraceID: "req-1"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var names []string
+	for _, s := range tr.ended {
+		names = append(names, s.name)
+	}
+	want := []string{"authn", "authz", "serial", "spec", "sign", "audit", "kamini.sign_user"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("spans=%v want %v", names, want)
+	}
+	root := tr.ended[len(tr.ended)-1]
+	if root.err != nil || root.attrs["kamini.serial"] != uint64(1) || root.attrs["kamini.trace_id"] != "req-1" {
+		t.Fatalf("root span: %+v", root)
+	}
+}
+
+func TestSignUser_TracedFailure(t *testing.T) {
+	tr := &fakeTracer{}
+	deny := domain.PolicyDeny{Code: domain.DenyDefault}
+	svc := NewSignUserService(SignUserService{
+		Log:   nolog{},
+		Auth:  fakeAuth{id: domain.Identity{Subject: "s"}},
+		Authz: fakeAuthz{err: deny},
+		Audit: &sink{},
+		Clock: fakeClock{t: time.Now().UTC()},
+		TTL:   domain.TTL{Default: time.Hour, Max: 2 * time.Hour},
+		Trace: tr,
+	})
+	if _, err := svc.Execute(context.Background(), SignUserInput{Bearer: "t", PublicKeyAuthorized: "ssh-ed25519 AAAA"}); err == nil {
+		t.Fatalf("expected deny error")
+	}
+	if len(tr.ended) != 3 || tr.ended[1].name != "authz" || !errors.Is(tr.ended[1].err, deny) || tr.ended[2].err == nil {
+		t.Fatalf("unexpected spans: %+v", tr.ended)
+	}
+}